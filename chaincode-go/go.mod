@@ -1,9 +0,0 @@
-module github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go
-
-go 1.18
-
-require (
-	github.com/hyperledger/fabric-chaincode-go v0.0.0-20220720122508-9207360bbddd
-	github.com/hyperledger/fabric-contract-api-go v1.2.1
-	github.com/stretchr/testify v1.8.4
-)
\ No newline at end of file
@@ -0,0 +1,224 @@
+// Package analyzer runs off-chain tamper analysis of evidence files and
+// submits the results to the evidence contract, signed with the key the
+// analyzer was registered with. The contract only accepts results from
+// registered analyzers whose signatures verify.
+package analyzer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// Severities of a finding
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Request identifies an evidence file to analyze and what the ledger records
+// about it
+type Request struct {
+	EvidenceID string // ID of the evidence
+	FileHash   string // File hash recorded on the ledger
+	Metadata   string // Metadata JSON recorded on the ledger
+	Path       string // Local path of the evidence file
+}
+
+// Finding is one observation made during an analysis
+type Finding struct {
+	Check    string  `json:"check"`    // Name of the check that made the finding
+	Severity string  `json:"severity"` // info, warning or critical
+	Detail   string  `json:"detail"`   // Human readable explanation
+	Weight   float64 `json:"weight"`   // Contribution to the tamper probability (0-1)
+}
+
+// Report is the outcome of analyzing one evidence file
+type Report struct {
+	EvidenceID        string    `json:"evidenceID"`
+	FileHash          string    `json:"fileHash"`
+	TamperProbability float64   `json:"tamperProbability"`
+	Findings          []Finding `json:"findings"`
+}
+
+// Analyzer examines evidence files for signs of tampering
+type Analyzer interface {
+	Analyze(ctx context.Context, request *Request) (*Report, error)
+}
+
+// Combine returns the tamper probability of a set of findings, treating each
+// weight as the independent probability that its finding indicates tampering
+func Combine(findings []Finding) float64 {
+	untampered := 1.0
+	for _, finding := range findings {
+		untampered *= 1 - math.Min(math.Max(finding.Weight, 0), 1)
+	}
+	return 1 - untampered
+}
+
+// Statement is the content of an analysis result signed by the analyzer. It
+// must match AnalysisStatement in the chaincode field for field, in the same
+// order, because the signature covers its JSON encoding.
+type Statement struct {
+	AnalyzerID        string  `json:"AnalyzerID"`
+	EvidenceID        string  `json:"EvidenceID"`
+	FileHash          string  `json:"FileHash"`
+	TamperProbability float64 `json:"TamperProbability"`
+	AnalysisDetails   string  `json:"AnalysisDetails"`
+	AnalyzedAt        string  `json:"AnalyzedAt"`
+}
+
+// Attestation is a statement with the analyzer's signature, in the form the
+// SubmitAIAnalysis transaction takes
+type Attestation struct {
+	Statement Statement `json:"Statement"`
+	Signature string    `json:"Signature"`
+}
+
+// Signer signs analysis reports with an analyzer's private key
+type Signer struct {
+	analyzerID string
+	key        *ecdsa.PrivateKey
+}
+
+// NewSigner creates a signer for the analyzer registered under analyzerID
+func NewSigner(analyzerID string, key *ecdsa.PrivateKey) *Signer {
+	return &Signer{analyzerID: analyzerID, key: key}
+}
+
+// NewSignerFromPEM creates a signer from a PEM encoded ECDSA private key, in
+// either SEC 1 or PKCS #8 form
+func NewSignerFromPEM(analyzerID string, keyPEM []byte) (*Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("analyzer key is not PEM encoded")
+	}
+
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return NewSigner(analyzerID, key), nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse analyzer key: %w", err)
+	}
+	ecdsaKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("analyzer key is not an ECDSA key")
+	}
+
+	return NewSigner(analyzerID, ecdsaKey), nil
+}
+
+// PublicKeyPEM returns the PEM encoded public key to register the analyzer with
+func (s *Signer) PublicKeyPEM() (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(&s.key.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// Sign turns a report into a signed attestation
+func (s *Signer) Sign(report *Report, analyzedAt time.Time) (*Attestation, error) {
+	details, err := json.Marshal(report.Findings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal findings: %w", err)
+	}
+
+	statement := Statement{
+		AnalyzerID:        s.analyzerID,
+		EvidenceID:        report.EvidenceID,
+		FileHash:          report.FileHash,
+		TamperProbability: report.TamperProbability,
+		AnalysisDetails:   string(details),
+		AnalyzedAt:        analyzedAt.UTC().Format(time.RFC3339),
+	}
+
+	statementJSON, err := json.Marshal(statement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal statement: %w", err)
+	}
+	digest := sha256.Sum256(statementJSON)
+
+	signature, err := ecdsa.SignASN1(rand.Reader, s.key, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign statement: %w", err)
+	}
+
+	return &Attestation{
+		Statement: statement,
+		Signature: base64.StdEncoding.EncodeToString(signature),
+	}, nil
+}
+
+// Service analyzes evidence with an analyzer and submits the signed results
+// to the evidence contract
+type Service struct {
+	analyzer Analyzer
+	signer   *Signer
+	contract *client.Contract
+}
+
+// NewService creates a service that submits results to the given contract
+func NewService(analyzer Analyzer, signer *Signer, contract *client.Contract) *Service {
+	return &Service{analyzer: analyzer, signer: signer, contract: contract}
+}
+
+// Process reads what the ledger records about an evidence item, analyzes the
+// local copy of its file, and submits the signed result
+func (s *Service) Process(ctx context.Context, evidenceID string, path string) (*Attestation, error) {
+	evidenceJSON, err := s.contract.EvaluateTransaction("ReadEvidence", evidenceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read evidence %s: %w", evidenceID, err)
+	}
+
+	var evidence struct {
+		FileHash string `json:"FileHash"`
+		Metadata string `json:"Metadata"`
+	}
+	if err := json.Unmarshal(evidenceJSON, &evidence); err != nil {
+		return nil, fmt.Errorf("failed to parse evidence %s: %w", evidenceID, err)
+	}
+
+	report, err := s.analyzer.Analyze(ctx, &Request{
+		EvidenceID: evidenceID,
+		FileHash:   evidence.FileHash,
+		Metadata:   evidence.Metadata,
+		Path:       path,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze evidence %s: %w", evidenceID, err)
+	}
+	if report.FileHash != evidence.FileHash {
+		return nil, errors.New("the report must carry the file hash recorded on the ledger")
+	}
+
+	attestation, err := s.signer.Sign(report, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	attestationJSON, err := json.Marshal(attestation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal attestation: %w", err)
+	}
+
+	if _, err := s.contract.SubmitTransaction("SubmitAIAnalysis", string(attestationJSON)); err != nil {
+		return nil, fmt.Errorf("failed to submit analysis of evidence %s: %w", evidenceID, err)
+	}
+
+	return attestation, nil
+}
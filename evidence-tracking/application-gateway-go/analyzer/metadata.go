@@ -0,0 +1,164 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// EXIF tags read by readJPEGExif
+const (
+	tagSoftware         = 0x0131
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagDateTimeOriginal = 0x9003
+)
+
+// exifASCII is the EXIF type of NUL terminated ASCII strings
+const exifASCII = 2
+
+// readJPEGExif returns the ASCII tags of IFD0 and the EXIF sub-IFD of a JPEG,
+// read from the APP1 segment in the header of the file. A JPEG without EXIF
+// data gives an empty map.
+func readJPEGExif(header []byte) (map[uint16]string, error) {
+	tags := map[uint16]string{}
+
+	// Walk the marker segments that precede the image data
+	offset := 2
+	for offset+4 <= len(header) {
+		if header[offset] != 0xFF {
+			return nil, fmt.Errorf("expected JPEG marker at byte %d", offset)
+		}
+		marker := header[offset+1]
+		length := int(binary.BigEndian.Uint16(header[offset+2:]))
+		if marker == 0xDA || marker == 0xD9 {
+			return tags, nil
+		}
+		segment := offset + 4
+		if length < 2 || segment+length-2 > len(header) {
+			return tags, nil
+		}
+		data := header[segment : segment+length-2]
+		if marker == 0xE1 && bytes.HasPrefix(data, []byte("Exif\x00\x00")) {
+			return tags, readTIFF(data[6:], tags)
+		}
+		offset = segment + length - 2
+	}
+
+	return tags, nil
+}
+
+// readTIFF reads the ASCII tags of IFD0 and of the EXIF sub-IFD it points to
+func readTIFF(tiff []byte, tags map[uint16]string) error {
+	if len(tiff) < 8 {
+		return errors.New("TIFF header is truncated")
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return errors.New("TIFF header has no byte order mark")
+	}
+
+	exifOffset, err := readIFD(tiff, order, order.Uint32(tiff[4:]), tags)
+	if err != nil {
+		return err
+	}
+	if exifOffset != 0 {
+		_, err = readIFD(tiff, order, exifOffset, tags)
+	}
+	return err
+}
+
+// readIFD reads the ASCII entries of one image file directory into tags, and
+// returns the offset of the EXIF sub-IFD if the directory points to one
+func readIFD(tiff []byte, order binary.ByteOrder, offset uint32, tags map[uint16]string) (uint32, error) {
+	if int64(offset)+2 > int64(len(tiff)) {
+		return 0, fmt.Errorf("IFD offset %d is outside the EXIF data", offset)
+	}
+	count := int(order.Uint16(tiff[offset:]))
+
+	var exifOffset uint32
+	for i := 0; i < count; i++ {
+		entry := int(offset) + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0, errors.New("IFD entry is outside the EXIF data")
+		}
+		tag := order.Uint16(tiff[entry:])
+		kind := order.Uint16(tiff[entry+2:])
+		length := order.Uint32(tiff[entry+4:])
+		value := tiff[entry+8 : entry+12]
+
+		if tag == tagExifIFD {
+			exifOffset = order.Uint32(value)
+			continue
+		}
+		if kind != exifASCII {
+			continue
+		}
+
+		// Strings longer than four bytes are stored at an offset
+		var data []byte
+		if length > 4 {
+			start := order.Uint32(value)
+			if int64(start)+int64(length) > int64(len(tiff)) {
+				continue
+			}
+			data = tiff[start : start+length]
+		} else {
+			data = value[:length]
+		}
+		tags[tag] = string(bytes.TrimRight(data, "\x00 "))
+	}
+
+	return exifOffset, nil
+}
+
+// readMP4Boxes walks the top level boxes of an MP4 file, counting the boxes of
+// each type, and returns the offset at which the last box ends
+func readMP4Boxes(file io.ReadSeeker, size int64) (map[string]int, int64, error) {
+	boxes := map[string]int{}
+	var offset int64
+	header := make([]byte, 16)
+
+	for offset < size {
+		if _, err := io.ReadFull(file, header[:8]); err != nil {
+			return boxes, offset, fmt.Errorf("box header at byte %d is truncated", offset)
+		}
+		boxSize := int64(binary.BigEndian.Uint32(header))
+		boxType := string(header[4:8])
+		headerLength := int64(8)
+
+		switch boxSize {
+		case 0:
+			// The box extends to the end of the file
+			boxSize = size - offset
+		case 1:
+			if _, err := io.ReadFull(file, header[8:16]); err != nil {
+				return boxes, offset, fmt.Errorf("large box header at byte %d is truncated", offset)
+			}
+			boxSize = int64(binary.BigEndian.Uint64(header[8:]))
+			headerLength = 16
+		}
+		if boxSize < headerLength {
+			return boxes, offset, fmt.Errorf("box %q at byte %d has invalid size %d", boxType, offset, boxSize)
+		}
+
+		boxes[boxType]++
+		if offset+boxSize > size {
+			return boxes, offset + boxSize, nil
+		}
+		if _, err := file.Seek(boxSize-headerLength, io.SeekCurrent); err != nil {
+			return boxes, offset, fmt.Errorf("failed to skip box %q at byte %d: %v", boxType, offset, err)
+		}
+		offset += boxSize
+	}
+
+	return boxes, offset, nil
+}
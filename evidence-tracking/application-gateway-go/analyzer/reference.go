@@ -0,0 +1,275 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// headerSize is how much of the start of a file is read to identify its
+// format and parse its embedded metadata
+const headerSize = 256 * 1024
+
+// editingSoftware are substrings of the EXIF Software tag written by image
+// editors rather than by cameras
+var editingSoftware = []string{"photoshop", "gimp", "lightroom", "snapseed", "pixelmator", "affinity", "paint.net", "picsart"}
+
+// formatAliases maps the formats evidence metadata may declare to the format
+// names detectFormat returns
+var formatAliases = map[string]string{
+	"jpg":  "jpeg",
+	"jpeg": "jpeg",
+	"png":  "png",
+	"mp4":  "mp4",
+	"m4v":  "mp4",
+	"mov":  "mp4",
+	"pdf":  "pdf",
+}
+
+// ReferenceAnalyzer checks that an evidence file matches the hash recorded on
+// the ledger, that its container format matches the format its metadata
+// declares, that MP4 containers are structurally intact, and that JPEG EXIF
+// data shows no sign of editing
+type ReferenceAnalyzer struct{}
+
+// NewReferenceAnalyzer creates the reference analyzer
+func NewReferenceAnalyzer() *ReferenceAnalyzer {
+	return &ReferenceAnalyzer{}
+}
+
+// Analyze implements Analyzer
+func (a *ReferenceAnalyzer) Analyze(ctx context.Context, request *Request) (*Report, error) {
+	file, err := os.Open(request.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open evidence file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat evidence file: %w", err)
+	}
+
+	header := make([]byte, headerSize)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("failed to read evidence file: %w", err)
+	}
+	header = header[:n]
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind evidence file: %w", err)
+	}
+	digest := sha256.New()
+	if _, err := io.Copy(digest, file); err != nil {
+		return nil, fmt.Errorf("failed to hash evidence file: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	findings = append(findings, checkHash(request.FileHash, hex.EncodeToString(digest.Sum(nil))))
+
+	format := detectFormat(header)
+	findings = append(findings, checkDeclaredFormat(request.Metadata, request.Path, format)...)
+
+	switch format {
+	case "mp4":
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to rewind evidence file: %w", err)
+		}
+		findings = append(findings, checkMP4Structure(file, info.Size())...)
+	case "jpeg":
+		findings = append(findings, checkJPEGExif(header)...)
+	}
+
+	return &Report{
+		EvidenceID:        request.EvidenceID,
+		FileHash:          request.FileHash,
+		TamperProbability: Combine(findings),
+		Findings:          findings,
+	}, nil
+}
+
+// checkHash compares the SHA-256 digest of the file with the hash on the
+// ledger. Hashes that are not SHA-256 digests, such as IPFS content IDs,
+// cannot be compared locally.
+func checkHash(recorded string, actual string) Finding {
+	expected := strings.ToLower(strings.TrimPrefix(recorded, "sha256:"))
+	if len(expected) != sha256.Size*2 {
+		return Finding{
+			Check:    "file-hash",
+			Severity: SeverityInfo,
+			Detail:   fmt.Sprintf("recorded hash %s is not a SHA-256 digest and cannot be checked locally; file SHA-256 is %s", recorded, actual),
+			Weight:   0.1,
+		}
+	}
+	if expected != actual {
+		return Finding{
+			Check:    "file-hash",
+			Severity: SeverityCritical,
+			Detail:   fmt.Sprintf("file SHA-256 %s does not match recorded hash %s", actual, expected),
+			Weight:   0.95,
+		}
+	}
+	return Finding{Check: "file-hash", Severity: SeverityInfo, Detail: "file matches recorded SHA-256 hash"}
+}
+
+// detectFormat identifies a file format from its leading bytes
+func detectFormat(header []byte) string {
+	switch {
+	case len(header) >= 3 && header[0] == 0xFF && header[1] == 0xD8 && header[2] == 0xFF:
+		return "jpeg"
+	case len(header) >= 8 && string(header[:8]) == "\x89PNG\r\n\x1a\n":
+		return "png"
+	case len(header) >= 8 && string(header[4:8]) == "ftyp":
+		return "mp4"
+	case len(header) >= 5 && string(header[:5]) == "%PDF-":
+		return "pdf"
+	}
+	return ""
+}
+
+// checkDeclaredFormat compares the detected format with the format declared
+// in the evidence metadata and implied by the file extension
+func checkDeclaredFormat(metadata string, path string, detected string) []Finding {
+	var findings []Finding
+
+	var declared struct {
+		Format string `json:"format"`
+	}
+	if metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &declared); err != nil {
+			findings = append(findings, Finding{
+				Check:    "metadata",
+				Severity: SeverityWarning,
+				Detail:   fmt.Sprintf("evidence metadata is not valid JSON: %v", err),
+				Weight:   0.1,
+			})
+		}
+	}
+
+	claims := map[string]string{
+		"declared format": strings.ToLower(declared.Format),
+		"file extension":  strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")),
+	}
+	for _, source := range []string{"declared format", "file extension"} {
+		claimed, known := formatAliases[claims[source]]
+		if !known {
+			continue
+		}
+		if detected == "" {
+			findings = append(findings, Finding{
+				Check:    "container-format",
+				Severity: SeverityWarning,
+				Detail:   fmt.Sprintf("%s is %s but the file content is not a recognised format", source, claims[source]),
+				Weight:   0.4,
+			})
+		} else if claimed != detected {
+			findings = append(findings, Finding{
+				Check:    "container-format",
+				Severity: SeverityWarning,
+				Detail:   fmt.Sprintf("%s is %s but the file content is %s", source, claims[source], detected),
+				Weight:   0.4,
+			})
+		}
+	}
+
+	return findings
+}
+
+// checkJPEGExif looks for signs of editing in the EXIF data of a JPEG
+func checkJPEGExif(header []byte) []Finding {
+	tags, err := readJPEGExif(header)
+	if err != nil {
+		return []Finding{{
+			Check:    "exif",
+			Severity: SeverityWarning,
+			Detail:   fmt.Sprintf("EXIF data could not be read: %v", err),
+			Weight:   0.1,
+		}}
+	}
+	if len(tags) == 0 {
+		return []Finding{{
+			Check:    "exif",
+			Severity: SeverityInfo,
+			Detail:   "image has no EXIF data, which is common after re-encoding",
+			Weight:   0.15,
+		}}
+	}
+
+	var findings []Finding
+	software := strings.ToLower(tags[tagSoftware])
+	for _, editor := range editingSoftware {
+		if strings.Contains(software, editor) {
+			findings = append(findings, Finding{
+				Check:    "exif",
+				Severity: SeverityWarning,
+				Detail:   fmt.Sprintf("image was last written by editing software %q", tags[tagSoftware]),
+				Weight:   0.5,
+			})
+			break
+		}
+	}
+
+	modified, original := tags[tagDateTime], tags[tagDateTimeOriginal]
+	if modified != "" && original != "" && modified != original {
+		findings = append(findings, Finding{
+			Check:    "exif",
+			Severity: SeverityWarning,
+			Detail:   fmt.Sprintf("image was modified at %s after being taken at %s", modified, original),
+			Weight:   0.3,
+		})
+	}
+
+	return findings
+}
+
+// checkMP4Structure walks the top level boxes of an MP4 container. The boxes
+// must exactly cover the file, and the file must hold one movie header.
+func checkMP4Structure(file io.ReadSeeker, size int64) []Finding {
+	boxes, end, err := readMP4Boxes(file, size)
+	if err != nil {
+		return []Finding{{
+			Check:    "container-structure",
+			Severity: SeverityWarning,
+			Detail:   fmt.Sprintf("MP4 container is malformed: %v", err),
+			Weight:   0.5,
+		}}
+	}
+
+	var findings []Finding
+	if end != size {
+		findings = append(findings, Finding{
+			Check:    "container-structure",
+			Severity: SeverityWarning,
+			Detail:   fmt.Sprintf("MP4 boxes end at byte %d but the file is %d bytes long", end, size),
+			Weight:   0.4,
+		})
+	}
+	if boxes["moov"] != 1 {
+		findings = append(findings, Finding{
+			Check:    "container-structure",
+			Severity: SeverityWarning,
+			Detail:   fmt.Sprintf("MP4 container has %d movie boxes, expected 1", boxes["moov"]),
+			Weight:   0.4,
+		})
+	}
+	if boxes["mdat"] == 0 {
+		findings = append(findings, Finding{
+			Check:    "container-structure",
+			Severity: SeverityWarning,
+			Detail:   "MP4 container has no media data",
+			Weight:   0.3,
+		})
+	}
+
+	return findings
+}
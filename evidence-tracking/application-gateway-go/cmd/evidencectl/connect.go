@@ -0,0 +1,144 @@
+package main
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-gateway/pkg/hash"
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// connection is a Gateway connection for the identity of a profile
+type connection struct {
+	clientConnection *grpc.ClientConn
+	gateway          *client.Gateway
+}
+
+// connect creates a Gateway connection for the identity of a profile
+func connect(profile *Profile) (*connection, error) {
+	clientConnection, err := newGrpcConnection(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newIdentity(profile)
+	if err != nil {
+		clientConnection.Close()
+		return nil, err
+	}
+
+	sign, err := newSign(profile)
+	if err != nil {
+		clientConnection.Close()
+		return nil, err
+	}
+
+	gw, err := client.Connect(
+		id,
+		client.WithSign(sign),
+		client.WithHash(hash.SHA256),
+		client.WithClientConnection(clientConnection),
+		// Default timeouts for different gRPC calls
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(1*time.Minute),
+	)
+	if err != nil {
+		clientConnection.Close()
+		return nil, fmt.Errorf("failed to connect to the gateway: %w", err)
+	}
+
+	return &connection{clientConnection: clientConnection, gateway: gw}, nil
+}
+
+// Close closes the Gateway connection and the gRPC connection under it
+func (c *connection) Close() {
+	c.gateway.Close()
+	c.clientConnection.Close()
+}
+
+// newGrpcConnection creates a gRPC connection to the Gateway server.
+func newGrpcConnection(profile *Profile) (*grpc.ClientConn, error) {
+	certificatePEM, err := os.ReadFile(profile.TLSCertPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TLS certificate file: %w", err)
+	}
+
+	certificate, err := identity.CertificateFromPEM(certificatePEM)
+	if err != nil {
+		return nil, err
+	}
+
+	certPool := x509.NewCertPool()
+	certPool.AddCert(certificate)
+	transportCredentials := credentials.NewClientTLSFromCert(certPool, profile.PeerHostAlias)
+
+	connection, err := grpc.NewClient(profile.PeerEndpoint, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC connection: %w", err)
+	}
+
+	return connection, nil
+}
+
+// newIdentity creates a client identity for this Gateway connection using an X.509 certificate.
+func newIdentity(profile *Profile) (*identity.X509Identity, error) {
+	certificatePEM, err := readFileOrFirstFile(profile.CertPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate file: %w", err)
+	}
+
+	certificate, err := identity.CertificateFromPEM(certificatePEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return identity.NewX509Identity(profile.MSPID, certificate)
+}
+
+// newSign creates a function that generates a digital signature from a message digest using a private key.
+func newSign(profile *Profile) (identity.Sign, error) {
+	privateKeyPEM, err := readFileOrFirstFile(profile.KeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key file: %w", err)
+	}
+
+	privateKey, err := identity.PrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return identity.NewPrivateKeySign(privateKey)
+}
+
+// readFileOrFirstFile reads a file, or the first file in a directory, such as
+// the signcerts and keystore directories of an MSP
+func readFileOrFirstFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return os.ReadFile(path)
+	}
+
+	dir, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	fileNames, err := dir.Readdirnames(1)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(filepath.Join(path, fileNames[0]))
+}
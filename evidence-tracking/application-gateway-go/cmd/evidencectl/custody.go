@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+)
+
+var custodyCommand = &command{
+	usage:   "transfer [-reason <reason>] [-location <location>] <evidence-id> <to-custodian>\n       evidencectl custody accept <evidence-id>\n       evidencectl custody reject [-reason <reason>] <evidence-id>",
+	summary: "Hand evidence over to another custodian, or accept or reject a hand-off",
+	run:     runCustody,
+}
+
+func runCustody(env *env, flags *flag.FlagSet, args []string) error {
+	if len(args) == 0 {
+		flags.Usage()
+		return errUsage
+	}
+	action, args := args[0], args[1:]
+
+	switch action {
+	case "transfer":
+		reason := flags.String("reason", "", "why the evidence is handed over")
+		location := flags.String("location", "", "where the hand-off takes place")
+		if err := parseArgs(flags, args, 2); err != nil {
+			return err
+		}
+
+		contract, err := env.contract()
+		if err != nil {
+			return err
+		}
+		status, err := contract.RequestCustodyTransfer(flags.Arg(0), flags.Arg(1), *reason, *location)
+		if err != nil {
+			return err
+		}
+		return env.printer.printTransaction(flags.Arg(0), status)
+
+	case "accept":
+		if err := parseArgs(flags, args, 1); err != nil {
+			return err
+		}
+
+		contract, err := env.contract()
+		if err != nil {
+			return err
+		}
+		status, err := contract.AcceptCustodyTransfer(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		return env.printer.printTransaction(flags.Arg(0), status)
+
+	case "reject":
+		reason := flags.String("reason", "", "why the hand-off is rejected or withdrawn")
+		if err := parseArgs(flags, args, 1); err != nil {
+			return err
+		}
+
+		contract, err := env.contract()
+		if err != nil {
+			return err
+		}
+		status, err := contract.RejectCustodyTransfer(flags.Arg(0), *reason)
+		if err != nil {
+			return err
+		}
+		return env.printer.printTransaction(flags.Arg(0), status)
+	}
+
+	flags.Usage()
+	return errUsage
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/application-gateway-go/evidence"
+)
+
+var submitCommand = &command{
+	usage:   "-id <evidence-id> -case <case-id> [flags] <file>",
+	summary: "Hash a local file and record it as new evidence",
+	run:     runSubmit,
+}
+
+func runSubmit(env *env, flags *flag.FlagSet, args []string) error {
+	id := flags.String("id", "", "`ID` of the new evidence")
+	caseID := flags.String("case", "", "`ID` of the case the evidence belongs to")
+	description := flags.String("description", "", "description of the evidence")
+	evidenceType := flags.String("type", "", "registered evidence `type` the metadata matches, empty for general evidence")
+	tags := flags.String("tags", "", "comma separated `tags`")
+	metadata := flags.String("metadata", "", "metadata `JSON`")
+	if err := parseArgs(flags, args, 1); err != nil {
+		return err
+	}
+	if *id == "" || *caseID == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	fileHash, err := hashFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	contract, err := env.contract()
+	if err != nil {
+		return err
+	}
+	status, err := contract.SubmitEvidence(*id, *description, *caseID, *evidenceType, fileHash, splitList(*tags), *metadata)
+	if err != nil {
+		return err
+	}
+
+	result := &submitResult{
+		transactionResult: transactionResult{EvidenceID: *id, TransactionID: status.TransactionID, BlockNumber: status.BlockNumber},
+		FileHash:          fileHash,
+	}
+	return env.printer.print(result, func(t *tableWriter) {
+		t.row("EVIDENCE", "FILE HASH", "TRANSACTION", "BLOCK")
+		t.row(result.EvidenceID, result.FileHash, result.TransactionID, strconv.FormatUint(result.BlockNumber, 10))
+	})
+}
+
+// submitResult is the output of the submit command
+type submitResult struct {
+	transactionResult
+	FileHash string `json:"FileHash"` // SHA-256 of the file, as recorded
+}
+
+var showCommand = &command{
+	usage:   "<evidence-id>",
+	summary: "Show an evidence record",
+	run:     runShow,
+}
+
+func runShow(env *env, flags *flag.FlagSet, args []string) error {
+	if err := parseArgs(flags, args, 1); err != nil {
+		return err
+	}
+
+	contract, err := env.contract()
+	if err != nil {
+		return err
+	}
+	record, err := contract.ReadEvidence(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return env.printer.print(record, func(t *tableWriter) {
+		t.row("ID", record.ID)
+		t.row("Case", record.CaseID)
+		t.row("Description", record.Description)
+		t.row("Type", record.EvidenceType)
+		t.row("File hash", record.FileHash)
+		t.row("Status", record.Status)
+		t.row("Status since", record.StatusUpdatedTime)
+		t.row("Custodian", record.CurrentCustodian)
+		if record.PendingTransfer != nil {
+			t.row("Pending transfer", fmt.Sprintf("to %s, requested %s", record.PendingTransfer.ToCustodian, record.PendingTransfer.RequestedAt))
+		}
+		t.row("Submitted by", record.SubmittedBy)
+		t.row("Submitted", record.SubmittedTime)
+		t.row("Tags", strings.Join(record.Tags, ", "))
+		t.row("Metadata", record.Metadata)
+		t.row("Proof verified", strconv.FormatBool(record.ProofVerified))
+		t.row("AI verified", strconv.FormatBool(record.AIVerified))
+		t.row("Integrity", fmt.Sprintf("%s (version %d)", record.Integrity, record.IntegrityVersion))
+		if record.PrivateDetailsHash != "" {
+			t.row("Private details", record.PrivateDetailsHash)
+		}
+	})
+}
+
+var historyCommand = &command{
+	usage:   "<evidence-id>",
+	summary: "Show the history of an evidence record, oldest change first",
+	run:     runHistory,
+}
+
+func runHistory(env *env, flags *flag.FlagSet, args []string) error {
+	if err := parseArgs(flags, args, 1); err != nil {
+		return err
+	}
+
+	contract, err := env.contract()
+	if err != nil {
+		return err
+	}
+	history, err := contract.GetEvidenceHistory(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if history == nil {
+		history = []*evidence.EvidenceHistory{}
+	}
+
+	return env.printer.print(history, func(t *tableWriter) {
+		t.row("SEQ", "TIME", "ACTION", "BY", "TRANSACTION", "DESCRIPTION")
+		for _, entry := range history {
+			t.row(strconv.Itoa(entry.Sequence), entry.ModifiedAt, entry.Action, entry.ModifiedBy, entry.TxID, entry.Description)
+		}
+	})
+}
+
+var statusCommand = &command{
+	usage:   "[-reason <code>] <evidence-id> <status>",
+	summary: "Move evidence to a new status",
+	run:     runStatus,
+}
+
+func runStatus(env *env, flags *flag.FlagSet, args []string) error {
+	reason := flags.String("reason", "", "reason `code`, required by some transitions")
+	if err := parseArgs(flags, args, 2); err != nil {
+		return err
+	}
+
+	contract, err := env.contract()
+	if err != nil {
+		return err
+	}
+	status, err := contract.UpdateEvidenceStatus(flags.Arg(0), flags.Arg(1), *reason)
+	if err != nil {
+		return err
+	}
+
+	return env.printer.printTransaction(flags.Arg(0), status)
+}
+
+// splitList splits a comma separated list, dropping empty items
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
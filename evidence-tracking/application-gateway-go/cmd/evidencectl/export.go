@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/application-gateway-go/evidence"
+)
+
+var exportCommand = &command{
+	usage:   "[-out <file>] <evidence-id>",
+	summary: "Export an evidence record with its verifiable history and custody chain as JSON",
+	run:     runExport,
+}
+
+// evidenceExport is everything the ledger records about an evidence item, in
+// a form that can be handed over and checked without access to the ledger
+type evidenceExport struct {
+	Evidence     *evidence.Evidence           `json:"Evidence"`     // The evidence record
+	HistoryProof *evidence.HistoryProof       `json:"HistoryProof"` // History chain, with the transactions that wrote it
+	HistoryCheck *evidence.HistoryChainReport `json:"HistoryCheck"` // Outcome of verifying the history chain on the ledger
+	CustodyChain *evidence.CustodyChain       `json:"CustodyChain"` // Chain of custody, with any gaps found in it
+	AIAnalyses   []*evidence.AIAnalysisResult `json:"AIAnalyses"`   // Tamper analysis results
+	ZKProofs     []*evidence.ZKProof          `json:"ZKProofs"`     // Zero-knowledge proofs of the file commitment
+	Relations    []*evidence.EvidenceRelation `json:"Relations"`    // Evidence the item was derived from or relates to
+	ExportedAt   string                       `json:"ExportedAt"`   // When evidencectl made the export
+}
+
+func runExport(env *env, flags *flag.FlagSet, args []string) error {
+	out := flags.String("out", "", "write the export to `file` instead of standard output")
+	if err := parseArgs(flags, args, 1); err != nil {
+		return err
+	}
+	id := flags.Arg(0)
+
+	contract, err := env.contract()
+	if err != nil {
+		return err
+	}
+
+	export := &evidenceExport{ExportedAt: time.Now().UTC().Format(time.RFC3339)}
+	if export.Evidence, err = contract.ReadEvidence(id); err != nil {
+		return err
+	}
+	if export.HistoryProof, err = contract.ExportHistoryProof(id); err != nil {
+		return err
+	}
+	if export.HistoryCheck, err = contract.VerifyHistoryChain(id); err != nil {
+		return err
+	}
+	if export.CustodyChain, err = contract.GetCustodyChain(id); err != nil {
+		return err
+	}
+	if export.AIAnalyses, err = contract.GetAIAnalysisResults(id); err != nil {
+		return err
+	}
+	if export.ZKProofs, err = contract.GetZKProofs(id); err != nil {
+		return err
+	}
+	if export.Relations, err = contract.GetEvidenceAncestors(id); err != nil {
+		return err
+	}
+
+	exportJSON, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal export: %w", err)
+	}
+	exportJSON = append(exportJSON, '\n')
+
+	if *out == "" {
+		_, err = env.printer.out.Write(exportJSON)
+		return err
+	}
+	if err := os.WriteFile(*out, exportJSON, 0o600); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+// Command evidencectl records and inspects evidence on the ledger from the
+// command line, for investigators and custodians working outside the web UI.
+//
+// Usage:
+//
+//	evidencectl [-profile name] [-output table|json] <command> [arguments]
+//
+// Each profile is an organization identity and the gateway peer it connects
+// through, read from the file named by EVIDENCECTL_CONFIG or from
+// evidencectl/profiles.json in the user's config directory. Run evidencectl
+// without a command to list the commands.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/application-gateway-go/evidence"
+)
+
+// env is what a command runs with. The Gateway connection is only made once
+// a command has checked its arguments.
+type env struct {
+	profile *Profile
+	printer *printer
+	conn    *connection
+}
+
+// contract connects to the gateway, if not already connected, and returns the
+// evidence contract of the profile
+func (e *env) contract() (*evidence.Contract, error) {
+	if e.conn == nil {
+		conn, err := connect(e.profile)
+		if err != nil {
+			return nil, err
+		}
+		e.conn = conn
+	}
+
+	network := e.conn.gateway.GetNetwork(e.profile.Channel)
+	return evidence.NewContract(network.GetContract(e.profile.Chaincode)), nil
+}
+
+// command is an evidencectl subcommand. It defines its flags on the flag set it
+// is given, and parses its arguments with parseArgs.
+type command struct {
+	usage   string // Arguments of the command
+	summary string // What the command does
+	run     func(env *env, flags *flag.FlagSet, args []string) error
+}
+
+var commands = map[string]*command{
+	"submit":      submitCommand,
+	"show":        showCommand,
+	"history":     historyCommand,
+	"search":      searchCommand,
+	"status":      statusCommand,
+	"custody":     custodyCommand,
+	"verify-file": verifyFileCommand,
+	"export":      exportCommand,
+	"vault":       vaultCommand,
+}
+
+// errUsage is returned by commands given invalid arguments, after they have
+// printed their usage
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	err := run(os.Args[1:], os.Stdout, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, "evidencectl:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := flag.NewFlagSet("evidencectl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	profileName := flags.String("profile", os.Getenv("EVIDENCECTL_PROFILE"), "`name` of the identity profile to use, the default profile if empty")
+	output := flags.String("output", formatTable, "output `format`, table or json")
+	flags.Usage = func() { printUsage(flags) }
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		printUsage(flags)
+		return errUsage
+	}
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		printUsage(flags)
+		return fmt.Errorf("unknown command: %s", flags.Arg(0))
+	}
+
+	printer, err := newPrinter(*output, stdout)
+	if err != nil {
+		return err
+	}
+
+	path, err := profilesPath()
+	if err != nil {
+		return err
+	}
+	profile, err := loadProfile(path, *profileName)
+	if err != nil {
+		return err
+	}
+
+	cmdFlags := flag.NewFlagSet(flags.Arg(0), flag.ContinueOnError)
+	cmdFlags.SetOutput(stderr)
+	cmdFlags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: evidencectl %s %s\n\n%s\n", flags.Arg(0), cmd.usage, cmd.summary)
+		cmdFlags.PrintDefaults()
+	}
+
+	e := &env{profile: profile, printer: printer}
+	defer func() {
+		if e.conn != nil {
+			e.conn.Close()
+		}
+	}()
+	return cmd.run(e, cmdFlags, flags.Args()[1:])
+}
+
+func printUsage(flags *flag.FlagSet) {
+	out := flags.Output()
+	fmt.Fprintln(out, "Usage: evidencectl [flags] <command> [arguments]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Flags:")
+	flags.PrintDefaults()
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-12s %s\n", name, commands[name].summary)
+	}
+}
+
+// parseArgs parses the flags of a command and checks that it was given the
+// number of positional arguments it takes. Flags come before the positional
+// arguments.
+func parseArgs(flags *flag.FlagSet, args []string, positional int) error {
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != positional {
+		flags.Usage()
+		return errUsage
+	}
+	return nil
+}
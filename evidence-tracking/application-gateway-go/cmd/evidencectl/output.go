@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// printer writes the result of a command as JSON, or as a table for people to
+// read
+type printer struct {
+	format string
+	out    io.Writer
+}
+
+func newPrinter(format string, out io.Writer) (*printer, error) {
+	if format != formatTable && format != formatJSON {
+		return nil, fmt.Errorf("output must be %s or %s, not %q", formatTable, formatJSON, format)
+	}
+	return &printer{format: format, out: out}, nil
+}
+
+// print writes value as indented JSON, or calls table to write it as a table
+func (p *printer) print(value interface{}, table func(t *tableWriter)) error {
+	if p.format == formatJSON {
+		valueJSON, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal output: %w", err)
+		}
+		_, err = fmt.Fprintln(p.out, string(valueJSON))
+		return err
+	}
+
+	t := &tableWriter{writer: tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)}
+	table(t)
+	return t.writer.Flush()
+}
+
+// tableWriter writes rows of aligned columns
+type tableWriter struct {
+	writer *tabwriter.Writer
+}
+
+// row writes one row of cells. Tabs and line breaks within a cell are
+// replaced by spaces, so they cannot break the alignment.
+func (t *tableWriter) row(cells ...string) {
+	for i, cell := range cells {
+		cells[i] = strings.Join(strings.Fields(cell), " ")
+		if cells[i] == "" {
+			cells[i] = "-"
+		}
+	}
+	fmt.Fprintln(t.writer, strings.Join(cells, "\t"))
+}
+
+// transactionResult is the output of a command that submits a transaction
+type transactionResult struct {
+	EvidenceID    string `json:"EvidenceID"`    // ID of the evidence the transaction changed
+	TransactionID string `json:"TransactionID"` // ID of the transaction
+	BlockNumber   uint64 `json:"BlockNumber"`   // Block the transaction was committed in
+}
+
+// printTransaction writes the result of a committed transaction
+func (p *printer) printTransaction(evidenceID string, status *client.Status) error {
+	result := &transactionResult{
+		EvidenceID:    evidenceID,
+		TransactionID: status.TransactionID,
+		BlockNumber:   status.BlockNumber,
+	}
+
+	return p.print(result, func(t *tableWriter) {
+		t.row("EVIDENCE", "TRANSACTION", "BLOCK")
+		t.row(result.EvidenceID, result.TransactionID, fmt.Sprint(result.BlockNumber))
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPrinter(t *testing.T) {
+	for _, format := range []string{formatTable, formatJSON} {
+		_, err := newPrinter(format, &bytes.Buffer{})
+		require.NoError(t, err)
+	}
+
+	_, err := newPrinter("yaml", &bytes.Buffer{})
+	require.EqualError(t, err, `output must be table or json, not "yaml"`)
+}
+
+func TestPrintTable(t *testing.T) {
+	var out bytes.Buffer
+	p, err := newPrinter(formatTable, &out)
+	require.NoError(t, err)
+
+	err = p.print(nil, func(t *tableWriter) {
+		t.row("ID", "STATUS", "DESCRIPTION")
+		t.row("EV1", "SUBMITTED", "Knife\tfound at\nthe scene")
+		t.row("EV10", "", "Blood sample")
+	})
+	require.NoError(t, err)
+	require.Equal(t, ""+
+		"ID    STATUS     DESCRIPTION\n"+
+		"EV1   SUBMITTED  Knife found at the scene\n"+
+		"EV10  -          Blood sample\n", out.String())
+}
+
+func TestPrintJSON(t *testing.T) {
+	var out bytes.Buffer
+	p, err := newPrinter(formatJSON, &out)
+	require.NoError(t, err)
+
+	err = p.print(map[string]string{"ID": "EV1"}, func(t *tableWriter) {
+		t.row("not written")
+	})
+	require.NoError(t, err)
+	require.Equal(t, "{\n  \"ID\": \"EV1\"\n}\n", out.String())
+}
+
+func TestPrintTransaction(t *testing.T) {
+	status := &client.Status{TransactionID: "tx1", BlockNumber: 42, Successful: true}
+
+	var table bytes.Buffer
+	p, err := newPrinter(formatTable, &table)
+	require.NoError(t, err)
+	require.NoError(t, p.printTransaction("EV1", status))
+	require.Equal(t, ""+
+		"EVIDENCE  TRANSACTION  BLOCK\n"+
+		"EV1       tx1          42\n", table.String())
+
+	var json bytes.Buffer
+	p, err = newPrinter(formatJSON, &json)
+	require.NoError(t, err)
+	require.NoError(t, p.printTransaction("EV1", status))
+	require.JSONEq(t, `{"EvidenceID": "EV1", "TransactionID": "tx1", "BlockNumber": 42}`, json.String())
+}
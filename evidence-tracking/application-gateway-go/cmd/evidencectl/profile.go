@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Defaults for the settings a profile may leave out, matching the network
+// started by evidence-tracking/network/startNetwork.sh
+const (
+	defaultChannel   = "evidencechannel"
+	defaultChaincode = "evidence"
+)
+
+// Profile is an organization identity and the gateway peer it connects
+// through. Relative paths are resolved against the directory of the profiles
+// file.
+type Profile struct {
+	MSPID         string `json:"mspID"`         // MSP of the identity
+	MSPPath       string `json:"mspPath"`       // MSP directory of the identity, holding signcerts and keystore, as written by fabric-ca-client enroll
+	CertPath      string `json:"certPath"`      // Certificate file, or directory holding it, if not in the MSP directory
+	KeyPath       string `json:"keyPath"`       // Private key file, or directory holding it, if not in the MSP directory
+	TLSCertPath   string `json:"tlsCertPath"`   // CA certificate of the gateway peer's TLS certificate
+	PeerEndpoint  string `json:"peerEndpoint"`  // Gateway peer endpoint, such as dns:///localhost:7051
+	PeerHostAlias string `json:"peerHostAlias"` // Host name the TLS certificate of the gateway peer was issued for
+	Channel       string `json:"channel"`       // Channel the evidence contract is deployed on
+	Chaincode     string `json:"chaincode"`     // Name the evidence contract is deployed under
+}
+
+// profilesFile is the file profiles are read from
+type profilesFile struct {
+	Default  string              `json:"default"` // Profile used when none is named
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+// profilesPath returns the path of the profiles file: the EVIDENCECTL_CONFIG
+// environment variable, or profiles.json in the user's evidencectl config
+// directory
+func profilesPath() (string, error) {
+	if path := os.Getenv("EVIDENCECTL_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the config directory, set EVIDENCECTL_CONFIG: %w", err)
+	}
+	return filepath.Join(configDir, "evidencectl", "profiles.json"), nil
+}
+
+// loadProfile reads the named profile, or the default profile if name is
+// empty, from a profiles file
+func loadProfile(path string, name string) (*Profile, error) {
+	fileJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+
+	var file profilesFile
+	if err := json.Unmarshal(fileJSON, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles %s: %w", path, err)
+	}
+
+	if name == "" {
+		name = file.Default
+	}
+	if name == "" {
+		return nil, fmt.Errorf("no profile given and %s names no default, profiles are %s", path, profileNames(file.Profiles))
+	}
+	profile, ok := file.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %s does not exist in %s, profiles are %s", name, path, profileNames(file.Profiles))
+	}
+
+	if err := profile.resolve(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("invalid profile %s: %w", name, err)
+	}
+	return profile, nil
+}
+
+// resolve fills in the settings left out of a profile and makes its paths
+// absolute
+func (p *Profile) resolve(dir string) error {
+	if p.MSPID == "" {
+		return fmt.Errorf("mspID is required")
+	}
+	if p.PeerEndpoint == "" {
+		return fmt.Errorf("peerEndpoint is required")
+	}
+	if p.TLSCertPath == "" {
+		return fmt.Errorf("tlsCertPath is required")
+	}
+
+	if p.CertPath == "" && p.MSPPath != "" {
+		p.CertPath = filepath.Join(p.MSPPath, "signcerts")
+	}
+	if p.KeyPath == "" && p.MSPPath != "" {
+		p.KeyPath = filepath.Join(p.MSPPath, "keystore")
+	}
+	if p.CertPath == "" || p.KeyPath == "" {
+		return fmt.Errorf("mspPath, or certPath and keyPath, are required")
+	}
+
+	for _, path := range []*string{&p.CertPath, &p.KeyPath, &p.TLSCertPath} {
+		if !filepath.IsAbs(*path) {
+			*path = filepath.Join(dir, *path)
+		}
+	}
+
+	if p.Channel == "" {
+		p.Channel = defaultChannel
+	}
+	if p.Chaincode == "" {
+		p.Chaincode = defaultChaincode
+	}
+	return nil
+}
+
+func profileNames(profiles map[string]*Profile) string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
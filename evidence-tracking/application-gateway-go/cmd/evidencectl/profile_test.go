@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeProfiles writes a profiles file to a temporary directory and returns
+// its path
+func writeProfiles(t *testing.T, profilesJSON string) string {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	require.NoError(t, os.WriteFile(path, []byte(profilesJSON), 0o600))
+	return path
+}
+
+const testProfiles = `{
+  "default": "investigator",
+  "profiles": {
+    "investigator": {
+      "mspID": "Org1MSP",
+      "mspPath": "msp",
+      "tlsCertPath": "tls/ca.crt",
+      "peerEndpoint": "dns:///localhost:7051",
+      "peerHostAlias": "peer0.org1.example.com"
+    },
+    "custodian": {
+      "mspID": "Org2MSP",
+      "certPath": "/etc/custodian/cert.pem",
+      "keyPath": "keys",
+      "tlsCertPath": "/etc/custodian/ca.crt",
+      "peerEndpoint": "dns:///localhost:9051",
+      "channel": "labchannel",
+      "chaincode": "evidence-v2"
+    }
+  }
+}`
+
+func TestLoadProfile(t *testing.T) {
+	path := writeProfiles(t, testProfiles)
+	dir := filepath.Dir(path)
+
+	t.Run("default", func(t *testing.T) {
+		profile, err := loadProfile(path, "")
+		require.NoError(t, err)
+		require.Equal(t, &Profile{
+			MSPID:         "Org1MSP",
+			MSPPath:       "msp",
+			CertPath:      filepath.Join(dir, "msp", "signcerts"),
+			KeyPath:       filepath.Join(dir, "msp", "keystore"),
+			TLSCertPath:   filepath.Join(dir, "tls", "ca.crt"),
+			PeerEndpoint:  "dns:///localhost:7051",
+			PeerHostAlias: "peer0.org1.example.com",
+			Channel:       defaultChannel,
+			Chaincode:     defaultChaincode,
+		}, profile)
+	})
+
+	t.Run("named", func(t *testing.T) {
+		profile, err := loadProfile(path, "custodian")
+		require.NoError(t, err)
+		require.Equal(t, &Profile{
+			MSPID:        "Org2MSP",
+			CertPath:     "/etc/custodian/cert.pem",
+			KeyPath:      filepath.Join(dir, "keys"),
+			TLSCertPath:  "/etc/custodian/ca.crt",
+			PeerEndpoint: "dns:///localhost:9051",
+			Channel:      "labchannel",
+			Chaincode:    "evidence-v2",
+		}, profile)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := loadProfile(path, "auditor")
+		require.EqualError(t, err, "profile auditor does not exist in "+path+", profiles are custodian, investigator")
+	})
+
+	t.Run("no default", func(t *testing.T) {
+		path := writeProfiles(t, `{"profiles": {"investigator": {}}}`)
+		_, err := loadProfile(path, "")
+		require.EqualError(t, err, "no profile given and "+path+" names no default, profiles are investigator")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := loadProfile(filepath.Join(dir, "missing.json"), "")
+		require.ErrorContains(t, err, "failed to read profiles")
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		path := writeProfiles(t, `{"profiles": [`)
+		_, err := loadProfile(path, "")
+		require.ErrorContains(t, err, "failed to parse profiles "+path)
+	})
+}
+
+func TestLoadProfileValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		err     string
+	}{
+		{
+			name:    "no MSP ID",
+			profile: `{"mspPath": "msp", "tlsCertPath": "ca.crt", "peerEndpoint": "dns:///localhost:7051"}`,
+			err:     "mspID is required",
+		},
+		{
+			name:    "no peer endpoint",
+			profile: `{"mspID": "Org1MSP", "mspPath": "msp", "tlsCertPath": "ca.crt"}`,
+			err:     "peerEndpoint is required",
+		},
+		{
+			name:    "no TLS certificate",
+			profile: `{"mspID": "Org1MSP", "mspPath": "msp", "peerEndpoint": "dns:///localhost:7051"}`,
+			err:     "tlsCertPath is required",
+		},
+		{
+			name:    "no identity",
+			profile: `{"mspID": "Org1MSP", "tlsCertPath": "ca.crt", "peerEndpoint": "dns:///localhost:7051"}`,
+			err:     "mspPath, or certPath and keyPath, are required",
+		},
+		{
+			name:    "no key",
+			profile: `{"mspID": "Org1MSP", "certPath": "cert.pem", "tlsCertPath": "ca.crt", "peerEndpoint": "dns:///localhost:7051"}`,
+			err:     "mspPath, or certPath and keyPath, are required",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeProfiles(t, `{"profiles": {"test": `+test.profile+`}}`)
+			_, err := loadProfile(path, "test")
+			require.EqualError(t, err, "invalid profile test: "+test.err)
+		})
+	}
+}
+
+func TestExampleProfiles(t *testing.T) {
+	for _, name := range []string{"", "org1-investigator", "org2-custodian"} {
+		_, err := loadProfile("profiles.example.json", name)
+		require.NoError(t, err, "profile %q", name)
+	}
+}
+
+func TestProfilesPath(t *testing.T) {
+	t.Setenv("EVIDENCECTL_CONFIG", "/etc/evidencectl.json")
+	path, err := profilesPath()
+	require.NoError(t, err)
+	require.Equal(t, "/etc/evidencectl.json", path)
+
+	t.Setenv("EVIDENCECTL_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", "/home/officer/.config")
+	path, err = profilesPath()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join("/home/officer/.config", "evidencectl", "profiles.json"), path)
+}
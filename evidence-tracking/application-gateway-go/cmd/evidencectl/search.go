@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/application-gateway-go/evidence"
+)
+
+var searchCommand = &command{
+	usage:   "[flags]",
+	summary: "Search evidence; every criterion given must match",
+	run:     runSearch,
+}
+
+func runSearch(env *env, flags *flag.FlagSet, args []string) error {
+	var search evidence.EvidenceSearch
+	flags.StringVar(&search.CaseID, "case", "", "evidence of the case with this `ID`")
+	statuses := flags.String("status", "", "evidence in any of these comma separated `statuses`")
+	anyTags := flags.String("tag", "", "evidence with any of these comma separated `tags`")
+	allTags := flags.String("all-tags", "", "evidence with all of these comma separated `tags`")
+	flags.StringVar(&search.SubmittedBy, "submitted-by", "", "evidence submitted by the client with this `ID`")
+	flags.StringVar(&search.Custodian, "custodian", "", "evidence held by the custodian with this `ID`")
+	flags.StringVar(&search.SubmittedAfter, "after", "", "evidence submitted at or after this RFC 3339 `time`")
+	flags.StringVar(&search.SubmittedUntil, "until", "", "evidence submitted at or before this RFC 3339 `time`")
+	flags.StringVar(&search.SortBy, "sort", "", "sort on `field`, SubmittedTime or StatusUpdatedTime")
+	flags.BoolVar(&search.SortDescending, "desc", false, "sort newest first")
+	flags.IntVar(&search.Limit, "limit", 0, "return at most `n` records, 0 for all")
+	if err := parseArgs(flags, args, 0); err != nil {
+		return err
+	}
+	search.Statuses = splitList(*statuses)
+	search.AnyTags = splitList(*anyTags)
+	search.AllTags = splitList(*allTags)
+
+	contract, err := env.contract()
+	if err != nil {
+		return err
+	}
+	records, err := contract.SearchEvidenceAdvanced(search)
+	if err != nil {
+		return err
+	}
+	if records == nil {
+		records = []*evidence.Evidence{}
+	}
+
+	return env.printer.print(records, func(t *tableWriter) {
+		t.row("ID", "CASE", "STATUS", "CUSTODIAN", "SUBMITTED", "DESCRIPTION")
+		for _, record := range records {
+			t.row(record.ID, record.CaseID, record.Status, record.CurrentCustodian, record.SubmittedTime, record.Description)
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/application-gateway-go/vault"
+)
+
+var vaultCommand = &command{
+	usage:   "put [vault flags] [-evidence <evidence-id>] [-sha256 <digest>] <file>\n       evidencectl vault sweep [vault flags]\n       evidencectl vault serve [vault flags] [-listen <address>] [-interval <duration>]",
+	summary: "Store evidence files in an encrypted local vault and sweep them for drift from the ledger",
+	run:     runVault,
+}
+
+// vaultOptions are the flags shared by the vault subcommands
+type vaultOptions struct {
+	root    *string
+	keyFile *string
+	source  *string
+}
+
+func defineVaultFlags(flags *flag.FlagSet) *vaultOptions {
+	hostname, _ := os.Hostname()
+	return &vaultOptions{
+		root:    flags.String("root", os.Getenv("EVIDENCE_VAULT_ROOT"), "`directory` of the vault, EVIDENCE_VAULT_ROOT if not given"),
+		keyFile: flags.String("key-file", os.Getenv("EVIDENCE_VAULT_KEY_FILE"), "`file` holding the vault key as 64 hex digits, EVIDENCE_VAULT_KEY_FILE if not given"),
+		source:  flags.String("source", "evidence vault on "+hostname, "`name` of the vault in the integrity failures it reports"),
+	}
+}
+
+// open opens the vault named by the flags
+func (o *vaultOptions) open() (*vault.Vault, error) {
+	if *o.root == "" || *o.keyFile == "" {
+		return nil, errors.New("the vault root and key file are required")
+	}
+
+	keyHex, err := os.ReadFile(*o.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault key: %w", err)
+	}
+	key, err := vault.ParseKey(string(keyHex))
+	if err != nil {
+		return nil, err
+	}
+
+	return vault.Open(*o.root, key)
+}
+
+func runVault(env *env, flags *flag.FlagSet, args []string) error {
+	if len(args) == 0 {
+		flags.Usage()
+		return errUsage
+	}
+	action, args := args[0], args[1:]
+	options := defineVaultFlags(flags)
+
+	switch action {
+	case "put":
+		evidenceID := flags.String("evidence", "", "ID of the evidence to link the file to")
+		expected := flags.String("sha256", "", "only store the file if it has this SHA-256 `digest`")
+		if err := parseArgs(flags, args, 1); err != nil {
+			return err
+		}
+
+		v, err := options.open()
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		object, err := v.Put(file, *evidenceID, *expected)
+		if err != nil {
+			return err
+		}
+		return env.printer.print(object, func(t *tableWriter) {
+			t.row("SHA-256", object.SHA256)
+			t.row("CID", object.CID)
+			t.row("Size", fmt.Sprint(object.Size))
+			t.row("Evidence", strings.Join(object.EvidenceIDs, ", "))
+		})
+
+	case "sweep":
+		if err := parseArgs(flags, args, 0); err != nil {
+			return err
+		}
+
+		v, err := options.open()
+		if err != nil {
+			return err
+		}
+		sweeper, err := newSweeper(env, v, *options.source)
+		if err != nil {
+			return err
+		}
+		report, err := sweeper.Sweep(context.Background())
+		if err != nil {
+			return err
+		}
+		if err := printSweep(env.printer, report); err != nil {
+			return err
+		}
+		if report.Count(vault.CheckDrifted) > 0 {
+			return errMismatch
+		}
+		return nil
+
+	case "serve":
+		listen := flags.String("listen", "localhost:8090", "`address` to serve uploads on, none if empty")
+		interval := flags.Duration("interval", time.Hour, "time between integrity sweeps, none if 0")
+		if err := parseArgs(flags, args, 0); err != nil {
+			return err
+		}
+		return serveVault(env, options, *listen, *interval)
+	}
+
+	flags.Usage()
+	return errUsage
+}
+
+// newSweeper creates a sweeper that reports to the evidence contract as the
+// identity of the profile
+func newSweeper(env *env, v *vault.Vault, source string) (*vault.Sweeper, error) {
+	contract, err := env.contract()
+	if err != nil {
+		return nil, err
+	}
+	return vault.NewSweeper(v, contract, source), nil
+}
+
+// serveVault serves uploads and runs integrity sweeps until interrupted
+func serveVault(env *env, options *vaultOptions, listen string, interval time.Duration) error {
+	if listen == "" && interval <= 0 {
+		return errors.New("nothing to serve, give a listen address or a sweep interval")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	v, err := options.open()
+	if err != nil {
+		return err
+	}
+
+	errs := make(chan error, 2)
+	if listen != "" {
+		server := &http.Server{Addr: listen, Handler: vault.NewHandler(v)}
+		go func() {
+			<-ctx.Done()
+			server.Close()
+		}()
+		go func() {
+			log.Printf("vault: serving uploads on %s", listen)
+			if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				errs <- err
+			}
+		}()
+	}
+
+	if interval > 0 {
+		sweeper, err := newSweeper(env, v, *options.source)
+		if err != nil {
+			return err
+		}
+		go func() {
+			log.Printf("vault: sweeping every %s", interval)
+			sweeper.Run(ctx, interval, logSweep)
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errs:
+		return err
+	}
+}
+
+// logSweep logs the outcome of a scheduled sweep, with every check that was
+// not ok
+func logSweep(report *vault.SweepReport, err error) {
+	if err != nil {
+		log.Printf("vault: sweep failed: %v", err)
+		return
+	}
+
+	for _, check := range report.Checks {
+		switch {
+		case check.Status == vault.CheckOK && check.Error == "":
+		case check.TransactionID != "":
+			log.Printf("vault: %s of evidence %s drifted to %s, reported in transaction %s", check.SHA256, check.EvidenceID, check.ObservedHash, check.TransactionID)
+		default:
+			log.Printf("vault: %s of evidence %s is %s %s", check.SHA256, check.EvidenceID, check.Status, check.Error)
+		}
+	}
+	log.Printf("vault: swept %d checks in %s, %d drifted, %d failed", len(report.Checks), report.FinishedAt.Sub(report.StartedAt).Round(time.Millisecond), report.Count(vault.CheckDrifted), report.Count(vault.CheckFailed))
+}
+
+// printSweep writes the checks of a sweep
+func printSweep(p *printer, report *vault.SweepReport) error {
+	return p.print(report, func(t *tableWriter) {
+		t.row("SHA-256", "EVIDENCE", "STATUS", "OBSERVED", "REPORTED IN", "ERROR")
+		for _, check := range report.Checks {
+			t.row(check.SHA256, check.EvidenceID, check.Status, check.ObservedHash, check.TransactionID, check.Error)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var verifyFileCommand = &command{
+	usage:   "[-member <path>] <evidence-id> <file>",
+	summary: "Hash a local file and compare it with the hash on the ledger",
+	run:     runVerifyFile,
+}
+
+// errMismatch is returned when a file does not match the ledger, after the
+// comparison has been printed
+var errMismatch = errors.New("the file does not match the ledger")
+
+// verifyResult is the output of the verify-file command
+type verifyResult struct {
+	EvidenceID   string `json:"EvidenceID"`       // ID of the evidence
+	Member       string `json:"Member,omitempty"` // Path of the file within the bundle, if the evidence is a bundle
+	Path         string `json:"Path"`             // Local path of the file
+	RecordedHash string `json:"RecordedHash"`     // Hash on the ledger
+	FileHash     string `json:"FileHash"`         // SHA-256 of the local file
+	Match        bool   `json:"Match"`            // Whether the hashes match
+}
+
+func runVerifyFile(env *env, flags *flag.FlagSet, args []string) error {
+	member := flags.String("member", "", "`path` of the file within the evidence bundle, for evidence recorded as a bundle")
+	if err := parseArgs(flags, args, 2); err != nil {
+		return err
+	}
+	id, path := flags.Arg(0), flags.Arg(1)
+
+	fileHash, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+
+	contract, err := env.contract()
+	if err != nil {
+		return err
+	}
+
+	var recordedHash string
+	if *member != "" {
+		bundle, err := contract.GetEvidenceBundle(id)
+		if err != nil {
+			return err
+		}
+		for _, file := range bundle.Files {
+			if file.Path == *member {
+				recordedHash = file.SHA256
+			}
+		}
+		if recordedHash == "" {
+			return fmt.Errorf("bundle %s has no file %s", id, *member)
+		}
+	} else {
+		record, err := contract.ReadEvidence(id)
+		if err != nil {
+			return err
+		}
+		recordedHash = record.FileHash
+	}
+
+	expected := strings.ToLower(strings.TrimPrefix(recordedHash, "sha256:"))
+	if len(expected) != sha256.Size*2 {
+		return fmt.Errorf("the hash recorded for evidence %s, %s, is not a SHA-256 digest and cannot be checked locally; the file SHA-256 is %s", id, recordedHash, fileHash)
+	}
+
+	result := &verifyResult{
+		EvidenceID:   id,
+		Member:       *member,
+		Path:         path,
+		RecordedHash: recordedHash,
+		FileHash:     fileHash,
+		Match:        expected == fileHash,
+	}
+	err = env.printer.print(result, func(t *tableWriter) {
+		t.row("Evidence", result.EvidenceID)
+		if result.Member != "" {
+			t.row("Bundle member", result.Member)
+		}
+		t.row("File", result.Path)
+		t.row("Recorded hash", result.RecordedHash)
+		t.row("File hash", result.FileHash)
+		t.row("Match", fmt.Sprint(result.Match))
+	})
+	if err != nil {
+		return err
+	}
+
+	if !result.Match {
+		return errMismatch
+	}
+	return nil
+}
+
+// hashFile returns the lowercase hex SHA-256 digest of a file, the form in
+// which evidencectl records file hashes and the analyzer checks them
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	digest := sha256.New()
+	if _, err := io.Copy(digest, file); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
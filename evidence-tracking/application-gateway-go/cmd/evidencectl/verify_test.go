@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// testIdentity is the identity of the client connected to the fake gateway
+type testIdentity struct{}
+
+func (testIdentity) MspID() string       { return "Org1MSP" }
+func (testIdentity) Credentials() []byte { return []byte("officer1") }
+
+// fakeGateway is a gateway connection that records the arguments of the last
+// proposal it receives. It answers evaluated transactions with its result and
+// rejects proposals to be submitted, as it does not order transactions.
+type fakeGateway struct {
+	t         *testing.T
+	arguments []string
+	result    []byte
+}
+
+func (f *fakeGateway) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	switch request := args.(type) {
+	case *gateway.EvaluateRequest:
+		f.record(request.GetProposedTransaction())
+		reply.(*gateway.EvaluateResponse).Result = &peer.Response{Status: 200, Payload: f.result}
+		return nil
+	case *gateway.EndorseRequest:
+		f.record(request.GetProposedTransaction())
+		return errors.New("the fake gateway does not order transactions")
+	}
+	return errors.New("the fake gateway does not implement " + method)
+}
+
+func (f *fakeGateway) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, errors.New("the fake gateway does not implement " + method)
+}
+
+func (f *fakeGateway) record(signedProposal *peer.SignedProposal) {
+	var proposal peer.Proposal
+	require.NoError(f.t, proto.Unmarshal(signedProposal.GetProposalBytes(), &proposal))
+	var payload peer.ChaincodeProposalPayload
+	require.NoError(f.t, proto.Unmarshal(proposal.GetPayload(), &payload))
+	var invocation peer.ChaincodeInvocationSpec
+	require.NoError(f.t, proto.Unmarshal(payload.GetInput(), &invocation))
+
+	f.arguments = nil
+	for _, arg := range invocation.GetChaincodeSpec().GetInput().GetArgs() {
+		f.arguments = append(f.arguments, string(arg))
+	}
+}
+
+// newTestEnv returns an environment whose commands connect to a fake gateway
+// and print JSON
+func newTestEnv(t *testing.T) (*env, *fakeGateway, *bytes.Buffer) {
+	fake := &fakeGateway{t: t}
+	gw, err := client.Connect(
+		testIdentity{},
+		client.WithSign(func(digest []byte) ([]byte, error) { return digest, nil }),
+		client.WithClientConnection(fake),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { gw.Close() })
+
+	var out bytes.Buffer
+	printer, err := newPrinter(formatJSON, &out)
+	require.NoError(t, err)
+
+	profile := &Profile{Channel: defaultChannel, Chaincode: defaultChaincode}
+	return &env{profile: profile, printer: printer, conn: &connection{gateway: gw}}, fake, &out
+}
+
+// runCommand runs a command in an environment
+func runCommand(env *env, name string, args ...string) error {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return commands[name].run(env, flags, args)
+}
+
+// writeEvidenceFile writes a local evidence file and returns its path
+func writeEvidenceFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "knife.jpg")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// submittedHash submits a file with the submit command and returns the file
+// hash it passes to the contract
+func submittedHash(t *testing.T, path string) string {
+	env, fake, _ := newTestEnv(t)
+	err := runCommand(env, "submit", "-id", "EV1", "-case", "CASE1", "-tags", "knife, ,scene", path)
+	require.ErrorContains(t, err, "the fake gateway does not order transactions")
+
+	require.Len(t, fake.arguments, 8)
+	fileHash := fake.arguments[5]
+	require.Equal(t, []string{"SubmitEvidence", "EV1", "", "CASE1", "", fileHash, `["knife","scene"]`, ""}, fake.arguments)
+	return fileHash
+}
+
+// verifyFile runs the verify-file command against an evidence record with the
+// given file hash
+func verifyFile(t *testing.T, path string, recordedHash string) (*verifyResult, error) {
+	env, fake, out := newTestEnv(t)
+	fake.result = []byte(`{"ID":"EV1","FileHash":"` + recordedHash + `"}`)
+
+	err := runCommand(env, "verify-file", "EV1", path)
+	require.Equal(t, []string{"ReadEvidence", "EV1"}, fake.arguments)
+	if out.Len() == 0 {
+		return nil, err
+	}
+
+	var result verifyResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	return &result, err
+}
+
+func TestVerifyFileMatchesSubmit(t *testing.T) {
+	path := writeEvidenceFile(t, "photograph of the knife")
+	fileHash := submittedHash(t, path)
+	require.Equal(t, "388ffe4791d0b76806f4d882f8406088f43281a8048686288a5d5a73b7fc18ac", fileHash, "submit records the hex SHA-256 of the file")
+
+	for _, recordedHash := range []string{fileHash, "sha256:" + fileHash, strings.ToUpper(fileHash)} {
+		result, err := verifyFile(t, path, recordedHash)
+		require.NoError(t, err, "recorded hash %s", recordedHash)
+		require.Equal(t, &verifyResult{
+			EvidenceID:   "EV1",
+			Path:         path,
+			RecordedHash: recordedHash,
+			FileHash:     fileHash,
+			Match:        true,
+		}, result)
+	}
+}
+
+func TestVerifyFileMismatch(t *testing.T) {
+	recordedHash := submittedHash(t, writeEvidenceFile(t, "photograph of the knife"))
+	path := writeEvidenceFile(t, "photograph of the knife, retouched")
+
+	result, err := verifyFile(t, path, recordedHash)
+	require.ErrorIs(t, err, errMismatch)
+	require.False(t, result.Match)
+	require.Equal(t, recordedHash, result.RecordedHash)
+	require.NotEqual(t, recordedHash, result.FileHash)
+}
+
+func TestVerifyFileUncheckableHash(t *testing.T) {
+	path := writeEvidenceFile(t, "photograph of the knife")
+
+	_, err := verifyFile(t, path, "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o")
+	require.ErrorContains(t, err, "is not a SHA-256 digest and cannot be checked locally")
+}
@@ -0,0 +1,196 @@
+// Package events listens for the chaincode events emitted by the evidence
+// contract and hands them to subscribers. A checkpointer records the last
+// event handled, so a restarted listener replays only the events it missed.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// Version is the highest version of the event envelope this package
+// understands. Events with a higher version are still delivered, and
+// subscribers can check Event.Version to decide whether to handle them.
+const Version = 1
+
+// Names of the events emitted by the evidence contract
+const (
+	EvidenceSubmitted        = "EvidenceSubmitted"
+	EvidenceUpdated          = "EvidenceUpdated"
+	StatusChanged            = "StatusChanged"
+	CustodyTransferred       = "CustodyTransferred"
+	IntegrityVerified        = "IntegrityVerified"
+	ZKProofVerified          = "ZKProofVerified"
+	AIAnalysisRecorded       = "AIAnalysisRecorded"
+	EvidenceDisposed         = "EvidenceDisposed"
+	VerificationApproved     = "VerificationApproved"
+	IntegrityFailureReported = "IntegrityFailureReported"
+)
+
+// Event is a chaincode event emitted by the evidence contract, with the
+// position on the ledger at which it was committed
+type Event struct {
+	BlockNumber   uint64          `json:"-"`          // Block containing the transaction that emitted the event
+	TransactionID string          `json:"-"`          // Transaction that emitted the event
+	Version       int             `json:"Version"`    // Version of the event envelope
+	Type          string          `json:"Type"`       // Kind of change, which determines the Payload
+	EvidenceID    string          `json:"EvidenceID"` // ID of the evidence that changed
+	Actor         string          `json:"Actor"`      // ID of the client that made the change
+	Timestamp     string          `json:"Timestamp"`  // Transaction timestamp of the change
+	TxID          string          `json:"TxID"`       // Transaction that made the change
+	Payload       json.RawMessage `json:"Payload"`    // Details of the change
+}
+
+// StatusChange is the payload of a StatusChanged event
+type StatusChange struct {
+	From       string `json:"From"`
+	To         string `json:"To"`
+	ReasonCode string `json:"ReasonCode"`
+}
+
+// IntegrityCheck is the payload of an IntegrityVerified event
+type IntegrityCheck struct {
+	Valid                 bool     `json:"Valid"`
+	Integrity             string   `json:"Integrity"`
+	IntegrityVersion      int      `json:"IntegrityVersion"`
+	FailedAncestors       []string `json:"FailedAncestors"`
+	UnverifiableAncestors []string `json:"UnverifiableAncestors"`
+}
+
+// DecodePayload unmarshals the payload of the event into v
+func (e *Event) DecodePayload(v interface{}) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("failed to decode %s payload: %w", e.Type, err)
+	}
+	return nil
+}
+
+// Handler handles one event. Returning an error stops the listener before the
+// event is checkpointed, so the event is delivered again when it restarts.
+type Handler func(event *Event) error
+
+// Checkpointer records the last event handled by a listener.
+// *client.FileCheckpointer satisfies it.
+type Checkpointer interface {
+	client.Checkpoint
+	CheckpointChaincodeEvent(event *client.ChaincodeEvent) error
+}
+
+// Listener receives evidence events from a network and dispatches them to the
+// handlers subscribed to each event name
+type Listener struct {
+	network       *client.Network
+	chaincodeName string
+	checkpointer  Checkpointer
+	startBlock    *uint64
+
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// NewListener creates a listener for the events of the named chaincode. The
+// checkpointer may be nil, in which case no position is recorded and the
+// listener starts from the next block to be committed, or the start block.
+func NewListener(network *client.Network, chaincodeName string, checkpointer Checkpointer) *Listener {
+	return &Listener{
+		network:       network,
+		chaincodeName: chaincodeName,
+		checkpointer:  checkpointer,
+		handlers:      map[string][]Handler{},
+	}
+}
+
+// StartAt sets the block to replay events from when the checkpointer holds no
+// position yet
+func (l *Listener) StartAt(blockNumber uint64) {
+	l.startBlock = &blockNumber
+}
+
+// Subscribe registers a handler for events with the given name. An empty
+// name subscribes the handler to every event. Handlers subscribed to the event
+// name are called before those subscribed to every event, each in the order
+// they were subscribed.
+func (l *Listener) Subscribe(eventName string, handler Handler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.handlers[eventName] = append(l.handlers[eventName], handler)
+}
+
+// Run listens for events until the context is cancelled, the event stream
+// ends, or a handler fails. Each event is checkpointed once every subscribed
+// handler has handled it.
+func (l *Listener) Run(ctx context.Context) error {
+	var options []client.ChaincodeEventsOption
+	if l.startBlock != nil {
+		options = append(options, client.WithStartBlock(*l.startBlock))
+	}
+	if l.checkpointer != nil {
+		options = append(options, client.WithCheckpoint(l.checkpointer))
+	}
+
+	chaincodeEvents, err := l.network.ChaincodeEvents(ctx, l.chaincodeName, options...)
+	if err != nil {
+		return fmt.Errorf("failed to start chaincode event listening: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case chaincodeEvent, ok := <-chaincodeEvents:
+			if !ok {
+				return nil
+			}
+			if err := l.dispatch(chaincodeEvent); err != nil {
+				return err
+			}
+			if l.checkpointer != nil {
+				if err := l.checkpointer.CheckpointChaincodeEvent(chaincodeEvent); err != nil {
+					return fmt.Errorf("failed to checkpoint event: %w", err)
+				}
+			}
+		}
+	}
+}
+
+// dispatch decodes a chaincode event and hands it to its subscribers
+func (l *Listener) dispatch(chaincodeEvent *client.ChaincodeEvent) error {
+	event, err := decodeEvent(chaincodeEvent)
+	if err != nil {
+		return err
+	}
+
+	l.mu.RLock()
+	handlers := append(append([]Handler{}, l.handlers[event.Type]...), l.handlers[""]...)
+	l.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if err := handler(event); err != nil {
+			return fmt.Errorf("failed to handle %s event in transaction %s: %w", event.Type, event.TransactionID, err)
+		}
+	}
+
+	return nil
+}
+
+// decodeEvent unmarshals the envelope of a chaincode event
+func decodeEvent(chaincodeEvent *client.ChaincodeEvent) (*Event, error) {
+	var event Event
+	if err := json.Unmarshal(chaincodeEvent.Payload, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode %s event in transaction %s: %w", chaincodeEvent.EventName, chaincodeEvent.TransactionID, err)
+	}
+
+	event.BlockNumber = chaincodeEvent.BlockNumber
+	event.TransactionID = chaincodeEvent.TransactionID
+	if event.Type == "" {
+		event.Type = chaincodeEvent.EventName
+	}
+
+	return &event, nil
+}
@@ -0,0 +1,290 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// testIdentity is the identity of the client connected to the fake gateway
+type testIdentity struct{}
+
+func (testIdentity) MspID() string       { return "Org1MSP" }
+func (testIdentity) Credentials() []byte { return []byte("auditor1") }
+
+// block is a committed block and the chaincode events of its transactions
+type block struct {
+	number uint64
+	events []*peer.ChaincodeEvent
+}
+
+// fakeGateway is a gateway connection that serves the chaincode events of the
+// blocks it holds, from the position each request asks for, the way a peer
+// does
+type fakeGateway struct {
+	t        *testing.T
+	blocks   []block
+	requests []*gateway.ChaincodeEventsRequest // Every chaincode events request received
+}
+
+func (f *fakeGateway) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	return errors.New("the fake gateway does not implement " + method)
+}
+
+func (f *fakeGateway) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	require.Equal(f.t, gateway.Gateway_ChaincodeEvents_FullMethodName, method)
+	return &fakeEventStream{gateway: f, ctx: ctx}, nil
+}
+
+// fakeEventStream is the stream of one chaincode events request
+type fakeEventStream struct {
+	gateway   *fakeGateway
+	ctx       context.Context
+	responses []*gateway.ChaincodeEventsResponse
+}
+
+func (s *fakeEventStream) SendMsg(m interface{}) error {
+	var request gateway.ChaincodeEventsRequest
+	require.NoError(s.gateway.t, proto.Unmarshal(m.(*gateway.SignedChaincodeEventsRequest).GetRequest(), &request))
+	s.gateway.requests = append(s.gateway.requests, &request)
+
+	start := request.GetStartPosition().GetSpecified().GetNumber()
+	for _, b := range s.gateway.blocks {
+		if b.number < start {
+			continue
+		}
+		events := b.events
+		if b.number == start && request.GetAfterTransactionId() != "" {
+			for i, event := range events {
+				if event.GetTxId() == request.GetAfterTransactionId() {
+					events = events[i+1:]
+					break
+				}
+			}
+		}
+		s.responses = append(s.responses, &gateway.ChaincodeEventsResponse{BlockNumber: b.number, Events: events})
+	}
+	return nil
+}
+
+func (s *fakeEventStream) RecvMsg(m interface{}) error {
+	if len(s.responses) == 0 {
+		return io.EOF
+	}
+	proto.Merge(m.(*gateway.ChaincodeEventsResponse), s.responses[0])
+	s.responses = s.responses[1:]
+	return nil
+}
+
+func (s *fakeEventStream) Header() (metadata.MD, error) { return nil, nil }
+func (s *fakeEventStream) Trailer() metadata.MD         { return nil }
+func (s *fakeEventStream) CloseSend() error             { return nil }
+func (s *fakeEventStream) Context() context.Context     { return s.ctx }
+
+// newTestNetwork returns a network whose chaincode events come from a fake
+// gateway holding the given blocks
+func newTestNetwork(t *testing.T, blocks ...block) (*client.Network, *fakeGateway) {
+	fake := &fakeGateway{t: t, blocks: blocks}
+	gw, err := client.Connect(
+		testIdentity{},
+		client.WithSign(func(digest []byte) ([]byte, error) { return digest, nil }),
+		client.WithClientConnection(fake),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { gw.Close() })
+
+	return gw.GetNetwork("evidencechannel"), fake
+}
+
+// envelope returns the payload of an event emitted by the contract
+func envelope(t *testing.T, version int, eventType string, evidenceID string, payload interface{}) []byte {
+	payloadJSON, err := json.Marshal(payload)
+	require.NoError(t, err)
+	envelopeJSON, err := json.Marshal(&Event{
+		Version:    version,
+		Type:       eventType,
+		EvidenceID: evidenceID,
+		Actor:      "officer1",
+		Timestamp:  "2024-01-01T10:00:00Z",
+		TxID:       "ignored",
+		Payload:    payloadJSON,
+	})
+	require.NoError(t, err)
+	return envelopeJSON
+}
+
+// chaincodeEvent returns an event emitted by the contract in a transaction
+func chaincodeEvent(t *testing.T, txID string, eventType string, evidenceID string) *peer.ChaincodeEvent {
+	return &peer.ChaincodeEvent{
+		ChaincodeId: "evidence",
+		TxId:        txID,
+		EventName:   eventType,
+		Payload:     envelope(t, Version, eventType, evidenceID, map[string]string{"ID": evidenceID}),
+	}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	event, err := decodeEvent(&client.ChaincodeEvent{
+		BlockNumber:   7,
+		TransactionID: "tx1",
+		ChaincodeName: "evidence",
+		EventName:     StatusChanged,
+		Payload:       envelope(t, 1, StatusChanged, "EV001", &StatusChange{From: "received", To: "processing"}),
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), event.BlockNumber)
+	require.Equal(t, "tx1", event.TransactionID)
+	require.Equal(t, 1, event.Version)
+	require.Equal(t, StatusChanged, event.Type)
+	require.Equal(t, "EV001", event.EvidenceID)
+	require.Equal(t, "officer1", event.Actor)
+
+	var change StatusChange
+	require.NoError(t, event.DecodePayload(&change))
+	require.Equal(t, StatusChange{From: "received", To: "processing"}, change)
+
+	var changes []StatusChange
+	require.ErrorContains(t, event.DecodePayload(&changes), "failed to decode StatusChanged payload")
+}
+
+func TestDecodeEventWithoutType(t *testing.T) {
+	payload := envelope(t, 1, "", "EV001", &IntegrityCheck{Valid: true, UnverifiableAncestors: []string{"EV900"}})
+	event, err := decodeEvent(&client.ChaincodeEvent{TransactionID: "tx1", EventName: IntegrityVerified, Payload: payload})
+	require.NoError(t, err)
+	require.Equal(t, IntegrityVerified, event.Type)
+
+	var check IntegrityCheck
+	require.NoError(t, event.DecodePayload(&check))
+	require.True(t, check.Valid)
+	require.Equal(t, []string{"EV900"}, check.UnverifiableAncestors)
+}
+
+func TestDecodeMalformedEvent(t *testing.T) {
+	_, err := decodeEvent(&client.ChaincodeEvent{TransactionID: "tx1", EventName: EvidenceSubmitted, Payload: []byte("EV001 submitted")})
+	require.ErrorContains(t, err, "failed to decode EvidenceSubmitted event in transaction tx1")
+}
+
+func TestDispatch(t *testing.T) {
+	listener := NewListener(nil, "evidence", nil)
+	var handled []string
+	handle := func(name string) Handler {
+		return func(event *Event) error {
+			handled = append(handled, name+" "+event.Type)
+			return nil
+		}
+	}
+	listener.Subscribe("", handle("every"))
+	listener.Subscribe(StatusChanged, handle("first"))
+	listener.Subscribe(StatusChanged, handle("second"))
+
+	// Handlers of the event name come first, then those of every event
+	err := listener.dispatch(&client.ChaincodeEvent{TransactionID: "tx1", EventName: StatusChanged, Payload: envelope(t, 1, StatusChanged, "EV001", &StatusChange{})})
+	require.NoError(t, err)
+	require.Equal(t, []string{"first StatusChanged", "second StatusChanged", "every StatusChanged"}, handled)
+
+	// Events of a newer version or unknown type are still delivered
+	handled = nil
+	var version int
+	listener.Subscribe("", func(event *Event) error {
+		version = event.Version
+		return nil
+	})
+	err = listener.dispatch(&client.ChaincodeEvent{TransactionID: "tx2", EventName: "EvidenceSealed", Payload: envelope(t, Version+1, "EvidenceSealed", "EV001", map[string]string{})})
+	require.NoError(t, err)
+	require.Equal(t, []string{"every EvidenceSealed"}, handled)
+	require.Equal(t, Version+1, version)
+
+	// A failing handler stops the handlers after it
+	handled = nil
+	listener.Subscribe(EvidenceUpdated, func(event *Event) error {
+		return errors.New("database unavailable")
+	})
+	err = listener.dispatch(&client.ChaincodeEvent{TransactionID: "tx3", EventName: EvidenceUpdated, Payload: envelope(t, 1, EvidenceUpdated, "EV001", map[string]string{})})
+	require.EqualError(t, err, "failed to handle EvidenceUpdated event in transaction tx3: database unavailable")
+	require.Empty(t, handled)
+}
+
+func TestListenerResumesFromCheckpoint(t *testing.T) {
+	network, fake := newTestNetwork(t,
+		block{number: 5, events: []*peer.ChaincodeEvent{
+			chaincodeEvent(t, "tx1", EvidenceSubmitted, "EV003"),
+			chaincodeEvent(t, "tx2", StatusChanged, "EV003"),
+		}},
+		block{number: 6, events: []*peer.ChaincodeEvent{
+			chaincodeEvent(t, "tx3", IntegrityVerified, "EV003"),
+		}},
+	)
+	checkpointPath := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	// The first run fails on the second event, after checkpointing the first
+	checkpointer, err := client.NewFileCheckpointer(checkpointPath)
+	require.NoError(t, err)
+	listener := NewListener(network, "evidence", checkpointer)
+	listener.StartAt(5)
+	var handled []string
+	listener.Subscribe("", func(event *Event) error {
+		if event.TransactionID == "tx2" && len(fake.requests) == 1 {
+			return errors.New("database unavailable")
+		}
+		handled = append(handled, event.TransactionID)
+		return nil
+	})
+	err = listener.Run(context.Background())
+	require.EqualError(t, err, "failed to handle StatusChanged event in transaction tx2: database unavailable")
+	require.Equal(t, []string{"tx1"}, handled)
+	require.NoError(t, checkpointer.Close())
+
+	// A restarted listener resumes after the last event checkpointed, rather
+	// than from its start block
+	checkpointer, err = client.NewFileCheckpointer(checkpointPath)
+	require.NoError(t, err)
+	defer checkpointer.Close()
+	require.Equal(t, uint64(5), checkpointer.BlockNumber())
+	require.Equal(t, "tx1", checkpointer.TransactionID())
+
+	restarted := NewListener(network, "evidence", checkpointer)
+	restarted.StartAt(0)
+	restarted.Subscribe("", func(event *Event) error {
+		handled = append(handled, event.TransactionID)
+		return nil
+	})
+	require.NoError(t, restarted.Run(context.Background()))
+	require.Equal(t, []string{"tx1", "tx2", "tx3"}, handled)
+
+	require.Len(t, fake.requests, 2)
+	require.Equal(t, "evidence", fake.requests[1].GetChaincodeId())
+	require.Equal(t, uint64(5), fake.requests[1].GetStartPosition().GetSpecified().GetNumber())
+	require.Equal(t, "tx1", fake.requests[1].GetAfterTransactionId())
+	require.Equal(t, uint64(6), checkpointer.BlockNumber())
+	require.Equal(t, "tx3", checkpointer.TransactionID())
+}
+
+func TestListenerWithoutCheckpointer(t *testing.T) {
+	network, fake := newTestNetwork(t, block{number: 3, events: []*peer.ChaincodeEvent{
+		chaincodeEvent(t, "tx1", EvidenceSubmitted, "EV003"),
+	}})
+
+	listener := NewListener(network, "evidence", nil)
+	var handled []*Event
+	listener.Subscribe(EvidenceSubmitted, func(event *Event) error {
+		handled = append(handled, event)
+		return nil
+	})
+	require.NoError(t, listener.Run(context.Background()))
+
+	require.Len(t, handled, 1)
+	require.Equal(t, uint64(3), handled[0].BlockNumber)
+	require.Equal(t, "EV003", handled[0].EvidenceID)
+	require.NotNil(t, fake.requests[0].GetStartPosition().GetNextCommit())
+}
@@ -0,0 +1,34 @@
+package evidence
+
+import (
+	"strconv"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// SetAccessPolicy sets the roles, and optionally the organizations, allowed to
+// submit a transaction, replacing any existing or default policy for it
+func (c *Contract) SetAccessPolicy(function string, roles []string, mspIDs []string) (*client.Status, error) {
+	_, status, err := c.submit("SetAccessPolicy", client.WithArguments(function, listArgument(roles), listArgument(mspIDs)))
+	return status, err
+}
+
+// GetAccessPolicies returns the access policy in force for every transaction
+// that has one
+func (c *Contract) GetAccessPolicies() ([]*AccessPolicy, error) {
+	return evaluateResult[[]*AccessPolicy](c, "GetAccessPolicies")
+}
+
+// RecordEvidenceAccess adds an entry to the access log of an evidence item.
+// Submit it alongside ReadEvidence, which is only evaluated and leaves no
+// record.
+func (c *Contract) RecordEvidenceAccess(id string, purpose string) (*client.Status, error) {
+	_, status, err := c.submit("RecordEvidenceAccess", client.WithArguments(id, purpose))
+	return status, err
+}
+
+// GetAccessLog returns a page of the access log of an evidence item, oldest
+// first. Pass the bookmark from the previous page to fetch the next one.
+func (c *Contract) GetAccessLog(id string, pageSize int, bookmark string) (*AccessLogPage, error) {
+	return evaluateResult[*AccessLogPage](c, "GetAccessLog", client.WithArguments(id, strconv.Itoa(pageSize), bookmark))
+}
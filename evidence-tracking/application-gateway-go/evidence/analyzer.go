@@ -0,0 +1,40 @@
+package evidence
+
+import (
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// RegisterAnalyzer registers an analyzer and the PEM encoded public key its
+// results must be signed with
+func (c *Contract) RegisterAnalyzer(id string, name string, publicKeyPEM string) (*client.Status, error) {
+	_, status, err := c.submit("RegisterAnalyzer", client.WithArguments(id, name, publicKeyPEM))
+	return status, err
+}
+
+// RevokeAnalyzer stops results from an analyzer being accepted. Results
+// already recorded are kept.
+func (c *Contract) RevokeAnalyzer(id string) (*client.Status, error) {
+	_, status, err := c.submit("RevokeAnalyzer", client.WithArguments(id))
+	return status, err
+}
+
+// ReadAnalyzer returns the analyzer with the given ID
+func (c *Contract) ReadAnalyzer(id string) (*Analyzer, error) {
+	return evaluateResult[*Analyzer](c, "ReadAnalyzer", client.WithArguments(id))
+}
+
+// SubmitAIAnalysis records the result of a tamper analysis, signed by a
+// registered analyzer, and returns the result as recorded
+func (c *Contract) SubmitAIAnalysis(attestation AnalysisAttestation) (*AIAnalysisResult, *client.Status, error) {
+	attestationJSON, err := jsonArgument("attestation", attestation)
+	if err != nil {
+		return nil, nil, err
+	}
+	return submitResult[*AIAnalysisResult](c, "SubmitAIAnalysis", client.WithArguments(attestationJSON))
+}
+
+// GetAnalyzerTrackRecord returns every result accepted from an analyzer, with
+// totals that show how often it reports tampering
+func (c *Contract) GetAnalyzerTrackRecord(id string) (*AnalyzerTrackRecord, error) {
+	return evaluateResult[*AnalyzerTrackRecord](c, "GetAnalyzerTrackRecord", client.WithArguments(id))
+}
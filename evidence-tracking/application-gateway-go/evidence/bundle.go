@@ -0,0 +1,39 @@
+package evidence
+
+import (
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// SubmitEvidenceBundle issues a new evidence record for a set of files. The
+// Merkle root of the files becomes the FileHash of the evidence.
+func (c *Contract) SubmitEvidenceBundle(id string, description string, caseID string, evidenceType string, files []BundleFile, tags []string, metadata string) (*client.Status, error) {
+	if files == nil {
+		files = []BundleFile{}
+	}
+	filesJSON, err := jsonArgument("files", files)
+	if err != nil {
+		return nil, err
+	}
+
+	_, status, err := c.submit("SubmitEvidenceBundle", client.WithArguments(id, description, caseID, evidenceType, filesJSON, listArgument(tags), metadata))
+	return status, err
+}
+
+// GetEvidenceBundle returns the manifest of a bundle
+func (c *Contract) GetEvidenceBundle(id string) (*EvidenceBundle, error) {
+	return evaluateResult[*EvidenceBundle](c, "GetEvidenceBundle", client.WithArguments(id))
+}
+
+// VerifyBundleMember checks a proof that a file with the given path and
+// SHA-256 digest belongs to a bundle
+func (c *Contract) VerifyBundleMember(id string, path string, hash string, proof BundleProof) (bool, error) {
+	if proof.Steps == nil {
+		proof.Steps = []ProofStep{}
+	}
+	proofJSON, err := jsonArgument("proof", proof)
+	if err != nil {
+		return false, err
+	}
+
+	return evaluateResult[bool](c, "VerifyBundleMember", client.WithArguments(id, path, hash, proofJSON))
+}
@@ -0,0 +1,45 @@
+package evidence
+
+import (
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// CreateCase opens a new case. The case type must have a retention policy; an
+// empty case type creates a general case.
+func (c *Contract) CreateCase(id string, title string, caseType string, jurisdiction string, leadInvestigator string, assignedTeam []string) (*client.Status, error) {
+	_, status, err := c.submit("CreateCase", client.WithArguments(id, title, caseType, jurisdiction, leadInvestigator, listArgument(assignedTeam)))
+	return status, err
+}
+
+// ReadCase returns the case with the given ID
+func (c *Contract) ReadCase(id string) (*Case, error) {
+	return evaluateResult[*Case](c, "ReadCase", client.WithArguments(id))
+}
+
+// CaseExists returns true when a case with the given ID exists
+func (c *Contract) CaseExists(id string) (bool, error) {
+	return evaluateResult[bool](c, "CaseExists", client.WithArguments(id))
+}
+
+// GetAllCases returns all cases on the ledger
+func (c *Contract) GetAllCases() ([]*Case, error) {
+	return evaluateResult[[]*Case](c, "GetAllCases")
+}
+
+// AssignCase changes the lead investigator and the team assigned to a case
+func (c *Contract) AssignCase(id string, leadInvestigator string, assignedTeam []string) (*client.Status, error) {
+	_, status, err := c.submit("AssignCase", client.WithArguments(id, leadInvestigator, listArgument(assignedTeam)))
+	return status, err
+}
+
+// UpdateCaseStatus moves a case to a new status and records the change, with
+// its reason, in the case timeline
+func (c *Contract) UpdateCaseStatus(id string, newStatus string, reason string) (*client.Status, error) {
+	_, status, err := c.submit("UpdateCaseStatus", client.WithArguments(id, newStatus, reason))
+	return status, err
+}
+
+// GetEvidenceStatsByCaseID returns the dashboard of a case
+func (c *Contract) GetEvidenceStatsByCaseID(caseID string) (*CaseDashboard, error) {
+	return evaluateResult[*CaseDashboard](c, "GetEvidenceStatsByCaseID", client.WithArguments(caseID))
+}
@@ -0,0 +1,126 @@
+// Package evidence is a typed client for the evidence contract. It exposes
+// every transaction of the contract as a method over a Fabric Gateway
+// contract, encodes the arguments the way the contract parses them, decodes
+// the results, and turns the errors of the contract into typed errors.
+//
+// Methods that submit a transaction wait for it to be committed and return its
+// commit status, which carries the transaction ID and block number. Methods
+// that only read the ledger evaluate the transaction on a single peer.
+package evidence
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// privateDetailsKey is the transient map key the contract reads private
+// details from
+const privateDetailsKey = "evidence_private_details"
+
+// Contract is the evidence contract deployed on a channel
+type Contract struct {
+	contract *client.Contract
+}
+
+// NewContract wraps the evidence contract of a network, as returned by
+// network.GetContract
+func NewContract(contract *client.Contract) *Contract {
+	return &Contract{contract: contract}
+}
+
+// submit submits a transaction, waits for it to be committed, and returns its
+// result and commit status. A transaction that is endorsed but fails
+// validation returns its commit status along with a *client.CommitError.
+func (c *Contract) submit(name string, options ...client.ProposalOption) ([]byte, *client.Status, error) {
+	result, commit, err := c.contract.SubmitAsync(name, options...)
+	if err != nil {
+		return nil, nil, newError(name, err)
+	}
+
+	status, err := commit.Status()
+	if err != nil {
+		return nil, nil, newError(name, err)
+	}
+	if !status.Successful {
+		return nil, status, &client.CommitError{TransactionID: status.TransactionID, Code: status.Code}
+	}
+
+	return result, status, nil
+}
+
+// evaluate evaluates a transaction and returns its result
+func (c *Contract) evaluate(name string, options ...client.ProposalOption) ([]byte, error) {
+	result, err := c.contract.Evaluate(name, options...)
+	if err != nil {
+		return nil, newError(name, err)
+	}
+	return result, nil
+}
+
+// submitResult submits a transaction and decodes its JSON result
+func submitResult[T any](c *Contract, name string, options ...client.ProposalOption) (T, *client.Status, error) {
+	var value T
+	result, status, err := c.submit(name, options...)
+	if err != nil {
+		return value, status, err
+	}
+
+	value, err = decode[T](name, result)
+	return value, status, err
+}
+
+// evaluateResult evaluates a transaction and decodes its JSON result
+func evaluateResult[T any](c *Contract, name string, options ...client.ProposalOption) (T, error) {
+	var value T
+	result, err := c.evaluate(name, options...)
+	if err != nil {
+		return value, err
+	}
+
+	return decode[T](name, result)
+}
+
+// decode decodes the JSON result of a transaction. The contract returns an
+// empty result for a nil slice or pointer, which decodes to the zero value.
+func decode[T any](name string, result []byte) (T, error) {
+	var value T
+	if len(result) == 0 {
+		return value, nil
+	}
+	if err := json.Unmarshal(result, &value); err != nil {
+		return value, fmt.Errorf("failed to parse result of %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// listArgument encodes a list of strings as the JSON array the contract
+// parses. A nil list is passed as an empty array, as the contract rejects null
+// where it expects an array.
+func listArgument(values []string) string {
+	if values == nil {
+		values = []string{}
+	}
+	valuesJSON, _ := json.Marshal(values) // a list of strings always marshals
+	return string(valuesJSON)
+}
+
+// jsonArgument encodes an argument the contract parses as JSON
+func jsonArgument(name string, value interface{}) (string, error) {
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	return string(valueJSON), nil
+}
+
+// withPrivateDetails passes private details to a transaction in its transient
+// map, so they are not recorded in the transaction
+func withPrivateDetails(details *EvidencePrivateDetails) (client.ProposalOption, error) {
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private details: %w", err)
+	}
+	return client.WithTransient(map[string][]byte{privateDetailsKey: detailsJSON}), nil
+}
@@ -0,0 +1,262 @@
+package evidence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// testIdentity is the identity of the client connected to the fake gateway
+type testIdentity struct{}
+
+func (testIdentity) MspID() string       { return "Org1MSP" }
+func (testIdentity) Credentials() []byte { return []byte("officer1") }
+
+// proposal is a transaction proposal received by the fake gateway
+type proposal struct {
+	Transaction string
+	Arguments   []string
+	Transient   map[string][]byte
+}
+
+// fakeGateway is a gateway connection that records the proposals it receives.
+// It answers evaluated transactions with its result, and rejects every
+// proposal with its error, if it has one. Proposals to be submitted are
+// always rejected, as it does not order transactions.
+type fakeGateway struct {
+	t         *testing.T
+	proposals []proposal
+	result    []byte
+	err       error
+}
+
+func (f *fakeGateway) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	switch request := args.(type) {
+	case *gateway.EvaluateRequest:
+		f.record(request.GetProposedTransaction())
+		if f.err != nil {
+			return f.err
+		}
+		reply.(*gateway.EvaluateResponse).Result = &peer.Response{Status: 200, Payload: f.result}
+		return nil
+	case *gateway.EndorseRequest:
+		f.record(request.GetProposedTransaction())
+		if f.err != nil {
+			return f.err
+		}
+		return errors.New("the fake gateway does not order transactions")
+	}
+	return errors.New("the fake gateway does not implement " + method)
+}
+
+func (f *fakeGateway) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, errors.New("the fake gateway does not implement " + method)
+}
+
+// record decodes a signed proposal and records its transaction, arguments and
+// transient map
+func (f *fakeGateway) record(signedProposal *peer.SignedProposal) {
+	var proposed peer.Proposal
+	require.NoError(f.t, proto.Unmarshal(signedProposal.GetProposalBytes(), &proposed))
+	var payload peer.ChaincodeProposalPayload
+	require.NoError(f.t, proto.Unmarshal(proposed.GetPayload(), &payload))
+	var invocation peer.ChaincodeInvocationSpec
+	require.NoError(f.t, proto.Unmarshal(payload.GetInput(), &invocation))
+
+	args := invocation.GetChaincodeSpec().GetInput().GetArgs()
+	received := proposal{Transaction: string(args[0]), Transient: payload.GetTransientMap()}
+	for _, arg := range args[1:] {
+		received.Arguments = append(received.Arguments, string(arg))
+	}
+	f.proposals = append(f.proposals, received)
+}
+
+// lastProposal returns the proposal the fake gateway received last
+func (f *fakeGateway) lastProposal() proposal {
+	require.NotEmpty(f.t, f.proposals)
+	return f.proposals[len(f.proposals)-1]
+}
+
+// newTestContract returns the evidence contract over a fake gateway
+func newTestContract(t *testing.T) (*Contract, *fakeGateway) {
+	fake := &fakeGateway{t: t}
+	gw, err := client.Connect(
+		testIdentity{},
+		client.WithSign(func(digest []byte) ([]byte, error) { return digest, nil }),
+		client.WithClientConnection(fake),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { gw.Close() })
+
+	return NewContract(gw.GetNetwork("mychannel").GetContract("evidence")), fake
+}
+
+func TestListArgument(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		json   string
+	}{
+		{name: "nil", values: nil, json: `[]`},
+		{name: "empty", values: []string{}, json: `[]`},
+		{name: "values", values: []string{"dna", "blood"}, json: `["dna","blood"]`},
+		{name: "quoted", values: []string{`"quoted"`, "comma,separated"}, json: `["\"quoted\"","comma,separated"]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.json, listArgument(test.values))
+		})
+	}
+}
+
+func TestArguments(t *testing.T) {
+	proofVerified := false
+	tests := []struct {
+		name      string
+		call      func(*Contract) error
+		proposal  proposal
+		transient map[string]interface{}
+	}{
+		{
+			name: "list arguments",
+			call: func(c *Contract) error {
+				_, err := c.SubmitEvidence("EV1", "Knife", "CASE1", "", "QmHash", nil, "{}")
+				return err
+			},
+			proposal: proposal{Transaction: "SubmitEvidence", Arguments: []string{"EV1", "Knife", "CASE1", "", "QmHash", `[]`, "{}"}},
+		},
+		{
+			name: "list arguments with values",
+			call: func(c *Contract) error {
+				_, err := c.SetAccessPolicy("SubmitEvidence", []string{"officer", "admin"}, nil)
+				return err
+			},
+			proposal: proposal{Transaction: "SetAccessPolicy", Arguments: []string{"SubmitEvidence", `["officer","admin"]`, `[]`}},
+		},
+		{
+			name: "JSON argument",
+			call: func(c *Contract) error {
+				_, err := c.SearchEvidenceAdvanced(EvidenceSearch{AnyTags: []string{"dna"}, ProofVerified: &proofVerified})
+				return err
+			},
+			proposal: proposal{Transaction: "SearchEvidenceAdvanced", Arguments: []string{`{"AnyTags":["dna"],"ProofVerified":false}`}},
+		},
+		{
+			name: "page size",
+			call: func(c *Contract) error {
+				_, err := c.SearchEvidenceByTagsWithPagination([]string{"dna"}, 25, "bookmark")
+				return err
+			},
+			proposal: proposal{Transaction: "SearchEvidenceByTagsWithPagination", Arguments: []string{`["dna"]`, "25", "bookmark"}},
+		},
+		{
+			name: "private details",
+			call: func(c *Contract) error {
+				_, err := c.SubmitPrivateEvidence("EV1", "CASE1", "", "QmHash", []string{"dna"}, &EvidencePrivateDetails{
+					EvidenceID:  "EV1",
+					Description: "Knife",
+					Metadata:    "{}",
+					Salt:        "0123456789abcdef",
+				})
+				return err
+			},
+			proposal: proposal{Transaction: "SubmitPrivateEvidence", Arguments: []string{"EV1", "CASE1", "", "QmHash", `["dna"]`}},
+			transient: map[string]interface{}{
+				"EvidenceID":  "EV1",
+				"Description": "Knife",
+				"Metadata":    "{}",
+				"Salt":        "0123456789abcdef",
+			},
+		},
+		{
+			name: "private details to move",
+			call: func(c *Contract) error {
+				_, err := c.MoveEvidenceDetailsToPrivate("EV1", "0123456789abcdef")
+				return err
+			},
+			proposal: proposal{Transaction: "MoveEvidenceDetailsToPrivate", Arguments: []string{"EV1"}},
+			transient: map[string]interface{}{
+				"EvidenceID":  "EV1",
+				"Description": "",
+				"Metadata":    "",
+				"Salt":        "0123456789abcdef",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			contract, fake := newTestContract(t)
+			_ = test.call(contract) // submitted transactions are rejected once proposed
+
+			received := fake.lastProposal()
+			require.Equal(t, test.proposal.Transaction, received.Transaction)
+			require.Equal(t, test.proposal.Arguments, received.Arguments)
+
+			if test.transient == nil {
+				require.Empty(t, received.Transient)
+				return
+			}
+			require.Len(t, received.Transient, 1)
+			var details map[string]interface{}
+			require.NoError(t, json.Unmarshal(received.Transient[privateDetailsKey], &details))
+			require.Equal(t, test.transient, details)
+		})
+	}
+}
+
+func TestContractErrors(t *testing.T) {
+	t.Run("endorsement", func(t *testing.T) {
+		contract, fake := newTestContract(t)
+		fake.err = endorseError("the evidence EV1 already exists")
+
+		_, err := contract.SubmitEvidence("EV1", "Knife", "CASE1", "", "QmHash", nil, "{}")
+		require.ErrorIs(t, err, ErrAlreadyExists)
+
+		var contractErr *Error
+		require.ErrorAs(t, err, &contractErr)
+		require.Equal(t, "SubmitEvidence", contractErr.Transaction)
+		require.NotEmpty(t, contractErr.TransactionID)
+		require.Equal(t, "the evidence EV1 already exists", contractErr.Message)
+
+		var endorseErr *client.EndorseError
+		require.ErrorAs(t, err, &endorseErr)
+		require.Equal(t, endorseErr.TransactionID, contractErr.TransactionID)
+	})
+
+	t.Run("evaluation", func(t *testing.T) {
+		contract, fake := newTestContract(t)
+		fake.err = evaluateError("the evidence EV1 does not exist")
+
+		_, err := contract.ReadEvidence("EV1")
+		require.ErrorIs(t, err, ErrNotFound)
+		require.EqualError(t, err, "ReadEvidence failed: the evidence EV1 does not exist")
+	})
+}
+
+func TestResults(t *testing.T) {
+	contract, fake := newTestContract(t)
+
+	evidence, err := contract.ReadEvidence("EV1")
+	require.NoError(t, err)
+	require.Nil(t, evidence, "an empty result decodes to the zero value")
+
+	fake.result = []byte(`{"ID":"EV1","CaseID":"CASE1"}`)
+	evidence, err = contract.ReadEvidence("EV1")
+	require.NoError(t, err)
+	require.Equal(t, "EV1", evidence.ID)
+	require.Equal(t, "CASE1", evidence.CaseID)
+
+	fake.result = []byte(`not json`)
+	_, err = contract.ReadEvidence("EV1")
+	require.ErrorContains(t, err, "failed to parse result of ReadEvidence")
+}
@@ -0,0 +1,32 @@
+package evidence
+
+import (
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// RequestCustodyTransfer starts a hand-off of the evidence to another party.
+// Custody does not change until the receiving party accepts it.
+func (c *Contract) RequestCustodyTransfer(id string, toCustodian string, reason string, location string) (*client.Status, error) {
+	_, status, err := c.submit("RequestCustodyTransfer", client.WithArguments(id, toCustodian, reason, location))
+	return status, err
+}
+
+// AcceptCustodyTransfer completes a pending hand-off, making the submitting
+// client the current custodian
+func (c *Contract) AcceptCustodyTransfer(id string) (*client.Status, error) {
+	_, status, err := c.submit("AcceptCustodyTransfer", client.WithArguments(id))
+	return status, err
+}
+
+// RejectCustodyTransfer cancels a pending hand-off. Custody stays with the
+// current custodian.
+func (c *Contract) RejectCustodyTransfer(id string, reason string) (*client.Status, error) {
+	_, status, err := c.submit("RejectCustodyTransfer", client.WithArguments(id, reason))
+	return status, err
+}
+
+// GetCustodyChain returns the ordered chain of custody for an evidence item,
+// with any gaps found in it
+func (c *Contract) GetCustodyChain(id string) (*CustodyChain, error) {
+	return evaluateResult[*CustodyChain](c, "GetCustodyChain", client.WithArguments(id))
+}
@@ -0,0 +1,122 @@
+package evidence
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"google.golang.org/grpc/status"
+)
+
+// Kinds of error returned by the contract. Errors returned by the methods of
+// Contract match them with errors.Is.
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrAlreadyExists     = errors.New("already exists")
+	ErrForbidden         = errors.New("forbidden")
+	ErrInvalidTransition = errors.New("invalid transition")
+)
+
+// Error is a transaction that failed to be endorsed, submitted or committed.
+// It wraps the error returned by the gateway, which can be inspected with
+// errors.As, and the kind of error, if the contract returned one it
+// recognizes.
+type Error struct {
+	Transaction   string // Name of the transaction
+	TransactionID string // ID of the transaction, if it got as far as being proposed
+	Message       string // Error message returned by the contract, or by the gateway if the contract returned none
+	kind          error
+	err           error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s failed: %s", e.Transaction, e.Message)
+}
+
+// Unwrap returns the kind of error and the error returned by the gateway
+func (e *Error) Unwrap() []error {
+	var errs []error
+	if e.kind != nil {
+		errs = append(errs, e.kind)
+	}
+	return append(errs, e.err)
+}
+
+// chaincodeResponse finds the message of the contract in the error message of
+// the gateway, such as "chaincode response 500, the evidence EV1 does not
+// exist"
+var chaincodeResponse = regexp.MustCompile(`chaincode response \d+, (.*)`)
+
+// Messages of the contract for each kind of error
+var errorKinds = []struct {
+	kind    error
+	message *regexp.Regexp
+}{
+	{kind: ErrForbidden, message: regexp.MustCompile(`not authorized to|submitting client is not (the|a) |only the current custodian may |role \S+ may not `)},
+	{kind: ErrNotFound, message: regexp.MustCompile(`the .+ does not exist|no ZK proof exists for `)},
+	{kind: ErrAlreadyExists, message: regexp.MustCompile(`the .+ already exists`)},
+	{kind: ErrInvalidTransition, message: regexp.MustCompile(`transition from \S+ to \S+ is not permitted|cannot move case |evidence moves from \S+ to \S+ only once `)},
+}
+
+// newError wraps an error returned by the gateway for a transaction
+func newError(name string, err error) *Error {
+	message := chaincodeMessage(err)
+	result := &Error{
+		Transaction:   name,
+		TransactionID: transactionID(err),
+		Message:       message,
+		err:           err,
+	}
+
+	for _, errorKind := range errorKinds {
+		if errorKind.message.MatchString(message) {
+			result.kind = errorKind.kind
+			break
+		}
+	}
+
+	return result
+}
+
+// chaincodeMessage returns the error message of the contract. Endorsing peers
+// report it in the details of the gRPC status; an evaluated transaction also
+// reports it in the status message.
+func chaincodeMessage(err error) string {
+	grpcStatus, ok := status.FromError(err)
+	if !ok {
+		return err.Error()
+	}
+
+	for _, detail := range grpcStatus.Details() {
+		if errorDetail, ok := detail.(*gateway.ErrorDetail); ok {
+			if match := chaincodeResponse.FindStringSubmatch(errorDetail.GetMessage()); match != nil {
+				return match[1]
+			}
+		}
+	}
+	if match := chaincodeResponse.FindStringSubmatch(grpcStatus.Message()); match != nil {
+		return match[1]
+	}
+
+	return grpcStatus.Message()
+}
+
+// transactionID returns the ID of the transaction that failed, if the error
+// carries one
+func transactionID(err error) string {
+	var endorseErr *client.EndorseError
+	var submitErr *client.SubmitError
+	var commitStatusErr *client.CommitStatusError
+
+	switch {
+	case errors.As(err, &endorseErr):
+		return endorseErr.TransactionID
+	case errors.As(err, &submitErr):
+		return submitErr.TransactionID
+	case errors.As(err, &commitStatusErr):
+		return commitStatusErr.TransactionID
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package evidence
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// endorseError is the error the gateway returns when the contract rejects a
+// proposal: the message of the contract is in the details of the gRPC status
+func endorseError(message string) error {
+	grpcStatus, err := status.New(codes.Aborted, "failed to endorse transaction, see attached details for more info").
+		WithDetails(&gateway.ErrorDetail{
+			Address: "peer0.org1.example.com:7051",
+			MspId:   "Org1MSP",
+			Message: "chaincode response 500, " + message,
+		})
+	if err != nil {
+		panic(err)
+	}
+	return grpcStatus.Err()
+}
+
+// evaluateError is the error the gateway returns when the contract rejects an
+// evaluated transaction: the message of the contract is in the status message
+func evaluateError(message string) error {
+	return status.Error(codes.Unknown, "evaluate call to endorser returned error: chaincode response 500, "+message)
+}
+
+func TestErrorKinds(t *testing.T) {
+	tests := []struct {
+		message string
+		kind    error
+	}{
+		// Messages of the contract, as it formats them
+		{message: "submitting client not authorized to call SubmitEvidence, does not have a role attribute", kind: ErrForbidden},
+		{message: "submitting client not authorized to call SubmitEvidence, role auditor is not permitted", kind: ErrForbidden},
+		{message: "submitting client not authorized to call SubmitEvidence, organization Org2MSP is not permitted", kind: ErrForbidden},
+		{message: "submitting client is not the current custodian of evidence EV1", kind: ErrForbidden},
+		{message: "submitting client is not the lead investigator of case CASE1", kind: ErrForbidden},
+		{message: "submitting client is not a designated verifier of evidence EV1", kind: ErrForbidden},
+		{message: "submitting client is not a party to the pending custody transfer of evidence EV1", kind: ErrForbidden},
+		{message: "only the current custodian may commit to the file of evidence EV1", kind: ErrForbidden},
+		{message: "role analyst may not move evidence from RECEIVED to ANALYZED", kind: ErrForbidden},
+		{message: "the evidence EV1 does not exist", kind: ErrNotFound},
+		{message: "the case CASE1 does not exist", kind: ErrNotFound},
+		{message: "the evidence type DNA version 2 does not exist", kind: ErrNotFound},
+		{message: "the legal hold HOLD1 does not exist on case CASE1", kind: ErrNotFound},
+		{message: "no ZK proof exists for evidence EV1 at time 2024-01-01T00:00:00Z in transaction tx1", kind: ErrNotFound},
+		{message: "the evidence EV1 already exists", kind: ErrAlreadyExists},
+		{message: "the analyzer ANALYZER1 already exists", kind: ErrAlreadyExists},
+		{message: "the legal hold HOLD1 already exists on case CASE1", kind: ErrAlreadyExists},
+		{message: "transition from SUBMITTED to DESTROYED is not permitted", kind: ErrInvalidTransition},
+		{message: "cannot move case CASE1 from CLOSED to OPEN", kind: ErrInvalidTransition},
+		{message: "evidence moves from ANALYZED to VERIFIED only once its designated verifiers approve it with ApproveVerification", kind: ErrInvalidTransition},
+
+		// Messages that are not one of the kinds
+		{message: "evidence EV1 is not a bundle"},
+		{message: "page size must be between 1 and 1000, not 0"},
+		{message: "failed to read from world state: timeout"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.message, func(t *testing.T) {
+			for _, err := range []error{endorseError(test.message), evaluateError(test.message), errors.New(test.message)} {
+				contractErr := newError("Transaction", err)
+				require.Equal(t, test.message, contractErr.Message)
+				require.ErrorIs(t, contractErr, err)
+
+				for _, kind := range []error{ErrForbidden, ErrNotFound, ErrAlreadyExists, ErrInvalidTransition} {
+					require.Equal(t, kind == test.kind, errors.Is(contractErr, kind), "errors.Is(%v)", kind)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		message string
+	}{
+		{
+			name:    "chaincode response in details",
+			err:     endorseError("the evidence EV1 does not exist"),
+			message: "the evidence EV1 does not exist",
+		},
+		{
+			name:    "chaincode response in status message",
+			err:     evaluateError("the evidence EV1 does not exist"),
+			message: "the evidence EV1 does not exist",
+		},
+		{
+			name:    "status without chaincode response",
+			err:     status.Error(codes.Unavailable, "connection refused"),
+			message: "connection refused",
+		},
+		{
+			name:    "not a status",
+			err:     errors.New("context deadline exceeded"),
+			message: "context deadline exceeded",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			contractErr := newError("ReadEvidence", test.err)
+			require.Equal(t, test.message, contractErr.Message)
+			require.Equal(t, "ReadEvidence failed: "+test.message, contractErr.Error())
+		})
+	}
+}
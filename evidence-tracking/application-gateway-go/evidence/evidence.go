@@ -0,0 +1,176 @@
+package evidence
+
+import (
+	"strconv"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// InitLedger adds a base set of evidence records to the ledger
+func (c *Contract) InitLedger() (*client.Status, error) {
+	_, status, err := c.submit("InitLedger")
+	return status, err
+}
+
+// SubmitEvidence issues a new evidence record. The metadata must match the
+// schema of the evidence type, unless the type is empty.
+func (c *Contract) SubmitEvidence(id string, description string, caseID string, evidenceType string, fileHash string, tags []string, metadata string) (*client.Status, error) {
+	_, status, err := c.submit("SubmitEvidence", client.WithArguments(id, description, caseID, evidenceType, fileHash, listArgument(tags), metadata))
+	return status, err
+}
+
+// ReadEvidence returns the evidence record with the given ID
+func (c *Contract) ReadEvidence(id string) (*Evidence, error) {
+	return evaluateResult[*Evidence](c, "ReadEvidence", client.WithArguments(id))
+}
+
+// UpdateEvidenceStatus moves evidence to a new status. GetAllowedTransitions
+// lists the statuses it may move to and whether a reason code is required.
+func (c *Contract) UpdateEvidenceStatus(id string, newStatus string, reasonCode string) (*client.Status, error) {
+	_, status, err := c.submit("UpdateEvidenceStatus", client.WithArguments(id, newStatus, reasonCode))
+	return status, err
+}
+
+// UpdateEvidence updates the description, file hash, tags and metadata of an
+// evidence record
+func (c *Contract) UpdateEvidence(id string, description string, fileHash string, tags []string, metadata string) (*client.Status, error) {
+	_, status, err := c.submit("UpdateEvidence", client.WithArguments(id, description, fileHash, listArgument(tags), metadata))
+	return status, err
+}
+
+// EvidenceExists returns true when evidence with the given ID exists
+func (c *Contract) EvidenceExists(id string) (bool, error) {
+	return evaluateResult[bool](c, "EvidenceExists", client.WithArguments(id))
+}
+
+// GetEvidenceByCase returns all evidence submitted against a case
+func (c *Contract) GetEvidenceByCase(caseID string) ([]*Evidence, error) {
+	return evaluateResult[[]*Evidence](c, "GetEvidenceByCase", client.WithArguments(caseID))
+}
+
+// GetAllEvidence returns all evidence on the ledger
+func (c *Contract) GetAllEvidence() ([]*Evidence, error) {
+	return evaluateResult[[]*Evidence](c, "GetAllEvidence")
+}
+
+// GetEvidenceHistory returns the history chain of an evidence item, oldest
+// entry first
+func (c *Contract) GetEvidenceHistory(id string) ([]*EvidenceHistory, error) {
+	return evaluateResult[[]*EvidenceHistory](c, "GetEvidenceHistory", client.WithArguments(id))
+}
+
+// VerifyEvidenceIntegrity recomputes the integrity hash of an evidence record,
+// and of the items it was derived from or is part of, and returns whether they
+// all match. The outcome is recorded in an IntegrityVerified event.
+func (c *Contract) VerifyEvidenceIntegrity(id string) (bool, *client.Status, error) {
+	return submitResult[bool](c, "VerifyEvidenceIntegrity", client.WithArguments(id))
+}
+
+// ReportIntegrityFailure records that a stored copy of an evidence file,
+// re-hashed off-chain, no longer matches the file hash of the evidence
+func (c *Contract) ReportIntegrityFailure(id string, observedHash string, source string, details string) (*client.Status, error) {
+	_, status, err := c.submit("ReportIntegrityFailure", client.WithArguments(id, observedHash, source, details))
+	return status, err
+}
+
+// GetIntegrityFailures returns the integrity failures reported for an
+// evidence item, oldest first
+func (c *Contract) GetIntegrityFailures(id string) ([]*IntegrityFailure, error) {
+	return evaluateResult[[]*IntegrityFailure](c, "GetIntegrityFailures", client.WithArguments(id))
+}
+
+// CreateZKProof records a zero-knowledge proof that the prover knows the
+// opening of the file commitment of the evidence, bound to a verifier
+func (c *Contract) CreateZKProof(evidenceID string, proof CommitmentProof, verifierID string) (*ZKProof, *client.Status, error) {
+	proofJSON, err := jsonArgument("proof", proof)
+	if err != nil {
+		return nil, nil, err
+	}
+	return submitResult[*ZKProof](c, "CreateZKProof", client.WithArguments(evidenceID, proofJSON, verifierID))
+}
+
+// VerifyZKProof verifies a zero-knowledge proof, identified by the time and
+// transaction ID at which it was created, and marks the evidence as proven
+// when the proof holds. Only the verifier the proof is bound to may verify it.
+func (c *Contract) VerifyZKProof(evidenceID string, proofTime string, proofTxID string) (bool, *client.Status, error) {
+	return submitResult[bool](c, "VerifyZKProof", client.WithArguments(evidenceID, proofTime, proofTxID))
+}
+
+// GetAIAnalysisResults returns the analysis results recorded for an evidence
+// item
+func (c *Contract) GetAIAnalysisResults(evidenceID string) ([]*AIAnalysisResult, error) {
+	return evaluateResult[[]*AIAnalysisResult](c, "GetAIAnalysisResults", client.WithArguments(evidenceID))
+}
+
+// GetZKProofs returns the zero-knowledge proofs recorded for an evidence item
+func (c *Contract) GetZKProofs(evidenceID string) ([]*ZKProof, error) {
+	return evaluateResult[[]*ZKProof](c, "GetZKProofs", client.WithArguments(evidenceID))
+}
+
+// SearchEvidenceByTags returns the evidence with all of the given tags
+func (c *Contract) SearchEvidenceByTags(tags []string) ([]*Evidence, error) {
+	return evaluateResult[[]*Evidence](c, "SearchEvidenceByTags", client.WithArguments(listArgument(tags)))
+}
+
+// SearchEvidenceAdvanced returns the evidence matching every criterion of a
+// search
+func (c *Contract) SearchEvidenceAdvanced(search EvidenceSearch) ([]*Evidence, error) {
+	searchJSON, err := jsonArgument("search", search)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateResult[[]*Evidence](c, "SearchEvidenceAdvanced", client.WithArguments(searchJSON))
+}
+
+// GetAllEvidenceWithPagination returns a page of all evidence. An empty
+// bookmark fetches the first page, and the bookmark of the page fetches the
+// next; it is empty after the last page.
+func (c *Contract) GetAllEvidenceWithPagination(pageSize int, bookmark string) (*EvidencePage, error) {
+	return evaluateResult[*EvidencePage](c, "GetAllEvidenceWithPagination", client.WithArguments(strconv.Itoa(pageSize), bookmark))
+}
+
+// GetEvidenceByCaseWithPagination returns a page of the evidence of a case
+func (c *Contract) GetEvidenceByCaseWithPagination(caseID string, pageSize int, bookmark string) (*EvidencePage, error) {
+	return evaluateResult[*EvidencePage](c, "GetEvidenceByCaseWithPagination", client.WithArguments(caseID, strconv.Itoa(pageSize), bookmark))
+}
+
+// SearchEvidenceByTagsWithPagination returns a page of the evidence with all
+// of the given tags
+func (c *Contract) SearchEvidenceByTagsWithPagination(tags []string, pageSize int, bookmark string) (*EvidencePage, error) {
+	return evaluateResult[*EvidencePage](c, "SearchEvidenceByTagsWithPagination", client.WithArguments(listArgument(tags), strconv.Itoa(pageSize), bookmark))
+}
+
+// SearchEvidenceAdvancedWithPagination returns a page of the evidence matching
+// a search. The page size takes the place of the limit of the search.
+func (c *Contract) SearchEvidenceAdvancedWithPagination(search EvidenceSearch, pageSize int, bookmark string) (*EvidencePage, error) {
+	searchJSON, err := jsonArgument("search", search)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateResult[*EvidencePage](c, "SearchEvidenceAdvancedWithPagination", client.WithArguments(searchJSON, strconv.Itoa(pageSize), bookmark))
+}
+
+// GetAllowedTransitions returns the status changes the submitting client may
+// make to an evidence item
+func (c *Contract) GetAllowedTransitions(id string) ([]*StatusTransition, error) {
+	return evaluateResult[[]*StatusTransition](c, "GetAllowedTransitions", client.WithArguments(id))
+}
+
+// VerifyHistoryChain checks that the history chain of an evidence item is
+// intact
+func (c *Contract) VerifyHistoryChain(id string) (*HistoryChainReport, error) {
+	return evaluateResult[*HistoryChainReport](c, "VerifyHistoryChain", client.WithArguments(id))
+}
+
+// ExportHistoryProof exports the history chain of an evidence item together
+// with the transactions that wrote each entry, so that it can be checked
+// without access to the ledger
+func (c *Contract) ExportHistoryProof(id string) (*HistoryProof, error) {
+	return evaluateResult[*HistoryProof](c, "ExportHistoryProof", client.WithArguments(id))
+}
+
+// MigrateRecords upgrades up to batch evidence records stored in an older
+// layout, and returns how many it upgraded. Call it until it returns 0.
+func (c *Contract) MigrateRecords(batch int) (int, *client.Status, error) {
+	return submitResult[int](c, "MigrateRecords", client.WithArguments(strconv.Itoa(batch)))
+}
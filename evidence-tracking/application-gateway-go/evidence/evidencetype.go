@@ -0,0 +1,24 @@
+package evidence
+
+import (
+	"strconv"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// RegisterEvidenceType registers a new version of an evidence type, or its
+// first version, with the JSON Schema its metadata must match
+func (c *Contract) RegisterEvidenceType(name string, description string, schemaJSON string) (*EvidenceType, *client.Status, error) {
+	return submitResult[*EvidenceType](c, "RegisterEvidenceType", client.WithArguments(name, description, schemaJSON))
+}
+
+// ListEvidenceTypes returns the latest version of every evidence type
+func (c *Contract) ListEvidenceTypes() ([]*EvidenceType, error) {
+	return evaluateResult[[]*EvidenceType](c, "ListEvidenceTypes")
+}
+
+// GetEvidenceType returns a version of an evidence type, or its latest version
+// when version is 0
+func (c *Contract) GetEvidenceType(name string, version int) (*EvidenceType, error) {
+	return evaluateResult[*EvidenceType](c, "GetEvidenceType", client.WithArguments(name, strconv.Itoa(version)))
+}
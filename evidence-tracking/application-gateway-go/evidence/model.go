@@ -0,0 +1,526 @@
+package evidence
+
+// Evidence describes the structure of an evidence record
+type Evidence struct {
+	DocType             string           `json:"DocType"`                   // Always "evidence", distinguishes evidence in rich queries
+	ID                  string           `json:"ID"`                        // Unique identifier for the evidence
+	Description         string           `json:"Description"`               // Description of the evidence, empty when it is kept in the private details
+	CaseID              string           `json:"CaseID"`                    // ID of the case this evidence is associated with
+	FileHash            string           `json:"FileHash"`                  // IPFS hash of the evidence file
+	SubmittedBy         string           `json:"SubmittedBy"`               // ID of the user who submitted the evidence
+	SubmittedTime       string           `json:"SubmittedTime"`             // Timestamp when evidence was submitted
+	Status              string           `json:"Status"`                    // Current status of the evidence (e.g., "submitted", "processing", "verified")
+	StatusUpdatedTime   string           `json:"StatusUpdatedTime"`         // Timestamp when the evidence entered its current status
+	Tags                []string         `json:"Tags"`                      // Tags for categorizing evidence
+	Metadata            string           `json:"Metadata"`                  // Additional metadata in JSON format, empty when it is kept in the private details
+	EvidenceType        string           `json:"EvidenceType"`              // Type of the evidence, whose schema the metadata matches; empty for general evidence
+	EvidenceTypeVersion int              `json:"EvidenceTypeVersion"`       // Version of the evidence type the metadata was validated against
+	SchemaVersion       int              `json:"SchemaVersion"`             // Version of the layout the record is stored in
+	Integrity           string           `json:"Integrity"`                 // Hash of the record's content, recomputed on every write
+	IntegrityVersion    int              `json:"IntegrityVersion"`          // Version of the algorithm that computed Integrity
+	ProofVerified       bool             `json:"ProofVerified"`             // Whether zero-knowledge proof has been verified
+	AIVerified          bool             `json:"AIVerified"`                // Whether AI has verified the evidence integrity
+	FileCommitment      string           `json:"FileCommitment"`            // Pedersen commitment to the file digest that zero-knowledge proofs refer to
+	CurrentCustodian    string           `json:"CurrentCustodian"`          // ID of the party currently holding the evidence
+	PendingTransfer     *CustodyTransfer `json:"PendingTransfer,omitempty"` // Custody hand-off awaiting acceptance, if any
+	PrivateDetailsHash  string           `json:"PrivateDetailsHash"`        // SHA-256 of the private details held in the private data collection, if any
+}
+
+// EvidenceHistory describes a single change to an evidence record. The
+// entries of an evidence item form a hash chain.
+type EvidenceHistory struct {
+	EvidenceID        string `json:"EvidenceID"`        // ID of the evidence that was modified
+	Sequence          int    `json:"Sequence"`          // Position of the entry in the evidence item's history chain
+	ModifiedBy        string `json:"ModifiedBy"`        // ID of the user who made the modification
+	ModifiedAt        string `json:"ModifiedAt"`        // Timestamp of when the modification occurred
+	Action            string `json:"Action"`            // Type of action (e.g., "create", "update", "access")
+	Description       string `json:"Description"`       // Description of the changes made
+	PrevState         string `json:"PrevState"`         // JSON representation of the previous state (if applicable)
+	TxID              string `json:"TxID"`              // ID of the transaction that made the modification
+	PreviousIntegrity string `json:"PreviousIntegrity"` // Integrity hash of the record before the modification
+	Integrity         string `json:"Integrity"`         // Integrity hash of the record after the modification
+	PreviousEntryHash string `json:"PreviousEntryHash"` // Hash of the previous history entry, empty for the first
+	EntryHash         string `json:"EntryHash"`         // Hash of this entry, covering every other field
+}
+
+// ZKProof represents a zero-knowledge proof structure
+type ZKProof struct {
+	EvidenceID       string `json:"EvidenceID"`       // ID of the evidence
+	Curve            string `json:"Curve"`            // Elliptic curve group the proof is made in
+	GeneratorG       string `json:"GeneratorG"`       // Base point G of the group, compressed and hex encoded
+	GeneratorH       string `json:"GeneratorH"`       // Second generator H of the group, compressed and hex encoded
+	Commitment       string `json:"Commitment"`       // Pedersen commitment C = m·G + r·H to the file digest m
+	NonceCommitment  string `json:"NonceCommitment"`  // Prover's nonce commitment T
+	Challenge        string `json:"Challenge"`        // Fiat-Shamir challenge c
+	ResponseValue    string `json:"ResponseValue"`    // Response s1 for the committed digest
+	ResponseBlinding string `json:"ResponseBlinding"` // Response s2 for the blinding factor
+	ProverID         string `json:"ProverID"`         // ID of the client that submitted the proof
+	VerifierID       string `json:"VerifierID"`       // ID of the client the proof is bound to, the only one that may verify it
+	CreatedTime      string `json:"CreatedTime"`      // When the proof was created
+	TxID             string `json:"TxID"`             // ID of the transaction that created the proof
+}
+
+// AIAnalysisResult represents the result of AI-based tamper detection
+type AIAnalysisResult struct {
+	EvidenceID        string  `json:"EvidenceID"`        // ID of the evidence
+	TamperProbability float64 `json:"TamperProbability"` // Probability of tampering (0-1)
+	AnalysisDetails   string  `json:"AnalysisDetails"`   // Details of the analysis in JSON format
+	AnalyzedBy        string  `json:"AnalyzedBy"`        // ID of the registered analyzer
+	AnalyzedTime      string  `json:"AnalyzedTime"`      // When the result was recorded on the ledger
+	AnalyzerTime      string  `json:"AnalyzerTime"`      // When the analyzer performed the analysis, as signed by it
+	FileHash          string  `json:"FileHash"`          // File hash the analysis was performed on
+	Signature         string  `json:"Signature"`         // Analyzer's signature over the analysis statement
+	SubmittedBy       string  `json:"SubmittedBy"`       // ID of the client that submitted the result
+	TxID              string  `json:"TxID"`              // ID of the transaction that recorded the analysis
+}
+
+// EvidencePage is a page of evidence records, with the bookmark from which to
+// fetch the next page
+type EvidencePage struct {
+	Records             []*Evidence `json:"records"`
+	FetchedRecordsCount int32       `json:"fetchedRecordsCount"`
+	Bookmark            string      `json:"bookmark"`
+}
+
+// EvidenceSearch describes a structured evidence search. Every criterion is
+// optional, and a record must satisfy all the criteria that are given. Tags
+// are matched without regard to case.
+type EvidenceSearch struct {
+	CaseID         string   `json:"CaseID,omitempty"`         // Evidence of this case only
+	Statuses       []string `json:"Statuses,omitempty"`       // Evidence in any of these statuses
+	AnyTags        []string `json:"AnyTags,omitempty"`        // Evidence with at least one of these tags
+	AllTags        []string `json:"AllTags,omitempty"`        // Evidence with every one of these tags
+	SubmittedBy    string   `json:"SubmittedBy,omitempty"`    // Evidence submitted by this client
+	SubmittedAfter string   `json:"SubmittedAfter,omitempty"` // Evidence submitted at or after this RFC 3339 time
+	SubmittedUntil string   `json:"SubmittedUntil,omitempty"` // Evidence submitted at or before this RFC 3339 time
+	Custodian      string   `json:"Custodian,omitempty"`      // Evidence currently held by this custodian
+	ProofVerified  *bool    `json:"ProofVerified,omitempty"`  // Filter on the zero-knowledge proof flag, when given
+	AIVerified     *bool    `json:"AIVerified,omitempty"`     // Filter on the AI verification flag, when given
+	SortBy         string   `json:"SortBy,omitempty"`         // SubmittedTime or StatusUpdatedTime
+	SortDescending bool     `json:"SortDescending,omitempty"` // Sort newest first
+	Limit          int      `json:"Limit,omitempty"`          // Maximum number of results, 0 for no limit; ignored by paginated searches
+}
+
+// StatusTransition declares a permitted change of evidence status
+type StatusTransition struct {
+	From           string   `json:"From"`           // Status the evidence must currently have
+	To             string   `json:"To"`             // Status the evidence moves to
+	Roles          []string `json:"Roles"`          // Roles that may make the transition
+	RequiresReason bool     `json:"RequiresReason"` // Whether a reason code must be given
+	RequiresQuorum bool     `json:"RequiresQuorum"` // Whether the change is made by ApproveVerification once enough verifiers approve
+}
+
+// EvidencePrivateDetails are the sensitive fields of an evidence record, such
+// as victim names and locations, kept in the private data collection
+type EvidencePrivateDetails struct {
+	EvidenceID  string `json:"EvidenceID"`  // ID of the evidence
+	Description string `json:"Description"` // Description of the evidence
+	Metadata    string `json:"Metadata"`    // Additional metadata in JSON format
+	Salt        string `json:"Salt"`        // Random value chosen by the client, hashed along with the details
+}
+
+// AccessPolicy lists the roles, and optionally the organizations, allowed to
+// submit a transaction
+type AccessPolicy struct {
+	Function string   `json:"Function"` // Name of the transaction the policy applies to
+	Roles    []string `json:"Roles"`    // Values of the role attribute that may submit the transaction
+	MSPIDs   []string `json:"MSPIDs"`   // Organizations that may submit the transaction, empty for any organization
+}
+
+// AccessRecord records that a client accessed an evidence item, and why
+type AccessRecord struct {
+	EvidenceID string `json:"EvidenceID"` // ID of the evidence that was accessed
+	AccessedBy string `json:"AccessedBy"` // ID of the client that accessed the evidence
+	MSPID      string `json:"MSPID"`      // MSP of the client that accessed the evidence
+	Role       string `json:"Role"`       // Role of the client at the time of access
+	Purpose    string `json:"Purpose"`    // Purpose of the access stated by the client
+	AccessedAt string `json:"AccessedAt"` // When the access was recorded
+	TxID       string `json:"TxID"`       // ID of the transaction that recorded the access
+}
+
+// AccessLogPage is a page of access records, with the bookmark from which to
+// fetch the next page
+type AccessLogPage struct {
+	Records             []*AccessRecord `json:"records"`
+	FetchedRecordsCount int32           `json:"fetchedRecordsCount"`
+	Bookmark            string          `json:"bookmark"`
+}
+
+// Analyzer is an off-chain tamper analysis service whose signed results the
+// contract accepts
+type Analyzer struct {
+	ID           string `json:"ID"`           // Unique identifier for the analyzer
+	Name         string `json:"Name"`         // Human readable name of the analyzer
+	PublicKey    string `json:"PublicKey"`    // PEM encoded ECDSA public key results must be signed with
+	RegisteredBy string `json:"RegisteredBy"` // ID of the administrator that registered the analyzer
+	RegisteredAt string `json:"RegisteredAt"` // When the analyzer was registered
+	Active       bool   `json:"Active"`       // Whether results from the analyzer are accepted
+	RevokedAt    string `json:"RevokedAt"`    // When the analyzer was revoked, empty while it is active
+}
+
+// AnalysisStatement is the content of an analysis result that the analyzer
+// signs. The signature covers the SHA-256 digest of its JSON encoding, with
+// the fields in the order declared here.
+type AnalysisStatement struct {
+	AnalyzerID        string  `json:"AnalyzerID"`        // ID of the analyzer that performed the analysis
+	EvidenceID        string  `json:"EvidenceID"`        // ID of the evidence analyzed
+	FileHash          string  `json:"FileHash"`          // File hash of the evidence at the time of analysis
+	TamperProbability float64 `json:"TamperProbability"` // Probability of tampering (0-1)
+	AnalysisDetails   string  `json:"AnalysisDetails"`   // Details of the analysis in JSON format
+	AnalyzedAt        string  `json:"AnalyzedAt"`        // When the analyzer performed the analysis
+}
+
+// AnalysisAttestation is an analysis statement with the analyzer's signature
+type AnalysisAttestation struct {
+	Statement AnalysisStatement `json:"Statement"` // Signed content of the result
+	Signature string            `json:"Signature"` // Base64 ASN.1 ECDSA signature over the statement digest
+}
+
+// AnalyzerResultRef indexes an analysis result under the analyzer that made it
+type AnalyzerResultRef struct {
+	AnalyzerID        string  `json:"AnalyzerID"`        // ID of the analyzer
+	EvidenceID        string  `json:"EvidenceID"`        // ID of the evidence analyzed
+	TamperProbability float64 `json:"TamperProbability"` // Probability of tampering reported
+	AnalyzedTime      string  `json:"AnalyzedTime"`      // When the result was recorded
+	TxID              string  `json:"TxID"`              // Transaction that recorded the result
+}
+
+// AnalyzerTrackRecord summarises the results an analyzer has submitted
+type AnalyzerTrackRecord struct {
+	Analyzer           *Analyzer            `json:"Analyzer"`           // The analyzer
+	TotalAnalyses      int                  `json:"TotalAnalyses"`      // Number of results accepted from the analyzer
+	TamperFindings     int                  `json:"TamperFindings"`     // Number of results at or above the tamper threshold
+	AverageProbability float64              `json:"AverageProbability"` // Mean tamper probability reported
+	EvidenceAnalyzed   int                  `json:"EvidenceAnalyzed"`   // Number of distinct evidence items analyzed
+	Results            []*AnalyzerResultRef `json:"Results"`            // Every result accepted, oldest first
+}
+
+// EvidenceBundle is the manifest of an evidence item made up of many files,
+// such as a phone extraction. The FileHash of the evidence is the Merkle root
+// of the manifest.
+type EvidenceBundle struct {
+	EvidenceID string       `json:"EvidenceID"` // ID of the evidence
+	Algorithm  string       `json:"Algorithm"`  // How the Merkle root was computed
+	Root       string       `json:"Root"`       // Merkle root of the files
+	Files      []BundleFile `json:"Files"`      // Files of the bundle
+}
+
+// BundleFile is one entry of a bundle manifest. Only the path and digest are
+// committed to by the tree; the size and CID help locate and check a copy of
+// the file.
+type BundleFile struct {
+	Path   string `json:"Path"`          // Path of the file within the bundle
+	Size   int64  `json:"Size"`          // Size of the file in bytes
+	SHA256 string `json:"SHA256"`        // Lowercase hex SHA-256 digest of the file
+	CID    string `json:"CID,omitempty"` // IPFS content identifier of the file, if it is stored on IPFS
+}
+
+// ProofStep is the sibling of a node on the path from a leaf to the root
+type ProofStep struct {
+	Hash string `json:"Hash"` // Hex hash of the sibling
+	Left bool   `json:"Left"` // Whether the sibling is on the left of the node
+}
+
+// BundleProof is the path from a file's leaf to the root of a bundle
+type BundleProof struct {
+	Steps []ProofStep `json:"Steps"`
+}
+
+// Case describes an investigation that evidence is submitted against
+type Case struct {
+	ID               string       `json:"ID"`               // Unique identifier for the case
+	Title            string       `json:"Title"`            // Short title of the case
+	CaseType         string       `json:"CaseType"`         // Type of case, which determines how long its evidence is retained
+	Jurisdiction     string       `json:"Jurisdiction"`     // Court or authority the case falls under
+	LeadInvestigator string       `json:"LeadInvestigator"` // ID of the investigator leading the case
+	AssignedTeam     []string     `json:"AssignedTeam"`     // IDs of the other members assigned to the case
+	Status           string       `json:"Status"`           // Current status of the case (open, closed, archived or appealed)
+	OpenedAt         string       `json:"OpenedAt"`         // When the case was opened
+	ClosedAt         string       `json:"ClosedAt"`         // When the case was last closed, empty while it is open
+	Timeline         []*CaseEvent `json:"Timeline"`         // Every change of case status, oldest first
+}
+
+// CaseEvent records a change of status in the timeline of a case
+type CaseEvent struct {
+	Status    string `json:"Status"`    // Status the case moved to
+	ChangedBy string `json:"ChangedBy"` // ID of the client that made the change
+	ChangedAt string `json:"ChangedAt"` // When the change was made
+	Reason    string `json:"Reason"`    // Why the change was made
+	TxID      string `json:"TxID"`      // Transaction that made the change
+}
+
+// CaseDashboard summarises the state of a case and of all its evidence
+type CaseDashboard struct {
+	Case                *Case             `json:"Case"`                // The case itself
+	TotalEvidence       int               `json:"TotalEvidence"`       // Number of evidence items submitted against the case
+	StatusCounts        []*StatusCount    `json:"StatusCounts"`        // Number of evidence items in each status
+	AIVerifiedEvidence  int               `json:"AIVerifiedEvidence"`  // Number of items verified by AI analysis
+	ZKPVerifiedEvidence int               `json:"ZKPVerifiedEvidence"` // Number of items with a verified zero-knowledge proof
+	CustodyHolders      []*CustodyHolding `json:"CustodyHolders"`      // Who currently holds the evidence of the case
+	PendingAnalyses     []*DashboardItem  `json:"PendingAnalyses"`     // Items received for, or undergoing, analysis
+	OverdueItems        []*DashboardItem  `json:"OverdueItems"`        // Items that have waited too long for their next step
+	GeneratedAt         string            `json:"GeneratedAt"`         // Time the dashboard was computed at
+}
+
+// StatusCount is the number of evidence items in a status
+type StatusCount struct {
+	Status string `json:"Status"` // Evidence status
+	Count  int    `json:"Count"`  // Number of evidence items in the status
+}
+
+// CustodyHolding lists the evidence held by one custodian
+type CustodyHolding struct {
+	Custodian   string   `json:"Custodian"`   // ID of the custodian
+	EvidenceIDs []string `json:"EvidenceIDs"` // Evidence the custodian currently holds
+}
+
+// DashboardItem is an evidence item called out on the case dashboard
+type DashboardItem struct {
+	EvidenceID string `json:"EvidenceID"` // ID of the evidence
+	Status     string `json:"Status"`     // Status of the evidence
+	Since      string `json:"Since"`      // When the item entered the state it is reported for
+	Detail     string `json:"Detail"`     // Human readable explanation
+}
+
+// CustodyTransfer describes a hand-off that has been requested by the current
+// custodian but not yet accepted by the receiving party
+type CustodyTransfer struct {
+	FromCustodian string `json:"FromCustodian"` // ID of the custodian releasing the evidence
+	FromMSPID     string `json:"FromMSPID"`     // MSP of the custodian releasing the evidence
+	ToCustodian   string `json:"ToCustodian"`   // ID of the party that must accept the evidence
+	Reason        string `json:"Reason"`        // Why the evidence is being handed over
+	Location      string `json:"Location"`      // Where the hand-off takes place
+	RequestedAt   string `json:"RequestedAt"`   // When the transfer was requested
+	RequestTxID   string `json:"RequestTxID"`   // Transaction in which the releasing custodian signed the request
+}
+
+// CustodyEntry records a completed hand-off in the chain of custody. The
+// request and the acceptance are separate transactions signed by the releasing
+// and the receiving party, so the two transaction IDs tie each entry to both
+// signatures on the ledger.
+type CustodyEntry struct {
+	EvidenceID    string `json:"EvidenceID"`    // ID of the evidence that changed hands
+	Sequence      int    `json:"Sequence"`      // Position of this entry in the chain, starting at 0
+	FromCustodian string `json:"FromCustodian"` // ID of the releasing custodian (empty when custody is first established)
+	FromMSPID     string `json:"FromMSPID"`     // MSP of the releasing custodian
+	ToCustodian   string `json:"ToCustodian"`   // ID of the receiving custodian
+	ToMSPID       string `json:"ToMSPID"`       // MSP of the receiving custodian
+	Reason        string `json:"Reason"`        // Why the evidence was handed over
+	Location      string `json:"Location"`      // Where the hand-off took place
+	RequestedAt   string `json:"RequestedAt"`   // When the hand-off was requested
+	AcceptedAt    string `json:"AcceptedAt"`    // When the receiving custodian accepted the evidence
+	RequestTxID   string `json:"RequestTxID"`   // Transaction signed by the releasing custodian
+	AcceptTxID    string `json:"AcceptTxID"`    // Transaction signed by the receiving custodian
+}
+
+// CustodyGap describes a break detected while walking the chain of custody
+type CustodyGap struct {
+	Sequence    int    `json:"Sequence"`    // Sequence number at which the break was detected
+	Description string `json:"Description"` // Human readable explanation of the break
+}
+
+// CustodyChain is the ordered chain of custody for an evidence item
+type CustodyChain struct {
+	EvidenceID       string          `json:"EvidenceID"`       // ID of the evidence
+	CurrentCustodian string          `json:"CurrentCustodian"` // ID of the party currently holding the evidence
+	Entries          []*CustodyEntry `json:"Entries"`          // Custody entries ordered by sequence number
+	Gaps             []*CustodyGap   `json:"Gaps"`             // Breaks found in the chain, empty when the chain is unbroken
+	Unbroken         bool            `json:"Unbroken"`         // Whether the chain accounts for every hand-off
+}
+
+// EvidenceType is one version of the definition of a kind of evidence. The
+// metadata of evidence of the type must match its JSON Schema. Registering a
+// type again adds a version; records keep the version their metadata was
+// validated against.
+type EvidenceType struct {
+	Name         string `json:"Name"`         // Name of the type, declared by evidence records
+	Version      int    `json:"Version"`      // Version of the definition, starting at 1
+	Description  string `json:"Description"`  // What the type covers
+	Schema       string `json:"Schema"`       // JSON Schema the metadata must match
+	RegisteredBy string `json:"RegisteredBy"` // ID of the client that registered this version
+	RegisteredAt string `json:"RegisteredAt"` // When this version was registered
+}
+
+// HistoryChainReport is the outcome of verifying the history chain of an
+// evidence item
+type HistoryChainReport struct {
+	EvidenceID string        `json:"EvidenceID"`      // ID of the evidence
+	Length     int           `json:"Length"`          // Number of history entries found
+	HeadHash   string        `json:"HeadHash"`        // Hash of the last entry according to the chain head
+	Valid      bool          `json:"Valid"`           // Whether the chain is intact
+	Break      *HistoryBreak `json:"Break,omitempty"` // First broken link, if any
+}
+
+// HistoryBreak describes the first point at which a history chain fails to
+// verify
+type HistoryBreak struct {
+	Sequence int    `json:"Sequence"` // Sequence number at which the chain breaks
+	TxID     string `json:"TxID"`     // Transaction that wrote the offending entry, if there is one
+	Reason   string `json:"Reason"`   // Why the chain breaks there
+}
+
+// LedgerWrite identifies the transaction that last wrote a history entry to
+// the ledger. The block holding a transaction can be found from its ID with
+// the GetBlockByTxID function of the qscc system chaincode.
+type LedgerWrite struct {
+	Sequence  int    `json:"Sequence"`  // Sequence number of the history entry
+	Key       string `json:"Key"`       // World state key of the history entry
+	TxID      string `json:"TxID"`      // Transaction that last wrote the key
+	Timestamp string `json:"Timestamp"` // Timestamp of that transaction
+}
+
+// HistoryProof is a self-contained export of the history chain of an evidence
+// item. It can be checked without access to the ledger, and each entry can be
+// traced to the transaction that wrote it.
+type HistoryProof struct {
+	EvidenceID       string          `json:"EvidenceID"`       // ID of the evidence
+	ChannelID        string          `json:"ChannelID"`        // Channel whose ledger holds the chain
+	Algorithm        string          `json:"Algorithm"`        // How entry hashes are computed
+	Head             *HistoryHead    `json:"Head,omitempty"`   // Head of the chain, absent if the item has no history
+	Entries          []*HistoryEntry `json:"Entries"`          // History entries in sequence order
+	Writes           []*LedgerWrite  `json:"Writes"`           // Transactions that wrote each entry
+	Integrity        string          `json:"Integrity"`        // Current integrity hash of the evidence record
+	IntegrityVersion int             `json:"IntegrityVersion"` // Version of the algorithm that computed Integrity
+	ExportedAt       string          `json:"ExportedAt"`       // Timestamp of the exporting transaction
+	ExportTxID       string          `json:"ExportTxID"`       // ID of the exporting transaction
+}
+
+// HistoryHead records the last entry of a history chain, so entries removed
+// from the end of the chain are detected too
+type HistoryHead struct {
+	EvidenceID string `json:"EvidenceID"`
+	Sequence   int    `json:"Sequence"`
+	EntryHash  string `json:"EntryHash"`
+}
+
+// HistoryEntry is one link of an exported history chain. Its fields match
+// those of EvidenceHistory.
+type HistoryEntry struct {
+	EvidenceID        string `json:"EvidenceID"`
+	Sequence          int    `json:"Sequence"`
+	ModifiedBy        string `json:"ModifiedBy"`
+	ModifiedAt        string `json:"ModifiedAt"`
+	Action            string `json:"Action"`
+	Description       string `json:"Description"`
+	PrevState         string `json:"PrevState"`
+	TxID              string `json:"TxID"`
+	PreviousIntegrity string `json:"PreviousIntegrity"`
+	Integrity         string `json:"Integrity"`
+	PreviousEntryHash string `json:"PreviousEntryHash"`
+	EntryHash         string `json:"EntryHash"`
+}
+
+// EvidenceRelation is a typed link from one evidence item to another
+type EvidenceRelation struct {
+	FromID      string `json:"FromID"`      // ID of the evidence the relationship starts from
+	Type        string `json:"Type"`        // Type of relationship
+	ToID        string `json:"ToID"`        // ID of the related evidence
+	Description string `json:"Description"` // How the items are related
+	CreatedBy   string `json:"CreatedBy"`   // ID of the client that recorded the relationship
+	CreatedAt   string `json:"CreatedAt"`   // When the relationship was recorded
+	TxID        string `json:"TxID"`        // Transaction that recorded the relationship
+}
+
+// RetentionPolicy is how long the evidence of a type of case must be kept
+// after the case is closed before it may be disposed of
+type RetentionPolicy struct {
+	CaseType      string `json:"CaseType"`      // Type of case the policy applies to
+	RetentionDays int    `json:"RetentionDays"` // Days evidence is kept after the case is closed
+	Indefinite    bool   `json:"Indefinite"`    // Whether evidence must be kept forever
+}
+
+// LegalHold prevents the disposition of evidence, either of every item of a
+// case or of a single item, until it is released
+type LegalHold struct {
+	ID            string `json:"ID"`            // Identifier of the hold, unique within its case
+	CaseID        string `json:"CaseID"`        // Case the hold applies to
+	EvidenceID    string `json:"EvidenceID"`    // Evidence the hold applies to, empty for all evidence of the case
+	Authority     string `json:"Authority"`     // Court or authority that ordered the hold
+	Reason        string `json:"Reason"`        // Why the evidence must be preserved
+	PlacedBy      string `json:"PlacedBy"`      // ID of the client that placed the hold
+	PlacedAt      string `json:"PlacedAt"`      // When the hold was placed
+	Active        bool   `json:"Active"`        // Whether the hold is still in force
+	ReleasedBy    string `json:"ReleasedBy"`    // ID of the client that released the hold
+	ReleasedAt    string `json:"ReleasedAt"`    // When the hold was released
+	ReleaseReason string `json:"ReleaseReason"` // Why the hold was released
+}
+
+// EvidenceTombstone is what remains on the ledger of a disposed evidence
+// item. The content of the record is removed, but its final integrity hash is
+// kept so the item's history chain can still be checked.
+type EvidenceTombstone struct {
+	EvidenceID       string `json:"EvidenceID"`       // ID of the disposed evidence
+	CaseID           string `json:"CaseID"`           // Case the evidence belonged to
+	FileHash         string `json:"FileHash"`         // Hash of the evidence file
+	FinalIntegrity   string `json:"FinalIntegrity"`   // Integrity hash of the record when it was disposed of
+	IntegrityVersion int    `json:"IntegrityVersion"` // Version of the algorithm that computed FinalIntegrity
+	CourtOrderRef    string `json:"CourtOrderRef"`    // Reference of the court order authorising the disposition
+	Reason           string `json:"Reason"`           // Why the evidence was disposed of
+	RequestedBy      string `json:"RequestedBy"`      // ID of the client that gave the first approval
+	ApprovedBy       string `json:"ApprovedBy"`       // ID of the client that gave the second approval
+	DisposedAt       string `json:"DisposedAt"`       // When the evidence was disposed of
+	TxID             string `json:"TxID"`             // Transaction that disposed of the evidence
+}
+
+// Verifier is a client designated to approve the verification of an evidence
+// item
+type Verifier struct {
+	ID    string `json:"ID"`    // ID of the client, as the contract identifies it
+	MSPID string `json:"MSPID"` // MSP the client belongs to
+}
+
+// VerificationPanel is the set of verifiers whose approval an evidence item
+// needs before it can be marked as verified
+type VerificationPanel struct {
+	EvidenceID   string     `json:"EvidenceID"`   // ID of the evidence to verify
+	Verifiers    []Verifier `json:"Verifiers"`    // Clients that may approve the verification
+	Quorum       int        `json:"Quorum"`       // Number of approvals needed
+	Orgs         []string   `json:"Orgs"`         // Organizations whose peers must endorse changes to the evidence
+	DesignatedBy string     `json:"DesignatedBy"` // ID of the client that designated the verifiers
+	DesignatedAt string     `json:"DesignatedAt"` // When the verifiers were designated
+}
+
+// VerificationApproval is the sign-off of one designated verifier
+type VerificationApproval struct {
+	EvidenceID    string `json:"EvidenceID"`    // ID of the evidence approved
+	VerifierID    string `json:"VerifierID"`    // ID of the verifier
+	VerifierMSPID string `json:"VerifierMSPID"` // MSP of the verifier
+	Findings      string `json:"Findings"`      // What the verifier found
+	Integrity     string `json:"Integrity"`     // Integrity hash of the evidence that was approved
+	ApprovedAt    string `json:"ApprovedAt"`    // When the approval was given
+	TxID          string `json:"TxID"`          // Transaction that recorded the approval
+}
+
+// VerificationStatus is the progress of an evidence item towards its
+// verification quorum
+type VerificationStatus struct {
+	Panel     *VerificationPanel      `json:"Panel"`     // Designated verifiers and quorum
+	Status    string                  `json:"Status"`    // Current status of the evidence
+	Approvals []*VerificationApproval `json:"Approvals"` // Every approval recorded, ordered by verifier ID
+	Current   int                     `json:"Current"`   // Approvals given for the evidence as it now stands
+}
+
+// CommitmentProof is a zero-knowledge proof of knowledge of the opening of the
+// file commitment of an evidence item, as passed to CreateZKProof. Points are
+// hex encoded in SEC 1 compressed form and scalars as 32 byte big-endian hex.
+type CommitmentProof struct {
+	Commitment       string `json:"Commitment"`       // Commitment C the proof is about
+	NonceCommitment  string `json:"NonceCommitment"`  // Prover's nonce commitment T
+	Challenge        string `json:"Challenge"`        // Fiat-Shamir challenge c
+	ResponseValue    string `json:"ResponseValue"`    // Response s1 for the committed value
+	ResponseBlinding string `json:"ResponseBlinding"` // Response s2 for the blinding factor
+}
+
+// IntegrityFailure records that a stored copy of an evidence file no longer
+// matches the file hash on the ledger
+type IntegrityFailure struct {
+	EvidenceID   string `json:"EvidenceID"`   // ID of the evidence whose file failed the check
+	FileHash     string `json:"FileHash"`     // File hash of the evidence on the ledger when the failure was reported
+	ObservedHash string `json:"ObservedHash"` // Hash the reporting client computed over its copy of the file
+	Source       string `json:"Source"`       // Where the copy is kept, such as the name of a vault
+	Details      string `json:"Details"`      // Human readable explanation of the failure
+	ReportedBy   string `json:"ReportedBy"`   // ID of the client that reported the failure
+	MSPID        string `json:"MSPID"`        // MSP of the client that reported the failure
+	ReportedAt   string `json:"ReportedAt"`   // When the failure was reported
+	TxID         string `json:"TxID"`         // ID of the transaction that recorded the failure
+}
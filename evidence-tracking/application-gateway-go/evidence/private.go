@@ -0,0 +1,51 @@
+package evidence
+
+import (
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// SubmitPrivateEvidence issues a new evidence record whose description and
+// metadata are kept in the private data collection. The details are passed in
+// the transient map, and only their hash is put on the public record. The
+// salt of the details must be a random value of at least 16 characters.
+func (c *Contract) SubmitPrivateEvidence(id string, caseID string, evidenceType string, fileHash string, tags []string, details *EvidencePrivateDetails) (*client.Status, error) {
+	transient, err := withPrivateDetails(details)
+	if err != nil {
+		return nil, err
+	}
+
+	_, status, err := c.submit("SubmitPrivateEvidence", client.WithArguments(id, caseID, evidenceType, fileHash, listArgument(tags)), transient)
+	return status, err
+}
+
+// UpdateEvidencePrivateDetails replaces the private details of an evidence
+// record
+func (c *Contract) UpdateEvidencePrivateDetails(id string, details *EvidencePrivateDetails) (*client.Status, error) {
+	transient, err := withPrivateDetails(details)
+	if err != nil {
+		return nil, err
+	}
+
+	_, status, err := c.submit("UpdateEvidencePrivateDetails", client.WithArguments(id), transient)
+	return status, err
+}
+
+// MoveEvidenceDetailsToPrivate moves the description and metadata of an
+// evidence record submitted with public details into the private data
+// collection, hashed with the given salt. The old values remain in the blocks
+// of the transactions that wrote them.
+func (c *Contract) MoveEvidenceDetailsToPrivate(id string, salt string) (*client.Status, error) {
+	transient, err := withPrivateDetails(&EvidencePrivateDetails{EvidenceID: id, Salt: salt})
+	if err != nil {
+		return nil, err
+	}
+
+	_, status, err := c.submit("MoveEvidenceDetailsToPrivate", client.WithArguments(id), transient)
+	return status, err
+}
+
+// ReadEvidencePrivateDetails returns the private details of an evidence
+// record. The gateway peer must belong to the organization of the client.
+func (c *Contract) ReadEvidencePrivateDetails(id string) (*EvidencePrivateDetails, error) {
+	return evaluateResult[*EvidencePrivateDetails](c, "ReadEvidencePrivateDetails", client.WithArguments(id))
+}
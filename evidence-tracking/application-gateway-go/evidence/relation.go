@@ -0,0 +1,33 @@
+package evidence
+
+import (
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// RegisterDerivedEvidence issues a new evidence record for an artifact
+// produced from existing evidence, such as a disk image of a seized laptop,
+// linked to its parent by a derived_from relationship
+func (c *Contract) RegisterDerivedEvidence(id string, parentID string, evidenceType string, description string, fileHash string, tags []string, metadata string) (*client.Status, error) {
+	_, status, err := c.submit("RegisterDerivedEvidence", client.WithArguments(id, parentID, evidenceType, description, fileHash, listArgument(tags), metadata))
+	return status, err
+}
+
+// LinkEvidence records a relationship between two existing evidence items
+func (c *Contract) LinkEvidence(fromID string, relationType string, toID string, description string) (*client.Status, error) {
+	_, status, err := c.submit("LinkEvidence", client.WithArguments(fromID, relationType, toID, description))
+	return status, err
+}
+
+// GetEvidenceAncestors returns every relationship leading from an evidence
+// item to the items it relates to, directly or through other items, nearest
+// first
+func (c *Contract) GetEvidenceAncestors(id string) ([]*EvidenceRelation, error) {
+	return evaluateResult[[]*EvidenceRelation](c, "GetEvidenceAncestors", client.WithArguments(id))
+}
+
+// GetEvidenceDescendants returns every relationship leading to an evidence
+// item from the items that relate to it, directly or through other items,
+// nearest first
+func (c *Contract) GetEvidenceDescendants(id string) ([]*EvidenceRelation, error) {
+	return evaluateResult[[]*EvidenceRelation](c, "GetEvidenceDescendants", client.WithArguments(id))
+}
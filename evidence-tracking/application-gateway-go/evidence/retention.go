@@ -0,0 +1,63 @@
+package evidence
+
+import (
+	"strconv"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// SetRetentionPolicy sets how long the evidence of a type of case must be kept
+// after the case is closed
+func (c *Contract) SetRetentionPolicy(caseType string, retentionDays int, indefinite bool) (*client.Status, error) {
+	_, status, err := c.submit("SetRetentionPolicy", client.WithArguments(caseType, strconv.Itoa(retentionDays), strconv.FormatBool(indefinite)))
+	return status, err
+}
+
+// GetRetentionPolicies returns the retention policy in force for every case
+// type that has one
+func (c *Contract) GetRetentionPolicies() ([]*RetentionPolicy, error) {
+	return evaluateResult[[]*RetentionPolicy](c, "GetRetentionPolicies")
+}
+
+// PlaceLegalHold places a hold on the evidence of a case, or on a single item
+// of it when an evidence ID is given
+func (c *Contract) PlaceLegalHold(caseID string, holdID string, evidenceID string, authority string, reason string) (*client.Status, error) {
+	_, status, err := c.submit("PlaceLegalHold", client.WithArguments(caseID, holdID, evidenceID, authority, reason))
+	return status, err
+}
+
+// ReleaseLegalHold lifts a legal hold
+func (c *Contract) ReleaseLegalHold(caseID string, holdID string, reason string) (*client.Status, error) {
+	_, status, err := c.submit("ReleaseLegalHold", client.WithArguments(caseID, holdID, reason))
+	return status, err
+}
+
+// GetLegalHolds returns every legal hold placed on a case, active or released
+func (c *Contract) GetLegalHolds(caseID string) ([]*LegalHold, error) {
+	return evaluateResult[[]*LegalHold](c, "GetLegalHolds", client.WithArguments(caseID))
+}
+
+// RequestEvidenceDisposition gives the first approval to dispose of an
+// evidence item under a court order
+func (c *Contract) RequestEvidenceDisposition(id string, courtOrderRef string, reason string) (*client.Status, error) {
+	_, status, err := c.submit("RequestEvidenceDisposition", client.WithArguments(id, courtOrderRef, reason))
+	return status, err
+}
+
+// CancelEvidenceDisposition withdraws a pending disposition request
+func (c *Contract) CancelEvidenceDisposition(id string, reason string) (*client.Status, error) {
+	_, status, err := c.submit("CancelEvidenceDisposition", client.WithArguments(id, reason))
+	return status, err
+}
+
+// DisposeEvidence gives the second approval to a pending disposition and
+// disposes of the evidence, returning the tombstone that replaces it. The
+// approver must not be the client that requested the disposition.
+func (c *Contract) DisposeEvidence(id string, courtOrderRef string) (*EvidenceTombstone, *client.Status, error) {
+	return submitResult[*EvidenceTombstone](c, "DisposeEvidence", client.WithArguments(id, courtOrderRef))
+}
+
+// GetEvidenceTombstone returns the tombstone of a disposed evidence item
+func (c *Contract) GetEvidenceTombstone(id string) (*EvidenceTombstone, error) {
+	return evaluateResult[*EvidenceTombstone](c, "GetEvidenceTombstone", client.WithArguments(id))
+}
@@ -0,0 +1,36 @@
+package evidence
+
+import (
+	"strconv"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// DesignateVerifiers names the verifiers of an evidence item and how many of
+// them must approve it. From then on changes to the evidence must be endorsed
+// by the peers of every verifier's organization.
+func (c *Contract) DesignateVerifiers(id string, verifiers []Verifier, quorum int) (*client.Status, error) {
+	if verifiers == nil {
+		verifiers = []Verifier{}
+	}
+	verifiersJSON, err := jsonArgument("verifiers", verifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	_, status, err := c.submit("DesignateVerifiers", client.WithArguments(id, verifiersJSON, strconv.Itoa(quorum)))
+	return status, err
+}
+
+// ApproveVerification records the sign-off of a designated verifier. The
+// approval that reaches the quorum moves the evidence to verified.
+func (c *Contract) ApproveVerification(id string, findings string) (*client.Status, error) {
+	_, status, err := c.submit("ApproveVerification", client.WithArguments(id, findings))
+	return status, err
+}
+
+// GetVerificationStatus returns the designated verifiers of an evidence item
+// and the approvals they have given
+func (c *Contract) GetVerificationStatus(id string) (*VerificationStatus, error) {
+	return evaluateResult[*VerificationStatus](c, "GetVerificationStatus", client.WithArguments(id))
+}
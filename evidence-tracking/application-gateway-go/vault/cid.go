@@ -0,0 +1,49 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+)
+
+// A CIDv1 is the version, the content codec and the multihash of the content,
+// each prefixed by its multicodec code. All the codes used here are below 0x80,
+// so each is a single varint byte.
+const (
+	cidVersion   = 0x01
+	codecRaw     = 0x55
+	multihashSHA = 0x12 // sha2-256
+	multibase32  = "b"  // base32, lowercase, without padding
+)
+
+var cidEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// cidPrefix precedes the SHA-256 digest in a raw CIDv1
+var cidPrefix = []byte{cidVersion, codecRaw, multihashSHA, sha256.Size}
+
+// CID returns the CIDv1 of content with the given SHA-256 digest, using the
+// raw codec and encoded in base32, such as bafkrei.... It is the CID IPFS
+// gives the file when it is added as a single raw block, with
+// ipfs add --cid-version 1 --raw-leaves, which holds for files up to the IPFS
+// chunk size. IPFS gives larger files the CID of the DAG it splits them into
+// instead.
+func CID(digest []byte) string {
+	return multibase32 + strings.ToLower(cidEncoding.EncodeToString(append(cidPrefix[:len(cidPrefix):len(cidPrefix)], digest...)))
+}
+
+// digestFromCID returns the hex SHA-256 digest in a raw CIDv1, and false for
+// anything else, including CIDs of IPFS DAGs, which do not carry the digest of
+// the file
+func digestFromCID(cid string) (string, bool) {
+	if !strings.HasPrefix(cid, multibase32) {
+		return "", false
+	}
+
+	decoded, err := cidEncoding.DecodeString(strings.ToUpper(cid[len(multibase32):]))
+	if err != nil || len(decoded) != len(cidPrefix)+sha256.Size || !bytes.HasPrefix(decoded, cidPrefix) {
+		return "", false
+	}
+	return hex.EncodeToString(decoded[len(cidPrefix):]), true
+}
@@ -0,0 +1,53 @@
+package vault
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCID(t *testing.T) {
+	// CIDs IPFS gives the content with ipfs add --cid-version 1 --raw-leaves
+	tests := []struct {
+		content string
+		cid     string
+	}{
+		{content: "", cid: "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"},
+		{content: "hello world", cid: "bafkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cid, func(t *testing.T) {
+			sum := sha256.Sum256([]byte(test.content))
+			require.Equal(t, test.cid, CID(sum[:]))
+
+			digest, ok := digestFromCID(test.cid)
+			require.True(t, ok)
+			require.Equal(t, hex.EncodeToString(sum[:]), digest)
+		})
+	}
+}
+
+func TestDigestFromCIDRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		cid  string
+	}{
+		{name: "CIDv0", cid: "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o"},
+		{name: "CIDv1 of a DAG", cid: "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"},
+		{name: "other multibase", cid: "zb2rhe5P4gXftAwvA4eXQ5HJwsER2owDyS9sKaQRRVQPn93bA"},
+		{name: "not base32", cid: "bafkrei!"},
+		{name: "truncated", cid: "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquv"},
+		{name: "hex digest", cid: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{name: "empty", cid: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, ok := digestFromCID(test.cid)
+			require.False(t, ok)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package vault
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Objects are encrypted as a stream of AES-256-GCM sealed chunks, so files of
+// any size can be written and read without holding them in memory. A stored
+// object is the magic string and a random nonce prefix, followed by the
+// chunks. The nonce of each chunk is the prefix, the big-endian chunk counter
+// and a byte that is 1 for the last chunk and 0 for the others, so chunks
+// cannot be reordered, dropped or cut off without failing authentication.
+const (
+	magic           = "EVV1"
+	keySize         = 32
+	chunkSize       = 64 * 1024
+	noncePrefixSize = 7
+)
+
+// ErrCorrupt is returned when stored content fails authentication, because it
+// was altered, truncated or encrypted under another key
+var ErrCorrupt = errors.New("stored content failed authentication")
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("vault key must be %d bytes, not %d", keySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the nonce of a chunk
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, noncePrefixSize, noncePrefixSize+5)
+	copy(nonce, prefix)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+// encryptWriter encrypts what is written to it. Close must be called to seal
+// the last chunk.
+type encryptWriter struct {
+	aead    cipher.AEAD
+	out     io.Writer
+	prefix  []byte
+	counter uint32
+	buffer  []byte
+}
+
+func newEncryptWriter(aead cipher.AEAD, out io.Writer) (*encryptWriter, error) {
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	if _, err := io.WriteString(out, magic); err != nil {
+		return nil, err
+	}
+	if _, err := out.Write(prefix); err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{
+		aead:   aead,
+		out:    out,
+		prefix: prefix,
+		buffer: make([]byte, 0, chunkSize),
+	}, nil
+}
+
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// A full chunk is only sealed once more data arrives, because the
+		// last chunk is sealed differently
+		if len(w.buffer) == chunkSize {
+			if err := w.seal(false); err != nil {
+				return written, err
+			}
+		}
+
+		n := min(chunkSize-len(w.buffer), len(p))
+		w.buffer = append(w.buffer, p[:n]...)
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close seals the last chunk, which is empty if nothing was written
+func (w *encryptWriter) Close() error {
+	return w.seal(true)
+}
+
+func (w *encryptWriter) seal(last bool) error {
+	if w.counter == math.MaxUint32 {
+		return errors.New("file is too large to encrypt")
+	}
+
+	sealed := w.aead.Seal(nil, chunkNonce(w.prefix, w.counter, last), w.buffer, nil)
+	if _, err := w.out.Write(sealed); err != nil {
+		return err
+	}
+
+	w.buffer = w.buffer[:0]
+	w.counter++
+	return nil
+}
+
+// decryptReader decrypts content written by an encryptWriter. Reads fail with
+// ErrCorrupt as soon as a chunk fails authentication.
+type decryptReader struct {
+	aead    cipher.AEAD
+	in      *bufio.Reader
+	prefix  []byte
+	counter uint32
+	sealed  []byte
+	plain   []byte // Decrypted content not yet read
+	done    bool   // Whether the last chunk has been decrypted
+}
+
+func newDecryptReader(aead cipher.AEAD, in io.Reader) (*decryptReader, error) {
+	header := make([]byte, len(magic)+noncePrefixSize)
+	if _, err := io.ReadFull(in, header); err != nil {
+		return nil, fmt.Errorf("%w: missing header", ErrCorrupt)
+	}
+	if string(header[:len(magic)]) != magic {
+		return nil, fmt.Errorf("%w: unknown format", ErrCorrupt)
+	}
+
+	return &decryptReader{
+		aead:   aead,
+		in:     bufio.NewReader(in),
+		prefix: header[len(magic):],
+		sealed: make([]byte, chunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// open reads and decrypts the next chunk
+func (r *decryptReader) open() error {
+	n, err := io.ReadFull(r.in, r.sealed)
+	last := false
+	switch {
+	case err == io.EOF:
+		return fmt.Errorf("%w: truncated after chunk %d", ErrCorrupt, r.counter)
+	case err == io.ErrUnexpectedEOF:
+		last = true
+	case err != nil:
+		return err
+	default:
+		// A full chunk is the last one if nothing follows it
+		if _, err := r.in.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	plain, err := r.aead.Open(r.sealed[:0], chunkNonce(r.prefix, r.counter, last), r.sealed[:n], nil)
+	if err != nil {
+		return fmt.Errorf("%w: chunk %d", ErrCorrupt, r.counter)
+	}
+
+	r.plain = plain
+	r.done = last
+	r.counter++
+	return nil
+}
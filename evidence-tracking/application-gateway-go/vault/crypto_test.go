@@ -0,0 +1,157 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"io"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sealedChunkSize is the size of a full chunk once sealed
+const sealedChunkSize = chunkSize + 16
+
+// headerSize is the size of the magic string and nonce prefix
+const headerSize = len(magic) + noncePrefixSize
+
+func newTestAEAD(t *testing.T, keyByte byte) cipher.AEAD {
+	aead, err := newAEAD(bytes.Repeat([]byte{keyByte}, keySize))
+	require.NoError(t, err)
+	return aead
+}
+
+// testContent returns size bytes of repeatable content
+func testContent(size int) []byte {
+	content := make([]byte, size)
+	rand.New(rand.NewSource(int64(size))).Read(content)
+	return content
+}
+
+// encrypt encrypts content, writing it in pieces of writeSize bytes
+func encrypt(t *testing.T, aead cipher.AEAD, content []byte, writeSize int) []byte {
+	var stored bytes.Buffer
+	w, err := newEncryptWriter(aead, &stored)
+	require.NoError(t, err)
+
+	for len(content) > 0 {
+		n := min(writeSize, len(content))
+		written, err := w.Write(content[:n])
+		require.NoError(t, err)
+		require.Equal(t, n, written)
+		content = content[n:]
+	}
+	require.NoError(t, w.Close())
+	return stored.Bytes()
+}
+
+// decrypt decrypts stored content
+func decrypt(aead cipher.AEAD, stored []byte) ([]byte, error) {
+	r, err := newDecryptReader(aead, bytes.NewReader(stored))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	aead := newTestAEAD(t, 1)
+	sizes := []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 2 * chunkSize, 3*chunkSize + 17}
+
+	for _, size := range sizes {
+		for _, writeSize := range []int{1000, chunkSize, 5 * chunkSize} {
+			t.Run(strconv.Itoa(size)+"/"+strconv.Itoa(writeSize), func(t *testing.T) {
+				content := testContent(size)
+				stored := encrypt(t, aead, content, writeSize)
+
+				// Content that fills its last chunk has no empty chunk after
+				// it; only empty content is sealed as an empty chunk
+				chunks := max(1, (size+chunkSize-1)/chunkSize)
+				require.Len(t, stored, headerSize+size+chunks*aead.Overhead())
+				require.Equal(t, magic, string(stored[:len(magic)]))
+
+				decrypted, err := decrypt(aead, stored)
+				require.NoError(t, err)
+				require.Equal(t, content, decrypted)
+			})
+		}
+	}
+}
+
+func TestEncryptUsesFreshNonces(t *testing.T) {
+	aead := newTestAEAD(t, 1)
+	content := testContent(100)
+
+	first := encrypt(t, aead, content, len(content))
+	second := encrypt(t, aead, content, len(content))
+	require.NotEqual(t, first[len(magic):headerSize], second[len(magic):headerSize])
+	require.NotEqual(t, first[headerSize:], second[headerSize:])
+}
+
+func TestDecryptDetectsCorruption(t *testing.T) {
+	aead := newTestAEAD(t, 1)
+	content := testContent(2*chunkSize + 100)
+	stored := encrypt(t, aead, content, chunkSize)
+	require.Len(t, stored, headerSize+2*sealedChunkSize+100+aead.Overhead())
+
+	chunk := func(i int) []byte {
+		start := headerSize + i*sealedChunkSize
+		return stored[start:min(start+sealedChunkSize, len(stored))]
+	}
+	join := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+	flip := func(offset int) []byte {
+		altered := bytes.Clone(stored)
+		altered[offset] ^= 0x01
+		return altered
+	}
+
+	tests := []struct {
+		name   string
+		stored []byte
+	}{
+		{name: "tampered first chunk", stored: flip(headerSize + 10)},
+		{name: "tampered middle chunk", stored: flip(headerSize + sealedChunkSize + 10)},
+		{name: "tampered tag of last chunk", stored: flip(len(stored) - 1)},
+		{name: "tampered nonce prefix", stored: flip(len(magic))},
+		{name: "truncated last chunk", stored: stored[:len(stored)-1]},
+		{name: "truncated at chunk boundary", stored: stored[:headerSize+2*sealedChunkSize]},
+		{name: "truncated to header", stored: stored[:headerSize]},
+		{name: "dropped middle chunk", stored: join(stored[:headerSize], chunk(0), chunk(2))},
+		{name: "reordered chunks", stored: join(stored[:headerSize], chunk(1), chunk(0), chunk(2))},
+		{name: "duplicated chunk", stored: join(stored[:headerSize], chunk(0), chunk(0), chunk(1), chunk(2))},
+		{name: "appended data", stored: join(stored, []byte("more"))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := decrypt(aead, test.stored)
+			require.ErrorIs(t, err, ErrCorrupt)
+		})
+	}
+
+	t.Run("other key", func(t *testing.T) {
+		_, err := decrypt(newTestAEAD(t, 2), stored)
+		require.ErrorIs(t, err, ErrCorrupt)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := decrypt(aead, join([]byte("EVV2"), stored[len(magic):]))
+		require.ErrorIs(t, err, ErrCorrupt)
+		require.ErrorContains(t, err, "unknown format")
+	})
+
+	t.Run("missing header", func(t *testing.T) {
+		_, err := decrypt(aead, stored[:headerSize-1])
+		require.ErrorIs(t, err, ErrCorrupt)
+		require.ErrorContains(t, err, "missing header")
+	})
+}
+
+func TestNewAEADRejectsKeySize(t *testing.T) {
+	_, err := newAEAD(make([]byte, 16))
+	require.EqualError(t, err, "vault key must be 32 bytes, not 16")
+}
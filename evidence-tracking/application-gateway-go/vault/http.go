@@ -0,0 +1,78 @@
+package vault
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// NewHandler returns an HTTP handler for uploading files to a vault and
+// reading them back:
+//
+//	POST /objects?evidenceID=<id>&sha256=<digest>  store the request body, both parameters optional
+//	GET  /objects/{sha256}                         read a file
+//	GET  /objects/{sha256}/meta                    read the metadata of a file
+//
+// The handler does not authenticate its clients, so it should only be served
+// where the clients are trusted.
+func NewHandler(v *Vault) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /objects", func(w http.ResponseWriter, r *http.Request) {
+		object, err := v.Put(r.Body, r.URL.Query().Get("evidenceID"), r.URL.Query().Get("sha256"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, object)
+	})
+
+	mux.HandleFunc("GET /objects/{sha256}", func(w http.ResponseWriter, r *http.Request) {
+		content, object, err := v.Get(r.PathValue("sha256"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		defer content.Close()
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", strconv.FormatInt(object.Size, 10))
+		w.Header().Set("ETag", strconv.Quote(object.SHA256))
+		w.Header().Set("X-Content-CID", object.CID)
+		if _, err := io.Copy(w, content); err != nil {
+			// The status has been sent, so the only way left to tell the
+			// client the file is incomplete is to drop the connection
+			panic(http.ErrAbortHandler)
+		}
+	})
+
+	mux.HandleFunc("GET /objects/{sha256}/meta", func(w http.ResponseWriter, r *http.Request) {
+		object, err := v.Stat(r.PathValue("sha256"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, object)
+	})
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrInvalidAddress), errors.Is(err, ErrHashMismatch):
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
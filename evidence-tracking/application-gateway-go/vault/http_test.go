@@ -0,0 +1,79 @@
+package vault
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	server := httptest.NewServer(NewHandler(openTestVault(t)))
+	defer server.Close()
+	address := sha256Hex("photograph of the knife")
+
+	response, err := http.Post(server.URL+"/objects?evidenceID=EV1&sha256="+address, "application/octet-stream", strings.NewReader("photograph of the knife"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, response.StatusCode)
+	var object Object
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&object))
+	response.Body.Close()
+	require.Equal(t, address, object.SHA256)
+	require.Equal(t, []string{"EV1"}, object.EvidenceIDs)
+
+	response, err = http.Get(server.URL + "/objects/" + address)
+	require.NoError(t, err)
+	content, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, "photograph of the knife", string(content))
+	require.Equal(t, `"`+address+`"`, response.Header.Get("ETag"))
+	require.Equal(t, object.CID, response.Header.Get("X-Content-CID"))
+
+	response, err = http.Get(server.URL + "/objects/" + address + "/meta")
+	require.NoError(t, err)
+	var meta Object
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&meta))
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, object.SHA256, meta.SHA256)
+}
+
+func TestHandlerErrors(t *testing.T) {
+	server := httptest.NewServer(NewHandler(openTestVault(t)))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{name: "unexpected hash", method: http.MethodPost, path: "/objects?sha256=" + sha256Hex("other"), body: "photograph of the knife", status: http.StatusBadRequest},
+		{name: "missing object", method: http.MethodGet, path: "/objects/" + sha256Hex("other"), status: http.StatusNotFound},
+		{name: "missing metadata", method: http.MethodGet, path: "/objects/" + sha256Hex("other") + "/meta", status: http.StatusNotFound},
+		{name: "invalid address", method: http.MethodGet, path: "/objects/knife.jpg", status: http.StatusBadRequest},
+		{name: "escaped traversal", method: http.MethodGet, path: "/objects/..%2F..%2Fkey", status: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request, err := http.NewRequest(test.method, server.URL+test.path, strings.NewReader(test.body))
+			require.NoError(t, err)
+			response, err := http.DefaultClient.Do(request)
+			require.NoError(t, err)
+			defer response.Body.Close()
+
+			require.Equal(t, test.status, response.StatusCode)
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(response.Body).Decode(&body))
+			require.NotEmpty(t, body["error"])
+		})
+	}
+}
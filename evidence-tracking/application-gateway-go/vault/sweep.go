@@ -0,0 +1,225 @@
+package vault
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/application-gateway-go/evidence"
+)
+
+// Outcomes of checking a stored file
+const (
+	CheckOK           = "ok"           // The file matches its address and the file hash on the ledger
+	CheckDrifted      = "drifted"      // The file no longer matches
+	CheckUnverifiable = "unverifiable" // The file hash on the ledger is not a SHA-256 digest or raw CID, such as a CIDv0
+	CheckFailed       = "failed"       // The file or the evidence could not be read
+)
+
+// Check is the outcome of checking one stored file against one evidence item
+// it is linked to. Files not linked to evidence are only checked against
+// their address.
+type Check struct {
+	SHA256        string `json:"sha256"`                  // Address of the file in the vault
+	EvidenceID    string `json:"evidenceID,omitempty"`    // Evidence the file was checked against
+	Status        string `json:"status"`                  // ok, drifted, unverifiable or failed
+	RecordedHash  string `json:"recordedHash,omitempty"`  // File hash recorded on the ledger
+	ObservedHash  string `json:"observedHash,omitempty"`  // Hash of the file as it is now stored
+	TransactionID string `json:"transactionID,omitempty"` // Transaction that reported the drift, if this sweep reported it
+	Error         string `json:"error,omitempty"`         // Why the check failed
+}
+
+// SweepReport is the outcome of one integrity sweep
+type SweepReport struct {
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Checks     []Check   `json:"checks"`
+}
+
+// Count returns the number of checks with a status
+func (r *SweepReport) Count(status string) int {
+	count := 0
+	for _, check := range r.Checks {
+		if check.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Sweeper re-hashes the files in a vault and reports those that have drifted
+// to the evidence contract. The identity of the contract must hold a role that
+// may call ReportIntegrityFailure.
+type Sweeper struct {
+	vault    *Vault
+	contract *evidence.Contract
+	source   string
+}
+
+// NewSweeper creates a sweeper. Integrity failures it reports name source as
+// the place the drifted copy is kept.
+func NewSweeper(vault *Vault, contract *evidence.Contract, source string) *Sweeper {
+	return &Sweeper{vault: vault, contract: contract, source: source}
+}
+
+// Run sweeps the vault immediately and then at every interval, until ctx is
+// done. The outcome of each sweep is passed to handle.
+func (s *Sweeper) Run(ctx context.Context, interval time.Duration, handle func(report *SweepReport, err error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		handle(s.Sweep(ctx))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep checks every file in the vault once. Each file is decrypted and
+// re-hashed, and compared with its address and with the file hash of each
+// evidence item it is linked to. A drift is reported to the contract once;
+// it is reported again only if the file is seen to match in between or
+// drifts to a different hash.
+func (s *Sweeper) Sweep(ctx context.Context) (*SweepReport, error) {
+	report := &SweepReport{StartedAt: time.Now().UTC(), Checks: []Check{}}
+
+	objects, err := s.vault.Objects()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, object := range objects {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		report.Checks = append(report.Checks, s.checkObject(object)...)
+	}
+
+	report.FinishedAt = time.Now().UTC()
+	return report, nil
+}
+
+func (s *Sweeper) checkObject(object *Object) []Check {
+	observed, err := s.vault.Hash(object.SHA256)
+	details := fmt.Sprintf("the stored copy re-hashed to %s but was stored as %s", observed, object.SHA256)
+	if errors.Is(err, ErrCorrupt) {
+		// The content cannot be recovered, so what is observed is the copy
+		// as stored
+		details = fmt.Sprintf("the stored copy failed authenticated decryption (%v); the observed hash is of the encrypted copy", err)
+		observed, err = s.vault.hashStored(object.SHA256)
+	}
+	if err != nil {
+		return []Check{{SHA256: object.SHA256, Status: CheckFailed, Error: err.Error()}}
+	}
+	if observed == object.SHA256 {
+		details = "the stored copy does not match the file hash recorded on the ledger"
+	}
+
+	if len(object.EvidenceIDs) == 0 {
+		check := Check{SHA256: object.SHA256, Status: CheckOK, ObservedHash: observed}
+		if observed != object.SHA256 {
+			check.Status = CheckDrifted
+		}
+		return []Check{check}
+	}
+
+	checks := make([]Check, 0, len(object.EvidenceIDs))
+	for _, evidenceID := range object.EvidenceIDs {
+		checks = append(checks, s.checkEvidence(object, evidenceID, observed, details))
+	}
+	return checks
+}
+
+// checkEvidence compares the observed hash of a stored file with the file
+// hash of an evidence item, and reports a drift not reported before
+func (s *Sweeper) checkEvidence(object *Object, evidenceID string, observed string, details string) Check {
+	check := Check{SHA256: object.SHA256, EvidenceID: evidenceID, ObservedHash: observed}
+
+	record, err := s.contract.ReadEvidence(evidenceID)
+	if err != nil {
+		check.Status = CheckFailed
+		check.Error = err.Error()
+		return check
+	}
+	check.RecordedHash = record.FileHash
+
+	expected, ok := recordedDigest(record.FileHash)
+	if !ok {
+		check.Status = CheckUnverifiable
+		return check
+	}
+
+	if observed == expected {
+		check.Status = CheckOK
+		if _, reported := object.Reported[evidenceID]; reported {
+			err = s.vault.update(object.SHA256, func(object *Object) {
+				delete(object.Reported, evidenceID)
+			})
+			if err != nil {
+				check.Error = err.Error()
+			}
+		}
+		return check
+	}
+
+	check.Status = CheckDrifted
+	if object.Reported[evidenceID] == observed {
+		return check
+	}
+
+	status, err := s.contract.ReportIntegrityFailure(evidenceID, formatLike(record.FileHash, observed), s.source, details)
+	if err != nil {
+		check.Error = fmt.Sprintf("failed to report integrity failure: %v", err)
+		return check
+	}
+	check.TransactionID = status.TransactionID
+
+	err = s.vault.update(object.SHA256, func(object *Object) {
+		if object.Reported == nil {
+			object.Reported = map[string]string{}
+		}
+		object.Reported[evidenceID] = observed
+	})
+	if err != nil {
+		check.Error = err.Error()
+	}
+	return check
+}
+
+// recordedDigest returns the hex SHA-256 digest in a file hash recorded on the
+// ledger, written as hex with or without a sha256: prefix, or as a raw CIDv1
+func recordedDigest(recorded string) (string, bool) {
+	if digest, ok := digestFromCID(recorded); ok {
+		return digest, true
+	}
+
+	digest := strings.ToLower(strings.TrimPrefix(recorded, "sha256:"))
+	if len(digest) != sha256.Size*2 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return "", false
+	}
+	return digest, true
+}
+
+// formatLike writes a hex digest in the form of a recorded file hash, so the
+// two can be compared on the ledger
+func formatLike(recorded string, digest string) string {
+	if _, ok := digestFromCID(recorded); ok {
+		sum, _ := hex.DecodeString(digest)
+		return CID(sum)
+	}
+	if strings.HasPrefix(recorded, "sha256:") {
+		return "sha256:" + digest
+	}
+	return digest
+}
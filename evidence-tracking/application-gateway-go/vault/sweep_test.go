@@ -0,0 +1,274 @@
+package vault
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/application-gateway-go/evidence"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// testIdentity is the identity of the client connected to the fake ledger
+type testIdentity struct{}
+
+func (testIdentity) MspID() string       { return "Org2MSP" }
+func (testIdentity) Credentials() []byte { return []byte("custodian1") }
+
+// fakeLedger is a gateway connection that answers ReadEvidence with the file
+// hashes it holds and records the integrity failures reported to it
+type fakeLedger struct {
+	t          *testing.T
+	fileHashes map[string]string // File hash of each evidence item, by evidence ID
+	reports    [][]string        // Arguments of each ReportIntegrityFailure transaction
+}
+
+func (f *fakeLedger) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	switch request := args.(type) {
+	case *gateway.EvaluateRequest:
+		arguments := f.arguments(request.GetProposedTransaction())
+		require.Equal(f.t, "ReadEvidence", arguments[0])
+		fileHash, ok := f.fileHashes[arguments[1]]
+		if !ok {
+			return status.Errorf(codes.Unknown, "evaluate call to endorser returned error: chaincode response 500, the evidence %s does not exist", arguments[1])
+		}
+		record, err := json.Marshal(&evidence.Evidence{ID: arguments[1], FileHash: fileHash})
+		require.NoError(f.t, err)
+		reply.(*gateway.EvaluateResponse).Result = &peer.Response{Status: 200, Payload: record}
+		return nil
+	case *gateway.EndorseRequest:
+		arguments := f.arguments(request.GetProposedTransaction())
+		require.Equal(f.t, "ReportIntegrityFailure", arguments[0])
+		f.reports = append(f.reports, arguments[1:])
+		reply.(*gateway.EndorseResponse).PreparedTransaction = f.preparedTransaction(request.GetChannelId())
+		return nil
+	case *gateway.SubmitRequest:
+		return nil
+	case *gateway.SignedCommitStatusRequest:
+		reply.(*gateway.CommitStatusResponse).Result = peer.TxValidationCode_VALID
+		return nil
+	}
+	return errors.New("the fake ledger does not implement " + method)
+}
+
+func (f *fakeLedger) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, errors.New("the fake ledger does not implement " + method)
+}
+
+// arguments decodes the transaction name and arguments of a signed proposal
+func (f *fakeLedger) arguments(signedProposal *peer.SignedProposal) []string {
+	var proposal peer.Proposal
+	require.NoError(f.t, proto.Unmarshal(signedProposal.GetProposalBytes(), &proposal))
+	var payload peer.ChaincodeProposalPayload
+	require.NoError(f.t, proto.Unmarshal(proposal.GetPayload(), &payload))
+	var invocation peer.ChaincodeInvocationSpec
+	require.NoError(f.t, proto.Unmarshal(payload.GetInput(), &invocation))
+
+	var arguments []string
+	for _, arg := range invocation.GetChaincodeSpec().GetInput().GetArgs() {
+		arguments = append(arguments, string(arg))
+	}
+	return arguments
+}
+
+// preparedTransaction returns an endorsed transaction with an empty result
+func (f *fakeLedger) preparedTransaction(channel string) *common.Envelope {
+	marshal := func(message proto.Message) []byte {
+		messageBytes, err := proto.Marshal(message)
+		require.NoError(f.t, err)
+		return messageBytes
+	}
+
+	chaincodeAction := marshal(&peer.ChaincodeAction{Response: &peer.Response{Status: 200}})
+	responsePayload := marshal(&peer.ProposalResponsePayload{Extension: chaincodeAction})
+	actionPayload := marshal(&peer.ChaincodeActionPayload{Action: &peer.ChaincodeEndorsedAction{ProposalResponsePayload: responsePayload}})
+	transaction := marshal(&peer.Transaction{Actions: []*peer.TransactionAction{{Payload: actionPayload}}})
+	payload := marshal(&common.Payload{
+		Header: &common.Header{ChannelHeader: marshal(&common.ChannelHeader{ChannelId: channel})},
+		Data:   transaction,
+	})
+	return &common.Envelope{Payload: payload}
+}
+
+// newTestSweeper returns a sweeper of a vault that reports to a fake ledger
+func newTestSweeper(t *testing.T, v *Vault, fileHashes map[string]string) (*Sweeper, *fakeLedger) {
+	ledger := &fakeLedger{t: t, fileHashes: fileHashes}
+	gw, err := client.Connect(
+		testIdentity{},
+		client.WithSign(func(digest []byte) ([]byte, error) { return digest, nil }),
+		client.WithClientConnection(ledger),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { gw.Close() })
+
+	contract := evidence.NewContract(gw.GetNetwork("evidencechannel").GetContract("evidence"))
+	return NewSweeper(v, contract, "vault-1"), ledger
+}
+
+// putObject stores content in a vault, linked to evidence items
+func putObject(t *testing.T, v *Vault, content string, evidenceIDs ...string) *Object {
+	object, err := v.Put(strings.NewReader(content), "", "")
+	require.NoError(t, err)
+	for _, evidenceID := range evidenceIDs {
+		object, err = v.Put(strings.NewReader(content), evidenceID, object.SHA256)
+		require.NoError(t, err)
+	}
+	return object
+}
+
+// sweep runs a sweep and returns its checks by evidence ID, or by address for
+// files not linked to evidence
+func sweep(t *testing.T, sweeper *Sweeper) map[string]Check {
+	report, err := sweeper.Sweep(context.Background())
+	require.NoError(t, err)
+
+	checks := map[string]Check{}
+	for _, check := range report.Checks {
+		key := check.EvidenceID
+		if key == "" {
+			key = check.SHA256
+		}
+		checks[key] = check
+	}
+	require.Len(t, checks, len(report.Checks))
+	return checks
+}
+
+// replaceContent replaces the stored copy of an object with the stored copy
+// of another, which decrypts to the content of the other
+func replaceContent(t *testing.T, v *Vault, address string, with string) {
+	stored, err := os.ReadFile(v.blobPath(with))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(v.blobPath(address), stored, 0o600))
+}
+
+func TestSweep(t *testing.T) {
+	v := openTestVault(t)
+	knife := putObject(t, v, "photograph of the knife", "EV1", "EV2", "EV3", "EV4")
+	retouched := putObject(t, v, "photograph of the knife, retouched")
+	original, err := os.ReadFile(v.blobPath(knife.SHA256))
+	require.NoError(t, err)
+
+	sweeper, ledger := newTestSweeper(t, v, map[string]string{
+		"EV1": knife.SHA256,
+		"EV2": knife.CID,
+		"EV3": "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o", // CIDv0, as seeded by InitLedger
+	})
+
+	checks := sweep(t, sweeper)
+	require.Equal(t, CheckOK, checks["EV1"].Status)
+	require.Equal(t, CheckOK, checks["EV2"].Status)
+	require.Equal(t, CheckUnverifiable, checks["EV3"].Status)
+	require.Equal(t, CheckFailed, checks["EV4"].Status)
+	require.Contains(t, checks["EV4"].Error, "the evidence EV4 does not exist")
+	require.Equal(t, CheckOK, checks[retouched.SHA256].Status)
+	require.Empty(t, ledger.reports)
+
+	// A drift is reported once for each verifiable evidence item, in the form
+	// of its recorded hash
+	replaceContent(t, v, knife.SHA256, retouched.SHA256)
+	checks = sweep(t, sweeper)
+	require.Equal(t, CheckDrifted, checks["EV1"].Status)
+	require.Equal(t, retouched.SHA256, checks["EV1"].ObservedHash)
+	require.NotEmpty(t, checks["EV1"].TransactionID)
+	require.Equal(t, CheckDrifted, checks["EV2"].Status)
+	require.NotEmpty(t, checks["EV2"].TransactionID)
+	require.Equal(t, CheckUnverifiable, checks["EV3"].Status)
+	require.Empty(t, checks["EV3"].TransactionID)
+	details := "the stored copy re-hashed to " + retouched.SHA256 + " but was stored as " + knife.SHA256
+	require.Equal(t, [][]string{
+		{"EV1", retouched.SHA256, "vault-1", details},
+		{"EV2", retouched.CID, "vault-1", details},
+	}, ledger.reports)
+
+	object, err := v.Stat(knife.SHA256)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"EV1": retouched.SHA256, "EV2": retouched.SHA256}, object.Reported)
+
+	// The same drift is not reported again
+	checks = sweep(t, sweeper)
+	require.Equal(t, CheckDrifted, checks["EV1"].Status)
+	require.Empty(t, checks["EV1"].TransactionID)
+	require.Equal(t, CheckDrifted, checks["EV2"].Status)
+	require.Empty(t, checks["EV2"].TransactionID)
+	require.Len(t, ledger.reports, 2)
+
+	// Once the copy matches again, a later drift is reported again
+	require.NoError(t, os.WriteFile(v.blobPath(knife.SHA256), original, 0o600))
+	checks = sweep(t, sweeper)
+	require.Equal(t, CheckOK, checks["EV1"].Status)
+	require.Equal(t, CheckOK, checks["EV2"].Status)
+	object, err = v.Stat(knife.SHA256)
+	require.NoError(t, err)
+	require.Empty(t, object.Reported)
+
+	replaceContent(t, v, knife.SHA256, retouched.SHA256)
+	checks = sweep(t, sweeper)
+	require.NotEmpty(t, checks["EV1"].TransactionID)
+	require.NotEmpty(t, checks["EV2"].TransactionID)
+	require.Len(t, ledger.reports, 4)
+}
+
+func TestSweepReportsCorruptCopy(t *testing.T) {
+	v := openTestVault(t)
+	knife := putObject(t, v, "photograph of the knife", "EV1")
+	sweeper, ledger := newTestSweeper(t, v, map[string]string{"EV1": "sha256:" + knife.SHA256})
+
+	stored, err := os.ReadFile(v.blobPath(knife.SHA256))
+	require.NoError(t, err)
+	stored[len(stored)-1] ^= 0x01
+	require.NoError(t, os.WriteFile(v.blobPath(knife.SHA256), stored, 0o600))
+	storedHash := sha256.Sum256(stored)
+
+	// The content cannot be recovered, so the hash of the copy as stored is
+	// reported
+	checks := sweep(t, sweeper)
+	require.Equal(t, CheckDrifted, checks["EV1"].Status)
+	require.Equal(t, hex.EncodeToString(storedHash[:]), checks["EV1"].ObservedHash)
+	require.Len(t, ledger.reports, 1)
+	require.Equal(t, "sha256:"+hex.EncodeToString(storedHash[:]), ledger.reports[0][1])
+	require.Contains(t, ledger.reports[0][3], "failed authenticated decryption")
+}
+
+func TestRecordedDigest(t *testing.T) {
+	digest := sha256Hex("photograph of the knife")
+	sum, _ := hex.DecodeString(digest)
+
+	tests := []struct {
+		recorded string
+		ok       bool
+	}{
+		{recorded: digest, ok: true},
+		{recorded: strings.ToUpper(digest), ok: true},
+		{recorded: "sha256:" + digest, ok: true},
+		{recorded: CID(sum), ok: true},
+		{recorded: "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o"},
+		{recorded: "QmHash"},
+		{recorded: "md5:" + digest[:32]},
+		{recorded: strings.Repeat("z", 64)},
+		{recorded: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.recorded, func(t *testing.T) {
+			recorded, ok := recordedDigest(test.recorded)
+			require.Equal(t, test.ok, ok)
+			if test.ok {
+				require.Equal(t, digest, recorded)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+// Package vault keeps copies of evidence files on local disk, addressed by
+// the SHA-256 digest of their content and encrypted at rest, and sweeps them
+// for drift from the file hashes recorded on the ledger. A file that no longer
+// matches is reported to the evidence contract as an integrity failure.
+//
+// Each file is stored once, however many evidence items it is linked to, as
+// objects/<first two hex digits>/<sha256> under the vault root. The JSON
+// metadata of the object is kept beside it in <sha256>.json.
+package vault
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned for an object the vault does not hold
+	ErrNotFound = errors.New("object not found")
+	// ErrHashMismatch is returned by Put when a file does not have the
+	// SHA-256 digest the uploader expected
+	ErrHashMismatch = errors.New("file does not match the expected SHA-256 digest")
+	// ErrInvalidAddress is returned for an address that is not a hex SHA-256
+	// digest
+	ErrInvalidAddress = errors.New("address is not a SHA-256 digest")
+)
+
+// Object describes a file held by the vault
+type Object struct {
+	SHA256      string            `json:"sha256"`             // Hex SHA-256 digest of the file, its address in the vault
+	CID         string            `json:"cid"`                // CIDv1 of the file, see CID
+	Size        int64             `json:"size"`               // Size of the file in bytes, before encryption
+	EvidenceIDs []string          `json:"evidenceIDs"`        // Evidence items the file was stored for
+	StoredAt    time.Time         `json:"storedAt"`           // When the file was first stored
+	Reported    map[string]string `json:"reported,omitempty"` // Observed hash last reported as an integrity failure, by evidence ID
+}
+
+// Vault is a content-addressed store of encrypted evidence files
+type Vault struct {
+	root string
+	aead cipher.AEAD
+	mu   sync.Mutex // Serializes changes to object metadata
+}
+
+// Open opens the vault under root, creating it if it does not exist. Files
+// are encrypted with AES-256-GCM under key, which must be 32 bytes.
+func Open(root string, key []byte) (*Vault, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Vault{root: root, aead: aead}
+	for _, dir := range []string{v.objectsDir(), v.tmpDir()} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create vault directory: %w", err)
+		}
+	}
+
+	return v, nil
+}
+
+// ParseKey decodes a vault key written as 64 hex digits, as printed by
+// openssl rand -hex 32
+func ParseKey(keyHex string) ([]byte, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(keyHex))
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("vault key must be %d hex digits", keySize*2)
+	}
+	return key, nil
+}
+
+// Put encrypts and stores a file read from r, and links it to an evidence
+// item if evidenceID is not empty. If expectedSHA256 is not empty, the file
+// is only stored if it has that digest. Storing a file the vault already holds
+// only adds the link.
+func (v *Vault) Put(r io.Reader, evidenceID string, expectedSHA256 string) (*Object, error) {
+	tmp, err := os.CreateTemp(v.tmpDir(), "upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upload file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	encrypter, err := newEncryptWriter(v.aead, tmp)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.New()
+	size, err := io.Copy(io.MultiWriter(digest, encrypter), r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+	if err := encrypter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+
+	sum := digest.Sum(nil)
+	address := hex.EncodeToString(sum)
+	if expectedSHA256 != "" && !strings.EqualFold(strings.TrimPrefix(expectedSHA256, "sha256:"), address) {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrHashMismatch, expectedSHA256, address)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	object, err := v.readObject(address)
+	if errors.Is(err, ErrNotFound) {
+		object = &Object{
+			SHA256:      address,
+			CID:         CID(sum),
+			Size:        size,
+			EvidenceIDs: []string{},
+			StoredAt:    time.Now().UTC(),
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	// The content of an object is only written if it is missing. A stored copy
+	// that has drifted is left in place for the sweep to report.
+	if _, err := os.Stat(v.blobPath(address)); errors.Is(err, fs.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(v.blobPath(address)), 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create object directory: %w", err)
+		}
+		if err := os.Rename(tmp.Name(), v.blobPath(address)); err != nil {
+			return nil, fmt.Errorf("failed to store object %s: %w", address, err)
+		}
+	}
+
+	if evidenceID != "" && !contains(object.EvidenceIDs, evidenceID) {
+		object.EvidenceIDs = append(object.EvidenceIDs, evidenceID)
+		sort.Strings(object.EvidenceIDs)
+	}
+	if err := v.writeObject(object); err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+// Get returns the decrypted content of an object, which the caller must
+// close, and its metadata. Reading fails if the stored content was altered.
+func (v *Vault) Get(address string) (io.ReadCloser, *Object, error) {
+	address, err := parseAddress(address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	object, err := v.Stat(address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(v.blobPath(address))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("%w: %s has metadata but no content", ErrNotFound, address)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open object %s: %w", address, err)
+	}
+
+	decrypter, err := newDecryptReader(v.aead, file)
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to open object %s: %w", address, err)
+	}
+
+	return &readCloser{Reader: decrypter, Closer: file}, object, nil
+}
+
+// Stat returns the metadata of an object
+func (v *Vault) Stat(address string) (*Object, error) {
+	address, err := parseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return v.readObject(address)
+}
+
+// Objects returns the metadata of every object in the vault, ordered by
+// address
+func (v *Vault) Objects() ([]*Object, error) {
+	objects := []*Object{}
+	err := filepath.WalkDir(v.objectsDir(), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		address, err := parseAddress(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			return nil // Not an object
+		}
+		object, err := v.readObject(address)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, object)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	return objects, nil
+}
+
+// Hash decrypts an object and returns the hex SHA-256 digest of its content.
+// The error wraps ErrCorrupt if the stored content fails authentication.
+func (v *Vault) Hash(address string) (string, error) {
+	content, _, err := v.Get(address)
+	if err != nil {
+		return "", err
+	}
+	defer content.Close()
+
+	digest := sha256.New()
+	if _, err := io.Copy(digest, content); err != nil {
+		return "", fmt.Errorf("failed to read object %s: %w", address, err)
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// hashStored returns the hex SHA-256 digest of an object as stored, encrypted
+func (v *Vault) hashStored(address string) (string, error) {
+	file, err := os.Open(v.blobPath(address))
+	if err != nil {
+		return "", fmt.Errorf("failed to open object %s: %w", address, err)
+	}
+	defer file.Close()
+
+	digest := sha256.New()
+	if _, err := io.Copy(digest, file); err != nil {
+		return "", fmt.Errorf("failed to read object %s: %w", address, err)
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// update applies a change to the metadata of an object and saves it
+func (v *Vault) update(address string, change func(object *Object)) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	object, err := v.readObject(address)
+	if err != nil {
+		return err
+	}
+	change(object)
+	return v.writeObject(object)
+}
+
+func (v *Vault) readObject(address string) (*Object, error) {
+	objectJSON, err := os.ReadFile(v.metadataPath(address))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", address, err)
+	}
+
+	var object Object
+	if err := json.Unmarshal(objectJSON, &object); err != nil {
+		return nil, fmt.Errorf("failed to parse object %s: %w", address, err)
+	}
+	return &object, nil
+}
+
+// writeObject saves the metadata of an object, replacing it atomically
+func (v *Vault) writeObject(object *Object) error {
+	objectJSON, err := json.MarshalIndent(object, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal object %s: %w", object.SHA256, err)
+	}
+
+	tmp, err := os.CreateTemp(v.tmpDir(), "metadata-*")
+	if err != nil {
+		return fmt.Errorf("failed to write object %s: %w", object.SHA256, err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := tmp.Write(objectJSON); err != nil {
+		return fmt.Errorf("failed to write object %s: %w", object.SHA256, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write object %s: %w", object.SHA256, err)
+	}
+	if err := os.Rename(tmp.Name(), v.metadataPath(object.SHA256)); err != nil {
+		return fmt.Errorf("failed to write object %s: %w", object.SHA256, err)
+	}
+	return nil
+}
+
+func (v *Vault) objectsDir() string {
+	return filepath.Join(v.root, "objects")
+}
+
+// tmpDir holds files being written. It is on the same file system as the
+// objects, so finished files can be renamed into place.
+func (v *Vault) tmpDir() string {
+	return filepath.Join(v.root, "tmp")
+}
+
+func (v *Vault) blobPath(address string) string {
+	return filepath.Join(v.objectsDir(), address[:2], address)
+}
+
+func (v *Vault) metadataPath(address string) string {
+	return v.blobPath(address) + ".json"
+}
+
+// parseAddress checks that an address is a hex SHA-256 digest, so it cannot
+// name a path outside the vault, and returns it in lowercase
+func parseAddress(address string) (string, error) {
+	address = strings.ToLower(address)
+	if len(address) != sha256.Size*2 {
+		return "", fmt.Errorf("%w: %s", ErrInvalidAddress, address)
+	}
+	if _, err := hex.DecodeString(address); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidAddress, address)
+	}
+	return address, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,120 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// openTestVault opens a vault in a temporary directory
+func openTestVault(t *testing.T) *Vault {
+	v, err := Open(filepath.Join(t.TempDir(), "vault"), bytes.Repeat([]byte{1}, keySize))
+	require.NoError(t, err)
+	return v
+}
+
+// sha256Hex returns the hex SHA-256 digest of content
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// readObject returns the decrypted content of an object
+func readObject(t *testing.T, v *Vault, address string) string {
+	content, _, err := v.Get(address)
+	require.NoError(t, err)
+	defer content.Close()
+
+	contentBytes, err := io.ReadAll(content)
+	require.NoError(t, err)
+	return string(contentBytes)
+}
+
+func TestParseAddress(t *testing.T) {
+	address := sha256Hex("photograph of the knife")
+
+	parsed, err := parseAddress(strings.ToUpper(address))
+	require.NoError(t, err)
+	require.Equal(t, address, parsed)
+
+	invalid := []string{
+		"",
+		"..",
+		"../../etc/passwd",
+		"../" + address[3:],
+		address[:30] + "/../" + address[34:],
+		address[:62] + "/.",
+		address + "/..",
+		address[:63],
+		address + "0",
+		strings.Repeat("g", 64),
+	}
+	for _, address := range invalid {
+		_, err := parseAddress(address)
+		require.ErrorIs(t, err, ErrInvalidAddress, "address %q", address)
+	}
+}
+
+func TestVaultRejectsTraversal(t *testing.T) {
+	v := openTestVault(t)
+
+	// Addresses of the length of a digest that would name paths outside the
+	// objects directory
+	for _, address := range []string{"../../" + strings.Repeat("a", 58), ".." + string(filepath.Separator) + strings.Repeat("a", 61)} {
+		_, _, err := v.Get(address)
+		require.ErrorIs(t, err, ErrInvalidAddress)
+		_, err = v.Stat(address)
+		require.ErrorIs(t, err, ErrInvalidAddress)
+	}
+}
+
+func TestVaultPut(t *testing.T) {
+	v := openTestVault(t)
+	address := sha256Hex("photograph of the knife")
+
+	object, err := v.Put(strings.NewReader("photograph of the knife"), "EV2", "")
+	require.NoError(t, err)
+	require.Equal(t, address, object.SHA256)
+	require.Equal(t, int64(len("photograph of the knife")), object.Size)
+	require.Equal(t, []string{"EV2"}, object.EvidenceIDs)
+	require.True(t, strings.HasPrefix(object.CID, "bafkrei"))
+
+	// The file is stored once, and linked to each evidence item
+	object, err = v.Put(strings.NewReader("photograph of the knife"), "EV1", "sha256:"+strings.ToUpper(address))
+	require.NoError(t, err)
+	require.Equal(t, []string{"EV1", "EV2"}, object.EvidenceIDs)
+
+	objects, err := v.Objects()
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	require.Equal(t, "photograph of the knife", readObject(t, v, address))
+
+	// The file is encrypted at rest
+	stored, err := os.ReadFile(v.blobPath(address))
+	require.NoError(t, err)
+	require.NotContains(t, string(stored), "knife")
+
+	_, err = v.Put(strings.NewReader("photograph of the knife, retouched"), "EV1", address)
+	require.ErrorIs(t, err, ErrHashMismatch)
+
+	_, err = v.Stat(sha256Hex("something else"))
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestParseKey(t *testing.T) {
+	key, err := ParseKey(strings.Repeat("0f", keySize) + "\n")
+	require.NoError(t, err)
+	require.Equal(t, bytes.Repeat([]byte{0x0f}, keySize), key)
+
+	for _, keyHex := range []string{"", strings.Repeat("0f", keySize-1), strings.Repeat("zz", keySize)} {
+		_, err := ParseKey(keyHex)
+		require.EqualError(t, err, "vault key must be 64 hex digits")
+	}
+}
@@ -1,3 +0,0 @@
-module github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go-new
-
-go 1.18
@@ -0,0 +1,247 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// roleAttribute is the certificate attribute, issued by the Fabric CA, that
+// carries the role of the submitting client
+const roleAttribute = "role"
+
+// accessPolicyObjectType is the composite key object type under which access
+// policies are stored, keyed by transaction name
+const accessPolicyObjectType = "accesspolicy"
+
+// Roles recognised by the evidence contract
+const (
+	roleInvestigator = "investigator"
+	roleCustodian    = "custodian"
+	roleAnalyst      = "analyst"
+	roleProsecutor   = "prosecutor"
+	roleAuditor      = "auditor"
+	roleAdmin        = "admin"
+)
+
+// AccessPolicy lists the roles, and optionally the organizations, allowed to
+// submit a transaction
+type AccessPolicy struct {
+	Function string   `json:"Function"` // Name of the transaction the policy applies to
+	Roles    []string `json:"Roles"`    // Values of the role attribute that may submit the transaction
+	MSPIDs   []string `json:"MSPIDs"`   // Organizations that may submit the transaction, empty for any organization
+}
+
+// clientIdentity is the verified identity of the client submitting a transaction
+type clientIdentity struct {
+	ID    string // Decoded X.509 subject and issuer of the client
+	MSPID string // MSP the client belongs to
+	Role  string // Value of the client's role attribute
+}
+
+// defaultAccessPolicies are written to the ledger by InitLedger, and apply to
+// any transaction whose policy has not been stored on the ledger
+var defaultAccessPolicies = []*AccessPolicy{
+	{Function: "InitLedger", Roles: []string{roleAdmin}},
+	{Function: "SubmitEvidence", Roles: []string{roleInvestigator, roleCustodian}},
+	{Function: "UpdateEvidence", Roles: []string{roleInvestigator}},
+	{Function: "SubmitEvidenceBundle", Roles: []string{roleInvestigator, roleCustodian}},
+	{Function: "RegisterDerivedEvidence", Roles: []string{roleInvestigator, roleCustodian, roleAnalyst}},
+	{Function: "LinkEvidence", Roles: []string{roleInvestigator, roleCustodian, roleAnalyst}},
+	{Function: "SubmitPrivateEvidence", Roles: []string{roleInvestigator, roleCustodian}},
+	{Function: "UpdateEvidencePrivateDetails", Roles: []string{roleInvestigator}},
+	{Function: "MoveEvidenceDetailsToPrivate", Roles: []string{roleInvestigator, roleAdmin}},
+	{Function: "ReadEvidencePrivateDetails", Roles: []string{roleInvestigator, roleProsecutor}},
+	{Function: "UpdateEvidenceStatus", Roles: []string{roleInvestigator, roleCustodian, roleAnalyst, roleProsecutor}},
+	{Function: "DesignateVerifiers", Roles: []string{roleProsecutor, roleAdmin}},
+	{Function: "ApproveVerification", Roles: []string{roleInvestigator, roleAnalyst, roleProsecutor}},
+	{Function: "RequestCustodyTransfer", Roles: []string{roleInvestigator, roleCustodian, roleAnalyst, roleProsecutor}},
+	{Function: "AcceptCustodyTransfer", Roles: []string{roleInvestigator, roleCustodian, roleAnalyst, roleProsecutor}},
+	{Function: "RejectCustodyTransfer", Roles: []string{roleInvestigator, roleCustodian, roleAnalyst, roleProsecutor}},
+	{Function: "VerifyEvidenceIntegrity", Roles: []string{roleInvestigator, roleCustodian, roleAnalyst, roleProsecutor, roleAuditor}},
+	{Function: "ReportIntegrityFailure", Roles: []string{roleCustodian, roleAuditor}},
+	{Function: "CreateZKProof", Roles: []string{roleInvestigator, roleAnalyst}},
+	{Function: "VerifyZKProof", Roles: []string{roleAnalyst, roleProsecutor}},
+	{Function: "SubmitAIAnalysis", Roles: []string{roleAnalyst}},
+	{Function: "RegisterAnalyzer", Roles: []string{roleAdmin}},
+	{Function: "RevokeAnalyzer", Roles: []string{roleAdmin}},
+	{Function: "RecordEvidenceAccess", Roles: []string{roleInvestigator, roleCustodian, roleAnalyst, roleProsecutor, roleAuditor}},
+	{Function: "CreateCase", Roles: []string{roleInvestigator, roleAdmin}},
+	{Function: "AssignCase", Roles: []string{roleInvestigator, roleAdmin}},
+	{Function: "UpdateCaseStatus", Roles: []string{roleInvestigator, roleProsecutor, roleAdmin}},
+	{Function: "GetAccessLog", Roles: []string{roleAuditor, roleAdmin}},
+	{Function: "SetAccessPolicy", Roles: []string{roleAdmin}},
+	{Function: "MigrateRecords", Roles: []string{roleAdmin}},
+	{Function: "ReindexRecords", Roles: []string{roleAdmin}},
+	{Function: "SetRetentionPolicy", Roles: []string{roleAdmin}},
+	{Function: "RegisterEvidenceType", Roles: []string{roleAdmin}},
+	{Function: "PlaceLegalHold", Roles: []string{roleProsecutor, roleAdmin}},
+	{Function: "ReleaseLegalHold", Roles: []string{roleProsecutor, roleAdmin}},
+	{Function: "RequestEvidenceDisposition", Roles: []string{roleCustodian, roleProsecutor}},
+	{Function: "CancelEvidenceDisposition", Roles: []string{roleCustodian, roleProsecutor, roleAdmin}},
+	{Function: "DisposeEvidence", Roles: []string{roleCustodian, roleProsecutor, roleAdmin}},
+}
+
+// SetAccessPolicy stores the access policy for a transaction on the ledger,
+// replacing any existing or default policy for it
+func (s *SmartContract) SetAccessPolicy(
+	ctx contractapi.TransactionContextInterface,
+	function string,
+	roles []string,
+	mspIDs []string,
+) error {
+	_, err := authorize(ctx, "SetAccessPolicy")
+	if err != nil {
+		return err
+	}
+
+	if function == "" {
+		return fmt.Errorf("a transaction name is required")
+	}
+	if len(roles) == 0 {
+		return fmt.Errorf("the access policy for %s must allow at least one role", function)
+	}
+
+	return putAccessPolicy(ctx, &AccessPolicy{
+		Function: function,
+		Roles:    roles,
+		MSPIDs:   mspIDs,
+	})
+}
+
+// GetAccessPolicies returns the access policy in force for every transaction
+// that has one, whether stored on the ledger or a default
+func (s *SmartContract) GetAccessPolicies(ctx contractapi.TransactionContextInterface) ([]*AccessPolicy, error) {
+	policies := map[string]*AccessPolicy{}
+	for _, policy := range defaultAccessPolicies {
+		policies[policy.Function] = policy
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(accessPolicyObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var policy AccessPolicy
+		err = json.Unmarshal(queryResponse.Value, &policy)
+		if err != nil {
+			return nil, err
+		}
+		policies[policy.Function] = &policy
+	}
+
+	var result []*AccessPolicy
+	for _, policy := range policies {
+		result = append(result, policy)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Function < result[j].Function
+	})
+
+	return result, nil
+}
+
+// authorize checks the submitting client against the access policy for a
+// transaction, and returns the verified identity of the client. Transactions
+// must take the identity of the submitter from here rather than from their
+// arguments.
+func authorize(ctx contractapi.TransactionContextInterface, function string) (*clientIdentity, error) {
+	policy, err := getAccessPolicy(ctx, function)
+	if err != nil {
+		return nil, err
+	}
+
+	role, found, err := ctx.GetClientIdentity().GetAttributeValue(roleAttribute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client role: %v", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("submitting client not authorized to call %s, does not have a %s attribute", function, roleAttribute)
+	}
+	if !containsString(policy.Roles, role) {
+		return nil, fmt.Errorf("submitting client not authorized to call %s, role %s is not permitted", function, role)
+	}
+
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client MSP ID: %v", err)
+	}
+	if len(policy.MSPIDs) > 0 && !containsString(policy.MSPIDs, mspID) {
+		return nil, fmt.Errorf("submitting client not authorized to call %s, organization %s is not permitted", function, mspID)
+	}
+
+	clientID, err := getSubmittingClientIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &clientIdentity{
+		ID:    clientID,
+		MSPID: mspID,
+		Role:  role,
+	}, nil
+}
+
+// getAccessPolicy returns the policy stored on the ledger for a transaction,
+// falling back to the default policy. Transactions without any policy are denied.
+func getAccessPolicy(ctx contractapi.TransactionContextInterface, function string) (*AccessPolicy, error) {
+	policyKey, err := ctx.GetStub().CreateCompositeKey(accessPolicyObjectType, []string{function})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create access policy key: %v", err)
+	}
+
+	policyJSON, err := ctx.GetStub().GetState(policyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read access policy: %v", err)
+	}
+	if policyJSON != nil {
+		var policy AccessPolicy
+		err = json.Unmarshal(policyJSON, &policy)
+		if err != nil {
+			return nil, err
+		}
+		return &policy, nil
+	}
+
+	for _, policy := range defaultAccessPolicies {
+		if policy.Function == function {
+			return policy, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no access policy is defined for %s", function)
+}
+
+// putAccessPolicy stores an access policy under a composite key
+func putAccessPolicy(ctx contractapi.TransactionContextInterface, policy *AccessPolicy) error {
+	policyKey, err := ctx.GetStub().CreateCompositeKey(accessPolicyObjectType, []string{policy.Function})
+	if err != nil {
+		return fmt.Errorf("failed to create access policy key: %v", err)
+	}
+
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(policyKey, policyJSON)
+}
+
+// containsString returns true when value is in values
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetAccessPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   *mocks.Client
+		function string
+		roles    []string
+		err      string
+	}{
+		{name: "without transaction name", client: admin, roles: []string{"analyst"}, err: "a transaction name is required"},
+		{name: "without roles", client: admin, function: "SubmitEvidence", err: "the access policy for SubmitEvidence must allow at least one role"},
+		{name: "role not permitted", client: officer1, function: "SubmitEvidence", roles: []string{"analyst"}, err: "submitting client not authorized to call SetAccessPolicy, role investigator is not permitted"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, contract := setupLedger(t)
+			err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+				return contract.SetAccessPolicy(tx, test.function, test.roles, nil)
+			})
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestAccessPolicyIsEnforced(t *testing.T) {
+	ledger, contract := setupLedger(t)
+
+	err := ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.SetAccessPolicy(tx, "SubmitEvidence", []string{"custodian"}, []string{"Org2MSP"})
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		client *mocks.Client
+		err    string
+	}{
+		{client: officer1, err: "submitting client not authorized to call SubmitEvidence, role investigator is not permitted"},
+		{client: custodian, err: "submitting client not authorized to call SubmitEvidence, organization Org1MSP is not permitted"},
+		{client: newClient("custodian2", "Org2MSP", "custodian")},
+	}
+	for _, test := range tests {
+		err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+			return contract.SubmitEvidence(tx, "EV003", "Glove", "CASE1001", "", "QmGlove", nil, "")
+		})
+		if test.err != "" {
+			require.EqualError(t, err, test.err, test.client.ID)
+		} else {
+			require.NoError(t, err, test.client.ID)
+		}
+	}
+
+	policies := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.AccessPolicy, error) {
+		return contract.GetAccessPolicies(tx)
+	})
+	var overridden *chaincode.AccessPolicy
+	for i, policy := range policies {
+		if i > 0 {
+			require.Less(t, policies[i-1].Function, policy.Function)
+		}
+		if policy.Function == "SubmitEvidence" {
+			overridden = policy
+		}
+	}
+	require.NotNil(t, overridden)
+	require.Equal(t, []string{"custodian"}, overridden.Roles)
+	require.Equal(t, []string{"Org2MSP"}, overridden.MSPIDs)
+}
@@ -0,0 +1,111 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// AccessRecord records that a client accessed an evidence item, and why
+type AccessRecord struct {
+	EvidenceID string `json:"EvidenceID"` // ID of the evidence that was accessed
+	AccessedBy string `json:"AccessedBy"` // ID of the client that accessed the evidence
+	MSPID      string `json:"MSPID"`      // MSP of the client that accessed the evidence
+	Role       string `json:"Role"`       // Role of the client at the time of access
+	Purpose    string `json:"Purpose"`    // Purpose of the access stated by the client
+	AccessedAt string `json:"AccessedAt"` // When the access was recorded
+	TxID       string `json:"TxID"`       // ID of the transaction that recorded the access
+}
+
+// AccessLogPage is a page of access records, with the bookmark from which to
+// fetch the next page
+type AccessLogPage struct {
+	Records             []*AccessRecord `json:"records"`
+	FetchedRecordsCount int32           `json:"fetchedRecordsCount"`
+	Bookmark            string          `json:"bookmark"`
+}
+
+// RecordEvidenceAccess adds an entry to the access log of an evidence item,
+// capturing the verified identity of the client and the purpose it states.
+// Clients submit it alongside evaluating ReadEvidence, which is a pure query.
+func (s *SmartContract) RecordEvidenceAccess(ctx contractapi.TransactionContextInterface, id string, purpose string) error {
+	caller, err := authorize(ctx, "RecordEvidenceAccess")
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.EvidenceExists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("the evidence %s does not exist", id)
+	}
+	if purpose == "" {
+		return fmt.Errorf("a purpose is required to access evidence %s", id)
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	txID := ctx.GetStub().GetTxID()
+
+	key, err := accessRecordKey(ctx, id, currentTime, txID)
+	if err != nil {
+		return err
+	}
+
+	return putRecord(ctx, key, &AccessRecord{
+		EvidenceID: id,
+		AccessedBy: caller.ID,
+		MSPID:      caller.MSPID,
+		Role:       caller.Role,
+		Purpose:    purpose,
+		AccessedAt: currentTime,
+		TxID:       txID,
+	})
+}
+
+// GetAccessLog returns a page of the access log of an evidence item, oldest
+// first. Pass the bookmark from the previous page to fetch the next one.
+// Paginated queries are only valid for read only transactions.
+func (s *SmartContract) GetAccessLog(ctx contractapi.TransactionContextInterface, id string, pageSize int, bookmark string) (*AccessLogPage, error) {
+	_, err := authorize(ctx, "GetAccessLog")
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := checkPageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(accessObjectType, []string{id}, size, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	records := []*AccessRecord{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var record AccessRecord
+		err = json.Unmarshal(queryResponse.Value, &record)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, &record)
+	}
+
+	return &AccessLogPage{
+		Records:             records,
+		FetchedRecordsCount: responseMetadata.FetchedRecordsCount,
+		Bookmark:            responseMetadata.Bookmark,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordEvidenceAccess(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  *mocks.Client
+		id      string
+		purpose string
+		err     string
+	}{
+		{name: "with purpose", client: prosecutor, id: "EV001", purpose: "Trial preparation"},
+		{name: "without purpose", client: prosecutor, id: "EV001", err: "a purpose is required to access evidence EV001"},
+		{name: "unknown evidence", client: prosecutor, id: "EV404", purpose: "Trial preparation", err: "the evidence EV404 does not exist"},
+		{name: "role not permitted", client: admin, id: "EV001", purpose: "Curiosity", err: "submitting client not authorized to call RecordEvidenceAccess, role admin is not permitted"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, contract := setupLedger(t)
+			accessedAt := ledger.Now().Format("2006-01-02T15:04:05Z")
+
+			err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+				return contract.RecordEvidenceAccess(tx, test.id, test.purpose)
+			})
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+
+			page := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.AccessLogPage, error) {
+				return contract.GetAccessLog(tx, test.id, 10, "")
+			})
+			require.Len(t, page.Records, 1)
+			record := page.Records[0]
+			require.Equal(t, test.client.ID, record.AccessedBy)
+			require.Equal(t, test.client.MSPID, record.MSPID)
+			require.Equal(t, "prosecutor", record.Role)
+			require.Equal(t, test.purpose, record.Purpose)
+			require.Equal(t, accessedAt, record.AccessedAt)
+		})
+	}
+}
+
+func TestGetAccessLog(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	for _, client := range []*mocks.Client{officer1, analyst, prosecutor, auditor, custodian} {
+		err := ledger.Submit(client, func(tx *mocks.Transaction) error {
+			return contract.RecordEvidenceAccess(tx, "EV001", "Review")
+		})
+		require.NoError(t, err)
+	}
+	err := ledger.Submit(officer2, func(tx *mocks.Transaction) error {
+		return contract.RecordEvidenceAccess(tx, "EV002", "Review")
+	})
+	require.NoError(t, err)
+
+	var accessedBy []string
+	bookmark := ""
+	for {
+		page := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.AccessLogPage, error) {
+			return contract.GetAccessLog(tx, "EV001", 2, bookmark)
+		})
+		require.LessOrEqual(t, len(page.Records), 2)
+		require.Equal(t, int32(len(page.Records)), page.FetchedRecordsCount)
+		for _, record := range page.Records {
+			accessedBy = append(accessedBy, record.AccessedBy)
+		}
+		if page.Bookmark == "" {
+			break
+		}
+		bookmark = page.Bookmark
+	}
+	require.Equal(t, []string{"officer1", "analyst1", "prosecutor1", "auditor1", "custodian1"}, accessedBy)
+
+	err = ledger.Evaluate(officer1, func(tx *mocks.Transaction) error {
+		_, err := contract.GetAccessLog(tx, "EV001", 2, "")
+		return err
+	})
+	require.EqualError(t, err, "submitting client not authorized to call GetAccessLog, role investigator is not permitted")
+}
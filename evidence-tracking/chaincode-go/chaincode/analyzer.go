@@ -0,0 +1,406 @@
+package chaincode
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// tamperThreshold is the tamper probability from which an analysis counts as
+// a tamper finding, and below which the evidence is marked as AI verified
+const tamperThreshold = 0.5
+
+// Analyzer is an off-chain tamper analysis service whose signed results the
+// contract accepts
+type Analyzer struct {
+	ID           string `json:"ID"`           // Unique identifier for the analyzer
+	Name         string `json:"Name"`         // Human readable name of the analyzer
+	PublicKey    string `json:"PublicKey"`    // PEM encoded ECDSA public key results must be signed with
+	RegisteredBy string `json:"RegisteredBy"` // ID of the administrator that registered the analyzer
+	RegisteredAt string `json:"RegisteredAt"` // When the analyzer was registered
+	Active       bool   `json:"Active"`       // Whether results from the analyzer are accepted
+	RevokedAt    string `json:"RevokedAt"`    // When the analyzer was revoked, empty while it is active
+}
+
+// AnalysisStatement is the content of an analysis result that the analyzer
+// signs. The signature covers the SHA-256 digest of its JSON encoding, with
+// the fields in the order declared here.
+type AnalysisStatement struct {
+	AnalyzerID        string  `json:"AnalyzerID"`        // ID of the analyzer that performed the analysis
+	EvidenceID        string  `json:"EvidenceID"`        // ID of the evidence analyzed
+	FileHash          string  `json:"FileHash"`          // File hash of the evidence at the time of analysis
+	TamperProbability float64 `json:"TamperProbability"` // Probability of tampering (0-1)
+	AnalysisDetails   string  `json:"AnalysisDetails"`   // Details of the analysis in JSON format
+	AnalyzedAt        string  `json:"AnalyzedAt"`        // When the analyzer performed the analysis
+}
+
+// AnalysisAttestation is an analysis statement with the analyzer's signature
+type AnalysisAttestation struct {
+	Statement AnalysisStatement `json:"Statement"` // Signed content of the result
+	Signature string            `json:"Signature"` // Base64 ASN.1 ECDSA signature over the statement digest
+}
+
+// AnalyzerResultRef indexes an analysis result under the analyzer that made it
+type AnalyzerResultRef struct {
+	AnalyzerID        string  `json:"AnalyzerID"`        // ID of the analyzer
+	EvidenceID        string  `json:"EvidenceID"`        // ID of the evidence analyzed
+	TamperProbability float64 `json:"TamperProbability"` // Probability of tampering reported
+	AnalyzedTime      string  `json:"AnalyzedTime"`      // When the result was recorded
+	TxID              string  `json:"TxID"`              // Transaction that recorded the result
+}
+
+// AnalyzerTrackRecord summarises the results an analyzer has submitted
+type AnalyzerTrackRecord struct {
+	Analyzer           *Analyzer            `json:"Analyzer"`           // The analyzer
+	TotalAnalyses      int                  `json:"TotalAnalyses"`      // Number of results accepted from the analyzer
+	TamperFindings     int                  `json:"TamperFindings"`     // Number of results at or above the tamper threshold
+	AverageProbability float64              `json:"AverageProbability"` // Mean tamper probability reported
+	EvidenceAnalyzed   int                  `json:"EvidenceAnalyzed"`   // Number of distinct evidence items analyzed
+	Results            []*AnalyzerResultRef `json:"Results"`            // Every result accepted, oldest first
+}
+
+// RegisterAnalyzer registers an analyzer and the public key its results must
+// be signed with
+func (s *SmartContract) RegisterAnalyzer(ctx contractapi.TransactionContextInterface, id string, name string, publicKeyPEM string) error {
+	caller, err := authorize(ctx, "RegisterAnalyzer")
+	if err != nil {
+		return err
+	}
+
+	existing, err := getAnalyzer(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("the analyzer %s already exists", id)
+	}
+
+	_, err = parseAnalyzerKey(publicKeyPEM)
+	if err != nil {
+		return err
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	return putAnalyzer(ctx, &Analyzer{
+		ID:           id,
+		Name:         name,
+		PublicKey:    publicKeyPEM,
+		RegisteredBy: caller.ID,
+		RegisteredAt: currentTime,
+		Active:       true,
+	})
+}
+
+// RevokeAnalyzer stops results from an analyzer being accepted. Results
+// already recorded are kept.
+func (s *SmartContract) RevokeAnalyzer(ctx contractapi.TransactionContextInterface, id string) error {
+	_, err := authorize(ctx, "RevokeAnalyzer")
+	if err != nil {
+		return err
+	}
+
+	analyzer, err := s.ReadAnalyzer(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !analyzer.Active {
+		return fmt.Errorf("the analyzer %s is already revoked", id)
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	analyzer.Active = false
+	analyzer.RevokedAt = currentTime
+
+	return putAnalyzer(ctx, analyzer)
+}
+
+// ReadAnalyzer returns the analyzer stored in the world state with given id
+func (s *SmartContract) ReadAnalyzer(ctx contractapi.TransactionContextInterface, id string) (*Analyzer, error) {
+	analyzer, err := getAnalyzer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if analyzer == nil {
+		return nil, fmt.Errorf("the analyzer %s does not exist", id)
+	}
+
+	return analyzer, nil
+}
+
+// SubmitAIAnalysis records the result of a tamper analysis. The result is only
+// accepted from an active registered analyzer whose signature over it
+// verifies, only for the file hash the evidence currently has, and only when
+// it was made after the analyzer's previous result for the evidence. The AI
+// verification flag follows the most recently made analysis of the current
+// file.
+func (s *SmartContract) SubmitAIAnalysis(ctx contractapi.TransactionContextInterface, attestation AnalysisAttestation) (*AIAnalysisResult, error) {
+	caller, err := authorize(ctx, "SubmitAIAnalysis")
+	if err != nil {
+		return nil, err
+	}
+
+	statement := attestation.Statement
+	analyzer, err := s.ReadAnalyzer(ctx, statement.AnalyzerID)
+	if err != nil {
+		return nil, err
+	}
+	if !analyzer.Active {
+		return nil, fmt.Errorf("the analyzer %s has been revoked", analyzer.ID)
+	}
+
+	err = verifyAttestation(analyzer, &attestation)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := s.ReadEvidence(ctx, statement.EvidenceID)
+	if err != nil {
+		return nil, err
+	}
+	if statement.FileHash != evidence.FileHash {
+		return nil, fmt.Errorf("the analysis is of file %s, but evidence %s has file %s", statement.FileHash, evidence.ID, evidence.FileHash)
+	}
+	if statement.TamperProbability < 0 || statement.TamperProbability > 1 {
+		return nil, fmt.Errorf("tamper probability %v is not between 0 and 1", statement.TamperProbability)
+	}
+
+	analyzedAt, err := time.Parse(time.RFC3339, statement.AnalyzedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid analysis time %q: %v", statement.AnalyzedAt, err)
+	}
+
+	// A signed result can only be recorded once, and an analyzer's results
+	// must be recorded in the order it made them, so that a stale result
+	// submitted late cannot replace a newer finding
+	results, err := getAIResults(ctx, evidence.ID)
+	if err != nil {
+		return nil, err
+	}
+	newest := true
+	for _, existing := range results {
+		existingAt, err := time.Parse(time.RFC3339, existing.AnalyzerTime)
+		if err != nil {
+			continue // Results recorded before analyses were signed carry no analysis time
+		}
+		if existing.FileHash == evidence.FileHash && existingAt.After(analyzedAt) {
+			newest = false
+		}
+		if existing.AnalyzedBy != analyzer.ID {
+			continue
+		}
+		if existing.AnalyzerTime == statement.AnalyzedAt {
+			return nil, fmt.Errorf("the analysis was already recorded for evidence %s in transaction %s", evidence.ID, existing.TxID)
+		}
+		if !analyzedAt.After(existingAt) {
+			return nil, fmt.Errorf("the analysis made at %s is not newer than the analysis made by %s at %s, recorded in transaction %s",
+				statement.AnalyzedAt, analyzer.ID, existing.AnalyzerTime, existing.TxID)
+		}
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txID := ctx.GetStub().GetTxID()
+
+	result := &AIAnalysisResult{
+		EvidenceID:        evidence.ID,
+		TamperProbability: statement.TamperProbability,
+		AnalysisDetails:   statement.AnalysisDetails,
+		AnalyzedBy:        analyzer.ID,
+		AnalyzedTime:      currentTime,
+		AnalyzerTime:      statement.AnalyzedAt,
+		FileHash:          statement.FileHash,
+		Signature:         attestation.Signature,
+		SubmittedBy:       caller.ID,
+		TxID:              txID,
+	}
+
+	// Store the result on the ledger, and index it under the analyzer
+	resultKey, err := aiResultKey(ctx, evidence.ID, currentTime, txID)
+	if err != nil {
+		return nil, err
+	}
+	err = putRecord(ctx, resultKey, result)
+	if err != nil {
+		return nil, err
+	}
+
+	refKey, err := analyzerResultKey(ctx, analyzer.ID, currentTime, txID)
+	if err != nil {
+		return nil, err
+	}
+	err = putRecord(ctx, refKey, &AnalyzerResultRef{
+		AnalyzerID:        analyzer.ID,
+		EvidenceID:        evidence.ID,
+		TamperProbability: statement.TamperProbability,
+		AnalyzedTime:      currentTime,
+		TxID:              txID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the evidence with the AI verification status, unless another
+	// analyzer has since made a newer analysis
+	previousIntegrity := evidence.Integrity
+	if newest {
+		evidence.AIVerified = statement.TamperProbability < tamperThreshold
+	}
+	err = putEvidence(ctx, evidence)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the AI analysis in history
+	err = recordHistory(ctx, evidence, previousIntegrity, caller.ID, "ai_analysis",
+		fmt.Sprintf("AI analysis by '%s' with tamper probability: %.2f", analyzer.ID, statement.TamperProbability), "")
+	if err != nil {
+		return nil, err
+	}
+
+	err = emitEvidenceEvent(ctx, eventAIAnalysisRecorded, evidence.ID, caller.ID, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetAnalyzerTrackRecord returns every result accepted from an analyzer,
+// with totals that show how often it reports tampering
+func (s *SmartContract) GetAnalyzerTrackRecord(ctx contractapi.TransactionContextInterface, id string) (*AnalyzerTrackRecord, error) {
+	analyzer, err := s.ReadAnalyzer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &AnalyzerTrackRecord{
+		Analyzer: analyzer,
+		Results:  []*AnalyzerResultRef{},
+	}
+	evidenceAnalyzed := map[string]bool{}
+	totalProbability := 0.0
+
+	err = forEachRecord(ctx, analyzerResultObjectType, id, func(value []byte) error {
+		var ref AnalyzerResultRef
+		err := json.Unmarshal(value, &ref)
+		if err != nil {
+			return err
+		}
+
+		record.Results = append(record.Results, &ref)
+		record.TotalAnalyses++
+		if ref.TamperProbability >= tamperThreshold {
+			record.TamperFindings++
+		}
+		totalProbability += ref.TamperProbability
+		evidenceAnalyzed[ref.EvidenceID] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	record.EvidenceAnalyzed = len(evidenceAnalyzed)
+	if record.TotalAnalyses > 0 {
+		record.AverageProbability = totalProbability / float64(record.TotalAnalyses)
+	}
+
+	return record, nil
+}
+
+// verifyAttestation checks the signature of an analysis result against the
+// analyzer's registered key
+func verifyAttestation(analyzer *Analyzer, attestation *AnalysisAttestation) error {
+	publicKey, err := parseAnalyzerKey(analyzer.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(attestation.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to base64 decode analysis signature: %v", err)
+	}
+
+	statementJSON, err := json.Marshal(attestation.Statement)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(statementJSON)
+
+	if !ecdsa.VerifyASN1(publicKey, digest[:], signature) {
+		return fmt.Errorf("the analysis is not signed by analyzer %s", analyzer.ID)
+	}
+
+	return nil
+}
+
+// parseAnalyzerKey parses a PEM encoded ECDSA public key
+func parseAnalyzerKey(publicKeyPEM string) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("the analyzer public key is not PEM encoded")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse analyzer public key: %v", err)
+	}
+
+	publicKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("the analyzer public key is not an ECDSA key")
+	}
+
+	return publicKey, nil
+}
+
+// getAnalyzer returns the analyzer with the given ID, or nil if there is none
+func getAnalyzer(ctx contractapi.TransactionContextInterface, id string) (*Analyzer, error) {
+	key, err := analyzerKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	analyzerJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if analyzerJSON == nil {
+		return nil, nil
+	}
+
+	var analyzer Analyzer
+	err = json.Unmarshal(analyzerJSON, &analyzer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &analyzer, nil
+}
+
+// putAnalyzer stores an analyzer in the world state
+func putAnalyzer(ctx contractapi.TransactionContextInterface, analyzer *Analyzer) error {
+	key, err := analyzerKey(ctx, analyzer.ID)
+	if err != nil {
+		return err
+	}
+
+	return putRecord(ctx, key, analyzer)
+}
@@ -0,0 +1,362 @@
+package chaincode_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// newAnalyzerKey generates an analyzer signing key, and returns it with its
+// PEM encoded public key
+func newAnalyzerKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER}))
+}
+
+// attest signs an analysis statement the way an analyzer does
+func attest(t *testing.T, key *ecdsa.PrivateKey, statement chaincode.AnalysisStatement) chaincode.AnalysisAttestation {
+	t.Helper()
+
+	statementJSON, err := json.Marshal(statement)
+	require.NoError(t, err)
+	digest := sha256.Sum256(statementJSON)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+
+	return chaincode.AnalysisAttestation{
+		Statement: statement,
+		Signature: base64.StdEncoding.EncodeToString(signature),
+	}
+}
+
+// registerAnalyzer registers an analyzer with a new key, and returns the key
+func registerAnalyzer(t *testing.T, ledger *mocks.Ledger, contract *chaincode.SmartContract, id string) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, publicKeyPEM := newAnalyzerKey(t)
+	err := ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.RegisterAnalyzer(tx, id, "Tamper detector", publicKeyPEM)
+	})
+	require.NoError(t, err)
+
+	return key
+}
+
+// analysisOf returns a statement of the analysis of EV003 by analyzer AI1
+func analysisOf(tamperProbability float64, analyzedAt string) chaincode.AnalysisStatement {
+	return chaincode.AnalysisStatement{
+		AnalyzerID:        "AI1",
+		EvidenceID:        "EV003",
+		FileHash:          "QmEV003",
+		TamperProbability: tamperProbability,
+		AnalysisDetails:   `{"model": "v2"}`,
+		AnalyzedAt:        analyzedAt,
+	}
+}
+
+func TestRegisterAnalyzer(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	_, publicKeyPEM := newAnalyzerKey(t)
+
+	tests := []struct {
+		name      string
+		client    *mocks.Client
+		publicKey string
+		err       string
+	}{
+		{name: "without PEM encoding", client: admin, publicKey: "not a key", err: "the analyzer public key is not PEM encoded"},
+		{name: "role not permitted", client: analyst, publicKey: publicKeyPEM, err: "submitting client not authorized to call RegisterAnalyzer, role analyst is not permitted"},
+	}
+	for _, test := range tests {
+		err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+			return contract.RegisterAnalyzer(tx, "AI1", "Tamper detector", test.publicKey)
+		})
+		require.EqualError(t, err, test.err, test.name)
+	}
+
+	registeredAt := ledger.Now().Format("2006-01-02T15:04:05Z")
+	err := ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.RegisterAnalyzer(tx, "AI1", "Tamper detector", publicKeyPEM)
+	})
+	require.NoError(t, err)
+
+	analyzer := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.Analyzer, error) {
+		return contract.ReadAnalyzer(tx, "AI1")
+	})
+	require.Equal(t, &chaincode.Analyzer{
+		ID:           "AI1",
+		Name:         "Tamper detector",
+		PublicKey:    publicKeyPEM,
+		RegisteredBy: "admin1",
+		RegisteredAt: registeredAt,
+		Active:       true,
+	}, analyzer)
+
+	err = ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.RegisterAnalyzer(tx, "AI1", "Tamper detector", publicKeyPEM)
+	})
+	require.EqualError(t, err, "the analyzer AI1 already exists")
+}
+
+func TestRevokeAnalyzer(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	key := registerAnalyzer(t, ledger, contract, "AI1")
+
+	err := ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.RevokeAnalyzer(tx, "AI2")
+	})
+	require.EqualError(t, err, "the analyzer AI2 does not exist")
+
+	err = ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.RevokeAnalyzer(tx, "AI1")
+	})
+	require.NoError(t, err)
+	err = ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.RevokeAnalyzer(tx, "AI1")
+	})
+	require.EqualError(t, err, "the analyzer AI1 is already revoked")
+
+	err = ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		_, err := contract.SubmitAIAnalysis(tx, attest(t, key, analysisOf(0.1, "2024-01-01T08:00:00Z")))
+		return err
+	})
+	require.EqualError(t, err, "the analyzer AI1 has been revoked")
+}
+
+func TestSubmitAIAnalysis(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	key := registerAnalyzer(t, ledger, contract, "AI1")
+	otherKey, _ := newAnalyzerKey(t)
+
+	forged := attest(t, key, analysisOf(0.9, "2024-01-01T08:00:00Z"))
+	forged.Statement.TamperProbability = 0.1
+
+	staleFile := analysisOf(0.1, "2024-01-01T08:00:00Z")
+	staleFile.FileHash = "QmOld"
+
+	unknownAnalyzer := analysisOf(0.1, "2024-01-01T08:00:00Z")
+	unknownAnalyzer.AnalyzerID = "AI2"
+
+	tests := []struct {
+		name        string
+		client      *mocks.Client
+		attestation chaincode.AnalysisAttestation
+		err         string
+	}{
+		{name: "unknown analyzer", client: analyst, attestation: attest(t, key, unknownAnalyzer), err: "the analyzer AI2 does not exist"},
+		{name: "signed with another key", client: analyst, attestation: attest(t, otherKey, analysisOf(0.1, "2024-01-01T08:00:00Z")), err: "the analysis is not signed by analyzer AI1"},
+		{name: "altered after signing", client: analyst, attestation: forged, err: "the analysis is not signed by analyzer AI1"},
+		{name: "of another file", client: analyst, attestation: attest(t, key, staleFile), err: "the analysis is of file QmOld, but evidence EV003 has file QmEV003"},
+		{name: "probability out of range", client: analyst, attestation: attest(t, key, analysisOf(1.5, "2024-01-01T08:00:00Z")), err: "tamper probability 1.5 is not between 0 and 1"},
+		{name: "role not permitted", client: officer1, attestation: attest(t, key, analysisOf(0.1, "2024-01-01T08:00:00Z")), err: "submitting client not authorized to call SubmitAIAnalysis, role investigator is not permitted"},
+	}
+	for _, test := range tests {
+		err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+			_, err := contract.SubmitAIAnalysis(tx, test.attestation)
+			return err
+		})
+		require.EqualError(t, err, test.err, test.name)
+	}
+	require.False(t, readEvidence(t, ledger, contract, "EV003").AIVerified)
+
+	attestation := attest(t, key, analysisOf(0.1, "2024-01-01T08:00:00Z"))
+	result := submitResult(t, ledger, analyst, func(tx *mocks.Transaction) (*chaincode.AIAnalysisResult, error) {
+		return contract.SubmitAIAnalysis(tx, attestation)
+	})
+	require.Equal(t, "AI1", result.AnalyzedBy)
+	require.Equal(t, "analyst1", result.SubmittedBy)
+	require.Equal(t, attestation.Signature, result.Signature)
+	require.True(t, readEvidence(t, ledger, contract, "EV003").AIVerified)
+
+	event := lastEvent(t, ledger)
+	require.Equal(t, "AIAnalysisRecorded", event.Type)
+
+	// The same signed result cannot be recorded twice
+	err := ledger.Submit(analyst2, func(tx *mocks.Transaction) error {
+		_, err := contract.SubmitAIAnalysis(tx, attestation)
+		return err
+	})
+	require.EqualError(t, err, "the analysis was already recorded for evidence EV003 in transaction "+result.TxID)
+
+	// A later analysis that finds tampering clears the AI verification
+	err = ledger.Submit(analyst2, func(tx *mocks.Transaction) error {
+		_, err := contract.SubmitAIAnalysis(tx, attest(t, key, analysisOf(0.8, "2024-01-01T10:00:00Z")))
+		return err
+	})
+	require.NoError(t, err)
+	require.False(t, readEvidence(t, ledger, contract, "EV003").AIVerified)
+
+	// An older analysis submitted late cannot replace the tamper finding
+	err = ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		_, err := contract.SubmitAIAnalysis(tx, attest(t, key, analysisOf(0.1, "2024-01-01T09:00:00Z")))
+		return err
+	})
+	require.EqualError(t, err, "the analysis made at 2024-01-01T09:00:00Z is not newer than the analysis made by AI1 at 2024-01-01T10:00:00Z, recorded in transaction "+lastEvent(t, ledger).TxID)
+	require.False(t, readEvidence(t, ledger, contract, "EV003").AIVerified)
+
+	err = ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		_, err := contract.SubmitAIAnalysis(tx, attest(t, key, analysisOf(0.1, "yesterday")))
+		return err
+	})
+	require.ErrorContains(t, err, `invalid analysis time "yesterday"`)
+
+	results := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.AIAnalysisResult, error) {
+		return contract.GetAIAnalysisResults(tx, "EV003")
+	})
+	require.Len(t, results, 2)
+	require.Equal(t, 0.1, results[0].TamperProbability)
+	require.Equal(t, 0.8, results[1].TamperProbability)
+}
+
+func TestSubmitAIAnalysisFollowsNewestAnalysis(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	key := registerAnalyzer(t, ledger, contract, "AI1")
+	otherKey := registerAnalyzer(t, ledger, contract, "AI2")
+
+	err := ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		_, err := contract.SubmitAIAnalysis(tx, attest(t, key, analysisOf(0.9, "2024-01-01T10:00:00Z")))
+		return err
+	})
+	require.NoError(t, err)
+
+	// Another analyzer's older analysis is recorded, but does not clear the
+	// newer tamper finding
+	older := analysisOf(0.1, "2024-01-01T09:00:00Z")
+	older.AnalyzerID = "AI2"
+	err = ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		_, err := contract.SubmitAIAnalysis(tx, attest(t, otherKey, older))
+		return err
+	})
+	require.NoError(t, err)
+	require.False(t, readEvidence(t, ledger, contract, "EV003").AIVerified)
+
+	// Analysis times are compared as times, not as text
+	stale := analysisOf(0.1, "2024-01-01T09:30:00+01:00")
+	stale.AnalyzerID = "AI2"
+	err = ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		_, err := contract.SubmitAIAnalysis(tx, attest(t, otherKey, stale))
+		return err
+	})
+	require.EqualError(t, err, "the analysis made at 2024-01-01T09:30:00+01:00 is not newer than the analysis made by AI2 at 2024-01-01T09:00:00Z, recorded in transaction "+lastEvent(t, ledger).TxID)
+}
+
+func TestSubmitAIAnalysisOfNewFile(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	key := registerAnalyzer(t, ledger, contract, "AI1")
+	otherKey := registerAnalyzer(t, ledger, contract, "AI2")
+
+	err := ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		_, err := contract.SubmitAIAnalysis(tx, attest(t, key, analysisOf(0.1, "2024-01-01T10:00:00Z")))
+		return err
+	})
+	require.NoError(t, err)
+	require.True(t, readEvidence(t, ledger, contract, "EV003").AIVerified)
+
+	// A new file is not verified by the analysis of the old one
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.UpdateEvidence(tx, "EV003", "Evidence EV003", "QmRescanned", nil, `{"source": "test"}`)
+	})
+	require.NoError(t, err)
+	require.False(t, readEvidence(t, ledger, contract, "EV003").AIVerified)
+
+	// An analysis of the new file counts, even when it was made before the
+	// analysis of the old file
+	rescanned := analysisOf(0.2, "2024-01-01T09:00:00Z")
+	rescanned.AnalyzerID = "AI2"
+	rescanned.FileHash = "QmRescanned"
+	err = ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		_, err := contract.SubmitAIAnalysis(tx, attest(t, otherKey, rescanned))
+		return err
+	})
+	require.NoError(t, err)
+	require.True(t, readEvidence(t, ledger, contract, "EV003").AIVerified)
+}
+
+// gatewayAnalyzerKeyPEM is the public key of the analyzer key in the tests
+// of the analyzer package of application-gateway-go
+const gatewayAnalyzerKeyPEM = `-----BEGIN PUBLIC KEY-----
+MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEV9QeO+xI6as0m4zYlU0UgfT2jvBc
+P1fccutjdcoAJBh/AwW5fscpEcd5JHkj50yM4ylbNSvYdBMCbWFXva1tAg==
+-----END PUBLIC KEY-----
+`
+
+// gatewayAttestationJSON is an attestation made by the analyzer package of
+// application-gateway-go with that key, as it is passed to SubmitAIAnalysis.
+// Its statement is the one the tests of that package sign.
+const gatewayAttestationJSON = `{"Statement":{"AnalyzerID":"AI1","EvidenceID":"EV003","FileHash":"QmEV003","TamperProbability":0.09999999999999998,"AnalysisDetails":"[{\"check\":\"file-hash\",\"severity\":\"info\",\"detail\":\"recorded hash QmEV003 is not a SHA-256 digest and cannot be checked locally\",\"weight\":0.1}]","AnalyzedAt":"2024-01-01T08:00:00Z"},"Signature":"MEUCIQCZlli8fmPG3Xb/MadToU4FE8+PrtWN877rJzJYzM1NnQIgBVVi+LR9TpL/Kr4cr8S6/8hFtGkeKJtmLVVNkVOQKhM="}`
+
+func TestSubmitAIAnalysisSignedByGatewayAnalyzer(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	err := ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.RegisterAnalyzer(tx, "AI1", "Reference analyzer", gatewayAnalyzerKeyPEM)
+	})
+	require.NoError(t, err)
+
+	var attestation chaincode.AnalysisAttestation
+	require.NoError(t, json.Unmarshal([]byte(gatewayAttestationJSON), &attestation))
+	result := submitResult(t, ledger, analyst, func(tx *mocks.Transaction) (*chaincode.AIAnalysisResult, error) {
+		return contract.SubmitAIAnalysis(tx, attestation)
+	})
+	require.Equal(t, "AI1", result.AnalyzedBy)
+	require.Equal(t, "2024-01-01T08:00:00Z", result.AnalyzerTime)
+	require.True(t, readEvidence(t, ledger, contract, "EV003").AIVerified)
+}
+
+func TestGetAnalyzerTrackRecord(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	submitEvidence(t, ledger, contract, "EV004")
+	key := registerAnalyzer(t, ledger, contract, "AI1")
+
+	ev004 := analysisOf(0.9, "2024-01-01T08:00:00Z")
+	ev004.EvidenceID = "EV004"
+	ev004.FileHash = "QmEV004"
+	for _, statement := range []chaincode.AnalysisStatement{
+		analysisOf(0.1, "2024-01-01T08:00:00Z"),
+		analysisOf(0.2, "2024-01-01T09:00:00Z"),
+		ev004,
+	} {
+		err := ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+			_, err := contract.SubmitAIAnalysis(tx, attest(t, key, statement))
+			return err
+		})
+		require.NoError(t, err)
+	}
+
+	record := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.AnalyzerTrackRecord, error) {
+		return contract.GetAnalyzerTrackRecord(tx, "AI1")
+	})
+	require.Equal(t, 3, record.TotalAnalyses)
+	require.Equal(t, 1, record.TamperFindings)
+	require.Equal(t, 2, record.EvidenceAnalyzed)
+	require.InDelta(t, 0.4, record.AverageProbability, 1e-9)
+	require.Len(t, record.Results, 3)
+	require.Equal(t, "EV004", record.Results[2].EvidenceID)
+
+	err := ledger.Evaluate(auditor, func(tx *mocks.Transaction) error {
+		_, err := contract.GetAnalyzerTrackRecord(tx, "AI2")
+		return err
+	})
+	require.EqualError(t, err, "the analyzer AI2 does not exist")
+}
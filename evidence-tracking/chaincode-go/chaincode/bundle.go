@@ -0,0 +1,157 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/merkle"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// EvidenceBundle is the manifest of an evidence item made up of many files,
+// such as a phone extraction. The FileHash of the evidence is the Merkle root
+// of the manifest.
+type EvidenceBundle struct {
+	EvidenceID string        `json:"EvidenceID"` // ID of the evidence
+	Algorithm  string        `json:"Algorithm"`  // How the Merkle root was computed
+	Root       string        `json:"Root"`       // Merkle root of the files
+	Files      []merkle.File `json:"Files"`      // Files of the bundle
+}
+
+// SubmitEvidenceBundle issues a new evidence record for a set of files. The
+// Merkle root of the manifest becomes the FileHash of the evidence, and the
+// manifest is stored alongside it.
+func (s *SmartContract) SubmitEvidenceBundle(
+	ctx contractapi.TransactionContextInterface,
+	id string,
+	description string,
+	caseID string,
+	evidenceType string,
+	files []merkle.File,
+	tags []string,
+	metadata string,
+) error {
+	caller, err := authorize(ctx, "SubmitEvidenceBundle")
+	if err != nil {
+		return err
+	}
+
+	root, err := merkle.Root(files)
+	if err != nil {
+		return fmt.Errorf("invalid manifest for evidence %s: %v", id, err)
+	}
+
+	err = putBundle(ctx, &EvidenceBundle{
+		EvidenceID: id,
+		Algorithm:  merkle.Algorithm,
+		Root:       root,
+		Files:      files,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.submitEvidence(ctx, caller, &Evidence{
+		ID:           id,
+		Description:  description,
+		CaseID:       caseID,
+		EvidenceType: evidenceType,
+		FileHash:     root,
+		Tags:         tags,
+		Metadata:     metadata,
+	}, nil, nil)
+}
+
+// GetEvidenceBundle returns the manifest of a bundle
+func (s *SmartContract) GetEvidenceBundle(ctx contractapi.TransactionContextInterface, id string) (*EvidenceBundle, error) {
+	bundle, err := getBundle(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if bundle == nil {
+		return nil, fmt.Errorf("evidence %s is not a bundle", id)
+	}
+
+	return bundle, nil
+}
+
+// VerifyBundleMember checks a proof, built off-chain with merkle.Prove, that a
+// file with the given path and SHA-256 digest belongs to a bundle. The proof
+// is checked against the FileHash of the evidence, which is covered by its
+// integrity hash, so the rest of the manifest is not needed.
+func (s *SmartContract) VerifyBundleMember(
+	ctx contractapi.TransactionContextInterface,
+	id string,
+	path string,
+	hash string,
+	proof merkle.Proof,
+) (bool, error) {
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	bundle, err := getBundle(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if bundle == nil {
+		return false, fmt.Errorf("evidence %s is not a bundle", id)
+	}
+
+	return merkle.Verify(evidence.FileHash, path, hash, &proof) == nil, nil
+}
+
+// bundleMatches reports whether the manifest of a bundle still has the
+// Merkle root recorded as the FileHash of its evidence. Evidence that is not a
+// bundle always matches.
+func bundleMatches(ctx contractapi.TransactionContextInterface, evidence *Evidence) (bool, error) {
+	bundle, err := getBundle(ctx, evidence.ID)
+	if err != nil {
+		return false, err
+	}
+	if bundle == nil {
+		return true, nil
+	}
+
+	root, err := merkle.Root(bundle.Files)
+	if err != nil {
+		return false, nil
+	}
+
+	return root == bundle.Root && root == evidence.FileHash, nil
+}
+
+// getBundle returns the manifest of an evidence item, or nil if the item is
+// not a bundle
+func getBundle(ctx contractapi.TransactionContextInterface, evidenceID string) (*EvidenceBundle, error) {
+	key, err := bundleKey(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	bundleJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if bundleJSON == nil {
+		return nil, nil
+	}
+
+	var bundle EvidenceBundle
+	err = json.Unmarshal(bundleJSON, &bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bundle, nil
+}
+
+// putBundle stores the manifest of a bundle
+func putBundle(ctx contractapi.TransactionContextInterface, bundle *EvidenceBundle) error {
+	key, err := bundleKey(ctx, bundle.EvidenceID)
+	if err != nil {
+		return err
+	}
+	return putRecord(ctx, key, bundle)
+}
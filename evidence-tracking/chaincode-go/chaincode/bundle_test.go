@@ -0,0 +1,129 @@
+package chaincode_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+// digest returns the hex SHA-256 digest of file content
+func digest(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// phoneExtraction is the manifest of a bundle of files taken from a phone
+var phoneExtraction = []merkle.File{
+	{Path: "contacts.db", Size: 8, SHA256: digest("contacts")},
+	{Path: "messages/sms.db", Size: 8, SHA256: digest("messages")},
+	{Path: "photos/IMG_0001.jpg", Size: 5, SHA256: digest("photo")},
+}
+
+// submitBundle submits the phone extraction as EV003
+func submitBundle(t *testing.T, ledger *mocks.Ledger, contract *chaincode.SmartContract) {
+	t.Helper()
+	err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.SubmitEvidenceBundle(tx, "EV003", "Phone extraction", "CASE1001", "", phoneExtraction, []string{"phone"}, "")
+	})
+	require.NoError(t, err)
+}
+
+func TestSubmitEvidenceBundle(t *testing.T) {
+	ledger, contract := setupLedger(t)
+
+	err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.SubmitEvidenceBundle(tx, "EV003", "Phone extraction", "CASE1001", "", nil, nil, "")
+	})
+	require.EqualError(t, err, "invalid manifest for evidence EV003: a bundle needs at least one file")
+
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.SubmitEvidenceBundle(tx, "EV003", "Phone extraction", "CASE1001", "", []merkle.File{phoneExtraction[0], phoneExtraction[0]}, nil, "")
+	})
+	require.EqualError(t, err, "invalid manifest for evidence EV003: the path contacts.db appears more than once in the bundle")
+
+	submitBundle(t, ledger, contract)
+
+	root, err := merkle.Root(phoneExtraction)
+	require.NoError(t, err)
+	require.Equal(t, root, readEvidence(t, ledger, contract, "EV003").FileHash)
+
+	bundle := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) (*chaincode.EvidenceBundle, error) {
+		return contract.GetEvidenceBundle(tx, "EV003")
+	})
+	require.Equal(t, merkle.Algorithm, bundle.Algorithm)
+	require.Equal(t, root, bundle.Root)
+	require.Len(t, bundle.Files, 3)
+
+	err = ledger.Evaluate(officer1, func(tx *mocks.Transaction) error {
+		_, err := contract.GetEvidenceBundle(tx, "EV001")
+		return err
+	})
+	require.EqualError(t, err, "evidence EV001 is not a bundle")
+
+	// The root cannot be replaced by an ordinary file hash
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.UpdateEvidence(tx, "EV003", "Phone extraction", "QmOther", []string{"phone"}, "")
+	})
+	require.EqualError(t, err, "the file hash of evidence EV003 is the root of its bundle and cannot be changed")
+}
+
+func TestVerifyBundleMember(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitBundle(t, ledger, contract)
+
+	proof, err := merkle.Prove(phoneExtraction, "messages/sms.db")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		path  string
+		hash  string
+		valid bool
+	}{
+		{name: "member", path: "messages/sms.db", hash: digest("messages"), valid: true},
+		{name: "altered file", path: "messages/sms.db", hash: digest("deleted"), valid: false},
+		{name: "moved file", path: "messages/old.db", hash: digest("messages"), valid: false},
+	}
+	for _, test := range tests {
+		valid := evaluate(t, ledger, prosecutor, func(tx *mocks.Transaction) (bool, error) {
+			return contract.VerifyBundleMember(tx, "EV003", test.path, test.hash, *proof)
+		})
+		require.Equal(t, test.valid, valid, test.name)
+	}
+
+	err = ledger.Evaluate(prosecutor, func(tx *mocks.Transaction) error {
+		_, err := contract.VerifyBundleMember(tx, "EV001", "messages/sms.db", digest("messages"), *proof)
+		return err
+	})
+	require.EqualError(t, err, "evidence EV001 is not a bundle")
+}
+
+func TestVerifyEvidenceIntegrityOfBundle(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitBundle(t, ledger, contract)
+
+	valid := submitResult(t, ledger, auditor, func(tx *mocks.Transaction) (bool, error) {
+		return contract.VerifyEvidenceIntegrity(tx, "EV003")
+	})
+	require.True(t, valid)
+
+	// Swap a file in the stored manifest behind the contract's back
+	key := compositeKey("bundle", "EV003")
+	var bundle chaincode.EvidenceBundle
+	require.NoError(t, json.Unmarshal(ledger.GetState(key), &bundle))
+	bundle.Files[2].SHA256 = digest("edited photo")
+	bundleJSON, err := json.Marshal(bundle)
+	require.NoError(t, err)
+	ledger.SetState(key, bundleJSON)
+
+	valid = submitResult(t, ledger, auditor, func(tx *mocks.Transaction) (bool, error) {
+		return contract.VerifyEvidenceIntegrity(tx, "EV003")
+	})
+	require.False(t, valid)
+}
@@ -0,0 +1,436 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Statuses in the case lifecycle
+const (
+	caseStatusOpen     = "open"
+	caseStatusClosed   = "closed"
+	caseStatusArchived = "archived"
+	caseStatusAppealed = "appealed"
+)
+
+// caseStatusTransitions lists, for each case status, the statuses a case may
+// move to. A case is opened when it is created; it can be closed, reopened,
+// appealed once closed, and finally archived. Archived cases are read only.
+var caseStatusTransitions = map[string][]string{
+	caseStatusOpen:     {caseStatusClosed},
+	caseStatusClosed:   {caseStatusOpen, caseStatusAppealed, caseStatusArchived},
+	caseStatusAppealed: {caseStatusClosed},
+}
+
+// overdueAfter is how long evidence may remain in a status before the case
+// dashboard reports it as overdue
+var overdueAfter = map[string]time.Duration{
+	statusInTransit:  48 * time.Hour,
+	statusReceived:   7 * 24 * time.Hour,
+	statusProcessing: 30 * 24 * time.Hour,
+	statusAnalyzed:   14 * 24 * time.Hour,
+}
+
+// pendingTransferOverdueAfter is how long a custody transfer may wait for
+// acceptance before the case dashboard reports it as overdue
+const pendingTransferOverdueAfter = 48 * time.Hour
+
+// Case describes an investigation that evidence is submitted against
+type Case struct {
+	ID               string       `json:"ID"`               // Unique identifier for the case
+	Title            string       `json:"Title"`            // Short title of the case
+	CaseType         string       `json:"CaseType"`         // Type of case, which determines how long its evidence is retained
+	Jurisdiction     string       `json:"Jurisdiction"`     // Court or authority the case falls under
+	LeadInvestigator string       `json:"LeadInvestigator"` // ID of the investigator leading the case
+	AssignedTeam     []string     `json:"AssignedTeam"`     // IDs of the other members assigned to the case
+	Status           string       `json:"Status"`           // Current status of the case (open, closed, archived or appealed)
+	OpenedAt         string       `json:"OpenedAt"`         // When the case was opened
+	ClosedAt         string       `json:"ClosedAt"`         // When the case was last closed, empty while it is open
+	Timeline         []*CaseEvent `json:"Timeline"`         // Every change of case status, oldest first
+}
+
+// CaseEvent records a change of status in the timeline of a case
+type CaseEvent struct {
+	Status    string `json:"Status"`    // Status the case moved to
+	ChangedBy string `json:"ChangedBy"` // ID of the client that made the change
+	ChangedAt string `json:"ChangedAt"` // When the change was made
+	Reason    string `json:"Reason"`    // Why the change was made
+	TxID      string `json:"TxID"`      // Transaction that made the change
+}
+
+// CaseDashboard summarises the state of a case and of all its evidence
+type CaseDashboard struct {
+	Case                *Case             `json:"Case"`                // The case itself
+	TotalEvidence       int               `json:"TotalEvidence"`       // Number of evidence items submitted against the case
+	StatusCounts        []*StatusCount    `json:"StatusCounts"`        // Number of evidence items in each status
+	AIVerifiedEvidence  int               `json:"AIVerifiedEvidence"`  // Number of items verified by AI analysis
+	ZKPVerifiedEvidence int               `json:"ZKPVerifiedEvidence"` // Number of items with a verified zero-knowledge proof
+	CustodyHolders      []*CustodyHolding `json:"CustodyHolders"`      // Who currently holds the evidence of the case
+	PendingAnalyses     []*DashboardItem  `json:"PendingAnalyses"`     // Items received for, or undergoing, analysis
+	OverdueItems        []*DashboardItem  `json:"OverdueItems"`        // Items that have waited too long for their next step
+	GeneratedAt         string            `json:"GeneratedAt"`         // Time the dashboard was computed at
+}
+
+// StatusCount is the number of evidence items in a status
+type StatusCount struct {
+	Status string `json:"Status"` // Evidence status
+	Count  int    `json:"Count"`  // Number of evidence items in the status
+}
+
+// CustodyHolding lists the evidence held by one custodian
+type CustodyHolding struct {
+	Custodian   string   `json:"Custodian"`   // ID of the custodian
+	EvidenceIDs []string `json:"EvidenceIDs"` // Evidence the custodian currently holds
+}
+
+// DashboardItem is an evidence item called out on the case dashboard
+type DashboardItem struct {
+	EvidenceID string `json:"EvidenceID"` // ID of the evidence
+	Status     string `json:"Status"`     // Status of the evidence
+	Since      string `json:"Since"`      // When the item entered the state it is reported for
+	Detail     string `json:"Detail"`     // Human readable explanation
+}
+
+// CreateCase opens a new case. Evidence can only be submitted against cases
+// that have been created. The case type must have a retention policy; an
+// empty case type creates a general case.
+func (s *SmartContract) CreateCase(
+	ctx contractapi.TransactionContextInterface,
+	id string,
+	title string,
+	caseType string,
+	jurisdiction string,
+	leadInvestigator string,
+	assignedTeam []string,
+) error {
+	caller, err := authorize(ctx, "CreateCase")
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.CaseExists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("the case %s already exists", id)
+	}
+	if leadInvestigator == "" {
+		return fmt.Errorf("a lead investigator is required")
+	}
+	if caseType == "" {
+		caseType = caseTypeGeneral
+	}
+	_, err = getRetentionPolicy(ctx, caseType)
+	if err != nil {
+		return err
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	c := &Case{
+		ID:               id,
+		Title:            title,
+		CaseType:         caseType,
+		Jurisdiction:     jurisdiction,
+		LeadInvestigator: leadInvestigator,
+		AssignedTeam:     assignedTeam,
+		Status:           caseStatusOpen,
+		OpenedAt:         currentTime,
+		Timeline: []*CaseEvent{
+			{
+				Status:    caseStatusOpen,
+				ChangedBy: caller.ID,
+				ChangedAt: currentTime,
+				Reason:    "Case opened",
+				TxID:      ctx.GetStub().GetTxID(),
+			},
+		},
+	}
+
+	return putCase(ctx, c)
+}
+
+// ReadCase returns the case stored in the world state with given id
+func (s *SmartContract) ReadCase(ctx contractapi.TransactionContextInterface, id string) (*Case, error) {
+	key, err := caseKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	caseJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if caseJSON == nil {
+		return nil, fmt.Errorf("the case %s does not exist", id)
+	}
+
+	var c Case
+	err = json.Unmarshal(caseJSON, &c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// CaseExists returns true when a case with given ID exists in world state
+func (s *SmartContract) CaseExists(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
+	key, err := caseKey(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	caseJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	return caseJSON != nil, nil
+}
+
+// GetAllCases returns all cases found in world state
+func (s *SmartContract) GetAllCases(ctx contractapi.TransactionContextInterface) ([]*Case, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(caseObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	cases := []*Case{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var c Case
+		err = json.Unmarshal(queryResponse.Value, &c)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, &c)
+	}
+
+	return cases, nil
+}
+
+// AssignCase changes the lead investigator and the team assigned to a case.
+// Only the current lead investigator or an administrator may reassign a case.
+func (s *SmartContract) AssignCase(
+	ctx contractapi.TransactionContextInterface,
+	id string,
+	leadInvestigator string,
+	assignedTeam []string,
+) error {
+	caller, err := authorize(ctx, "AssignCase")
+	if err != nil {
+		return err
+	}
+
+	c, err := s.ReadCase(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if caller.Role != roleAdmin && caller.ID != c.LeadInvestigator {
+		return fmt.Errorf("submitting client is not the lead investigator of case %s", id)
+	}
+	if c.Status == caseStatusArchived {
+		return fmt.Errorf("the case %s is archived", id)
+	}
+	if leadInvestigator == "" {
+		return fmt.Errorf("a lead investigator is required")
+	}
+
+	c.LeadInvestigator = leadInvestigator
+	c.AssignedTeam = assignedTeam
+
+	return putCase(ctx, c)
+}
+
+// UpdateCaseStatus moves a case to a new status and records the change in the
+// case timeline. A reason is required for every change.
+func (s *SmartContract) UpdateCaseStatus(ctx contractapi.TransactionContextInterface, id string, newStatus string, reason string) error {
+	caller, err := authorize(ctx, "UpdateCaseStatus")
+	if err != nil {
+		return err
+	}
+
+	c, err := s.ReadCase(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !containsString(caseStatusTransitions[c.Status], newStatus) {
+		return fmt.Errorf("cannot move case %s from %s to %s", id, c.Status, newStatus)
+	}
+	if reason == "" {
+		return fmt.Errorf("a reason is required to change the status of case %s", id)
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.Status = newStatus
+	switch newStatus {
+	case caseStatusOpen:
+		c.ClosedAt = ""
+	case caseStatusClosed:
+		c.ClosedAt = currentTime
+	}
+	c.Timeline = append(c.Timeline, &CaseEvent{
+		Status:    newStatus,
+		ChangedBy: caller.ID,
+		ChangedAt: currentTime,
+		Reason:    reason,
+		TxID:      ctx.GetStub().GetTxID(),
+	})
+
+	return putCase(ctx, c)
+}
+
+// GetEvidenceStatsByCaseID returns the dashboard of a case: counts of its
+// evidence by status, who holds the evidence, which items are awaiting
+// analysis and which have been waiting too long for their next step
+func (s *SmartContract) GetEvidenceStatsByCaseID(ctx contractapi.TransactionContextInterface, caseID string) (*CaseDashboard, error) {
+	c, err := s.ReadCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	evidenceList, err := s.GetEvidenceByCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now, err := time.Parse(time.RFC3339, currentTime)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &CaseDashboard{
+		Case:            c,
+		TotalEvidence:   len(evidenceList),
+		StatusCounts:    []*StatusCount{},
+		CustodyHolders:  []*CustodyHolding{},
+		PendingAnalyses: []*DashboardItem{},
+		OverdueItems:    []*DashboardItem{},
+		GeneratedAt:     currentTime,
+	}
+
+	statusCounts := map[string]int{}
+	holdings := map[string][]string{}
+	for _, ev := range evidenceList {
+		statusCounts[ev.Status]++
+		holdings[currentCustodian(ev)] = append(holdings[currentCustodian(ev)], ev.ID)
+
+		if ev.AIVerified {
+			dashboard.AIVerifiedEvidence++
+		}
+		if ev.ProofVerified {
+			dashboard.ZKPVerifiedEvidence++
+		}
+
+		since := statusUpdatedTime(ev)
+		if ev.Status == statusReceived || ev.Status == statusProcessing {
+			dashboard.PendingAnalyses = append(dashboard.PendingAnalyses, &DashboardItem{
+				EvidenceID: ev.ID,
+				Status:     ev.Status,
+				Since:      since,
+				Detail:     fmt.Sprintf("Evidence is %s awaiting analysis", ev.Status),
+			})
+		}
+
+		if limit, ok := overdueAfter[ev.Status]; ok && isOverdue(since, limit, now) {
+			dashboard.OverdueItems = append(dashboard.OverdueItems, &DashboardItem{
+				EvidenceID: ev.ID,
+				Status:     ev.Status,
+				Since:      since,
+				Detail:     fmt.Sprintf("Evidence has been %s for longer than %s", ev.Status, limit),
+			})
+		}
+		if ev.PendingTransfer != nil && isOverdue(ev.PendingTransfer.RequestedAt, pendingTransferOverdueAfter, now) {
+			dashboard.OverdueItems = append(dashboard.OverdueItems, &DashboardItem{
+				EvidenceID: ev.ID,
+				Status:     ev.Status,
+				Since:      ev.PendingTransfer.RequestedAt,
+				Detail:     fmt.Sprintf("Custody transfer to %s has not been accepted", ev.PendingTransfer.ToCustodian),
+			})
+		}
+	}
+
+	for status, count := range statusCounts {
+		dashboard.StatusCounts = append(dashboard.StatusCounts, &StatusCount{Status: status, Count: count})
+	}
+	sort.Slice(dashboard.StatusCounts, func(i, j int) bool {
+		return dashboard.StatusCounts[i].Status < dashboard.StatusCounts[j].Status
+	})
+
+	for custodian, evidenceIDs := range holdings {
+		dashboard.CustodyHolders = append(dashboard.CustodyHolders, &CustodyHolding{Custodian: custodian, EvidenceIDs: evidenceIDs})
+	}
+	sort.Slice(dashboard.CustodyHolders, func(i, j int) bool {
+		return dashboard.CustodyHolders[i].Custodian < dashboard.CustodyHolders[j].Custodian
+	})
+
+	return dashboard, nil
+}
+
+// checkCaseAcceptsEvidence returns an error unless the case exists and is
+// open, or has been reopened on appeal
+func (s *SmartContract) checkCaseAcceptsEvidence(ctx contractapi.TransactionContextInterface, caseID string) error {
+	c, err := s.ReadCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if c.Status != caseStatusOpen && c.Status != caseStatusAppealed {
+		return fmt.Errorf("the case %s is %s and does not accept new evidence", caseID, c.Status)
+	}
+
+	return nil
+}
+
+// statusUpdatedTime returns when the evidence entered its current status.
+// Records written before the status time was kept fall back to the time the
+// evidence was submitted.
+func statusUpdatedTime(evidence *Evidence) string {
+	if evidence.StatusUpdatedTime != "" {
+		return evidence.StatusUpdatedTime
+	}
+	return evidence.SubmittedTime
+}
+
+// isOverdue reports whether more than limit has passed between since and now.
+// Times that cannot be parsed are never reported as overdue.
+func isOverdue(since string, limit time.Duration, now time.Time) bool {
+	sinceTime, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return false
+	}
+	return now.Sub(sinceTime) > limit
+}
+
+// putCase stores a case in the world state
+func putCase(ctx contractapi.TransactionContextInterface, c *Case) error {
+	key, err := caseKey(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+
+	return putRecord(ctx, key, c)
+}
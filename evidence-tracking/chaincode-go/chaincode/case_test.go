@@ -0,0 +1,218 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// updateCaseStatus moves a case to a new status as the prosecutor
+func updateCaseStatus(t *testing.T, ledger *mocks.Ledger, contract *chaincode.SmartContract, id string, status string) {
+	t.Helper()
+	err := ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+		return contract.UpdateCaseStatus(tx, id, status, "Case "+status)
+	})
+	require.NoError(t, err)
+}
+
+func TestCreateCase(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   *mocks.Client
+		id       string
+		caseType string
+		lead     string
+		err      string
+	}{
+		{name: "typed case", client: officer1, id: "CASE2001", caseType: "homicide", lead: "officer1"},
+		{name: "untyped case", client: admin, id: "CASE2001", lead: "officer2"},
+		{name: "existing ID", client: officer1, id: "CASE1001", lead: "officer1", err: "the case CASE1001 already exists"},
+		{name: "without lead investigator", client: officer1, id: "CASE2001", err: "a lead investigator is required"},
+		{name: "unknown case type", client: officer1, id: "CASE2001", caseType: "piracy", lead: "officer1", err: "no retention policy is defined for piracy cases"},
+		{name: "role not permitted", client: custodian, id: "CASE2001", lead: "officer1", err: "submitting client not authorized to call CreateCase, role custodian is not permitted"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, contract := setupLedger(t)
+
+			err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+				return contract.CreateCase(tx, test.id, "Harbour fraud", test.caseType, "District Court", test.lead, []string{"officer2"})
+			})
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+
+			c := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) (*chaincode.Case, error) {
+				return contract.ReadCase(tx, test.id)
+			})
+			require.Equal(t, "open", c.Status)
+			require.Equal(t, test.lead, c.LeadInvestigator)
+			require.Len(t, c.Timeline, 1)
+			require.Equal(t, test.client.ID, c.Timeline[0].ChangedBy)
+			if test.caseType == "" {
+				require.Equal(t, "general", c.CaseType)
+			}
+
+			cases := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) ([]*chaincode.Case, error) {
+				return contract.GetAllCases(tx)
+			})
+			require.Len(t, cases, 2)
+		})
+	}
+}
+
+func TestCaseExists(t *testing.T) {
+	ledger, contract := setupLedger(t)
+
+	for id, want := range map[string]bool{"CASE1001": true, "CASE404": false} {
+		exists := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) (bool, error) {
+			return contract.CaseExists(tx, id)
+		})
+		require.Equal(t, want, exists, id)
+	}
+
+	err := ledger.Evaluate(officer1, func(tx *mocks.Transaction) error {
+		_, err := contract.ReadCase(tx, "CASE404")
+		return err
+	})
+	require.EqualError(t, err, "the case CASE404 does not exist")
+}
+
+func TestAssignCase(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   *mocks.Client
+		archived bool
+		lead     string
+		err      string
+	}{
+		{name: "by the lead investigator", client: officer1, lead: "officer2"},
+		{name: "by an administrator", client: admin, lead: "officer2"},
+		{name: "by another investigator", client: officer2, lead: "officer2", err: "submitting client is not the lead investigator of case CASE1001"},
+		{name: "without lead investigator", client: officer1, err: "a lead investigator is required"},
+		{name: "archived case", client: admin, archived: true, lead: "officer2", err: "the case CASE1001 is archived"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, contract := setupLedger(t)
+			if test.archived {
+				updateCaseStatus(t, ledger, contract, "CASE1001", "closed")
+				updateCaseStatus(t, ledger, contract, "CASE1001", "archived")
+			}
+
+			err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+				return contract.AssignCase(tx, "CASE1001", test.lead, []string{"officer1"})
+			})
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+
+			c := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) (*chaincode.Case, error) {
+				return contract.ReadCase(tx, "CASE1001")
+			})
+			require.Equal(t, test.lead, c.LeadInvestigator)
+			require.Equal(t, []string{"officer1"}, c.AssignedTeam)
+		})
+	}
+}
+
+func TestUpdateCaseStatus(t *testing.T) {
+	ledger, contract := setupLedger(t)
+
+	err := ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+		return contract.UpdateCaseStatus(tx, "CASE1001", "archived", "Done")
+	})
+	require.EqualError(t, err, "cannot move case CASE1001 from open to archived")
+
+	err = ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+		return contract.UpdateCaseStatus(tx, "CASE1001", "closed", "")
+	})
+	require.EqualError(t, err, "a reason is required to change the status of case CASE1001")
+
+	closedAt := ledger.Now().Format(time.RFC3339)
+	updateCaseStatus(t, ledger, contract, "CASE1001", "closed")
+	c := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) (*chaincode.Case, error) {
+		return contract.ReadCase(tx, "CASE1001")
+	})
+	require.Equal(t, "closed", c.Status)
+	require.Equal(t, closedAt, c.ClosedAt)
+
+	// Closed cases do not accept evidence
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.SubmitEvidence(tx, "EV003", "Late find", "CASE1001", "", "QmLate", nil, "")
+	})
+	require.EqualError(t, err, "the case CASE1001 is closed and does not accept new evidence")
+
+	// Appealed cases do
+	updateCaseStatus(t, ledger, contract, "CASE1001", "appealed")
+	submitEvidence(t, ledger, contract, "EV003")
+
+	updateCaseStatus(t, ledger, contract, "CASE1001", "closed")
+	updateCaseStatus(t, ledger, contract, "CASE1001", "open")
+	c = evaluate(t, ledger, officer1, func(tx *mocks.Transaction) (*chaincode.Case, error) {
+		return contract.ReadCase(tx, "CASE1001")
+	})
+	require.Equal(t, "open", c.Status)
+	require.Empty(t, c.ClosedAt)
+
+	var statuses []string
+	for _, event := range c.Timeline {
+		statuses = append(statuses, event.Status)
+	}
+	require.Equal(t, []string{"open", "closed", "appealed", "closed", "open"}, statuses)
+	require.Equal(t, "prosecutor1", c.Timeline[1].ChangedBy)
+}
+
+func TestGetEvidenceStatsByCaseID(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	moveEvidence(t, ledger, contract, "EV003", "in_transit")
+	requestTransfer(t, ledger, contract)
+	err := ledger.Submit(officer2, func(tx *mocks.Transaction) error {
+		return contract.RequestCustodyTransfer(tx, "EV002", "analyst1", "Comparison", "Lab")
+	})
+	require.NoError(t, err)
+	err = ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		return contract.AcceptCustodyTransfer(tx, "EV002")
+	})
+	require.NoError(t, err)
+
+	ledger.Advance(49 * time.Hour)
+	generatedAt := ledger.Now().Format(time.RFC3339)
+
+	dashboard := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) (*chaincode.CaseDashboard, error) {
+		return contract.GetEvidenceStatsByCaseID(tx, "CASE1001")
+	})
+	require.Equal(t, "CASE1001", dashboard.Case.ID)
+	require.Equal(t, 3, dashboard.TotalEvidence)
+	require.Equal(t, generatedAt, dashboard.GeneratedAt)
+	require.Equal(t, []*chaincode.StatusCount{
+		{Status: "in_transit", Count: 1},
+		{Status: "processing", Count: 1},
+		{Status: "verified", Count: 1},
+	}, dashboard.StatusCounts)
+	require.Equal(t, []*chaincode.CustodyHolding{
+		{Custodian: "analyst1", EvidenceIDs: []string{"EV002"}},
+		{Custodian: "officer1", EvidenceIDs: []string{"EV001", "EV003"}},
+	}, dashboard.CustodyHolders)
+
+	require.Len(t, dashboard.PendingAnalyses, 1)
+	require.Equal(t, "EV002", dashboard.PendingAnalyses[0].EvidenceID)
+
+	require.Len(t, dashboard.OverdueItems, 2)
+	require.Equal(t, "Evidence has been in_transit for longer than 48h0m0s", dashboard.OverdueItems[0].Detail)
+	require.Equal(t, "Custody transfer to custodian1 has not been accepted", dashboard.OverdueItems[1].Detail)
+
+	err = ledger.Evaluate(officer1, func(tx *mocks.Transaction) error {
+		_, err := contract.GetEvidenceStatsByCaseID(tx, "CASE404")
+		return err
+	})
+	require.EqualError(t, err, "the case CASE404 does not exist")
+}
@@ -0,0 +1,338 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// CustodyTransfer describes a hand-off that has been requested by the current
+// custodian but not yet accepted by the receiving party
+type CustodyTransfer struct {
+	FromCustodian string `json:"FromCustodian"` // ID of the custodian releasing the evidence
+	FromMSPID     string `json:"FromMSPID"`     // MSP of the custodian releasing the evidence
+	ToCustodian   string `json:"ToCustodian"`   // ID of the party that must accept the evidence
+	Reason        string `json:"Reason"`        // Why the evidence is being handed over
+	Location      string `json:"Location"`      // Where the hand-off takes place
+	RequestedAt   string `json:"RequestedAt"`   // When the transfer was requested
+	RequestTxID   string `json:"RequestTxID"`   // Transaction in which the releasing custodian signed the request
+}
+
+// CustodyEntry records a completed hand-off in the chain of custody. The
+// request and the acceptance are separate transactions signed by the releasing
+// and the receiving party, so the two transaction IDs tie each entry to both
+// signatures on the ledger.
+type CustodyEntry struct {
+	EvidenceID    string `json:"EvidenceID"`    // ID of the evidence that changed hands
+	Sequence      int    `json:"Sequence"`      // Position of this entry in the chain, starting at 0
+	FromCustodian string `json:"FromCustodian"` // ID of the releasing custodian (empty when custody is first established)
+	FromMSPID     string `json:"FromMSPID"`     // MSP of the releasing custodian
+	ToCustodian   string `json:"ToCustodian"`   // ID of the receiving custodian
+	ToMSPID       string `json:"ToMSPID"`       // MSP of the receiving custodian
+	Reason        string `json:"Reason"`        // Why the evidence was handed over
+	Location      string `json:"Location"`      // Where the hand-off took place
+	RequestedAt   string `json:"RequestedAt"`   // When the hand-off was requested
+	AcceptedAt    string `json:"AcceptedAt"`    // When the receiving custodian accepted the evidence
+	RequestTxID   string `json:"RequestTxID"`   // Transaction signed by the releasing custodian
+	AcceptTxID    string `json:"AcceptTxID"`    // Transaction signed by the receiving custodian
+}
+
+// CustodyGap describes a break detected while walking the chain of custody
+type CustodyGap struct {
+	Sequence    int    `json:"Sequence"`    // Sequence number at which the break was detected
+	Description string `json:"Description"` // Human readable explanation of the break
+}
+
+// CustodyChain is the ordered chain of custody for an evidence item
+type CustodyChain struct {
+	EvidenceID       string          `json:"EvidenceID"`       // ID of the evidence
+	CurrentCustodian string          `json:"CurrentCustodian"` // ID of the party currently holding the evidence
+	Entries          []*CustodyEntry `json:"Entries"`          // Custody entries ordered by sequence number
+	Gaps             []*CustodyGap   `json:"Gaps"`             // Breaks found in the chain, empty when the chain is unbroken
+	Unbroken         bool            `json:"Unbroken"`         // Whether the chain accounts for every hand-off
+}
+
+// RequestCustodyTransfer starts a hand-off of the evidence to another party.
+// Only the current custodian may request a transfer, and custody does not
+// change until the receiving party accepts it.
+func (s *SmartContract) RequestCustodyTransfer(
+	ctx contractapi.TransactionContextInterface,
+	id string,
+	toCustodian string,
+	reason string,
+	location string,
+) error {
+	caller, err := authorize(ctx, "RequestCustodyTransfer")
+	if err != nil {
+		return err
+	}
+
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if caller.ID != currentCustodian(evidence) {
+		return fmt.Errorf("submitting client is not the current custodian of evidence %s", id)
+	}
+	if evidence.PendingTransfer != nil {
+		return fmt.Errorf("evidence %s already has a pending custody transfer to %s", id, evidence.PendingTransfer.ToCustodian)
+	}
+	if toCustodian == "" || toCustodian == caller.ID {
+		return fmt.Errorf("custody of evidence %s must be transferred to another party", id)
+	}
+	if reason == "" {
+		return fmt.Errorf("a reason is required to transfer custody of evidence %s", id)
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	previousIntegrity := evidence.Integrity
+	evidence.PendingTransfer = &CustodyTransfer{
+		FromCustodian: caller.ID,
+		FromMSPID:     caller.MSPID,
+		ToCustodian:   toCustodian,
+		Reason:        reason,
+		Location:      location,
+		RequestedAt:   currentTime,
+		RequestTxID:   ctx.GetStub().GetTxID(),
+	}
+
+	err = putEvidence(ctx, evidence)
+	if err != nil {
+		return err
+	}
+
+	return recordHistory(ctx, evidence, previousIntegrity, caller.ID, "custody_request",
+		fmt.Sprintf("Custody transfer to '%s' requested: %s", toCustodian, reason), "")
+}
+
+// AcceptCustodyTransfer completes a pending hand-off. Only the party named in
+// the request may accept it; on acceptance a custody entry is appended to the
+// chain and the accepting party becomes the current custodian.
+func (s *SmartContract) AcceptCustodyTransfer(ctx contractapi.TransactionContextInterface, id string) error {
+	caller, err := authorize(ctx, "AcceptCustodyTransfer")
+	if err != nil {
+		return err
+	}
+
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	transfer := evidence.PendingTransfer
+	if transfer == nil {
+		return fmt.Errorf("evidence %s has no pending custody transfer", id)
+	}
+
+	if caller.ID != transfer.ToCustodian {
+		return fmt.Errorf("submitting client is not the recipient of the pending custody transfer of evidence %s", id)
+	}
+
+	entries, err := getCustodyEntries(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	entry := &CustodyEntry{
+		EvidenceID:    id,
+		Sequence:      len(entries),
+		FromCustodian: transfer.FromCustodian,
+		FromMSPID:     transfer.FromMSPID,
+		ToCustodian:   caller.ID,
+		ToMSPID:       caller.MSPID,
+		Reason:        transfer.Reason,
+		Location:      transfer.Location,
+		RequestedAt:   transfer.RequestedAt,
+		AcceptedAt:    currentTime,
+		RequestTxID:   transfer.RequestTxID,
+		AcceptTxID:    ctx.GetStub().GetTxID(),
+	}
+	err = putCustodyEntry(ctx, entry)
+	if err != nil {
+		return err
+	}
+
+	previousIntegrity := evidence.Integrity
+	evidence.CurrentCustodian = caller.ID
+	evidence.PendingTransfer = nil
+	err = putEvidence(ctx, evidence)
+	if err != nil {
+		return err
+	}
+
+	err = recordHistory(ctx, evidence, previousIntegrity, caller.ID, "custody_transfer",
+		fmt.Sprintf("Custody transferred from '%s' to '%s'", transfer.FromCustodian, caller.ID), "")
+	if err != nil {
+		return err
+	}
+
+	return emitEvidenceEvent(ctx, eventCustodyTransferred, id, caller.ID, entry)
+}
+
+// RejectCustodyTransfer cancels a pending hand-off. The receiving party may
+// refuse the evidence, or the releasing custodian may withdraw the request.
+// Custody stays with the current custodian.
+func (s *SmartContract) RejectCustodyTransfer(ctx contractapi.TransactionContextInterface, id string, reason string) error {
+	caller, err := authorize(ctx, "RejectCustodyTransfer")
+	if err != nil {
+		return err
+	}
+
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	transfer := evidence.PendingTransfer
+	if transfer == nil {
+		return fmt.Errorf("evidence %s has no pending custody transfer", id)
+	}
+
+	if caller.ID != transfer.ToCustodian && caller.ID != transfer.FromCustodian {
+		return fmt.Errorf("submitting client is not a party to the pending custody transfer of evidence %s", id)
+	}
+
+	previousIntegrity := evidence.Integrity
+	evidence.PendingTransfer = nil
+	err = putEvidence(ctx, evidence)
+	if err != nil {
+		return err
+	}
+
+	return recordHistory(ctx, evidence, previousIntegrity, caller.ID, "custody_reject",
+		fmt.Sprintf("Custody transfer to '%s' rejected: %s", transfer.ToCustodian, reason), "")
+}
+
+// GetCustodyChain returns the ordered chain of custody for an evidence item and
+// flags any gaps, such as missing entries or a hand-off from a party that did
+// not hold the evidence at the time.
+func (s *SmartContract) GetCustodyChain(ctx contractapi.TransactionContextInterface, id string) (*CustodyChain, error) {
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := getCustodyEntries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := &CustodyChain{
+		EvidenceID:       id,
+		CurrentCustodian: currentCustodian(evidence),
+		Entries:          entries,
+		Gaps:             findCustodyGaps(entries, currentCustodian(evidence)),
+	}
+	chain.Unbroken = len(chain.Gaps) == 0
+
+	return chain, nil
+}
+
+// findCustodyGaps walks the custody entries in sequence order and reports every
+// point at which the chain does not account for who held the evidence
+func findCustodyGaps(entries []*CustodyEntry, custodian string) []*CustodyGap {
+	gaps := []*CustodyGap{}
+	if len(entries) == 0 {
+		return append(gaps, &CustodyGap{
+			Sequence:    0,
+			Description: "no custody entries are recorded for this evidence",
+		})
+	}
+
+	if entries[0].FromCustodian != "" {
+		gaps = append(gaps, &CustodyGap{
+			Sequence:    entries[0].Sequence,
+			Description: fmt.Sprintf("chain starts with a hand-off from '%s' instead of the initial custodian", entries[0].FromCustodian),
+		})
+	}
+
+	for i, entry := range entries {
+		if entry.Sequence != i {
+			gaps = append(gaps, &CustodyGap{
+				Sequence:    entry.Sequence,
+				Description: fmt.Sprintf("expected custody entry %d but found entry %d", i, entry.Sequence),
+			})
+		}
+		if i > 0 && entry.FromCustodian != entries[i-1].ToCustodian {
+			gaps = append(gaps, &CustodyGap{
+				Sequence: entry.Sequence,
+				Description: fmt.Sprintf("evidence was released by '%s' but was last received by '%s'",
+					entry.FromCustodian, entries[i-1].ToCustodian),
+			})
+		}
+	}
+
+	last := entries[len(entries)-1]
+	if last.ToCustodian != custodian {
+		gaps = append(gaps, &CustodyGap{
+			Sequence:    last.Sequence,
+			Description: fmt.Sprintf("current custodian '%s' does not match the last recipient '%s'", custodian, last.ToCustodian),
+		})
+	}
+
+	return gaps
+}
+
+// currentCustodian returns the party holding the evidence. Records written
+// before custody was tracked are held by their submitter.
+func currentCustodian(evidence *Evidence) string {
+	if evidence.CurrentCustodian == "" {
+		return evidence.SubmittedBy
+	}
+	return evidence.CurrentCustodian
+}
+
+// currentCustodianMSPID returns the MSP of the party holding the evidence, as
+// recorded by the last entry in its chain of custody
+func currentCustodianMSPID(ctx contractapi.TransactionContextInterface, evidence *Evidence) (string, error) {
+	entries, err := getCustodyEntries(ctx, evidence.ID)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "", fmt.Errorf("evidence %s has no chain of custody", evidence.ID)
+	}
+
+	return entries[len(entries)-1].ToMSPID, nil
+}
+
+// putInitialCustodyEntry records the first entry in the chain of custody, in
+// which the evidence's current custodian takes custody of it
+func putInitialCustodyEntry(ctx contractapi.TransactionContextInterface, evidence *Evidence, mspID string, reason string) error {
+	txID := ctx.GetStub().GetTxID()
+	return putCustodyEntry(ctx, &CustodyEntry{
+		EvidenceID:  evidence.ID,
+		Sequence:    0,
+		ToCustodian: evidence.CurrentCustodian,
+		ToMSPID:     mspID,
+		Reason:      reason,
+		RequestedAt: evidence.SubmittedTime,
+		AcceptedAt:  evidence.SubmittedTime,
+		RequestTxID: txID,
+		AcceptTxID:  txID,
+	})
+}
+
+// putCustodyEntry stores a custody entry
+func putCustodyEntry(ctx contractapi.TransactionContextInterface, entry *CustodyEntry) error {
+	key, err := custodyKey(ctx, entry.EvidenceID, entry.Sequence)
+	if err != nil {
+		return err
+	}
+
+	err = putRecord(ctx, key, entry)
+	if err != nil {
+		return fmt.Errorf("failed to record custody entry: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// compositeKey returns the composite key the shim builds from an object type
+// and attributes
+func compositeKey(objectType string, attributes ...string) string {
+	return "\x00" + objectType + "\x00" + strings.Join(attributes, "\x00") + "\x00"
+}
+
+// requestTransfer requests a transfer of EV003 from officer1 to custodian1
+func requestTransfer(t *testing.T, ledger *mocks.Ledger, contract *chaincode.SmartContract) {
+	t.Helper()
+	err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.RequestCustodyTransfer(tx, "EV003", "custodian1", "Lodge in evidence store", "Central station")
+	})
+	require.NoError(t, err)
+}
+
+func TestRequestCustodyTransfer(t *testing.T) {
+	tests := []struct {
+		name      string
+		client    *mocks.Client
+		recipient string
+		reason    string
+		err       string
+	}{
+		{name: "by the custodian", client: officer1, recipient: "custodian1", reason: "Lodge in evidence store"},
+		{name: "by another party", client: officer2, recipient: "custodian1", reason: "Lodge in evidence store", err: "submitting client is not the current custodian of evidence EV003"},
+		{name: "to the custodian", client: officer1, recipient: "officer1", reason: "Lodge in evidence store", err: "custody of evidence EV003 must be transferred to another party"},
+		{name: "to nobody", client: officer1, reason: "Lodge in evidence store", err: "custody of evidence EV003 must be transferred to another party"},
+		{name: "without reason", client: officer1, recipient: "custodian1", err: "a reason is required to transfer custody of evidence EV003"},
+		{name: "role not permitted", client: auditor, recipient: "custodian1", reason: "Audit", err: "submitting client not authorized to call RequestCustodyTransfer, role auditor is not permitted"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, contract := setupLedger(t)
+			submitEvidence(t, ledger, contract, "EV003")
+			requestedAt := ledger.Now().Format("2006-01-02T15:04:05Z")
+
+			err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+				return contract.RequestCustodyTransfer(tx, "EV003", test.recipient, test.reason, "Central station")
+			})
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				require.Nil(t, readEvidence(t, ledger, contract, "EV003").PendingTransfer)
+				return
+			}
+			require.NoError(t, err)
+
+			evidence := readEvidence(t, ledger, contract, "EV003")
+			require.Equal(t, "officer1", evidence.CurrentCustodian)
+			require.NotNil(t, evidence.PendingTransfer)
+			require.Equal(t, "custodian1", evidence.PendingTransfer.ToCustodian)
+			require.Equal(t, "Org1MSP", evidence.PendingTransfer.FromMSPID)
+			require.Equal(t, requestedAt, evidence.PendingTransfer.RequestedAt)
+
+			// Only one transfer may be pending at a time
+			err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+				return contract.RequestCustodyTransfer(tx, "EV003", "analyst1", "Analysis", "Lab")
+			})
+			require.EqualError(t, err, "evidence EV003 already has a pending custody transfer to custodian1")
+		})
+	}
+}
+
+func TestAcceptCustodyTransfer(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+
+	err := ledger.Submit(custodian, func(tx *mocks.Transaction) error {
+		return contract.AcceptCustodyTransfer(tx, "EV003")
+	})
+	require.EqualError(t, err, "evidence EV003 has no pending custody transfer")
+
+	requestTransfer(t, ledger, contract)
+	requestTxID := readEvidence(t, ledger, contract, "EV003").PendingTransfer.RequestTxID
+
+	err = ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		return contract.AcceptCustodyTransfer(tx, "EV003")
+	})
+	require.EqualError(t, err, "submitting client is not the recipient of the pending custody transfer of evidence EV003")
+
+	err = ledger.Submit(custodian, func(tx *mocks.Transaction) error {
+		return contract.AcceptCustodyTransfer(tx, "EV003")
+	})
+	require.NoError(t, err)
+
+	evidence := readEvidence(t, ledger, contract, "EV003")
+	require.Equal(t, "custodian1", evidence.CurrentCustodian)
+	require.Nil(t, evidence.PendingTransfer)
+
+	event := lastEvent(t, ledger)
+	require.Equal(t, "CustodyTransferred", event.Type)
+	var entry chaincode.CustodyEntry
+	require.NoError(t, json.Unmarshal(event.Payload, &entry))
+	require.Equal(t, 1, entry.Sequence)
+	require.Equal(t, "officer1", entry.FromCustodian)
+	require.Equal(t, "custodian1", entry.ToCustodian)
+	require.Equal(t, requestTxID, entry.RequestTxID)
+	require.NotEqual(t, entry.RequestTxID, entry.AcceptTxID)
+
+	chain := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.CustodyChain, error) {
+		return contract.GetCustodyChain(tx, "EV003")
+	})
+	require.True(t, chain.Unbroken)
+	require.Empty(t, chain.Gaps)
+	require.Len(t, chain.Entries, 2)
+	require.Equal(t, "custodian1", chain.CurrentCustodian)
+
+	// The new custodian can hand the evidence on
+	err = ledger.Submit(custodian, func(tx *mocks.Transaction) error {
+		return contract.RequestCustodyTransfer(tx, "EV003", "analyst1", "Analysis", "Lab")
+	})
+	require.NoError(t, err)
+}
+
+func TestRejectCustodyTransfer(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *mocks.Client
+		err    string
+	}{
+		{name: "by the recipient", client: custodian},
+		{name: "withdrawn by the custodian", client: officer1},
+		{name: "by another party", client: analyst, err: "submitting client is not a party to the pending custody transfer of evidence EV003"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, contract := setupLedger(t)
+			submitEvidence(t, ledger, contract, "EV003")
+			requestTransfer(t, ledger, contract)
+
+			err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+				return contract.RejectCustodyTransfer(tx, "EV003", "Store is full")
+			})
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+
+			evidence := readEvidence(t, ledger, contract, "EV003")
+			require.Nil(t, evidence.PendingTransfer)
+			require.Equal(t, "officer1", evidence.CurrentCustodian)
+
+			err = ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+				return contract.RejectCustodyTransfer(tx, "EV003", "Store is full")
+			})
+			require.EqualError(t, err, "evidence EV003 has no pending custody transfer")
+		})
+	}
+}
+
+func TestGetCustodyChainReportsGaps(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	requestTransfer(t, ledger, contract)
+	err := ledger.Submit(custodian, func(tx *mocks.Transaction) error {
+		return contract.AcceptCustodyTransfer(tx, "EV003")
+	})
+	require.NoError(t, err)
+
+	// Remove the entry of the hand-off from the ledger
+	ledger.SetState(compositeKey("custody", "EV003", "0000000001"), nil)
+
+	chain := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.CustodyChain, error) {
+		return contract.GetCustodyChain(tx, "EV003")
+	})
+	require.False(t, chain.Unbroken)
+	require.Len(t, chain.Gaps, 1)
+	require.Equal(t, "current custodian 'custodian1' does not match the last recipient 'officer1'", chain.Gaps[0].Description)
+
+	err = ledger.Evaluate(auditor, func(tx *mocks.Transaction) error {
+		_, err := contract.GetCustodyChain(tx, "EV404")
+		return err
+	})
+	require.EqualError(t, err, "the evidence EV404 does not exist")
+}
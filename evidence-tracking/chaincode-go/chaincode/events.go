@@ -0,0 +1,90 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// eventVersion is the version of the EvidenceEvent envelope. It is raised
+// whenever a field is removed or changes meaning, so that listeners can tell
+// which payloads they understand.
+const eventVersion = 1
+
+// Names of the chaincode events emitted for changes to evidence
+const (
+	eventEvidenceSubmitted        = "EvidenceSubmitted"
+	eventEvidenceUpdated          = "EvidenceUpdated"
+	eventStatusChanged            = "StatusChanged"
+	eventCustodyTransferred       = "CustodyTransferred"
+	eventIntegrityVerified        = "IntegrityVerified"
+	eventZKProofVerified          = "ZKProofVerified"
+	eventAIAnalysisRecorded       = "AIAnalysisRecorded"
+	eventEvidenceDisposed         = "EvidenceDisposed"
+	eventVerificationApproved     = "VerificationApproved"
+	eventIntegrityFailureReported = "IntegrityFailureReported"
+)
+
+// EvidenceEvent is the payload of every chaincode event emitted by the
+// evidence contract. The chaincode event name is the same as Type.
+type EvidenceEvent struct {
+	Version    int             `json:"Version"`    // Version of this envelope
+	Type       string          `json:"Type"`       // Kind of change, which determines the Payload
+	EvidenceID string          `json:"EvidenceID"` // ID of the evidence that changed
+	Actor      string          `json:"Actor"`      // ID of the client that made the change
+	Timestamp  string          `json:"Timestamp"`  // Transaction timestamp of the change
+	TxID       string          `json:"TxID"`       // Transaction that made the change
+	Payload    json.RawMessage `json:"Payload"`    // Details of the change
+}
+
+// StatusChange is the payload of a StatusChanged event
+type StatusChange struct {
+	From       string `json:"From"`       // Status before the change
+	To         string `json:"To"`         // Status after the change
+	ReasonCode string `json:"ReasonCode"` // Reason given for the change
+}
+
+// IntegrityCheck is the payload of an IntegrityVerified event
+type IntegrityCheck struct {
+	Valid                 bool     `json:"Valid"`                 // Whether the record matched its integrity hash
+	Integrity             string   `json:"Integrity"`             // Integrity hash stored on the record
+	IntegrityVersion      int      `json:"IntegrityVersion"`      // Version of the algorithm that computed the hash
+	FailedAncestors       []string `json:"FailedAncestors"`       // Evidence this item was derived from or is part of that failed its own check
+	UnverifiableAncestors []string `json:"UnverifiableAncestors"` // Legacy evidence this item was derived from or is part of that is not sealed yet
+}
+
+// emitEvidenceEvent sets the chaincode event of the transaction. Fabric keeps
+// only the last event set by a transaction, so each transaction must call this
+// at most once, after all its other writes.
+func emitEvidenceEvent(ctx contractapi.TransactionContextInterface, eventType string, evidenceID string, actor string, payload interface{}) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	eventJSON, err := json.Marshal(&EvidenceEvent{
+		Version:    eventVersion,
+		Type:       eventType,
+		EvidenceID: evidenceID,
+		Actor:      actor,
+		Timestamp:  currentTime,
+		TxID:       ctx.GetStub().GetTxID(),
+		Payload:    payloadJSON,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().SetEvent(eventType, eventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set %s event: %v", eventType, err)
+	}
+
+	return nil
+}
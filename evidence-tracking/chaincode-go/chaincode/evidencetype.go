@@ -0,0 +1,356 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/schema"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Evidence types with a default metadata schema. Evidence submitted without a
+// type is general evidence.
+const (
+	evidenceTypeGeneral       = "general"
+	evidenceTypeVideo         = "video"
+	evidenceTypeAudio         = "audio"
+	evidenceTypeFingerprint   = "fingerprint"
+	evidenceTypeDNA           = "dna"
+	evidenceTypeDigitalDevice = "digital_device"
+	evidenceTypeDocument      = "document"
+)
+
+// EvidenceType is one version of the definition of a kind of evidence. The
+// metadata of evidence of the type must match its JSON Schema, in the subset
+// supported by the schema package. Registering a type again adds a version;
+// records keep the version their metadata was validated against.
+type EvidenceType struct {
+	Name         string `json:"Name"`         // Name of the type, declared by evidence records
+	Version      int    `json:"Version"`      // Version of the definition, starting at 1
+	Description  string `json:"Description"`  // What the type covers
+	Schema       string `json:"Schema"`       // JSON Schema the metadata must match
+	RegisteredBy string `json:"RegisteredBy"` // ID of the client that registered this version
+	RegisteredAt string `json:"RegisteredAt"` // When this version was registered
+}
+
+// defaultEvidenceTypes are written to the ledger by InitLedger, and are the
+// first version of any type with no version stored on the ledger
+var defaultEvidenceTypes = []*EvidenceType{
+	{
+		Name:        evidenceTypeGeneral,
+		Version:     1,
+		Description: "Evidence of no more specific type",
+		Schema:      `{"type": "object"}`,
+	},
+	{
+		Name:        evidenceTypeVideo,
+		Version:     1,
+		Description: "Video recordings, such as surveillance or body camera footage",
+		Schema: `{
+			"type": "object",
+			"required": ["format"],
+			"properties": {
+				"format": {"type": "string", "minLength": 1},
+				"duration": {"type": "string", "pattern": "^[0-9]{2,}:[0-5][0-9]:[0-5][0-9]$"},
+				"location": {"type": "string"},
+				"resolution": {"type": "string"},
+				"fps": {"type": "number", "minimum": 0}
+			}
+		}`,
+	},
+	{
+		Name:        evidenceTypeAudio,
+		Version:     1,
+		Description: "Audio recordings, such as calls and interviews",
+		Schema: `{
+			"type": "object",
+			"required": ["format"],
+			"properties": {
+				"format": {"type": "string", "minLength": 1},
+				"duration": {"type": "string", "pattern": "^[0-9]{2,}:[0-5][0-9]:[0-5][0-9]$"},
+				"location": {"type": "string"},
+				"speakers": {"type": "array", "items": {"type": "string"}}
+			}
+		}`,
+	},
+	{
+		Name:        evidenceTypeFingerprint,
+		Version:     1,
+		Description: "Fingerprints lifted or recorded at a scene",
+		Schema: `{
+			"type": "object",
+			"required": ["type"],
+			"properties": {
+				"type": {"type": "string", "enum": ["latent", "patent", "plastic"]},
+				"surface": {"type": "string"},
+				"quality": {"type": "string", "enum": ["low", "medium", "high"]}
+			}
+		}`,
+	},
+	{
+		Name:        evidenceTypeDNA,
+		Version:     1,
+		Description: "Biological samples and DNA profiles",
+		Schema: `{
+			"type": "object",
+			"required": ["sampleType"],
+			"properties": {
+				"sampleType": {"type": "string", "minLength": 1},
+				"collectionMethod": {"type": "string"},
+				"labReference": {"type": "string"},
+				"sealNumber": {"type": "string"}
+			}
+		}`,
+	},
+	{
+		Name:        evidenceTypeDigitalDevice,
+		Version:     1,
+		Description: "Phones, computers, storage media and their extractions",
+		Schema: `{
+			"type": "object",
+			"required": ["deviceType"],
+			"properties": {
+				"deviceType": {"type": "string", "minLength": 1},
+				"make": {"type": "string"},
+				"model": {"type": "string"},
+				"serialNumber": {"type": "string"},
+				"imei": {"type": "string", "pattern": "^[0-9]{15}$"},
+				"extractionTool": {"type": "string"}
+			}
+		}`,
+	},
+	{
+		Name:        evidenceTypeDocument,
+		Version:     1,
+		Description: "Paper or electronic documents, statements and transcripts",
+		Schema: `{
+			"type": "object",
+			"required": ["documentType"],
+			"properties": {
+				"documentType": {"type": "string", "minLength": 1},
+				"pages": {"type": "integer", "minimum": 1},
+				"language": {"type": "string"}
+			}
+		}`,
+	},
+}
+
+// RegisterEvidenceType registers a new version of an evidence type, or its
+// first version, with the JSON Schema its metadata must match. Evidence
+// already recorded keeps the version it was validated against; new and
+// updated metadata is validated against the latest version.
+func (s *SmartContract) RegisterEvidenceType(
+	ctx contractapi.TransactionContextInterface,
+	name string,
+	description string,
+	schemaJSON string,
+) (*EvidenceType, error) {
+	caller, err := authorize(ctx, "RegisterEvidenceType")
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("an evidence type name is required")
+	}
+	_, err = schema.Parse([]byte(schemaJSON))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema for evidence type %s: %v", name, err)
+	}
+
+	latest, err := getLatestEvidenceType(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	version := 1
+	if latest != nil {
+		version = latest.Version + 1
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	evidenceType := &EvidenceType{
+		Name:         name,
+		Version:      version,
+		Description:  description,
+		Schema:       schemaJSON,
+		RegisteredBy: caller.ID,
+		RegisteredAt: currentTime,
+	}
+	err = putEvidenceType(ctx, evidenceType)
+	if err != nil {
+		return nil, err
+	}
+
+	return evidenceType, nil
+}
+
+// ListEvidenceTypes returns the latest version of every evidence type,
+// whether stored on the ledger or a default
+func (s *SmartContract) ListEvidenceTypes(ctx contractapi.TransactionContextInterface) ([]*EvidenceType, error) {
+	latest := map[string]*EvidenceType{}
+	for _, evidenceType := range defaultEvidenceTypes {
+		latest[evidenceType.Name] = evidenceType
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(evidenceTypeObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var evidenceType EvidenceType
+		err = json.Unmarshal(queryResponse.Value, &evidenceType)
+		if err != nil {
+			return nil, err
+		}
+		if current, ok := latest[evidenceType.Name]; !ok || evidenceType.Version >= current.Version {
+			latest[evidenceType.Name] = &evidenceType
+		}
+	}
+
+	var result []*EvidenceType
+	for _, evidenceType := range latest {
+		result = append(result, evidenceType)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// GetEvidenceType returns a version of an evidence type, or its latest
+// version when version is 0
+func (s *SmartContract) GetEvidenceType(ctx contractapi.TransactionContextInterface, name string, version int) (*EvidenceType, error) {
+	var evidenceType *EvidenceType
+	var err error
+	if version == 0 {
+		evidenceType, err = getLatestEvidenceType(ctx, name)
+	} else {
+		evidenceType, err = getEvidenceType(ctx, name, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if evidenceType == nil {
+		return nil, fmt.Errorf("the evidence type %s version %d does not exist", name, version)
+	}
+
+	return evidenceType, nil
+}
+
+// validateMetadata checks metadata against the latest version of an evidence
+// type and returns that version. Empty metadata is checked as an empty
+// object.
+func validateMetadata(ctx contractapi.TransactionContextInterface, typeName string, metadata string) (int, error) {
+	evidenceType, err := getLatestEvidenceType(ctx, typeName)
+	if err != nil {
+		return 0, err
+	}
+	if evidenceType == nil {
+		return 0, fmt.Errorf("unknown evidence type %s", typeName)
+	}
+
+	s, err := schema.Parse([]byte(evidenceType.Schema))
+	if err != nil {
+		return 0, fmt.Errorf("the schema of evidence type %s version %d is invalid: %v", typeName, evidenceType.Version, err)
+	}
+
+	if metadata == "" {
+		metadata = "{}"
+	}
+	err = s.Validate([]byte(metadata))
+	if err != nil {
+		return 0, fmt.Errorf("metadata does not match evidence type %s version %d: %v", typeName, evidenceType.Version, err)
+	}
+
+	return evidenceType.Version, nil
+}
+
+// evidenceTypeOf returns the type of an evidence record, treating untyped
+// records as general evidence
+func evidenceTypeOf(evidence *Evidence) string {
+	if evidence.EvidenceType == "" {
+		return evidenceTypeGeneral
+	}
+	return evidence.EvidenceType
+}
+
+// getLatestEvidenceType returns the latest version of an evidence type stored
+// on the ledger, falling back to the default type, or nil if there is neither
+func getLatestEvidenceType(ctx contractapi.TransactionContextInterface, name string) (*EvidenceType, error) {
+	var latest *EvidenceType
+	err := forEachRecord(ctx, evidenceTypeObjectType, name, func(value []byte) error {
+		var evidenceType EvidenceType
+		err := json.Unmarshal(value, &evidenceType)
+		if err != nil {
+			return err
+		}
+		latest = &evidenceType
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		return latest, nil
+	}
+
+	for _, evidenceType := range defaultEvidenceTypes {
+		if evidenceType.Name == name {
+			return evidenceType, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// getEvidenceType returns a version of an evidence type stored on the ledger,
+// falling back to the default type for version 1, or nil if there is neither
+func getEvidenceType(ctx contractapi.TransactionContextInterface, name string, version int) (*EvidenceType, error) {
+	key, err := evidenceTypeKey(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	typeJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if typeJSON != nil {
+		var evidenceType EvidenceType
+		err = json.Unmarshal(typeJSON, &evidenceType)
+		if err != nil {
+			return nil, err
+		}
+		return &evidenceType, nil
+	}
+
+	for _, evidenceType := range defaultEvidenceTypes {
+		if evidenceType.Name == name && evidenceType.Version == version {
+			return evidenceType, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// putEvidenceType stores a version of an evidence type
+func putEvidenceType(ctx contractapi.TransactionContextInterface, evidenceType *EvidenceType) error {
+	key, err := evidenceTypeKey(ctx, evidenceType.Name, evidenceType.Version)
+	if err != nil {
+		return err
+	}
+	return putRecord(ctx, key, evidenceType)
+}
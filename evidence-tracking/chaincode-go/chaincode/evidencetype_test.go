@@ -0,0 +1,147 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// videoSchemaV2 makes the location of video evidence required
+const videoSchemaV2 = `{
+	"type": "object",
+	"required": ["format", "location"],
+	"properties": {
+		"format": {"type": "string", "minLength": 1},
+		"location": {"type": "string", "minLength": 1}
+	}
+}`
+
+func TestRegisterEvidenceType(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   *mocks.Client
+		typeName string
+		schema   string
+		err      string
+	}{
+		{name: "without name", client: admin, schema: `{"type": "object"}`, err: "an evidence type name is required"},
+		{name: "invalid schema", client: admin, typeName: "firearm", schema: `{"type": `, err: "invalid schema for evidence type firearm: "},
+		{name: "role not permitted", client: officer1, typeName: "firearm", schema: `{"type": "object"}`, err: "submitting client not authorized to call RegisterEvidenceType, role investigator is not permitted"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, contract := setupLedger(t)
+			err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+				_, err := contract.RegisterEvidenceType(tx, test.typeName, "Firearms and ammunition", test.schema)
+				return err
+			})
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+func TestEvidenceTypeVersions(t *testing.T) {
+	ledger, contract := setupLedger(t)
+
+	types := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) ([]*chaincode.EvidenceType, error) {
+		return contract.ListEvidenceTypes(tx)
+	})
+	var names []string
+	for _, evidenceType := range types {
+		names = append(names, evidenceType.Name)
+		require.Equal(t, 1, evidenceType.Version)
+	}
+	require.Equal(t, []string{"audio", "digital_device", "dna", "document", "fingerprint", "general", "video"}, names)
+
+	registeredAt := ledger.Now().Format("2006-01-02T15:04:05Z")
+	video := submitResult(t, ledger, admin, func(tx *mocks.Transaction) (*chaincode.EvidenceType, error) {
+		return contract.RegisterEvidenceType(tx, "video", "Video recordings with a known location", videoSchemaV2)
+	})
+	require.Equal(t, 2, video.Version)
+	require.Equal(t, "admin1", video.RegisteredBy)
+	require.Equal(t, registeredAt, video.RegisteredAt)
+
+	tests := []struct {
+		version int
+		want    int
+		err     string
+	}{
+		{version: 0, want: 2},
+		{version: 1, want: 1},
+		{version: 2, want: 2},
+		{version: 3, err: "the evidence type video version 3 does not exist"},
+	}
+	for _, test := range tests {
+		err := ledger.Evaluate(officer1, func(tx *mocks.Transaction) error {
+			evidenceType, err := contract.GetEvidenceType(tx, "video", test.version)
+			if err == nil {
+				require.Equal(t, test.want, evidenceType.Version)
+			}
+			return err
+		})
+		if test.err != "" {
+			require.EqualError(t, err, test.err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+
+	types = evaluate(t, ledger, officer1, func(tx *mocks.Transaction) ([]*chaincode.EvidenceType, error) {
+		return contract.ListEvidenceTypes(tx)
+	})
+	require.Len(t, types, 7)
+	require.Equal(t, "video", types[6].Name)
+	require.Equal(t, 2, types[6].Version)
+}
+
+func TestSubmitEvidenceValidatesMetadata(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.SubmitEvidence(tx, "EV004", "Footage", "CASE1001", "video", "QmFootage", []string{"video"}, `{"format": "mp4"}`)
+	})
+	require.NoError(t, err)
+	submitResult(t, ledger, admin, func(tx *mocks.Transaction) (*chaincode.EvidenceType, error) {
+		return contract.RegisterEvidenceType(tx, "video", "Video recordings with a known location", videoSchemaV2)
+	})
+
+	tests := []struct {
+		name         string
+		evidenceType string
+		metadata     string
+		err          string
+	}{
+		{name: "unknown type", evidenceType: "hologram", metadata: `{}`, err: "unknown evidence type hologram"},
+		{name: "missing required property", evidenceType: "video", metadata: `{"format": "MP4"}`, err: "metadata does not match evidence type video version 2: "},
+		{name: "wrong property type", evidenceType: "document", metadata: `{"documentType": "statement", "pages": "two"}`, err: "metadata does not match evidence type document version 1: "},
+	}
+	for _, test := range tests {
+		err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+			return contract.SubmitEvidence(tx, "EV003", "Footage", "CASE1001", test.evidenceType, "QmFootage", nil, test.metadata)
+		})
+		require.ErrorContains(t, err, test.err, test.name)
+	}
+
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.SubmitEvidence(tx, "EV003", "Footage", "CASE1001", "video", "QmFootage", nil, `{"format": "MP4", "location": "Dock 4"}`)
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, readEvidence(t, ledger, contract, "EV003").EvidenceTypeVersion)
+
+	// Evidence recorded under the first version keeps it while its metadata
+	// is unchanged, and must match the latest version once it changes
+	ev004 := readEvidence(t, ledger, contract, "EV004")
+	require.Equal(t, 1, ev004.EvidenceTypeVersion)
+
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.UpdateEvidence(tx, "EV004", "Enhanced footage", ev004.FileHash, ev004.Tags, ev004.Metadata)
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, readEvidence(t, ledger, contract, "EV004").EvidenceTypeVersion)
+
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.UpdateEvidence(tx, "EV004", "Enhanced footage", ev004.FileHash, ev004.Tags, `{"format": "MP4"}`)
+	})
+	require.ErrorContains(t, err, "metadata does not match evidence type video version 2: ")
+}
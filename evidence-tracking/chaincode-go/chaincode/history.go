@@ -0,0 +1,284 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/historychain"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// HistoryChainReport is the outcome of verifying the history chain of an
+// evidence item
+type HistoryChainReport struct {
+	EvidenceID string              `json:"EvidenceID"`      // ID of the evidence
+	Length     int                 `json:"Length"`          // Number of history entries found
+	HeadHash   string              `json:"HeadHash"`        // Hash of the last entry according to the chain head
+	Valid      bool                `json:"Valid"`           // Whether the chain is intact
+	Break      *historychain.Break `json:"Break,omitempty"` // First broken link, if any
+}
+
+// LedgerWrite identifies the transaction that last wrote a history entry to
+// the ledger. The block holding a transaction can be found from its ID with
+// the GetBlockByTxID function of the qscc system chaincode.
+type LedgerWrite struct {
+	Sequence  int    `json:"Sequence"`  // Sequence number of the history entry
+	Key       string `json:"Key"`       // World state key of the history entry
+	TxID      string `json:"TxID"`      // Transaction that last wrote the key
+	Timestamp string `json:"Timestamp"` // Timestamp of that transaction
+}
+
+// HistoryProof is a self-contained export of the history chain of an evidence
+// item. It can be checked with historychain.Verify without access to the
+// ledger, and each entry can be traced to the transaction that wrote it.
+type HistoryProof struct {
+	EvidenceID       string                `json:"EvidenceID"`       // ID of the evidence
+	ChannelID        string                `json:"ChannelID"`        // Channel whose ledger holds the chain
+	Algorithm        string                `json:"Algorithm"`        // How entry hashes are computed
+	Head             *historychain.Head    `json:"Head,omitempty"`   // Head of the chain, absent if the item has no history
+	Entries          []*historychain.Entry `json:"Entries"`          // History entries in sequence order
+	Writes           []*LedgerWrite        `json:"Writes"`           // Transactions that wrote each entry
+	Integrity        string                `json:"Integrity"`        // Current integrity hash of the evidence record
+	IntegrityVersion int                   `json:"IntegrityVersion"` // Version of the algorithm that computed Integrity
+	ExportedAt       string                `json:"ExportedAt"`       // Timestamp of the exporting transaction
+	ExportTxID       string                `json:"ExportTxID"`       // ID of the exporting transaction
+}
+
+// VerifyHistoryChain walks the history chain of an evidence item and reports
+// the first broken link. The last entry must also leave the evidence record
+// with the integrity hash it has now, or had when it was disposed of.
+func (s *SmartContract) VerifyHistoryChain(ctx contractapi.TransactionContextInterface, id string) (*HistoryChainReport, error) {
+	integrity, _, err := finalIntegrity(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, head, err := getHistoryChain(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &HistoryChainReport{
+		EvidenceID: id,
+		Length:     len(entries),
+		Break:      historychain.Verify(entries, head),
+	}
+	if head != nil {
+		report.HeadHash = head.EntryHash
+	}
+
+	if report.Break == nil && head != nil {
+		last := entries[len(entries)-1]
+		if last.Integrity != "" && last.Integrity != integrity {
+			report.Break = &historychain.Break{
+				Sequence: last.Sequence,
+				TxID:     last.TxID,
+				Reason:   "evidence record does not have the integrity hash the last entry left it with",
+			}
+		}
+	}
+	report.Valid = report.Break == nil
+
+	return report, nil
+}
+
+// ExportHistoryProof exports the history chain of an evidence item together
+// with the transactions that wrote each entry, so that an external verifier
+// can check the chain without access to the ledger
+func (s *SmartContract) ExportHistoryProof(ctx contractapi.TransactionContextInterface, id string) (*HistoryProof, error) {
+	integrity, integrityVersion, err := finalIntegrity(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, head, err := getHistoryChain(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	writes := []*LedgerWrite{}
+	for _, entry := range entries {
+		key, err := historyKey(ctx, id, entry.ModifiedAt, entry.TxID)
+		if err != nil {
+			return nil, err
+		}
+		write, err := lastWrite(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		write.Sequence = entry.Sequence
+		writes = append(writes, write)
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HistoryProof{
+		EvidenceID:       id,
+		ChannelID:        ctx.GetStub().GetChannelID(),
+		Algorithm:        historychain.Algorithm,
+		Head:             head,
+		Entries:          entries,
+		Writes:           writes,
+		Integrity:        integrity,
+		IntegrityVersion: integrityVersion,
+		ExportedAt:       currentTime,
+		ExportTxID:       ctx.GetStub().GetTxID(),
+	}, nil
+}
+
+// finalIntegrity returns the integrity hash and version of an evidence record,
+// or of its tombstone if it has been disposed of
+func finalIntegrity(ctx contractapi.TransactionContextInterface, id string) (string, int, error) {
+	evidenceJSON, err := getEvidenceState(ctx, id)
+	if err != nil {
+		return "", 0, err
+	}
+	if evidenceJSON != nil {
+		evidence, err := decodeEvidence(evidenceJSON)
+		if err != nil {
+			return "", 0, err
+		}
+		return evidence.Integrity, evidence.IntegrityVersion, nil
+	}
+
+	tombstone, err := getTombstone(ctx, id)
+	if err != nil {
+		return "", 0, err
+	}
+	if tombstone == nil {
+		return "", 0, fmt.Errorf("the evidence %s does not exist", id)
+	}
+
+	return tombstone.FinalIntegrity, tombstone.IntegrityVersion, nil
+}
+
+// appendHistory links a history record to the end of its evidence item's
+// chain and stores it along with the new chain head. Items whose history
+// predates the chain have their existing entries chained first.
+func appendHistory(ctx contractapi.TransactionContextInterface, record *EvidenceHistory) error {
+	head, err := getHistoryHead(ctx, record.EvidenceID)
+	if err != nil {
+		return err
+	}
+	if head == nil {
+		head, err = chainLegacyHistory(ctx, record.EvidenceID)
+		if err != nil {
+			return err
+		}
+	}
+
+	entry := historychain.Entry(*record)
+	head = historychain.Link(&entry, head)
+	*record = EvidenceHistory(entry)
+
+	key, err := historyKey(ctx, record.EvidenceID, record.ModifiedAt, record.TxID)
+	if err != nil {
+		return err
+	}
+	err = putRecord(ctx, key, record)
+	if err != nil {
+		return err
+	}
+
+	return putHistoryHead(ctx, head)
+}
+
+// chainLegacyHistory links the history records of an evidence item that were
+// written before history was chained, in the order they were recorded, and
+// returns the head of the resulting chain. It returns nil if the item has no
+// history. The chain only protects these records from the time they are
+// chained onwards.
+func chainLegacyHistory(ctx contractapi.TransactionContextInterface, evidenceID string) (*historychain.Head, error) {
+	records, err := getHistoryRecords(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var head *historychain.Head
+	for _, record := range records {
+		entry := historychain.Entry(*record)
+		head = historychain.Link(&entry, head)
+
+		key, err := historyKey(ctx, evidenceID, entry.ModifiedAt, entry.TxID)
+		if err != nil {
+			return nil, err
+		}
+		err = putRecord(ctx, key, EvidenceHistory(entry))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if head != nil {
+		err = putHistoryHead(ctx, head)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return head, nil
+}
+
+// getHistoryChain returns the history entries of an evidence item in the form
+// they are hashed, in sequence order, along with the chain head, which is nil
+// if the item's history has never been chained
+func getHistoryChain(ctx contractapi.TransactionContextInterface, evidenceID string) ([]*historychain.Entry, *historychain.Head, error) {
+	records, err := getHistoryRecords(ctx, evidenceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries := make([]*historychain.Entry, 0, len(records))
+	for _, record := range records {
+		entry := historychain.Entry(*record)
+		entries = append(entries, &entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Sequence < entries[j].Sequence
+	})
+
+	head, err := getHistoryHead(ctx, evidenceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return entries, head, nil
+}
+
+// getHistoryHead returns the head of an evidence item's history chain, or nil
+// if its history has never been chained
+func getHistoryHead(ctx contractapi.TransactionContextInterface, evidenceID string) (*historychain.Head, error) {
+	key, err := historyHeadKey(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	headJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if headJSON == nil {
+		return nil, nil
+	}
+
+	var head historychain.Head
+	err = json.Unmarshal(headJSON, &head)
+	if err != nil {
+		return nil, err
+	}
+
+	return &head, nil
+}
+
+// putHistoryHead stores the head of an evidence item's history chain
+func putHistoryHead(ctx contractapi.TransactionContextInterface, head *historychain.Head) error {
+	key, err := historyHeadKey(ctx, head.EvidenceID)
+	if err != nil {
+		return err
+	}
+	return putRecord(ctx, key, head)
+}
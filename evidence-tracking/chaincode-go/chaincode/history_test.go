@@ -0,0 +1,152 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/historychain"
+	"github.com/stretchr/testify/require"
+)
+
+// historyKeys returns the keys of the history entries of an evidence item, in
+// key order
+func historyKeys(ledger *mocks.Ledger, id string) []string {
+	var keys []string
+	for _, key := range ledger.Keys() {
+		if strings.HasPrefix(key, compositeKey("history", id)) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// tamperHistory changes the description of a stored history entry behind the
+// contract's back
+func tamperHistory(t *testing.T, ledger *mocks.Ledger, key string, description string) {
+	t.Helper()
+
+	var entry chaincode.EvidenceHistory
+	require.NoError(t, json.Unmarshal(ledger.GetState(key), &entry))
+	entry.Description = description
+
+	entryJSON, err := json.Marshal(entry)
+	require.NoError(t, err)
+	ledger.SetState(key, entryJSON)
+}
+
+func TestVerifyHistoryChain(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	moveEvidence(t, ledger, contract, "EV003", "in_transit", "received")
+
+	report := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.HistoryChainReport, error) {
+		return contract.VerifyHistoryChain(tx, "EV003")
+	})
+	require.True(t, report.Valid)
+	require.Nil(t, report.Break)
+	require.Equal(t, 3, report.Length)
+	require.NotEmpty(t, report.HeadHash)
+
+	keys := historyKeys(ledger, "EV003")
+	require.Len(t, keys, 3)
+	tamperHistory(t, ledger, keys[1], "Nothing happened")
+
+	report = evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.HistoryChainReport, error) {
+		return contract.VerifyHistoryChain(tx, "EV003")
+	})
+	require.False(t, report.Valid)
+	require.Equal(t, 1, report.Break.Sequence)
+	require.Equal(t, "entry content does not match its hash", report.Break.Reason)
+}
+
+func TestVerifyHistoryChainDetectsRemovedEntry(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	moveEvidence(t, ledger, contract, "EV003", "in_transit", "received")
+
+	keys := historyKeys(ledger, "EV003")
+	ledger.SetState(keys[len(keys)-1], nil)
+
+	report := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.HistoryChainReport, error) {
+		return contract.VerifyHistoryChain(tx, "EV003")
+	})
+	require.False(t, report.Valid)
+	require.Equal(t, 2, report.Length)
+	require.Equal(t, "entry 2 recorded by the chain head is missing", report.Break.Reason)
+}
+
+func TestVerifyHistoryChainDetectsChangedRecord(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	first := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.HistoryProof, error) {
+		return contract.ExportHistoryProof(tx, "EV003")
+	}).Entries[0]
+
+	evidence := readEvidence(t, ledger, contract, "EV003")
+	err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.UpdateEvidence(tx, "EV003", "Changed", evidence.FileHash, evidence.Tags, evidence.Metadata)
+	})
+	require.NoError(t, err)
+
+	// Remove the entry of the update and rewind the chain head, leaving an
+	// intact chain that no longer accounts for the record
+	keys := historyKeys(ledger, "EV003")
+	ledger.SetState(keys[len(keys)-1], nil)
+	ledger.SetState(compositeKey("historyhead", "EV003"), mustMarshal(t, &historychain.Head{
+		EvidenceID: "EV003",
+		Sequence:   first.Sequence,
+		EntryHash:  first.EntryHash,
+	}))
+
+	report := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.HistoryChainReport, error) {
+		return contract.VerifyHistoryChain(tx, "EV003")
+	})
+	require.False(t, report.Valid)
+	require.Equal(t, 1, report.Length)
+	require.Equal(t, "evidence record does not have the integrity hash the last entry left it with", report.Break.Reason)
+}
+
+func TestExportHistoryProof(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	moveEvidence(t, ledger, contract, "EV003", "in_transit")
+
+	proof := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.HistoryProof, error) {
+		return contract.ExportHistoryProof(tx, "EV003")
+	})
+	require.Equal(t, "EV003", proof.EvidenceID)
+	require.Equal(t, historychain.Algorithm, proof.Algorithm)
+	require.Equal(t, readEvidence(t, ledger, contract, "EV003").Integrity, proof.Integrity)
+	require.Len(t, proof.Entries, 2)
+	require.Len(t, proof.Writes, 2)
+	require.Nil(t, historychain.Verify(proof.Entries, proof.Head))
+
+	// Each entry can be traced to the transaction that wrote it
+	for i, write := range proof.Writes {
+		require.Equal(t, i, write.Sequence)
+		require.Equal(t, proof.Entries[i].TxID, write.TxID)
+		require.Equal(t, proof.Entries[i].ModifiedAt, write.Timestamp)
+	}
+
+	// The exported proof can be checked without the ledger
+	proof.Entries[0].Description = "Nothing happened"
+	require.NotNil(t, historychain.Verify(proof.Entries, proof.Head))
+
+	err := ledger.Evaluate(auditor, func(tx *mocks.Transaction) error {
+		_, err := contract.ExportHistoryProof(tx, "EV404")
+		return err
+	})
+	require.EqualError(t, err, "the evidence EV404 does not exist")
+}
+
+// mustMarshal returns the JSON encoding of a value
+func mustMarshal(t *testing.T, value interface{}) []byte {
+	t.Helper()
+
+	valueJSON, err := json.Marshal(value)
+	require.NoError(t, err)
+	return valueJSON
+}
@@ -0,0 +1,105 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// IntegrityFailure records that a stored copy of an evidence file no longer
+// matches the file hash on the ledger
+type IntegrityFailure struct {
+	EvidenceID   string `json:"EvidenceID"`   // ID of the evidence whose file failed the check
+	FileHash     string `json:"FileHash"`     // File hash of the evidence on the ledger when the failure was reported
+	ObservedHash string `json:"ObservedHash"` // Hash the reporting client computed over its copy of the file
+	Source       string `json:"Source"`       // Where the copy is kept, such as the name of a vault
+	Details      string `json:"Details"`      // Human readable explanation of the failure
+	ReportedBy   string `json:"ReportedBy"`   // ID of the client that reported the failure
+	MSPID        string `json:"MSPID"`        // MSP of the client that reported the failure
+	ReportedAt   string `json:"ReportedAt"`   // When the failure was reported
+	TxID         string `json:"TxID"`         // ID of the transaction that recorded the failure
+}
+
+// ReportIntegrityFailure records that a stored copy of an evidence file,
+// re-hashed off-chain, no longer matches the file hash of the evidence. The
+// report is added to the evidence history and announced with an
+// IntegrityFailureReported event. The evidence record itself is unchanged.
+func (s *SmartContract) ReportIntegrityFailure(
+	ctx contractapi.TransactionContextInterface,
+	id string,
+	observedHash string,
+	source string,
+	details string,
+) error {
+	caller, err := authorize(ctx, "ReportIntegrityFailure")
+	if err != nil {
+		return err
+	}
+
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return err
+	}
+	if observedHash == "" {
+		return fmt.Errorf("the observed hash of evidence %s is required", id)
+	}
+	if observedHash == evidence.FileHash {
+		return fmt.Errorf("the observed hash matches the file hash of evidence %s", id)
+	}
+	if source == "" {
+		return fmt.Errorf("the source of the copy of evidence %s is required", id)
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	txID := ctx.GetStub().GetTxID()
+
+	failure := &IntegrityFailure{
+		EvidenceID:   id,
+		FileHash:     evidence.FileHash,
+		ObservedHash: observedHash,
+		Source:       source,
+		Details:      details,
+		ReportedBy:   caller.ID,
+		MSPID:        caller.MSPID,
+		ReportedAt:   currentTime,
+		TxID:         txID,
+	}
+
+	key, err := integrityFailureKey(ctx, id, currentTime, txID)
+	if err != nil {
+		return err
+	}
+	err = putRecord(ctx, key, failure)
+	if err != nil {
+		return err
+	}
+
+	description := fmt.Sprintf("Integrity failure reported by %s: file hash %s observed instead of %s", source, observedHash, evidence.FileHash)
+	err = recordHistory(ctx, evidence, evidence.Integrity, caller.ID, "integrity_failure", description, "")
+	if err != nil {
+		return err
+	}
+
+	return emitEvidenceEvent(ctx, eventIntegrityFailureReported, id, caller.ID, failure)
+}
+
+// GetIntegrityFailures returns the integrity failures reported for an
+// evidence item, oldest first
+func (s *SmartContract) GetIntegrityFailures(ctx contractapi.TransactionContextInterface, id string) ([]*IntegrityFailure, error) {
+	failures := []*IntegrityFailure{}
+	err := forEachRecord(ctx, integrityFailureObjectType, id, func(value []byte) error {
+		var failure IntegrityFailure
+		err := json.Unmarshal(value, &failure)
+		if err != nil {
+			return err
+		}
+		failures = append(failures, &failure)
+		return nil
+	})
+
+	return failures, err
+}
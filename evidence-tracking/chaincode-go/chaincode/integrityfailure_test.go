@@ -0,0 +1,102 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const driftedHash = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func TestReportIntegrityFailure(t *testing.T) {
+	tests := []struct {
+		name         string
+		client       *mocks.Client
+		id           string
+		observedHash string
+		source       string
+		err          string
+	}{
+		{name: "by custodian", client: custodian, id: "EV001", observedHash: driftedHash, source: "vault-lab1"},
+		{name: "by auditor", client: auditor, id: "EV001", observedHash: driftedHash, source: "vault-lab1"},
+		{name: "matching hash", client: custodian, id: "EV001", observedHash: "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o", source: "vault-lab1", err: "the observed hash matches the file hash of evidence EV001"},
+		{name: "without observed hash", client: custodian, id: "EV001", source: "vault-lab1", err: "the observed hash of evidence EV001 is required"},
+		{name: "without source", client: custodian, id: "EV001", observedHash: driftedHash, err: "the source of the copy of evidence EV001 is required"},
+		{name: "unknown evidence", client: custodian, id: "EV404", observedHash: driftedHash, source: "vault-lab1", err: "the evidence EV404 does not exist"},
+		{name: "role not permitted", client: officer1, id: "EV001", observedHash: driftedHash, source: "vault-lab1", err: "submitting client not authorized to call ReportIntegrityFailure, role investigator is not permitted"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, contract := setupLedger(t)
+			before := readEvidence(t, ledger, contract, "EV001")
+			reportedAt := ledger.Now().Format("2006-01-02T15:04:05Z")
+
+			err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+				return contract.ReportIntegrityFailure(tx, test.id, test.observedHash, test.source, "stored copy changed")
+			})
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+
+			failures := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.IntegrityFailure, error) {
+				return contract.GetIntegrityFailures(tx, test.id)
+			})
+			require.Len(t, failures, 1)
+			failure := failures[0]
+			require.Equal(t, before.FileHash, failure.FileHash)
+			require.Equal(t, test.observedHash, failure.ObservedHash)
+			require.Equal(t, test.source, failure.Source)
+			require.Equal(t, "stored copy changed", failure.Details)
+			require.Equal(t, test.client.ID, failure.ReportedBy)
+			require.Equal(t, test.client.MSPID, failure.MSPID)
+			require.Equal(t, reportedAt, failure.ReportedAt)
+
+			// The record is unchanged, and the report joins its history chain
+			require.Equal(t, before, readEvidence(t, ledger, contract, test.id))
+			history := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.EvidenceHistory, error) {
+				return contract.GetEvidenceHistory(tx, test.id)
+			})
+			last := history[len(history)-1]
+			require.Equal(t, "integrity_failure", last.Action)
+			require.Equal(t, before.Integrity, last.PreviousIntegrity)
+			require.Equal(t, before.Integrity, last.Integrity)
+			report := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.HistoryChainReport, error) {
+				return contract.VerifyHistoryChain(tx, test.id)
+			})
+			require.True(t, report.Valid)
+
+			event := lastEvent(t, ledger)
+			require.Equal(t, "IntegrityFailureReported", event.Type)
+			var payload chaincode.IntegrityFailure
+			require.NoError(t, json.Unmarshal(event.Payload, &payload))
+			require.Equal(t, failure, &payload)
+		})
+	}
+}
+
+func TestGetIntegrityFailures(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	for _, source := range []string{"vault-lab1", "vault-lab2"} {
+		err := ledger.Submit(custodian, func(tx *mocks.Transaction) error {
+			return contract.ReportIntegrityFailure(tx, "EV001", driftedHash, source, "")
+		})
+		require.NoError(t, err)
+	}
+
+	failures := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.IntegrityFailure, error) {
+		return contract.GetIntegrityFailures(tx, "EV001")
+	})
+	require.Len(t, failures, 2)
+	require.Equal(t, "vault-lab1", failures[0].Source)
+	require.Equal(t, "vault-lab2", failures[1].Source)
+
+	failures = evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.IntegrityFailure, error) {
+		return contract.GetIntegrityFailures(tx, "EV002")
+	})
+	require.Empty(t, failures)
+}
@@ -0,0 +1,280 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// evidenceKeyPrefix namespaces the keys of evidence records, so that a range
+// scan over the prefix touches evidence and nothing else. Evidence was once
+// stored under its bare ID; MigrateRecords moves such records.
+const evidenceKeyPrefix = "evidence~"
+
+// evidenceDocType is the DocType of evidence records, used by rich queries to
+// select evidence among the other JSON documents in the state database
+const evidenceDocType = "evidence"
+
+// Composite key object types of the records kept alongside each evidence item.
+// Every sub-record is keyed by evidence ID first, so all records of one type
+// for an evidence item can be read with a single partial composite key query.
+const (
+	historyObjectType     = "history"     // evidenceID, timestamp, txID
+	historyHeadObjectType = "historyhead" // evidenceID
+	zkProofObjectType     = "zkproof"     // evidenceID, timestamp, txID
+	aiResultObjectType    = "airesult"    // evidenceID, timestamp, txID
+	custodyObjectType     = "custody"     // evidenceID, sequence
+	accessObjectType      = "access"      // evidenceID, timestamp, txID
+	caseObjectType        = "case"        // caseID
+
+	analyzerObjectType       = "analyzer"       // analyzerID
+	analyzerResultObjectType = "analyzerresult" // analyzerID, timestamp, txID
+
+	retentionPolicyObjectType = "retentionpolicy" // caseType
+	legalHoldObjectType       = "legalhold"       // caseID, holdID
+	dispositionObjectType     = "disposition"     // evidenceID
+	tombstoneObjectType       = "tombstone"       // evidenceID
+
+	verificationPanelObjectType    = "verificationpanel"    // evidenceID
+	verificationApprovalObjectType = "verificationapproval" // evidenceID, verifierID
+
+	relationObjectType        = "relation"        // fromID, relationType, toID
+	relationReverseObjectType = "relationreverse" // toID, relationType, fromID
+
+	bundleObjectType       = "bundle"       // evidenceID
+	evidenceTypeObjectType = "evidencetype" // name, version
+
+	integrityFailureObjectType = "integrityfailure" // evidenceID, timestamp, txID
+)
+
+// evidenceKey returns the key of an evidence record
+func evidenceKey(id string) string {
+	return evidenceKeyPrefix + id
+}
+
+// evidenceKeyRange returns the start and end keys of a range scan over all
+// evidence records
+func evidenceKeyRange() (string, string) {
+	return evidenceKeyPrefix, evidenceKeyPrefix + string(utf8.MaxRune)
+}
+
+// historyKey returns the key of a history record
+func historyKey(ctx contractapi.TransactionContextInterface, evidenceID string, timestamp string, txID string) (string, error) {
+	return createRecordKey(ctx, historyObjectType, evidenceID, timestamp, txID)
+}
+
+// historyHeadKey returns the key of the head of an evidence item's history chain
+func historyHeadKey(ctx contractapi.TransactionContextInterface, evidenceID string) (string, error) {
+	return createRecordKey(ctx, historyHeadObjectType, evidenceID)
+}
+
+// zkProofKey returns the key of a zero-knowledge proof
+func zkProofKey(ctx contractapi.TransactionContextInterface, evidenceID string, timestamp string, txID string) (string, error) {
+	return createRecordKey(ctx, zkProofObjectType, evidenceID, timestamp, txID)
+}
+
+// aiResultKey returns the key of an AI analysis result
+func aiResultKey(ctx contractapi.TransactionContextInterface, evidenceID string, timestamp string, txID string) (string, error) {
+	return createRecordKey(ctx, aiResultObjectType, evidenceID, timestamp, txID)
+}
+
+// accessRecordKey returns the key of an access log record
+func accessRecordKey(ctx contractapi.TransactionContextInterface, evidenceID string, timestamp string, txID string) (string, error) {
+	return createRecordKey(ctx, accessObjectType, evidenceID, timestamp, txID)
+}
+
+// integrityFailureKey returns the key of an integrity failure report
+func integrityFailureKey(ctx contractapi.TransactionContextInterface, evidenceID string, timestamp string, txID string) (string, error) {
+	return createRecordKey(ctx, integrityFailureObjectType, evidenceID, timestamp, txID)
+}
+
+// custodyKey returns the key of a custody entry. The sequence number is zero
+// padded so that entries iterate in chain order.
+func custodyKey(ctx contractapi.TransactionContextInterface, evidenceID string, sequence int) (string, error) {
+	return createRecordKey(ctx, custodyObjectType, evidenceID, fmt.Sprintf("%010d", sequence))
+}
+
+// caseKey returns the key of a case
+func caseKey(ctx contractapi.TransactionContextInterface, caseID string) (string, error) {
+	return createRecordKey(ctx, caseObjectType, caseID)
+}
+
+// analyzerKey returns the key of a registered analyzer
+func analyzerKey(ctx contractapi.TransactionContextInterface, analyzerID string) (string, error) {
+	return createRecordKey(ctx, analyzerObjectType, analyzerID)
+}
+
+// analyzerResultKey returns the key under which an analysis result is indexed
+// for the analyzer that made it
+func analyzerResultKey(ctx contractapi.TransactionContextInterface, analyzerID string, timestamp string, txID string) (string, error) {
+	return createRecordKey(ctx, analyzerResultObjectType, analyzerID, timestamp, txID)
+}
+
+// retentionPolicyKey returns the key of the retention policy of a case type
+func retentionPolicyKey(ctx contractapi.TransactionContextInterface, caseType string) (string, error) {
+	return createRecordKey(ctx, retentionPolicyObjectType, caseType)
+}
+
+// legalHoldKey returns the key of a legal hold placed on a case
+func legalHoldKey(ctx contractapi.TransactionContextInterface, caseID string, holdID string) (string, error) {
+	return createRecordKey(ctx, legalHoldObjectType, caseID, holdID)
+}
+
+// dispositionKey returns the key of the pending disposition request of an
+// evidence item
+func dispositionKey(ctx contractapi.TransactionContextInterface, evidenceID string) (string, error) {
+	return createRecordKey(ctx, dispositionObjectType, evidenceID)
+}
+
+// tombstoneKey returns the key of the tombstone of a disposed evidence item
+func tombstoneKey(ctx contractapi.TransactionContextInterface, evidenceID string) (string, error) {
+	return createRecordKey(ctx, tombstoneObjectType, evidenceID)
+}
+
+// verificationPanelKey returns the key of the verification panel of an
+// evidence item
+func verificationPanelKey(ctx contractapi.TransactionContextInterface, evidenceID string) (string, error) {
+	return createRecordKey(ctx, verificationPanelObjectType, evidenceID)
+}
+
+// verificationApprovalKey returns the key of a verifier's approval of an
+// evidence item
+func verificationApprovalKey(ctx contractapi.TransactionContextInterface, evidenceID string, verifierID string) (string, error) {
+	return createRecordKey(ctx, verificationApprovalObjectType, evidenceID, verifierID)
+}
+
+// relationKey returns the key of a relationship, under the evidence it starts
+// from
+func relationKey(ctx contractapi.TransactionContextInterface, fromID string, relationType string, toID string) (string, error) {
+	return createRecordKey(ctx, relationObjectType, fromID, relationType, toID)
+}
+
+// relationReverseKey returns the key under which a relationship is indexed
+// for the evidence it leads to
+func relationReverseKey(ctx contractapi.TransactionContextInterface, toID string, relationType string, fromID string) (string, error) {
+	return createRecordKey(ctx, relationReverseObjectType, toID, relationType, fromID)
+}
+
+// bundleKey returns the key of the manifest of a bundle
+func bundleKey(ctx contractapi.TransactionContextInterface, evidenceID string) (string, error) {
+	return createRecordKey(ctx, bundleObjectType, evidenceID)
+}
+
+// evidenceTypeKey returns the key of a version of an evidence type. The
+// version is zero padded so that versions iterate in order.
+func evidenceTypeKey(ctx contractapi.TransactionContextInterface, name string, version int) (string, error) {
+	return createRecordKey(ctx, evidenceTypeObjectType, name, fmt.Sprintf("%06d", version))
+}
+
+// createRecordKey builds a composite key for a sub-record of an evidence item
+func createRecordKey(ctx contractapi.TransactionContextInterface, objectType string, attributes ...string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s key: %v", objectType, err)
+	}
+	return key, nil
+}
+
+// putRecord stores a sub-record of an evidence item under the given key
+func putRecord(ctx contractapi.TransactionContextInterface, key string, record interface{}) error {
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(key, recordJSON)
+}
+
+// getHistoryRecords returns the history records of an evidence item in
+// chronological order
+func getHistoryRecords(ctx contractapi.TransactionContextInterface, evidenceID string) ([]*EvidenceHistory, error) {
+	history := []*EvidenceHistory{}
+	err := forEachRecord(ctx, historyObjectType, evidenceID, func(value []byte) error {
+		var record EvidenceHistory
+		err := json.Unmarshal(value, &record)
+		if err != nil {
+			return err
+		}
+		history = append(history, &record)
+		return nil
+	})
+
+	return history, err
+}
+
+// getZKProofs returns the zero-knowledge proofs of an evidence item in the
+// order they were created
+func getZKProofs(ctx contractapi.TransactionContextInterface, evidenceID string) ([]*ZKProof, error) {
+	proofs := []*ZKProof{}
+	err := forEachRecord(ctx, zkProofObjectType, evidenceID, func(value []byte) error {
+		var proof ZKProof
+		err := json.Unmarshal(value, &proof)
+		if err != nil {
+			return err
+		}
+		proofs = append(proofs, &proof)
+		return nil
+	})
+
+	return proofs, err
+}
+
+// getAIResults returns the AI analysis results of an evidence item in the
+// order they were recorded
+func getAIResults(ctx contractapi.TransactionContextInterface, evidenceID string) ([]*AIAnalysisResult, error) {
+	results := []*AIAnalysisResult{}
+	err := forEachRecord(ctx, aiResultObjectType, evidenceID, func(value []byte) error {
+		var result AIAnalysisResult
+		err := json.Unmarshal(value, &result)
+		if err != nil {
+			return err
+		}
+		results = append(results, &result)
+		return nil
+	})
+
+	return results, err
+}
+
+// getCustodyEntries returns the custody entries of an evidence item in
+// sequence order
+func getCustodyEntries(ctx contractapi.TransactionContextInterface, evidenceID string) ([]*CustodyEntry, error) {
+	entries := []*CustodyEntry{}
+	err := forEachRecord(ctx, custodyObjectType, evidenceID, func(value []byte) error {
+		var entry CustodyEntry
+		err := json.Unmarshal(value, &entry)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, &entry)
+		return nil
+	})
+
+	return entries, err
+}
+
+// forEachRecord calls fn with the value of every record of the given type that
+// belongs to an evidence item, in key order
+func forEachRecord(ctx contractapi.TransactionContextInterface, objectType string, evidenceID string, fn func(value []byte) error) error {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, []string{evidenceID})
+	if err != nil {
+		return err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+
+		err = fn(queryResponse.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// evidenceSchemaVersion is the version of the layout putEvidence stores
+// evidence records in. Every record carries the version it was stored with in
+// SchemaVersion. decodeEvidence upgrades records of older layouts whenever
+// they are read, and MigrateRecords rewrites them.
+//
+// Records stored before layouts were versioned come from one of the evidence
+// contracts this one replaced:
+//
+//   - the first contract, which kept only the ID, description, case, file
+//     hash, submitter and status of evidence, under its bare ID
+//   - its successor, which added the submission time, tags and metadata
+//   - the contract that stored fields under lowercase names, times as
+//     time.Time values, and the IPFS address of the file beside its hash
+//
+// None of them enforced the evidence lifecycle, so their statuses are mapped
+// onto it through legacyStatuses, or to fallbackLegacyStatus.
+const evidenceSchemaVersion = 1
+
+// legacyStatuses maps the statuses of evidence stored before layouts were
+// versioned, upper-cased, onto the evidence lifecycle. The replaced contracts
+// accepted any status; records with a status not listed here get
+// fallbackLegacyStatus.
+var legacyStatuses = map[string]string{
+	"SUBMITTED":          statusSubmitted,
+	"IN_TRANSIT":         statusInTransit,
+	"IN TRANSIT":         statusInTransit,
+	"RECEIVED":           statusReceived,
+	"PROCESSING":         statusProcessing,
+	"ANALYZED":           statusAnalyzed,
+	"VERIFIED":           statusVerified,
+	"PRESENTED_IN_COURT": statusPresentedInCourt,
+	"PRESENTED IN COURT": statusPresentedInCourt,
+	"RELEASED":           statusReleased,
+	"DESTROYED":          statusDestroyed,
+}
+
+// fallbackLegacyStatus is the status of legacy evidence whose status is not
+// part of the evidence lifecycle. Such evidence starts the lifecycle again;
+// MigrateRecords keeps its original status in the PrevState of its history.
+const fallbackLegacyStatus = statusSubmitted
+
+// lowercaseEvidence is the layout of evidence stored by the contract that
+// used lowercase field names
+type lowercaseEvidence struct {
+	ID            string    `json:"id"`
+	CaseID        string    `json:"caseId"`
+	Description   string    `json:"description"`
+	FileHash      string    `json:"fileHash"`
+	IPFSHash      string    `json:"ipfsHash"`
+	SubmittedBy   string    `json:"submittedBy"`
+	SubmittedAt   time.Time `json:"submittedAt"`
+	LastUpdatedBy string    `json:"lastUpdatedBy"`
+	LastUpdatedAt time.Time `json:"lastUpdatedAt"`
+	Status        string    `json:"status"`
+	Metadata      string    `json:"metadata"`
+}
+
+// decodeEvidence decodes a stored evidence record of any layout, upgraded to
+// the current one
+func decodeEvidence(evidenceJSON []byte) (*Evidence, error) {
+	evidence, _, err := decodeEvidenceLayout(evidenceJSON)
+	return evidence, err
+}
+
+// decodeEvidenceLayout decodes a stored evidence record of any layout,
+// upgraded to the current one, and returns the schema version it was stored
+// with. Records stored before layouts were versioned have version 0.
+func decodeEvidenceLayout(evidenceJSON []byte) (*Evidence, int, error) {
+	// The layout is told apart by exact field names; encoding/json would
+	// match the lowercase names to the fields of Evidence
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(evidenceJSON, &fields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if fields["ID"] == nil && fields["id"] != nil {
+		var legacy lowercaseEvidence
+		err = json.Unmarshal(evidenceJSON, &legacy)
+		if err != nil {
+			return nil, 0, err
+		}
+		return upgradeLowercaseEvidence(&legacy), 0, nil
+	}
+
+	var evidence Evidence
+	err = json.Unmarshal(evidenceJSON, &evidence)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if fields["SchemaVersion"] == nil {
+		upgradeUnversionedEvidence(&evidence)
+		return &evidence, 0, nil
+	}
+
+	storedVersion := evidence.SchemaVersion
+	if storedVersion > evidenceSchemaVersion {
+		return nil, 0, fmt.Errorf("evidence %s is stored with schema version %d, newer than the %d this contract supports", evidence.ID, storedVersion, evidenceSchemaVersion)
+	}
+
+	return &evidence, storedVersion, nil
+}
+
+// upgradeLowercaseEvidence converts evidence of the lowercase layout. The IPFS
+// address takes the place of the file hash, as FileHash holds the IPFS address
+// of the file; MigrateRecords keeps the record as it was in its history.
+func upgradeLowercaseEvidence(legacy *lowercaseEvidence) *Evidence {
+	evidence := &Evidence{
+		ID:                legacy.ID,
+		Description:       legacy.Description,
+		CaseID:            legacy.CaseID,
+		FileHash:          legacy.FileHash,
+		SubmittedBy:       legacy.SubmittedBy,
+		SubmittedTime:     formatLegacyTime(legacy.SubmittedAt),
+		Status:            legacy.Status,
+		StatusUpdatedTime: formatLegacyTime(legacy.LastUpdatedAt),
+		Metadata:          legacy.Metadata,
+	}
+	if legacy.IPFSHash != "" {
+		evidence.FileHash = legacy.IPFSHash
+	}
+
+	upgradeUnversionedEvidence(evidence)
+
+	return evidence
+}
+
+// upgradeUnversionedEvidence fills in the fields that evidence stored before
+// layouts were versioned may lack, and maps its status onto the evidence
+// lifecycle. None of these fields are covered by the version 0 integrity hash
+// such records carry, so the hash still verifies.
+func upgradeUnversionedEvidence(evidence *Evidence) {
+	status, ok := legacyStatuses[strings.ToUpper(strings.TrimSpace(evidence.Status))]
+	if !ok {
+		status = fallbackLegacyStatus
+	}
+	evidence.Status = status
+	evidence.SchemaVersion = evidenceSchemaVersion
+
+	if evidence.CurrentCustodian == "" {
+		evidence.CurrentCustodian = evidence.SubmittedBy
+	}
+	if evidence.StatusUpdatedTime == "" {
+		evidence.StatusUpdatedTime = evidence.SubmittedTime
+	}
+}
+
+// formatLegacyTime formats a time of the lowercase layout the way the
+// contract formats transaction timestamps, and the zero time as empty
+func formatLegacyTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
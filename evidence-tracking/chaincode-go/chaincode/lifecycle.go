@@ -0,0 +1,118 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Statuses in the evidence lifecycle
+const (
+	statusSubmitted        = "submitted"
+	statusInTransit        = "in_transit"
+	statusReceived         = "received"
+	statusProcessing       = "processing"
+	statusAnalyzed         = "analyzed"
+	statusVerified         = "verified"
+	statusPresentedInCourt = "presented_in_court"
+	statusReleased         = "released"
+	statusDestroyed        = "destroyed"
+)
+
+// StatusTransition declares a permitted change of evidence status
+type StatusTransition struct {
+	From           string   `json:"From"`           // Status the evidence must currently have
+	To             string   `json:"To"`             // Status the evidence moves to
+	Roles          []string `json:"Roles"`          // Roles that may make the transition
+	RequiresReason bool     `json:"RequiresReason"` // Whether a reason code must be given
+	RequiresQuorum bool     `json:"RequiresQuorum"` // Whether the change is made by ApproveVerification once enough verifiers approve
+}
+
+// statusTransitions is the evidence lifecycle. Evidence normally moves
+// submitted -> in_transit -> received -> processing -> analyzed -> verified ->
+// presented_in_court. Any change not listed here is rejected. Evidence is
+// verified by a quorum of designated verifiers through ApproveVerification,
+// never by UpdateEvidenceStatus. It leaves the lifecycle only when it is
+// disposed of with RequestEvidenceDisposition and DisposeEvidence, which check
+// the retention policy and legal holds and need a court order and two
+// approvals; released and destroyed are kept for legacy records.
+var statusTransitions = []*StatusTransition{
+	{From: statusSubmitted, To: statusInTransit, Roles: []string{roleInvestigator, roleCustodian}},
+	{From: statusInTransit, To: statusReceived, Roles: []string{roleCustodian}},
+	{From: statusReceived, To: statusProcessing, Roles: []string{roleCustodian, roleAnalyst}},
+	{From: statusProcessing, To: statusAnalyzed, Roles: []string{roleAnalyst}},
+	{From: statusAnalyzed, To: statusProcessing, Roles: []string{roleAnalyst, roleInvestigator}, RequiresReason: true},
+	{From: statusAnalyzed, To: statusVerified, Roles: []string{roleInvestigator, roleAnalyst, roleProsecutor}, RequiresQuorum: true},
+	{From: statusVerified, To: statusPresentedInCourt, Roles: []string{roleProsecutor}},
+}
+
+// GetAllowedTransitions returns the status changes the submitting client may
+// make to an evidence item in its current status
+func (s *SmartContract) GetAllowedTransitions(ctx contractapi.TransactionContextInterface, id string) ([]*StatusTransition, error) {
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	role, found, err := ctx.GetClientIdentity().GetAttributeValue(roleAttribute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client role: %v", err)
+	}
+
+	allowed := []*StatusTransition{}
+	if !found {
+		return allowed, nil
+	}
+	for _, transition := range statusTransitions {
+		if transition.From == evidence.Status && containsString(transition.Roles, role) {
+			allowed = append(allowed, transition)
+		}
+	}
+
+	return allowed, nil
+}
+
+// checkStatusTransition returns an error unless the lifecycle permits a client
+// with the given role to move evidence from one status to another
+func checkStatusTransition(from string, to string, role string, reasonCode string) error {
+	for _, transition := range statusTransitions {
+		if transition.From != from || transition.To != to {
+			continue
+		}
+		if !containsString(transition.Roles, role) {
+			return fmt.Errorf("role %s may not move evidence from %s to %s", role, from, to)
+		}
+		if transition.RequiresQuorum {
+			return fmt.Errorf("evidence moves from %s to %s only once its designated verifiers approve it with ApproveVerification", from, to)
+		}
+		if transition.RequiresReason && reasonCode == "" {
+			return fmt.Errorf("a reason code is required to move evidence from %s to %s", from, to)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("transition from %s to %s is not permitted", from, to)
+}
+
+// lockedStatuses are the statuses in which the details of evidence can no
+// longer be changed, because it has been analyzed, verified or presented as it
+// stands. Evidence sent back from analyzed to processing can be changed again;
+// verification approvals given before the change no longer count.
+var lockedStatuses = []string{statusAnalyzed, statusVerified, statusPresentedInCourt, statusReleased, statusDestroyed}
+
+// checkDetailsUpdatable returns an error if the details of evidence can no
+// longer be changed in its current status
+func checkDetailsUpdatable(evidence *Evidence) error {
+	if containsString(lockedStatuses, evidence.Status) {
+		return fmt.Errorf("evidence %s is %s and its details can no longer be updated", evidence.ID, evidence.Status)
+	}
+	return nil
+}
+
+// statusChangeDescription describes a status change for the history log
+func statusChangeDescription(newStatus string, reasonCode string) string {
+	if reasonCode == "" {
+		return fmt.Sprintf("Status updated to '%s'", newStatus)
+	}
+	return fmt.Sprintf("Status updated to '%s' (reason: %s)", newStatus, reasonCode)
+}
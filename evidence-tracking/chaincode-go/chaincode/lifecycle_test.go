@@ -0,0 +1,171 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateEvidenceStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   []string // Statuses EV003 is moved through first
+		client *mocks.Client
+		to     string
+		reason string
+		err    string
+	}{
+		{
+			name:   "dispatch",
+			client: officer1,
+			to:     "in_transit",
+		},
+		{
+			name:   "receipt",
+			path:   []string{"in_transit"},
+			client: custodian,
+			to:     "received",
+		},
+		{
+			name:   "analysis",
+			path:   []string{"in_transit", "received", "processing"},
+			client: analyst,
+			to:     "analyzed",
+		},
+		{
+			name:   "reanalysis with reason",
+			path:   []string{"in_transit", "received", "processing", "analyzed"},
+			client: officer1,
+			to:     "processing",
+			reason: "NEW_LEAD",
+		},
+		{
+			name:   "reanalysis without reason",
+			path:   []string{"in_transit", "received", "processing", "analyzed"},
+			client: analyst,
+			to:     "processing",
+			err:    "cannot update status of evidence EV003: a reason code is required to move evidence from analyzed to processing",
+		},
+		{
+			name:   "verification",
+			path:   []string{"in_transit", "received", "processing", "analyzed"},
+			client: analyst,
+			to:     "verified",
+			err:    "cannot update status of evidence EV003: evidence moves from analyzed to verified only once its designated verifiers approve it with ApproveVerification",
+		},
+		{
+			name:   "skipped status",
+			client: officer1,
+			to:     "received",
+			err:    "cannot update status of evidence EV003: transition from submitted to received is not permitted",
+		},
+		{
+			name:   "role not permitted by the lifecycle",
+			path:   []string{"in_transit"},
+			client: officer1,
+			to:     "received",
+			err:    "cannot update status of evidence EV003: role investigator may not move evidence from in_transit to received",
+		},
+		{
+			name:   "role not permitted by the access policy",
+			client: auditor,
+			to:     "in_transit",
+			err:    "submitting client not authorized to call UpdateEvidenceStatus, role auditor is not permitted",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, contract := setupLedger(t)
+			submitEvidence(t, ledger, contract, "EV003")
+			moveEvidence(t, ledger, contract, "EV003", test.path...)
+			from := readEvidence(t, ledger, contract, "EV003").Status
+			changedAt := ledger.Now().Format("2006-01-02T15:04:05Z")
+
+			err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+				return contract.UpdateEvidenceStatus(tx, "EV003", test.to, test.reason)
+			})
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				require.Equal(t, from, readEvidence(t, ledger, contract, "EV003").Status)
+				return
+			}
+			require.NoError(t, err)
+
+			evidence := readEvidence(t, ledger, contract, "EV003")
+			require.Equal(t, test.to, evidence.Status)
+			require.Equal(t, changedAt, evidence.StatusUpdatedTime)
+
+			event := lastEvent(t, ledger)
+			require.Equal(t, "StatusChanged", event.Type)
+			var change chaincode.StatusChange
+			require.NoError(t, json.Unmarshal(event.Payload, &change))
+			require.Equal(t, chaincode.StatusChange{From: from, To: test.to, ReasonCode: test.reason}, change)
+		})
+	}
+}
+
+func TestUpdateEvidenceStatusCannotDispose(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	moveEvidence(t, ledger, contract, "EV001", "presented_in_court")
+
+	// Evidence presented in court is only disposed of with a court order and
+	// two approvals, never by a status change
+	tests := []struct {
+		client *mocks.Client
+		to     string
+	}{
+		{client: prosecutor, to: "released"},
+		{client: custodian, to: "released"},
+		{client: custodian, to: "destroyed"},
+	}
+	for _, test := range tests {
+		err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+			return contract.UpdateEvidenceStatus(tx, "EV001", test.to, "RETURNED_TO_OWNER")
+		})
+		require.EqualError(t, err, "cannot update status of evidence EV001: transition from presented_in_court to "+test.to+" is not permitted", test.client.ID)
+	}
+	require.Equal(t, "presented_in_court", readEvidence(t, ledger, contract, "EV001").Status)
+
+	transitions := evaluate(t, ledger, custodian, func(tx *mocks.Transaction) ([]*chaincode.StatusTransition, error) {
+		return contract.GetAllowedTransitions(tx, "EV001")
+	})
+	require.Empty(t, transitions)
+}
+
+func TestGetAllowedTransitions(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	moveEvidence(t, ledger, contract, "EV003", "in_transit", "received", "processing", "analyzed")
+
+	tests := []struct {
+		client *mocks.Client
+		want   []string
+	}{
+		{client: analyst, want: []string{"processing", "verified"}},
+		{client: officer1, want: []string{"processing", "verified"}},
+		{client: prosecutor, want: []string{"verified"}},
+		{client: custodian, want: nil},
+		{client: &mocks.Client{ID: "anonymous", MSPID: "Org1MSP"}, want: nil},
+	}
+	for _, test := range tests {
+		transitions := evaluate(t, ledger, test.client, func(tx *mocks.Transaction) ([]*chaincode.StatusTransition, error) {
+			return contract.GetAllowedTransitions(tx, "EV003")
+		})
+
+		var to []string
+		for _, transition := range transitions {
+			require.Equal(t, "analyzed", transition.From)
+			to = append(to, transition.To)
+		}
+		require.Equal(t, test.want, to, test.client.ID)
+	}
+
+	err := ledger.Evaluate(analyst, func(tx *mocks.Transaction) error {
+		_, err := contract.GetAllowedTransitions(tx, "EV404")
+		return err
+	})
+	require.EqualError(t, err, "the evidence EV404 does not exist")
+}
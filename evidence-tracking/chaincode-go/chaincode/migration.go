@@ -0,0 +1,359 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// legacyRecordTypes are the object types whose records were once stored under
+// hand-built prefix~evidenceID~timestamp[~txID] keys
+var legacyRecordTypes = []string{historyObjectType, zkProofObjectType, aiResultObjectType}
+
+// MigrateRecords converts records written by earlier versions of the
+// contract, and by the evidence contracts it replaced, and returns the number
+// of records migrated. It moves history, proof and analysis records stored
+// under legacy hand-built keys into the composite key index, moves evidence
+// stored under its bare ID into the evidence key namespace, chains history
+// written before history was hash-chained, and rewrites evidence stored in an
+// older layout or sealed with an older integrity hash. At most batch records
+// are migrated per call, so large ledgers can be migrated over several
+// transactions; a result of 0 means none are left.
+//
+// Evidence can be read by ID before it is migrated, but evidence under its
+// bare ID is only found by queries once it is. Each call works on one step
+// only, so every step reads the committed results of the steps before it.
+func (s *SmartContract) MigrateRecords(ctx contractapi.TransactionContextInterface, batch int) (int, error) {
+	_, err := authorize(ctx, "MigrateRecords")
+	if err != nil {
+		return 0, err
+	}
+
+	if batch <= 0 {
+		return 0, fmt.Errorf("batch must be a positive number")
+	}
+
+	return migrateRecords(ctx, batch)
+}
+
+// ReindexRecords is the name MigrateRecords had before it converted the
+// layouts of the replaced contracts, kept for the clients that call it
+func (s *SmartContract) ReindexRecords(ctx contractapi.TransactionContextInterface, limit int) (int, error) {
+	_, err := authorize(ctx, "ReindexRecords")
+	if err != nil {
+		return 0, err
+	}
+
+	if limit <= 0 {
+		return 0, fmt.Errorf("limit must be a positive number")
+	}
+
+	return migrateRecords(ctx, limit)
+}
+
+// migrateRecords runs the first migration step that has records left to
+// migrate, on up to limit records
+func migrateRecords(ctx contractapi.TransactionContextInterface, limit int) (int, error) {
+	var steps []func(contractapi.TransactionContextInterface, int) (int, error)
+	for _, objectType := range legacyRecordTypes {
+		objectType := objectType
+		steps = append(steps, func(ctx contractapi.TransactionContextInterface, limit int) (int, error) {
+			return reindexLegacyRecords(ctx, objectType, limit)
+		})
+	}
+	steps = append(steps, reindexLegacyEvidence, chainLegacyHistories, upgradeLegacyEvidence)
+
+	for _, step := range steps {
+		count, err := step(ctx, limit)
+		if err != nil || count > 0 {
+			return count, err
+		}
+	}
+
+	return 0, nil
+}
+
+// reindexLegacyRecords moves up to limit records of the given type from legacy
+// keys to composite keys. Records written before keys carried a transaction ID
+// have it recovered from the key's history.
+func reindexLegacyRecords(ctx contractapi.TransactionContextInterface, objectType string, limit int) (int, error) {
+	startKey := objectType + "~"
+	endKey := startKey + string(utf8.MaxRune)
+
+	resultsIterator, err := ctx.GetStub().GetStateByRange(startKey, endKey)
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	reindexed := 0
+	for resultsIterator.HasNext() && reindexed < limit {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return reindexed, err
+		}
+
+		// Legacy keys are prefix~evidenceID~timestamp, or
+		// prefix~evidenceID~timestamp~txID once transaction IDs were added
+		parts := strings.Split(queryResponse.Key, "~")
+		if len(parts) != 3 && len(parts) != 4 {
+			continue
+		}
+
+		var txID string
+		if len(parts) == 4 {
+			txID = parts[3]
+		} else {
+			write, err := lastWrite(ctx, queryResponse.Key)
+			if err != nil {
+				return reindexed, err
+			}
+			txID = write.TxID
+		}
+
+		key, err := createRecordKey(ctx, objectType, parts[1], parts[2], txID)
+		if err != nil {
+			return reindexed, err
+		}
+
+		// The record must name the transaction its new key does, or history
+		// chaining would rewrite it under a key without one
+		value, err := withTxID(queryResponse.Value, txID)
+		if err != nil {
+			return reindexed, fmt.Errorf("failed to reindex %s: %v", queryResponse.Key, err)
+		}
+
+		err = ctx.GetStub().PutState(key, value)
+		if err != nil {
+			return reindexed, fmt.Errorf("failed to reindex %s: %v", queryResponse.Key, err)
+		}
+		err = ctx.GetStub().DelState(queryResponse.Key)
+		if err != nil {
+			return reindexed, fmt.Errorf("failed to remove %s: %v", queryResponse.Key, err)
+		}
+		reindexed++
+	}
+
+	return reindexed, nil
+}
+
+// withTxID sets the TxID field of a JSON record that has none
+func withTxID(recordJSON []byte, txID string) ([]byte, error) {
+	var record map[string]json.RawMessage
+	err := json.Unmarshal(recordJSON, &record)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing string
+	if raw, ok := record["TxID"]; ok {
+		err = json.Unmarshal(raw, &existing)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if existing != "" {
+		return recordJSON, nil
+	}
+
+	record["TxID"], err = json.Marshal(txID)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(record)
+}
+
+// reindexLegacyEvidence moves up to limit evidence records from their bare ID
+// key to the evidence key namespace, in the current layout. A plain key holds
+// legacy evidence when its value is an evidence record of any layout with the
+// key as its ID. Only the plain keys either side of the evidence key namespace
+// are scanned; once the earlier steps have moved the other legacy records,
+// they hold nothing but legacy evidence.
+func reindexLegacyEvidence(ctx contractapi.TransactionContextInterface, limit int) (int, error) {
+	namespaceStart, namespaceEnd := evidenceKeyRange()
+
+	reindexed := 0
+	for _, keyRange := range [][2]string{{"", namespaceStart}, {namespaceEnd, ""}} {
+		count, err := reindexLegacyEvidenceInRange(ctx, keyRange[0], keyRange[1], limit-reindexed)
+		reindexed += count
+		if err != nil || reindexed >= limit {
+			return reindexed, err
+		}
+	}
+
+	return reindexed, nil
+}
+
+// reindexLegacyEvidenceInRange moves up to limit legacy evidence records
+// stored under bare ID keys in a key range
+func reindexLegacyEvidenceInRange(ctx contractapi.TransactionContextInterface, startKey string, endKey string, limit int) (int, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByRange(startKey, endKey)
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	reindexed := 0
+	for resultsIterator.HasNext() && reindexed < limit {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return reindexed, err
+		}
+		if strings.HasPrefix(queryResponse.Key, evidenceKeyPrefix) {
+			continue
+		}
+
+		// Values that are not evidence are left where they are
+		evidence, err := decodeEvidence(queryResponse.Value)
+		if err != nil || evidence.ID != queryResponse.Key {
+			continue
+		}
+
+		// resealEvidence removes the legacy key once the record is rewritten
+		resealed, err := resealEvidence(ctx, evidence, queryResponse.Value)
+		if err != nil {
+			return reindexed, fmt.Errorf("failed to reindex evidence %s: %v", evidence.ID, err)
+		}
+		if resealed {
+			reindexed++
+		}
+	}
+
+	return reindexed, nil
+}
+
+// chainLegacyHistories chains the history of up to limit evidence items whose
+// history was written before history was hash-chained
+func chainLegacyHistories(ctx contractapi.TransactionContextInterface, limit int) (int, error) {
+	startKey, endKey := evidenceKeyRange()
+	resultsIterator, err := ctx.GetStub().GetStateByRange(startKey, endKey)
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	chained := 0
+	for resultsIterator.HasNext() && chained < limit {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return chained, err
+		}
+
+		evidenceID := strings.TrimPrefix(queryResponse.Key, evidenceKeyPrefix)
+		head, err := getHistoryHead(ctx, evidenceID)
+		if err != nil {
+			return chained, err
+		}
+		if head != nil {
+			continue
+		}
+
+		head, err = chainLegacyHistory(ctx, evidenceID)
+		if err != nil {
+			return chained, fmt.Errorf("failed to chain history of evidence %s: %v", evidenceID, err)
+		}
+		if head != nil {
+			chained++
+		}
+	}
+
+	return chained, nil
+}
+
+// upgradeLegacyEvidence rewrites up to limit evidence records stored in an
+// older layout, or whose integrity hash was computed with an older algorithm
+// version or never computed
+func upgradeLegacyEvidence(ctx contractapi.TransactionContextInterface, limit int) (int, error) {
+	startKey, endKey := evidenceKeyRange()
+	resultsIterator, err := ctx.GetStub().GetStateByRange(startKey, endKey)
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	sealed := 0
+	for resultsIterator.HasNext() && sealed < limit {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return sealed, err
+		}
+
+		evidence, storedVersion, err := decodeEvidenceLayout(queryResponse.Value)
+		if err != nil {
+			return sealed, err
+		}
+		if storedVersion >= evidenceSchemaVersion && evidence.IntegrityVersion >= integrityVersion {
+			continue
+		}
+
+		resealed, err := resealEvidence(ctx, evidence, queryResponse.Value)
+		if err != nil {
+			return sealed, fmt.Errorf("failed to seal evidence %s: %v", evidence.ID, err)
+		}
+		if resealed {
+			sealed++
+		}
+	}
+
+	return sealed, nil
+}
+
+// resealEvidence rewrites a legacy evidence record in the current layout with
+// the current integrity hash, and records the change in its history along with
+// the record as it was stored. A record whose stored legacy hash no longer
+// matches its content has been tampered with; it is left as it is, so the
+// mismatch stays visible to VerifyEvidenceIntegrity.
+func resealEvidence(ctx contractapi.TransactionContextInterface, evidence *Evidence, storedJSON []byte) (bool, error) {
+	if evidence.Integrity != "" {
+		valid, err := checkIntegrity(evidence)
+		if err != nil {
+			return false, err
+		}
+		if !valid {
+			return false, nil
+		}
+	}
+
+	previousIntegrity := evidence.Integrity
+	err := putEvidence(ctx, evidence)
+	if err != nil {
+		return false, err
+	}
+
+	err = recordHistory(ctx, evidence, previousIntegrity, "system", "integrity_seal",
+		fmt.Sprintf("Record upgraded to schema version %d and integrity hash version %d", evidenceSchemaVersion, integrityVersion), string(storedJSON))
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// lastWrite returns the transaction that last wrote a key
+func lastWrite(ctx contractapi.TransactionContextInterface, key string) (*LedgerWrite, error) {
+	historyIterator, err := ctx.GetStub().GetHistoryForKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of %s: %v", key, err)
+	}
+	defer historyIterator.Close()
+
+	if !historyIterator.HasNext() {
+		return nil, fmt.Errorf("no history found for %s", key)
+	}
+
+	modification, err := historyIterator.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	return &LedgerWrite{
+		Key:       key,
+		TxID:      modification.GetTxId(),
+		Timestamp: modification.GetTimestamp().AsTime().UTC().Format(time.RFC3339),
+	}, nil
+}
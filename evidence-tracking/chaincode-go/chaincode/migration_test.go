@@ -0,0 +1,270 @@
+package chaincode_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// seedLegacyEvidence writes EV900 the way early versions of the contract did:
+// under its bare ID, without an integrity hash, with history under a
+// hand-built key without a transaction ID
+func seedLegacyEvidence(t *testing.T, ledger *mocks.Ledger) {
+	t.Helper()
+
+	ledger.SetState("EV900", mustMarshal(t, &chaincode.Evidence{
+		ID:                "EV900",
+		Description:       "Crowbar recovered at scene",
+		CaseID:            "CASE1001",
+		FileHash:          "QmCrowbar",
+		SubmittedBy:       "officer1",
+		SubmittedTime:     "2023-06-01T10:00:00Z",
+		Status:            "submitted",
+		StatusUpdatedTime: "2023-06-01T10:00:00Z",
+		Tags:              []string{"tool"},
+		CurrentCustodian:  "officer1",
+	}))
+	ledger.SetState("history~EV900~2023-06-01T10:00:00Z", mustMarshal(t, &chaincode.EvidenceHistory{
+		EvidenceID:  "EV900",
+		ModifiedBy:  "officer1",
+		ModifiedAt:  "2023-06-01T10:00:00Z",
+		Action:      "create",
+		Description: "Evidence submitted",
+	}))
+}
+
+func TestReindexRecords(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	seedLegacyEvidence(t, ledger)
+
+	// Legacy evidence can be read before it is migrated
+	require.Equal(t, "Crowbar recovered at scene", readEvidence(t, ledger, contract, "EV900").Description)
+
+	total := 0
+	for {
+		count := submitResult(t, ledger, admin, func(tx *mocks.Transaction) (int, error) {
+			return contract.ReindexRecords(tx, 1)
+		})
+		if count == 0 {
+			break
+		}
+		require.Equal(t, 1, count)
+		total += count
+		require.Less(t, total, 20, "migration does not finish")
+	}
+	require.Greater(t, total, 1)
+
+	require.Nil(t, ledger.GetState("EV900"))
+	require.Nil(t, ledger.GetState("history~EV900~2023-06-01T10:00:00Z"))
+	require.NotNil(t, ledger.GetState("evidence~EV900"))
+
+	history := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.EvidenceHistory, error) {
+		return contract.GetEvidenceHistory(tx, "EV900")
+	})
+	require.Len(t, history, 2)
+	require.Equal(t, "create", history[0].Action)
+	require.NotEmpty(t, history[0].TxID)
+	require.Equal(t, "integrity_seal", history[len(history)-1].Action)
+
+	report := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.HistoryChainReport, error) {
+		return contract.VerifyHistoryChain(tx, "EV900")
+	})
+	require.True(t, report.Valid, "%+v", report.Break)
+
+	valid := submitResult(t, ledger, auditor, func(tx *mocks.Transaction) (bool, error) {
+		return contract.VerifyEvidenceIntegrity(tx, "EV900")
+	})
+	require.True(t, valid)
+
+	// Nothing is left to migrate
+	count := submitResult(t, ledger, admin, func(tx *mocks.Transaction) (int, error) {
+		return contract.ReindexRecords(tx, 100)
+	})
+	require.Zero(t, count)
+}
+
+func TestReindexRecordsLeavesTamperedEvidence(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	tamperEvidence(t, ledger, "EV001", "IntegrityVersion", 0)
+	tamperEvidence(t, ledger, "EV001", "Description", "Altered")
+
+	count := submitResult(t, ledger, admin, func(tx *mocks.Transaction) (int, error) {
+		return contract.ReindexRecords(tx, 100)
+	})
+	require.Zero(t, count)
+
+	valid := submitResult(t, ledger, auditor, func(tx *mocks.Transaction) (bool, error) {
+		return contract.VerifyEvidenceIntegrity(tx, "EV001")
+	})
+	require.False(t, valid)
+}
+
+func TestReindexRecordsValidatesArguments(t *testing.T) {
+	ledger, contract := setupLedger(t)
+
+	err := ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		_, err := contract.ReindexRecords(tx, 0)
+		return err
+	})
+	require.EqualError(t, err, "limit must be a positive number")
+
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		_, err := contract.ReindexRecords(tx, 10)
+		return err
+	})
+	require.EqualError(t, err, "submitting client not authorized to call ReindexRecords, role investigator is not permitted")
+}
+
+// seedLegacyLayouts writes evidence the way the contracts this one replaced
+// did: EV901 in the lowercase layout, EV902 in the layout of the first
+// contract, and lab903 in the layout of its successor, with the unversioned
+// integrity hash and a status it set freely. lab903 sorts after the evidence
+// key namespace.
+func seedLegacyLayouts(t *testing.T, ledger *mocks.Ledger) map[string][]byte {
+	t.Helper()
+
+	legacyHash := sha256.Sum256([]byte("lab903" + "QmTyre" + "CASE1001" + "officer2" + "2023-05-06T12:00:00Z" + `{"tread": "worn"}`))
+	stored := map[string][]byte{
+		"EV901":  []byte(`{"id":"EV901","caseId":"CASE1001","description":"Phone found in the alley","fileHash":"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08","ipfsHash":"QmPhone","submittedBy":"officer2","submittedAt":"2023-05-04T10:30:00.123456789+02:00","lastUpdatedBy":"officer2","lastUpdatedAt":"2023-05-05T08:00:00Z","status":"PROCESSING","metadata":"{\"model\": \"unknown\"}"}`),
+		"EV902":  []byte(`{"ID":"EV902","Description":"Shoe print","CaseID":"CASE1001","FileHash":"QmShoePrint","SubmittedBy":"officer1","Status":"submitted"}`),
+		"lab903": []byte(`{"ID":"lab903","Description":"Tyre cast","CaseID":"CASE1001","FileHash":"QmTyre","SubmittedBy":"officer2","SubmittedTime":"2023-05-06T12:00:00Z","Status":"In Transit","Tags":["tyre"],"Metadata":"{\"tread\": \"worn\"}","Integrity":"` + hex.EncodeToString(legacyHash[:]) + `","ProofVerified":false,"AIVerified":false}`),
+	}
+	for id, record := range stored {
+		ledger.SetState(id, record)
+	}
+
+	return stored
+}
+
+func TestReadEvidenceOfLegacyLayouts(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	seedLegacyLayouts(t, ledger)
+
+	lowercase := readEvidence(t, ledger, contract, "EV901")
+	require.Equal(t, &chaincode.Evidence{
+		ID:                "EV901",
+		Description:       "Phone found in the alley",
+		CaseID:            "CASE1001",
+		FileHash:          "QmPhone",
+		SubmittedBy:       "officer2",
+		SubmittedTime:     "2023-05-04T08:30:00Z",
+		Status:            "processing",
+		StatusUpdatedTime: "2023-05-05T08:00:00Z",
+		Metadata:          `{"model": "unknown"}`,
+		SchemaVersion:     1,
+		CurrentCustodian:  "officer2",
+	}, lowercase)
+
+	first := readEvidence(t, ledger, contract, "EV902")
+	require.Equal(t, "Shoe print", first.Description)
+	require.Equal(t, "officer1", first.CurrentCustodian)
+	require.Equal(t, 1, first.SchemaVersion)
+
+	// The status is mapped onto the lifecycle, and the record keeps the
+	// integrity hash it was sealed with
+	successor := readEvidence(t, ledger, contract, "lab903")
+	require.Equal(t, "in_transit", successor.Status)
+	require.Equal(t, 1, successor.SchemaVersion)
+	valid := submitResult(t, ledger, auditor, func(tx *mocks.Transaction) (bool, error) {
+		return contract.VerifyEvidenceIntegrity(tx, "lab903")
+	})
+	require.True(t, valid)
+}
+
+func TestLegacyEvidenceWithUnknownStatus(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	lost := []byte(`{"id":"EV904","caseId":"CASE1001","description":"Wallet","fileHash":"QmWallet","submittedBy":"officer1","status":"LOST"}`)
+	ledger.SetState("EV904", lost)
+
+	// Evidence with a status outside the lifecycle starts it again
+	require.Equal(t, "submitted", readEvidence(t, ledger, contract, "EV904").Status)
+
+	count := submitResult(t, ledger, admin, func(tx *mocks.Transaction) (int, error) {
+		return contract.MigrateRecords(tx, 100)
+	})
+	require.Equal(t, 1, count)
+	require.Nil(t, ledger.GetState("EV904"))
+	require.Equal(t, "submitted", readEvidence(t, ledger, contract, "EV904").Status)
+
+	// The original status is kept in the history of the migration
+	history := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.EvidenceHistory, error) {
+		return contract.GetEvidenceHistory(tx, "EV904")
+	})
+	require.Equal(t, "integrity_seal", history[len(history)-1].Action)
+	require.Equal(t, string(lost), history[len(history)-1].PrevState)
+}
+
+func TestReadEvidenceOfNewerLayout(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	tamperEvidence(t, ledger, "EV001", "SchemaVersion", 2)
+
+	err := ledger.Evaluate(officer1, func(tx *mocks.Transaction) error {
+		_, err := contract.ReadEvidence(tx, "EV001")
+		return err
+	})
+	require.EqualError(t, err, "evidence EV001 is stored with schema version 2, newer than the 1 this contract supports")
+}
+
+func TestMigrateRecords(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	stored := seedLegacyLayouts(t, ledger)
+	lazy := readEvidence(t, ledger, contract, "EV901")
+
+	total := 0
+	for {
+		count := submitResult(t, ledger, admin, func(tx *mocks.Transaction) (int, error) {
+			return contract.MigrateRecords(tx, 2)
+		})
+		if count == 0 {
+			break
+		}
+		total += count
+		require.Less(t, total, 20, "migration does not finish")
+	}
+
+	for _, id := range []string{"EV901", "EV902", "lab903"} {
+		require.Nil(t, ledger.GetState(id), id)
+
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(ledger.GetState("evidence~"+id), &record), id)
+		require.Equal(t, float64(1), record["SchemaVersion"], id)
+		require.Contains(t, record, "EvidenceTypeVersion", id)
+
+		valid := submitResult(t, ledger, auditor, func(tx *mocks.Transaction) (bool, error) {
+			return contract.VerifyEvidenceIntegrity(tx, id)
+		})
+		require.True(t, valid, id)
+
+		// The history keeps the record as it was stored
+		history := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.EvidenceHistory, error) {
+			return contract.GetEvidenceHistory(tx, id)
+		})
+		last := history[len(history)-1]
+		require.Equal(t, "integrity_seal", last.Action, id)
+		require.Equal(t, string(stored[id]), last.PrevState, id)
+	}
+
+	migrated := readEvidence(t, ledger, contract, "EV901")
+	require.Equal(t, lazy.Description, migrated.Description)
+	require.Equal(t, lazy.SubmittedTime, migrated.SubmittedTime)
+	require.Equal(t, lazy.FileHash, migrated.FileHash)
+	require.Equal(t, "evidence", migrated.DocType)
+	require.Equal(t, "in_transit", readEvidence(t, ledger, contract, "lab903").Status)
+
+	// Migrated evidence is found by queries
+	caseEvidence := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) ([]*chaincode.Evidence, error) {
+		return contract.GetEvidenceByCase(tx, "CASE1001")
+	})
+	require.ElementsMatch(t, []string{"EV001", "EV002", "EV901", "EV902", "lab903"}, evidenceIDs(caseEvidence))
+
+	err := ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		_, err := contract.MigrateRecords(tx, 0)
+		return err
+	})
+	require.EqualError(t, err, "batch must be a positive number")
+}
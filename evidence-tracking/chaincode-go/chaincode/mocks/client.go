@@ -0,0 +1,58 @@
+package mocks
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+)
+
+// Client is the identity of a client submitting transactions. It implements
+// cid.ClientIdentity.
+type Client struct {
+	ID          string            // Identity of the client, such as x509::CN=user1::CN=ca; GetID returns it base64 encoded, as cid does
+	MSPID       string            // MSP the client belongs to
+	Attributes  map[string]string // Attributes of the client's certificate, such as its role
+	Certificate *x509.Certificate // Certificate returned by GetX509Certificate, if any
+}
+
+var _ cid.ClientIdentity = (*Client)(nil)
+
+// GetID returns the base64 encoded identity of the client
+func (c *Client) GetID() (string, error) {
+	return base64.StdEncoding.EncodeToString([]byte(c.ID)), nil
+}
+
+// GetMSPID returns the MSP the client belongs to
+func (c *Client) GetMSPID() (string, error) {
+	return c.MSPID, nil
+}
+
+// GetAttributeValue returns the value of an attribute of the client, and
+// whether the client has it
+func (c *Client) GetAttributeValue(attrName string) (string, bool, error) {
+	value, found := c.Attributes[attrName]
+	return value, found, nil
+}
+
+// AssertAttributeValue returns an error unless the client has an attribute
+// with the given value
+func (c *Client) AssertAttributeValue(attrName string, attrValue string) error {
+	value, found := c.Attributes[attrName]
+	if !found {
+		return fmt.Errorf("attribute '%s' was not found", attrName)
+	}
+	if value != attrValue {
+		return fmt.Errorf("attribute '%s' equals '%s', not '%s'", attrName, value, attrValue)
+	}
+	return nil
+}
+
+// GetX509Certificate returns the certificate of the client
+func (c *Client) GetX509Certificate() (*x509.Certificate, error) {
+	if c.Certificate == nil {
+		return nil, fmt.Errorf("client %s has no certificate", c.ID)
+	}
+	return c.Certificate, nil
+}
@@ -0,0 +1,290 @@
+// Package mocks provides an in-memory ledger for unit testing chaincode
+// against the behaviour of a Fabric peer rather than against canned return
+// values. A Ledger holds the committed world state, key history, private data,
+// key-level endorsement policies and chaincode events of one chaincode on one
+// channel. Each transaction runs against a Stub that, like a peer's
+// transaction simulator:
+//
+//   - reads only committed state, so a transaction does not see its own writes
+//   - buffers its writes and applies them only when the transaction commits
+//   - keeps only the last event set by the transaction
+//   - fails to commit if a key it read was changed by another transaction
+//     since it was read
+//   - rejects writes once it has run a paginated query or a query on private
+//     data, and paginated queries once it has written
+//
+// Rich queries are evaluated with the subset of CouchDB Mango selectors
+// described in query.go.
+package mocks
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DefaultChannelID is the channel a new Ledger reports to its transactions
+const DefaultChannelID = "mychannel"
+
+// DefaultStartTime is the timestamp of the first transaction on a new Ledger.
+// Each transaction after it is one second later.
+var DefaultStartTime = time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+// Ledger is the committed state of one chaincode on one channel
+type Ledger struct {
+	ChannelID string // Channel returned by GetChannelID
+
+	clock       time.Time
+	txCount     int
+	version     uint64
+	state       map[string]*versionedValue
+	history     map[string][]*queryresult.KeyModification
+	privateData map[string]map[string][]byte
+	parameters  map[string][]byte
+	privateEPs  map[string]map[string][]byte
+	events      []*peer.ChaincodeEvent
+}
+
+// versionedValue is a committed value with the ledger version that wrote it
+type versionedValue struct {
+	value   []byte
+	version uint64
+}
+
+// Transaction is the context of one transaction on a Ledger. It satisfies
+// contractapi.TransactionContextInterface, so it can be passed straight to
+// the transactions of a contract.
+type Transaction struct {
+	contractapi.TransactionContext
+	Stub *Stub // Stub the transaction runs against
+}
+
+// NewLedger returns an empty ledger on DefaultChannelID whose clock starts at
+// DefaultStartTime
+func NewLedger() *Ledger {
+	return &Ledger{
+		ChannelID:   DefaultChannelID,
+		clock:       DefaultStartTime,
+		state:       map[string]*versionedValue{},
+		history:     map[string][]*queryresult.KeyModification{},
+		privateData: map[string]map[string][]byte{},
+		parameters:  map[string][]byte{},
+		privateEPs:  map[string]map[string][]byte{},
+	}
+}
+
+// Now returns the timestamp the next transaction will have
+func (l *Ledger) Now() time.Time {
+	return l.clock
+}
+
+// Advance moves the clock of the ledger forward, as if no transactions were
+// submitted for the given time
+func (l *Ledger) Advance(d time.Duration) {
+	l.clock = l.clock.Add(d)
+}
+
+// NewTransaction starts a transaction submitted by a client. Its writes take
+// effect only if it is committed.
+func (l *Ledger) NewTransaction(client *Client) *Transaction {
+	l.txCount++
+	timestamp := l.clock
+	l.clock = l.clock.Add(time.Second)
+
+	txID := sha256.Sum256([]byte(fmt.Sprintf("tx%d", l.txCount)))
+	stub := newStub(l, fmt.Sprintf("%x", txID), timestamp)
+
+	tx := &Transaction{Stub: stub}
+	tx.SetStub(stub)
+	tx.SetClientIdentity(client)
+	return tx
+}
+
+// Commit applies the writes of the transaction to the ledger, as a peer does
+// once the transaction is ordered and validated
+func (tx *Transaction) Commit() error {
+	return tx.Stub.commit()
+}
+
+// Submit runs fn as a transaction submitted by a client, and commits it if fn
+// succeeds. It returns the error from fn or from the commit.
+func (l *Ledger) Submit(client *Client, fn func(tx *Transaction) error) error {
+	tx := l.NewTransaction(client)
+	err := fn(tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Evaluate runs fn as a query by a client. Anything it writes is discarded.
+func (l *Ledger) Evaluate(client *Client, fn func(tx *Transaction) error) error {
+	return fn(l.NewTransaction(client))
+}
+
+// GetState returns the committed value of a key, or nil if it has none
+func (l *Ledger) GetState(key string) []byte {
+	value, ok := l.state[key]
+	if !ok {
+		return nil
+	}
+	return copyBytes(value.value)
+}
+
+// SetState writes a key directly, bypassing any contract, as a legacy version
+// of a contract or someone tampering with a peer's state database would. A nil
+// value deletes the key. The write is recorded in the key's history under a
+// transaction of its own.
+func (l *Ledger) SetState(key string, value []byte) {
+	tx := l.NewTransaction(&Client{})
+	if value == nil {
+		tx.Stub.writes[key] = &write{delete: true}
+	} else {
+		tx.Stub.writes[key] = &write{value: copyBytes(value)}
+	}
+	tx.Stub.apply()
+}
+
+// Keys returns every key with a committed value, in key order
+func (l *Ledger) Keys() []string {
+	return sortedKeys(l.state)
+}
+
+// GetPrivateData returns the committed value of a key in a private data
+// collection, or nil if it has none
+func (l *Ledger) GetPrivateData(collection string, key string) []byte {
+	return copyBytes(l.privateData[collection][key])
+}
+
+// SetPrivateData writes a key of a private data collection directly,
+// bypassing any contract. A nil value deletes the key.
+func (l *Ledger) SetPrivateData(collection string, key string, value []byte) {
+	if value == nil {
+		delete(l.privateData[collection], key)
+		return
+	}
+	if l.privateData[collection] == nil {
+		l.privateData[collection] = map[string][]byte{}
+	}
+	l.privateData[collection][key] = copyBytes(value)
+}
+
+// ValidationParameter returns the committed key-level endorsement policy of a
+// key, or nil if it has none
+func (l *Ledger) ValidationParameter(key string) []byte {
+	return copyBytes(l.parameters[key])
+}
+
+// Events returns the chaincode events of committed transactions, oldest first
+func (l *Ledger) Events() []*peer.ChaincodeEvent {
+	return append([]*peer.ChaincodeEvent(nil), l.events...)
+}
+
+// LastEvent returns the chaincode event of the last committed transaction
+// that set one, or nil if none has
+func (l *Ledger) LastEvent() *peer.ChaincodeEvent {
+	if len(l.events) == 0 {
+		return nil
+	}
+	return l.events[len(l.events)-1]
+}
+
+// keysInRange returns the committed keys from startKey up to but excluding
+// endKey, in key order. An empty endKey has no upper bound.
+func (l *Ledger) keysInRange(startKey string, endKey string) []string {
+	var keys []string
+	for key := range l.state {
+		if key >= startKey && (endKey == "" || key < endKey) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// committedDocuments returns every committed key and value in key order, for
+// rich queries to match against
+func (l *Ledger) committedDocuments() []*queryresult.KV {
+	var documents []*queryresult.KV
+	for _, key := range sortedKeys(l.state) {
+		documents = append(documents, &queryresult.KV{Key: key, Value: copyBytes(l.state[key].value)})
+	}
+	return documents
+}
+
+// apply commits a set of writes under a new ledger version
+func (l *Ledger) apply(s *Stub) {
+	l.version++
+	timestamp := timestamppb.New(s.timestamp)
+
+	for _, key := range sortedKeys(s.writes) {
+		w := s.writes[key]
+		if w.delete {
+			delete(l.state, key)
+		} else {
+			l.state[key] = &versionedValue{value: w.value, version: l.version}
+		}
+
+		// Like the peer's history database, newest first
+		modification := &queryresult.KeyModification{
+			TxId:      s.txID,
+			Value:     w.value,
+			Timestamp: timestamp,
+			IsDelete:  w.delete,
+		}
+		l.history[key] = append([]*queryresult.KeyModification{modification}, l.history[key]...)
+	}
+
+	for collection, writes := range s.privateWrites {
+		for key, w := range writes {
+			if w.delete {
+				delete(l.privateData[collection], key)
+				continue
+			}
+			if l.privateData[collection] == nil {
+				l.privateData[collection] = map[string][]byte{}
+			}
+			l.privateData[collection][key] = w.value
+		}
+	}
+
+	for key, parameter := range s.parameters {
+		l.parameters[key] = parameter
+	}
+	for collection, parameters := range s.privateEPs {
+		if l.privateEPs[collection] == nil {
+			l.privateEPs[collection] = map[string][]byte{}
+		}
+		for key, parameter := range parameters {
+			l.privateEPs[collection][key] = parameter
+		}
+	}
+
+	if s.event != nil {
+		l.events = append(l.events, s.event)
+	}
+}
+
+// copyBytes returns a copy of a byte slice, keeping nil as nil
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
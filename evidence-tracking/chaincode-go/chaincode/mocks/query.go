@@ -0,0 +1,561 @@
+package mocks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/queryresult"
+)
+
+// Rich queries are evaluated against every committed value that is a JSON
+// object, in key order unless the query sorts them. The query may have the
+// fields selector, sort, limit, skip and use_index, which is ignored. Sort
+// takes a list of field names or of {"field": "asc"|"desc"} objects.
+//
+// Selectors support the combination operators
+//
+//	$and, $or, $nor, $not
+//
+// and the condition operators
+//
+//	$eq, $ne, $gt, $gte, $lt, $lte, $exists, $type, $in, $nin, $size,
+//	$all, $elemMatch, $allMatch, $regex, $not
+//
+// on fields given by name or by dotted path. A field compared with a value
+// that is not an operator object must equal it. Values of different types
+// compare in CouchDB collation order: null, false, true, numbers, strings,
+// arrays and objects. Any other operator or query field is an error, so a
+// query is never silently evaluated differently from CouchDB.
+
+// queryFields are the fields a rich query may have
+var queryFields = []string{"selector", "sort", "limit", "skip", "use_index"}
+
+// query is a parsed rich query
+type query struct {
+	selector map[string]interface{}
+	sort     []sortField
+	limit    int
+	skip     int
+}
+
+// sortField is a field results are sorted on
+type sortField struct {
+	path       string
+	descending bool
+}
+
+// parseQuery parses a CouchDB query
+func parseQuery(queryString string) (*query, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal([]byte(queryString), &fields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %s: %v", queryString, err)
+	}
+	for name := range fields {
+		if !containsString(queryFields, name) {
+			return nil, fmt.Errorf("unsupported query field %s", name)
+		}
+	}
+
+	q := &query{}
+	if fields["selector"] == nil {
+		return nil, fmt.Errorf("a query must have a selector")
+	}
+	err = json.Unmarshal(fields["selector"], &q.selector)
+	if err != nil {
+		return nil, fmt.Errorf("the selector must be an object: %v", err)
+	}
+	err = checkSelector(q.selector)
+	if err != nil {
+		return nil, err
+	}
+
+	if fields["sort"] != nil {
+		q.sort, err = parseSort(fields["sort"])
+		if err != nil {
+			return nil, err
+		}
+	}
+	for name, value := range map[string]*int{"limit": &q.limit, "skip": &q.skip} {
+		if fields[name] == nil {
+			continue
+		}
+		err = json.Unmarshal(fields[name], value)
+		if err != nil || *value < 0 {
+			return nil, fmt.Errorf("%s must be a non-negative integer", name)
+		}
+	}
+
+	return q, nil
+}
+
+// parseSort parses the sort field of a query
+func parseSort(raw json.RawMessage) ([]sortField, error) {
+	var entries []interface{}
+	err := json.Unmarshal(raw, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("sort must be a list")
+	}
+
+	var fields []sortField
+	for _, entry := range entries {
+		switch e := entry.(type) {
+		case string:
+			fields = append(fields, sortField{path: e})
+		case map[string]interface{}:
+			if len(e) != 1 {
+				return nil, fmt.Errorf("each sort entry must name a single field")
+			}
+			for path, direction := range e {
+				switch direction {
+				case "asc":
+					fields = append(fields, sortField{path: path})
+				case "desc":
+					fields = append(fields, sortField{path: path, descending: true})
+				default:
+					return nil, fmt.Errorf("sort direction of %s must be asc or desc", path)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("each sort entry must be a field name or an object")
+		}
+	}
+
+	// CouchDB sorts every field in the same direction
+	for _, field := range fields {
+		if field.descending != fields[0].descending {
+			return nil, fmt.Errorf("sort fields must all have the same direction")
+		}
+	}
+	return fields, nil
+}
+
+// run returns the documents that match the query, sorted, skipped and
+// limited as it asks
+func (q *query) run(documents []*queryresult.KV) ([]*queryresult.KV, error) {
+	type match struct {
+		kv  *queryresult.KV
+		doc map[string]interface{}
+	}
+
+	var matches []match
+	for _, kv := range documents {
+		var doc map[string]interface{}
+		if json.Unmarshal(kv.Value, &doc) != nil {
+			continue
+		}
+		ok, err := matchSelector(doc, q.selector)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, match{kv: kv, doc: doc})
+		}
+	}
+
+	if len(q.sort) > 0 {
+		sort.SliceStable(matches, func(i, j int) bool {
+			for _, field := range q.sort {
+				a, aok := lookup(matches[i].doc, field.path)
+				b, bok := lookup(matches[j].doc, field.path)
+				c := compareMissing(a, aok, b, bok)
+				if c == 0 {
+					continue
+				}
+				if field.descending {
+					return c > 0
+				}
+				return c < 0
+			}
+			return false
+		})
+	}
+
+	if q.skip >= len(matches) {
+		return []*queryresult.KV{}, nil
+	}
+	matches = matches[q.skip:]
+	if q.limit > 0 && len(matches) > q.limit {
+		matches = matches[:q.limit]
+	}
+
+	results := make([]*queryresult.KV, 0, len(matches))
+	for _, m := range matches {
+		results = append(results, m.kv)
+	}
+	return results, nil
+}
+
+// checkSelector returns an error if a selector uses an unsupported operator,
+// by matching it against an empty document
+func checkSelector(selector map[string]interface{}) error {
+	_, err := matchSelector(map[string]interface{}{}, selector)
+	return err
+}
+
+// matchSelector reports whether a document satisfies every clause of a
+// selector. Every clause is evaluated, so unsupported operators are reported
+// whatever the document.
+func matchSelector(doc interface{}, selector map[string]interface{}) (bool, error) {
+	matched := true
+	for _, name := range sortedKeys(selector) {
+		argument := selector[name]
+
+		var ok bool
+		var err error
+		switch name {
+		case "$and", "$or", "$nor":
+			ok, err = matchCombination(doc, name, argument)
+		case "$not":
+			sub, isObject := argument.(map[string]interface{})
+			if !isObject {
+				return false, fmt.Errorf("$not needs a selector")
+			}
+			ok, err = matchSelector(doc, sub)
+			ok = !ok
+		default:
+			if strings.HasPrefix(name, "$") {
+				return false, fmt.Errorf("unsupported operator %s", name)
+			}
+			value, exists := lookup(doc, name)
+			ok, err = matchCondition(value, exists, argument)
+		}
+		if err != nil {
+			return false, err
+		}
+		matched = matched && ok
+	}
+	return matched, nil
+}
+
+// matchCombination evaluates $and, $or or $nor over a list of selectors
+func matchCombination(doc interface{}, operator string, argument interface{}) (bool, error) {
+	selectors, isList := argument.([]interface{})
+	if !isList {
+		return false, fmt.Errorf("%s needs a list of selectors", operator)
+	}
+
+	count := 0
+	for _, s := range selectors {
+		sub, isObject := s.(map[string]interface{})
+		if !isObject {
+			return false, fmt.Errorf("%s needs a list of selectors", operator)
+		}
+		ok, err := matchSelector(doc, sub)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			count++
+		}
+	}
+
+	switch operator {
+	case "$and":
+		return count == len(selectors), nil
+	case "$or":
+		return count > 0, nil
+	default:
+		return count == 0, nil
+	}
+}
+
+// matchCondition reports whether a field value satisfies a condition. A
+// condition that is an object of operators applies each of them; any other
+// condition is an implicit $eq.
+func matchCondition(value interface{}, exists bool, condition interface{}) (bool, error) {
+	operators, isObject := condition.(map[string]interface{})
+	if !isObject || !isOperatorObject(operators) {
+		return exists && equal(value, condition), nil
+	}
+
+	matched := true
+	for _, operator := range sortedKeys(operators) {
+		ok, err := matchOperator(value, exists, operator, operators[operator])
+		if err != nil {
+			return false, err
+		}
+		matched = matched && ok
+	}
+	return matched, nil
+}
+
+// matchOperator reports whether a field value satisfies one condition
+// operator
+func matchOperator(value interface{}, exists bool, operator string, argument interface{}) (bool, error) {
+	switch operator {
+	case "$eq":
+		return exists && equal(value, argument), nil
+	case "$ne":
+		return exists && !equal(value, argument), nil
+	case "$gt":
+		return exists && collate(value, argument) > 0, nil
+	case "$gte":
+		return exists && collate(value, argument) >= 0, nil
+	case "$lt":
+		return exists && collate(value, argument) < 0, nil
+	case "$lte":
+		return exists && collate(value, argument) <= 0, nil
+
+	case "$exists":
+		want, ok := argument.(bool)
+		if !ok {
+			return false, fmt.Errorf("$exists needs true or false")
+		}
+		return exists == want, nil
+
+	case "$type":
+		name, ok := argument.(string)
+		if !ok {
+			return false, fmt.Errorf("$type needs a type name")
+		}
+		return exists && typeName(value) == name, nil
+
+	case "$in", "$nin":
+		values, ok := argument.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s needs a list", operator)
+		}
+		if !exists {
+			return false, nil
+		}
+		found := false
+		for _, v := range values {
+			if equal(value, v) || arrayContains(value, v) {
+				found = true
+				break
+			}
+		}
+		return found == (operator == "$in"), nil
+
+	case "$size":
+		size, ok := argument.(float64)
+		if !ok {
+			return false, fmt.Errorf("$size needs a number")
+		}
+		array, isArray := value.([]interface{})
+		return exists && isArray && float64(len(array)) == size, nil
+
+	case "$all":
+		values, ok := argument.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("$all needs a list")
+		}
+		if _, isArray := value.([]interface{}); !exists || !isArray {
+			return false, nil
+		}
+		for _, v := range values {
+			if !arrayContains(value, v) {
+				return false, nil
+			}
+		}
+		return true, nil
+
+	case "$elemMatch", "$allMatch":
+		array, isArray := value.([]interface{})
+		if !exists || !isArray {
+			// Still check the argument for unsupported operators
+			_, err := matchCondition(nil, false, argument)
+			return false, err
+		}
+		count := 0
+		for _, element := range array {
+			ok, err := matchElement(element, argument)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				count++
+			}
+		}
+		if operator == "$elemMatch" {
+			return count > 0, nil
+		}
+		return len(array) > 0 && count == len(array), nil
+
+	case "$regex":
+		pattern, ok := argument.(string)
+		if !ok {
+			return false, fmt.Errorf("$regex needs a string")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("invalid $regex %s: %v", pattern, err)
+		}
+		s, isString := value.(string)
+		return exists && isString && re.MatchString(s), nil
+
+	case "$not":
+		ok, err := matchCondition(value, exists, argument)
+		return !ok, err
+	}
+
+	return false, fmt.Errorf("unsupported operator %s", operator)
+}
+
+// matchElement reports whether an array element satisfies the argument of
+// $elemMatch or $allMatch, which is either a condition on the element itself
+// or a selector on the fields of an object element
+func matchElement(element interface{}, argument interface{}) (bool, error) {
+	condition, isObject := argument.(map[string]interface{})
+	if !isObject {
+		return false, fmt.Errorf("$elemMatch and $allMatch need an object")
+	}
+	if isOperatorObject(condition) {
+		return matchCondition(element, true, condition)
+	}
+	return matchSelector(element, condition)
+}
+
+// isOperatorObject reports whether every field of an object is an operator
+func isOperatorObject(object map[string]interface{}) bool {
+	if len(object) == 0 {
+		return false
+	}
+	for name := range object {
+		if !strings.HasPrefix(name, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup returns the value of a field, given by name or by dotted path, and
+// whether the document has it
+func lookup(doc interface{}, path string) (interface{}, bool) {
+	value := doc
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = object[name]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// arrayContains reports whether value is an array with an element equal to v
+func arrayContains(value interface{}, v interface{}) bool {
+	array, ok := value.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, element := range array {
+		if equal(element, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal reports whether two decoded JSON values are the same
+func equal(a interface{}, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// typeRank is the position of a type in CouchDB collation order
+func typeRank(value interface{}) int {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case bool:
+		if v {
+			return 2
+		}
+		return 1
+	case float64:
+		return 3
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	default:
+		return 6
+	}
+}
+
+// typeName returns the name $type gives the type of a value
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// collate compares two decoded JSON values in CouchDB collation order,
+// returning a negative number, zero or a positive number. Strings are
+// compared by code point rather than with the ICU collation CouchDB uses,
+// which agrees for the ASCII identifiers and timestamps contracts store.
+func collate(a interface{}, b interface{}) int {
+	if ra, rb := typeRank(a), typeRank(b); ra != rb {
+		return ra - rb
+	}
+
+	switch x := a.(type) {
+	case float64:
+		y := b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(x, b.(string))
+	case []interface{}:
+		y := b.([]interface{})
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := collate(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return len(x) - len(y)
+	case map[string]interface{}:
+		xj, _ := json.Marshal(x)
+		yj, _ := json.Marshal(b)
+		return bytes.Compare(xj, yj)
+	}
+	return 0
+}
+
+// compareMissing collates two sort values, placing missing fields first
+func compareMissing(a interface{}, aok bool, b interface{}, bok bool) int {
+	switch {
+	case !aok && !bok:
+		return 0
+	case !aok:
+		return -1
+	case !bok:
+		return 1
+	}
+	return collate(a, b)
+}
+
+// containsString returns true when value is in values
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,705 @@
+package mocks
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Characters that delimit composite keys, as in the shim
+const (
+	minUnicodeRuneValue   = 0            // U+0000, separates the parts of a composite key
+	maxUnicodeRuneValue   = utf8.MaxRune // U+10FFFF, ends the range of a partial composite key
+	compositeKeyNamespace = "\x00"
+	emptyKeySubstitute    = "\x01" // Start of a range query from the first simple key
+)
+
+// Stub is the ChaincodeStubInterface of one transaction on a Ledger. Args and
+// Transient are the arguments and transient map of the proposal, and may be
+// set before the transaction runs.
+type Stub struct {
+	Args      [][]byte          // Arguments of the proposal, function name first
+	Transient map[string][]byte // Transient map of the proposal
+
+	ledger    *Ledger
+	txID      string
+	timestamp time.Time
+	committed bool
+
+	reads         map[string]uint64
+	writes        map[string]*write
+	privateWrites map[string]map[string]*write
+	parameters    map[string][]byte
+	privateEPs    map[string]map[string][]byte
+	event         *peer.ChaincodeEvent
+
+	paginatedQuery   bool
+	privateDataQuery bool
+}
+
+var _ shim.ChaincodeStubInterface = (*Stub)(nil)
+
+// write is a buffered write to a key
+type write struct {
+	value  []byte
+	delete bool
+}
+
+// newStub returns the stub of a new transaction
+func newStub(ledger *Ledger, txID string, timestamp time.Time) *Stub {
+	return &Stub{
+		Transient:     map[string][]byte{},
+		ledger:        ledger,
+		txID:          txID,
+		timestamp:     timestamp,
+		reads:         map[string]uint64{},
+		writes:        map[string]*write{},
+		privateWrites: map[string]map[string]*write{},
+		parameters:    map[string][]byte{},
+		privateEPs:    map[string]map[string][]byte{},
+	}
+}
+
+// commit validates the read set of the transaction against the ledger and
+// applies its writes
+func (s *Stub) commit() error {
+	if s.committed {
+		return fmt.Errorf("transaction %s was already committed", s.txID)
+	}
+	s.committed = true
+
+	for _, key := range sortedKeys(s.reads) {
+		var current uint64
+		if value, ok := s.ledger.state[key]; ok {
+			current = value.version
+		}
+		if current != s.reads[key] {
+			return fmt.Errorf("MVCC_READ_CONFLICT: transaction %s read key %q, which has since been changed", s.txID, key)
+		}
+	}
+
+	s.apply()
+	return nil
+}
+
+// apply applies the writes of the transaction to the ledger without
+// validating them
+func (s *Stub) apply() {
+	s.committed = true
+	s.ledger.apply(s)
+}
+
+// GetArgs returns the arguments of the proposal
+func (s *Stub) GetArgs() [][]byte {
+	return s.Args
+}
+
+// GetStringArgs returns the arguments of the proposal as strings
+func (s *Stub) GetStringArgs() []string {
+	args := make([]string, 0, len(s.Args))
+	for _, arg := range s.Args {
+		args = append(args, string(arg))
+	}
+	return args
+}
+
+// GetFunctionAndParameters returns the first argument of the proposal as the
+// function name and the rest as its parameters
+func (s *Stub) GetFunctionAndParameters() (string, []string) {
+	args := s.GetStringArgs()
+	if len(args) == 0 {
+		return "", []string{}
+	}
+	return args[0], args[1:]
+}
+
+// GetArgsSlice returns the arguments of the proposal concatenated
+func (s *Stub) GetArgsSlice() ([]byte, error) {
+	return bytes.Join(s.Args, nil), nil
+}
+
+// GetTxID returns the ID of the transaction
+func (s *Stub) GetTxID() string {
+	return s.txID
+}
+
+// GetChannelID returns the channel of the ledger
+func (s *Stub) GetChannelID() string {
+	return s.ledger.ChannelID
+}
+
+// InvokeChaincode is not supported; the ledger holds a single chaincode
+func (s *Stub) InvokeChaincode(chaincodeName string, args [][]byte, channel string) *peer.Response {
+	return shim.Error(fmt.Sprintf("cannot invoke chaincode %s: the in-memory ledger holds a single chaincode", chaincodeName))
+}
+
+// GetState returns the committed value of a key, ignoring any write to it by
+// this transaction
+func (s *Stub) GetState(key string) ([]byte, error) {
+	value, ok := s.ledger.state[key]
+	if !ok {
+		s.reads[key] = 0
+		return nil, nil
+	}
+	s.reads[key] = value.version
+	return copyBytes(value.value), nil
+}
+
+// PutState buffers a write to a key
+func (s *Stub) PutState(key string, value []byte) error {
+	err := s.checkWrite(key)
+	if err != nil {
+		return err
+	}
+	s.writes[key] = &write{value: copyBytes(value)}
+	return nil
+}
+
+// DelState buffers the deletion of a key
+func (s *Stub) DelState(key string) error {
+	err := s.checkWrite(key)
+	if err != nil {
+		return err
+	}
+	s.writes[key] = &write{delete: true}
+	return nil
+}
+
+// SetStateValidationParameter buffers a change to the key-level endorsement
+// policy of a key
+func (s *Stub) SetStateValidationParameter(key string, ep []byte) error {
+	err := s.checkWrite(key)
+	if err != nil {
+		return err
+	}
+	s.parameters[key] = copyBytes(ep)
+	return nil
+}
+
+// GetStateValidationParameter returns the committed key-level endorsement
+// policy of a key
+func (s *Stub) GetStateValidationParameter(key string) ([]byte, error) {
+	return copyBytes(s.ledger.parameters[key]), nil
+}
+
+// GetStateByRange returns the committed simple keys from startKey up to but
+// excluding endKey. Empty keys leave the range open at that end.
+func (s *Stub) GetStateByRange(startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	err := validateSimpleKeys(startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	if startKey == "" {
+		startKey = emptyKeySubstitute
+	}
+
+	return s.rangeIterator(s.ledger.keysInRange(startKey, endKey)), nil
+}
+
+// GetStateByRangeWithPagination returns a page of the committed simple keys
+// in a range. The bookmark is the first key of the page; the bookmark
+// returned is the first key of the next page, or empty after the last page.
+func (s *Stub) GetStateByRangeWithPagination(startKey string, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	err := validateSimpleKeys(startKey, endKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if startKey == "" {
+		startKey = emptyKeySubstitute
+	}
+
+	return s.rangePage(startKey, endKey, pageSize, bookmark)
+}
+
+// GetStateByPartialCompositeKey returns the committed composite keys that
+// start with the given object type and attributes
+func (s *Stub) GetStateByPartialCompositeKey(objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	startKey, endKey, err := partialCompositeKeyRange(objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rangeIterator(s.ledger.keysInRange(startKey, endKey)), nil
+}
+
+// GetStateByPartialCompositeKeyWithPagination returns a page of the committed
+// composite keys that start with the given object type and attributes, with
+// bookmarks as for GetStateByRangeWithPagination
+func (s *Stub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	startKey, endKey, err := partialCompositeKeyRange(objectType, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.rangePage(startKey, endKey, pageSize, bookmark)
+}
+
+// CreateCompositeKey joins an object type and attributes into a composite key
+func (s *Stub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return createCompositeKey(objectType, attributes)
+}
+
+// SplitCompositeKey splits a composite key into its object type and
+// attributes
+func (s *Stub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	if !strings.HasPrefix(compositeKey, compositeKeyNamespace) {
+		return "", nil, fmt.Errorf("%q is not a composite key", compositeKey)
+	}
+
+	components := strings.Split(compositeKey[1:], string(rune(minUnicodeRuneValue)))
+	if len(components) < 2 || components[len(components)-1] != "" {
+		return "", nil, fmt.Errorf("%q is not a composite key", compositeKey)
+	}
+	components = components[:len(components)-1]
+
+	return components[0], components[1:], nil
+}
+
+// GetQueryResult runs a CouchDB rich query over the committed state
+func (s *Stub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	q, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := q.run(s.ledger.committedDocuments())
+	if err != nil {
+		return nil, err
+	}
+
+	return &stateIterator{results: results}, nil
+}
+
+// GetQueryResultWithPagination runs a CouchDB rich query over the committed
+// state and returns a page of its results. The page size takes the place of
+// any limit in the query. The bookmark is the number of results already
+// returned, and is empty after the last page.
+func (s *Stub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	err := s.checkPaginatedQuery(pageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	q, err := parseQuery(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	q.limit = 0
+
+	offset := 0
+	if bookmark != "" {
+		offset, err = strconv.Atoi(bookmark)
+		if err != nil || offset < 0 {
+			return nil, nil, fmt.Errorf("invalid bookmark %q", bookmark)
+		}
+	}
+	q.skip += offset
+
+	results, err := q.run(s.ledger.committedDocuments())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next := ""
+	if len(results) > int(pageSize) {
+		results = results[:pageSize]
+		next = strconv.Itoa(offset + int(pageSize))
+	}
+
+	return &stateIterator{results: results}, &peer.QueryResponseMetadata{
+		FetchedRecordsCount: int32(len(results)),
+		Bookmark:            next,
+	}, nil
+}
+
+// GetHistoryForKey returns the committed modifications of a key, newest
+// first, as the peer's history database does
+func (s *Stub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	return &historyIterator{results: append([]*queryresult.KeyModification(nil), s.ledger.history[key]...)}, nil
+}
+
+// GetPrivateData returns the committed value of a key in a private data
+// collection, ignoring any write to it by this transaction
+func (s *Stub) GetPrivateData(collection string, key string) ([]byte, error) {
+	if collection == "" {
+		return nil, fmt.Errorf("collection must not be an empty string")
+	}
+	return copyBytes(s.ledger.privateData[collection][key]), nil
+}
+
+// GetPrivateDataHash returns the SHA-256 hash of the committed value of a key
+// in a private data collection, or nil if it has none
+func (s *Stub) GetPrivateDataHash(collection string, key string) ([]byte, error) {
+	if collection == "" {
+		return nil, fmt.Errorf("collection must not be an empty string")
+	}
+	value, ok := s.ledger.privateData[collection][key]
+	if !ok {
+		return nil, nil
+	}
+	hash := sha256.Sum256(value)
+	return hash[:], nil
+}
+
+// PutPrivateData buffers a write to a key in a private data collection
+func (s *Stub) PutPrivateData(collection string, key string, value []byte) error {
+	err := s.checkPrivateWrite(collection, key)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return fmt.Errorf("private data value must not be nil")
+	}
+	s.privateWrite(collection)[key] = &write{value: copyBytes(value)}
+	return nil
+}
+
+// DelPrivateData buffers the deletion of a key in a private data collection
+func (s *Stub) DelPrivateData(collection string, key string) error {
+	err := s.checkPrivateWrite(collection, key)
+	if err != nil {
+		return err
+	}
+	s.privateWrite(collection)[key] = &write{delete: true}
+	return nil
+}
+
+// PurgePrivateData buffers the deletion of a key in a private data
+// collection. The ledger keeps no private data history, so purging is the
+// same as deleting.
+func (s *Stub) PurgePrivateData(collection string, key string) error {
+	return s.DelPrivateData(collection, key)
+}
+
+// SetPrivateDataValidationParameter buffers a change to the key-level
+// endorsement policy of a key in a private data collection
+func (s *Stub) SetPrivateDataValidationParameter(collection string, key string, ep []byte) error {
+	err := s.checkPrivateWrite(collection, key)
+	if err != nil {
+		return err
+	}
+	if s.privateEPs[collection] == nil {
+		s.privateEPs[collection] = map[string][]byte{}
+	}
+	s.privateEPs[collection][key] = copyBytes(ep)
+	return nil
+}
+
+// GetPrivateDataValidationParameter returns the committed key-level
+// endorsement policy of a key in a private data collection
+func (s *Stub) GetPrivateDataValidationParameter(collection string, key string) ([]byte, error) {
+	return copyBytes(s.ledger.privateEPs[collection][key]), nil
+}
+
+// GetPrivateDataByRange returns the committed simple keys of a private data
+// collection in a range. The transaction can no longer write afterwards.
+func (s *Stub) GetPrivateDataByRange(collection string, startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	err := validateSimpleKeys(startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	if startKey == "" {
+		startKey = emptyKeySubstitute
+	}
+
+	s.privateDataQuery = true
+	return s.privateRangeIterator(collection, startKey, endKey), nil
+}
+
+// GetPrivateDataByPartialCompositeKey returns the committed composite keys of
+// a private data collection that start with the given object type and
+// attributes. The transaction can no longer write afterwards.
+func (s *Stub) GetPrivateDataByPartialCompositeKey(collection string, objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	startKey, endKey, err := partialCompositeKeyRange(objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	s.privateDataQuery = true
+	return s.privateRangeIterator(collection, startKey, endKey), nil
+}
+
+// GetPrivateDataQueryResult runs a CouchDB rich query over the committed data
+// of a private data collection. The transaction can no longer write
+// afterwards.
+func (s *Stub) GetPrivateDataQueryResult(collection string, query string) (shim.StateQueryIteratorInterface, error) {
+	q, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	data := s.ledger.privateData[collection]
+	var documents []*queryresult.KV
+	for _, key := range sortedKeys(data) {
+		documents = append(documents, &queryresult.KV{Key: key, Value: copyBytes(data[key])})
+	}
+
+	results, err := q.run(documents)
+	if err != nil {
+		return nil, err
+	}
+
+	s.privateDataQuery = true
+	return &stateIterator{results: results}, nil
+}
+
+// GetCreator is not supported; the client identity is available from the
+// transaction context instead
+func (s *Stub) GetCreator() ([]byte, error) {
+	return nil, fmt.Errorf("the in-memory ledger does not serialize identities; use the client identity of the transaction")
+}
+
+// GetTransient returns the transient map of the proposal
+func (s *Stub) GetTransient() (map[string][]byte, error) {
+	return s.Transient, nil
+}
+
+// GetBinding is not supported; the ledger does not build proposals
+func (s *Stub) GetBinding() ([]byte, error) {
+	return nil, fmt.Errorf("the in-memory ledger does not build proposals")
+}
+
+// GetDecorations returns no decorations
+func (s *Stub) GetDecorations() map[string][]byte {
+	return map[string][]byte{}
+}
+
+// GetSignedProposal is not supported; the ledger does not build proposals
+func (s *Stub) GetSignedProposal() (*peer.SignedProposal, error) {
+	return nil, fmt.Errorf("the in-memory ledger does not build proposals")
+}
+
+// GetTxTimestamp returns the timestamp of the transaction
+func (s *Stub) GetTxTimestamp() (*timestamppb.Timestamp, error) {
+	return timestamppb.New(s.timestamp), nil
+}
+
+// SetEvent sets the chaincode event of the transaction, replacing any event
+// set before
+func (s *Stub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return fmt.Errorf("event name can not be empty string")
+	}
+	s.event = &peer.ChaincodeEvent{
+		TxId:      s.txID,
+		EventName: name,
+		Payload:   copyBytes(payload),
+	}
+	return nil
+}
+
+// Event returns the chaincode event set by the transaction, or nil
+func (s *Stub) Event() *peer.ChaincodeEvent {
+	return s.event
+}
+
+// checkWrite returns an error if the transaction may not write a key
+func (s *Stub) checkWrite(key string) error {
+	if key == "" {
+		return fmt.Errorf("key must not be an empty string")
+	}
+	if !utf8.ValidString(key) {
+		return fmt.Errorf("key %x is not a valid utf8 string", key)
+	}
+	if s.paginatedQuery {
+		return fmt.Errorf("transaction has already performed a paginated query, writes are not allowed")
+	}
+	if s.privateDataQuery {
+		return fmt.Errorf("transaction has already performed queries on private data, writes are not allowed")
+	}
+	return nil
+}
+
+// checkPrivateWrite returns an error if the transaction may not write a key
+// in a private data collection
+func (s *Stub) checkPrivateWrite(collection string, key string) error {
+	if collection == "" {
+		return fmt.Errorf("collection must not be an empty string")
+	}
+	return s.checkWrite(key)
+}
+
+// checkPaginatedQuery returns an error if the transaction may not run a
+// paginated query, and otherwise records that it has
+func (s *Stub) checkPaginatedQuery(pageSize int32) error {
+	if pageSize <= 0 {
+		return fmt.Errorf("page size must be greater than zero")
+	}
+	if len(s.writes) > 0 || len(s.privateWrites) > 0 || len(s.parameters) > 0 {
+		return fmt.Errorf("paginated queries are only valid for read only transactions")
+	}
+	s.paginatedQuery = true
+	return nil
+}
+
+// privateWrite returns the buffered writes of a private data collection
+func (s *Stub) privateWrite(collection string) map[string]*write {
+	if s.privateWrites[collection] == nil {
+		s.privateWrites[collection] = map[string]*write{}
+	}
+	return s.privateWrites[collection]
+}
+
+// rangeIterator returns an iterator over the committed values of keys
+func (s *Stub) rangeIterator(keys []string) *stateIterator {
+	results := make([]*queryresult.KV, 0, len(keys))
+	for _, key := range keys {
+		results = append(results, &queryresult.KV{Key: key, Value: copyBytes(s.ledger.state[key].value)})
+	}
+	return &stateIterator{results: results}
+}
+
+// rangePage returns a page of the committed keys in a range
+func (s *Stub) rangePage(startKey string, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	err := s.checkPaginatedQuery(pageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bookmark != "" {
+		if bookmark < startKey || (endKey != "" && bookmark >= endKey) {
+			return nil, nil, fmt.Errorf("bookmark %q is outside the range of the query", bookmark)
+		}
+		startKey = bookmark
+	}
+
+	keys := s.ledger.keysInRange(startKey, endKey)
+	next := ""
+	if len(keys) > int(pageSize) {
+		next = keys[pageSize]
+		keys = keys[:pageSize]
+	}
+
+	return s.rangeIterator(keys), &peer.QueryResponseMetadata{
+		FetchedRecordsCount: int32(len(keys)),
+		Bookmark:            next,
+	}, nil
+}
+
+// privateRangeIterator returns an iterator over the committed keys of a
+// private data collection in a range
+func (s *Stub) privateRangeIterator(collection string, startKey string, endKey string) *stateIterator {
+	data := s.ledger.privateData[collection]
+	var results []*queryresult.KV
+	for _, key := range sortedKeys(data) {
+		if key >= startKey && (endKey == "" || key < endKey) {
+			results = append(results, &queryresult.KV{Key: key, Value: copyBytes(data[key])})
+		}
+	}
+	return &stateIterator{results: results}
+}
+
+// createCompositeKey builds a composite key in the format the shim uses
+func createCompositeKey(objectType string, attributes []string) (string, error) {
+	err := validateCompositeKeyAttribute(objectType)
+	if err != nil {
+		return "", err
+	}
+
+	key := compositeKeyNamespace + objectType + string(rune(minUnicodeRuneValue))
+	for _, attribute := range attributes {
+		err = validateCompositeKeyAttribute(attribute)
+		if err != nil {
+			return "", err
+		}
+		key += attribute + string(rune(minUnicodeRuneValue))
+	}
+	return key, nil
+}
+
+// partialCompositeKeyRange returns the range of keys that start with a
+// partial composite key
+func partialCompositeKeyRange(objectType string, attributes []string) (string, string, error) {
+	startKey, err := createCompositeKey(objectType, attributes)
+	if err != nil {
+		return "", "", err
+	}
+	return startKey, startKey + string(maxUnicodeRuneValue), nil
+}
+
+// validateCompositeKeyAttribute rejects the parts of a composite key that
+// the shim rejects
+func validateCompositeKeyAttribute(s string) error {
+	if !utf8.ValidString(s) {
+		return fmt.Errorf("not a valid utf8 string: [%x]", s)
+	}
+	for index, r := range s {
+		if r == minUnicodeRuneValue || r == maxUnicodeRuneValue {
+			return fmt.Errorf("input contains unicode %#U starting at position [%d]. %#U and %#U are not allowed in the input attribute of a composite key",
+				r, index, rune(minUnicodeRuneValue), rune(maxUnicodeRuneValue))
+		}
+	}
+	return nil
+}
+
+// validateSimpleKeys rejects composite keys as the bounds of a range query
+func validateSimpleKeys(keys ...string) error {
+	for _, key := range keys {
+		if strings.HasPrefix(key, compositeKeyNamespace) {
+			return fmt.Errorf("first character of the key [%s] contains a null character which is not allowed", key)
+		}
+	}
+	return nil
+}
+
+// stateIterator iterates over the results of a state query
+type stateIterator struct {
+	results []*queryresult.KV
+	closed  bool
+}
+
+// HasNext reports whether the iterator has another result
+func (it *stateIterator) HasNext() bool {
+	return !it.closed && len(it.results) > 0
+}
+
+// Next returns the next result
+func (it *stateIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+	result := it.results[0]
+	it.results = it.results[1:]
+	return result, nil
+}
+
+// Close releases the iterator
+func (it *stateIterator) Close() error {
+	it.closed = true
+	return nil
+}
+
+// historyIterator iterates over the modifications of a key
+type historyIterator struct {
+	results []*queryresult.KeyModification
+	closed  bool
+}
+
+// HasNext reports whether the iterator has another modification
+func (it *historyIterator) HasNext() bool {
+	return !it.closed && len(it.results) > 0
+}
+
+// Next returns the next modification
+func (it *historyIterator) Next() (*queryresult.KeyModification, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+	result := it.results[0]
+	it.results = it.results[1:]
+	return result, nil
+}
+
+// Close releases the iterator
+func (it *historyIterator) Close() error {
+	it.closed = true
+	return nil
+}
@@ -0,0 +1,148 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// maxPageSize is the largest page a paginated query may request
+const maxPageSize = 1000
+
+// EvidencePage is a page of evidence records, with the bookmark from which to
+// fetch the next page
+type EvidencePage struct {
+	Records             []*Evidence `json:"records"`
+	FetchedRecordsCount int32       `json:"fetchedRecordsCount"`
+	Bookmark            string      `json:"bookmark"`
+}
+
+// GetAllEvidenceWithPagination returns a page of all evidence records in key
+// order. Pass the bookmark from the previous page to fetch the next one, or an
+// empty bookmark for the first page. Paginated queries are only valid for read
+// only transactions.
+func (s *SmartContract) GetAllEvidenceWithPagination(ctx contractapi.TransactionContextInterface, pageSize int, bookmark string) (*EvidencePage, error) {
+	size, err := checkPageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	startKey, endKey := evidenceKeyRange()
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByRangeWithPagination(startKey, endKey, size, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	records := []*Evidence{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		ev, err := decodeEvidence(queryResponse.Value)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, ev)
+	}
+
+	return &EvidencePage{
+		Records:             records,
+		FetchedRecordsCount: responseMetadata.FetchedRecordsCount,
+		Bookmark:            responseMetadata.Bookmark,
+	}, nil
+}
+
+// GetEvidenceByCaseWithPagination returns a page of the evidence associated
+// with a case. Only supported with CouchDB as the state database.
+func (s *SmartContract) GetEvidenceByCaseWithPagination(ctx contractapi.TransactionContextInterface, caseID string, pageSize int, bookmark string) (*EvidencePage, error) {
+	queryString, err := evidenceQueryString(map[string]interface{}{"CaseID": caseID})
+	if err != nil {
+		return nil, err
+	}
+
+	return getEvidencePageForQueryString(ctx, queryString, pageSize, bookmark)
+}
+
+// SearchEvidenceByTagsWithPagination returns a page of the evidence that has
+// all of the given tags. Tags are matched without regard to case. Only
+// supported with CouchDB as the state database.
+func (s *SmartContract) SearchEvidenceByTagsWithPagination(ctx contractapi.TransactionContextInterface, tags []string, pageSize int, bookmark string) (*EvidencePage, error) {
+	selector := map[string]interface{}{}
+	if len(tags) > 0 {
+		var conditions []interface{}
+		for _, tag := range tags {
+			conditions = append(conditions, tagCondition(tag))
+		}
+		selector["$and"] = conditions
+	}
+
+	queryString, err := evidenceQueryString(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	return getEvidencePageForQueryString(ctx, queryString, pageSize, bookmark)
+}
+
+// getEvidencePageForQueryString executes a rich query and returns a page of
+// the evidence it matches
+func getEvidencePageForQueryString(ctx contractapi.TransactionContextInterface, queryString string, pageSize int, bookmark string) (*EvidencePage, error) {
+	size, err := checkPageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetQueryResultWithPagination(queryString, size, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	records := []*Evidence{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		ev, err := decodeEvidence(queryResponse.Value)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, ev)
+	}
+
+	return &EvidencePage{
+		Records:             records,
+		FetchedRecordsCount: responseMetadata.FetchedRecordsCount,
+		Bookmark:            responseMetadata.Bookmark,
+	}, nil
+}
+
+// checkPageSize returns the page size of a paginated query as the peer
+// expects it, rejecting sizes that are not positive or exceed maxPageSize
+func checkPageSize(pageSize int) (int32, error) {
+	if pageSize <= 0 || pageSize > maxPageSize {
+		return 0, fmt.Errorf("page size must be between 1 and %d, not %d", maxPageSize, pageSize)
+	}
+
+	return int32(pageSize), nil
+}
+
+// evidenceQueryString builds a CouchDB query that matches evidence records
+// satisfying the given selector. Values are JSON encoded rather than spliced
+// into the query text, so they cannot change the structure of the query.
+func evidenceQueryString(selector map[string]interface{}) (string, error) {
+	selector["DocType"] = evidenceDocType
+
+	queryJSON, err := json.Marshal(map[string]interface{}{"selector": selector})
+	if err != nil {
+		return "", err
+	}
+
+	return string(queryJSON), nil
+}
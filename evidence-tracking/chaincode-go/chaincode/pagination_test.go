@@ -0,0 +1,107 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// allPages fetches every page of a paginated query, and returns the IDs of
+// the evidence on each page
+func allPages(t *testing.T, ledger *mocks.Ledger, query func(tx *mocks.Transaction, bookmark string) (*chaincode.EvidencePage, error)) [][]string {
+	t.Helper()
+
+	var pages [][]string
+	bookmark := ""
+	for {
+		page := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) (*chaincode.EvidencePage, error) {
+			return query(tx, bookmark)
+		})
+		require.Equal(t, int32(len(page.Records)), page.FetchedRecordsCount)
+		if len(page.Records) > 0 {
+			pages = append(pages, evidenceIDs(page.Records))
+		}
+		if page.Bookmark == "" {
+			return pages
+		}
+		bookmark = page.Bookmark
+		require.Less(t, len(pages), 10, "pagination does not finish")
+	}
+}
+
+func TestGetAllEvidenceWithPagination(t *testing.T) {
+	ledger, contract, _ := setupSearch(t)
+
+	pages := allPages(t, ledger, func(tx *mocks.Transaction, bookmark string) (*chaincode.EvidencePage, error) {
+		return contract.GetAllEvidenceWithPagination(tx, 2, bookmark)
+	})
+	require.Equal(t, [][]string{{"EV001", "EV002"}, {"EV003", "EV004"}, {"EV005"}}, pages)
+}
+
+func TestGetEvidenceByCaseWithPagination(t *testing.T) {
+	ledger, contract, _ := setupSearch(t)
+	err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.CreateCase(tx, "CASE2001", "Harbour fraud", "", "District Court", "officer1", nil)
+	})
+	require.NoError(t, err)
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.SubmitEvidence(tx, "EV006", "Ledger", "CASE2001", "", "QmLedger", nil, "")
+	})
+	require.NoError(t, err)
+
+	pages := allPages(t, ledger, func(tx *mocks.Transaction, bookmark string) (*chaincode.EvidencePage, error) {
+		return contract.GetEvidenceByCaseWithPagination(tx, "CASE1001", 3, bookmark)
+	})
+	require.Equal(t, [][]string{{"EV001", "EV002", "EV003"}, {"EV004", "EV005"}}, pages)
+
+	pages = allPages(t, ledger, func(tx *mocks.Transaction, bookmark string) (*chaincode.EvidencePage, error) {
+		return contract.GetEvidenceByCaseWithPagination(tx, "CASE2001", 3, bookmark)
+	})
+	require.Equal(t, [][]string{{"EV006"}}, pages)
+}
+
+func TestSearchEvidenceByTagsWithPagination(t *testing.T) {
+	ledger, contract, _ := setupSearch(t)
+
+	pages := allPages(t, ledger, func(tx *mocks.Transaction, bookmark string) (*chaincode.EvidencePage, error) {
+		return contract.SearchEvidenceByTagsWithPagination(tx, []string{"KNIFE"}, 1, bookmark)
+	})
+	require.Equal(t, [][]string{{"EV003"}, {"EV005"}}, pages)
+
+	// Tags are not patterns
+	pages = allPages(t, ledger, func(tx *mocks.Transaction, bookmark string) (*chaincode.EvidencePage, error) {
+		return contract.SearchEvidenceByTagsWithPagination(tx, []string{"kn.fe"}, 1, bookmark)
+	})
+	require.Empty(t, pages)
+}
+
+func TestPaginationRejectsPageSize(t *testing.T) {
+	ledger, contract, _ := setupSearch(t)
+
+	for _, pageSize := range []int{0, -1, 1001, 1 << 32} {
+		err := ledger.Evaluate(officer1, func(tx *mocks.Transaction) error {
+			_, err := contract.GetAllEvidenceWithPagination(tx, pageSize, "")
+			return err
+		})
+		require.ErrorContains(t, err, "page size must be between 1 and 1000", pageSize)
+
+		err = ledger.Evaluate(officer1, func(tx *mocks.Transaction) error {
+			_, err := contract.GetEvidenceByCaseWithPagination(tx, "CASE1001", pageSize, "")
+			return err
+		})
+		require.ErrorContains(t, err, "page size must be between 1 and 1000", pageSize)
+	}
+}
+
+func TestPaginationReturnsUndecodableRecords(t *testing.T) {
+	ledger, contract, _ := setupSearch(t)
+	ledger.SetState("evidence~EV004", []byte(`{"DocType": "evidence", "CaseID": "CASE1001", "Tags": "glove"}`))
+
+	err := ledger.Evaluate(officer1, func(tx *mocks.Transaction) error {
+		_, err := contract.GetEvidenceByCaseWithPagination(tx, "CASE1001", 10, "")
+		return err
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,334 @@
+package chaincode
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// privateDetailsCollection is the private data collection, defined in
+// collections_config.json, that holds the sensitive details of evidence
+const privateDetailsCollection = "evidencePrivateDetails"
+
+// privateDetailsTransientKey is the transient map entry that carries private
+// details, so they never appear in the transaction proposal or on the ledger
+const privateDetailsTransientKey = "evidence_private_details"
+
+// privateDetailsMembers are the organizations that are members of the private
+// details collection, as in collections_config.json. Only their peers hold
+// the details, so only they can endorse a transaction that reads or writes
+// them.
+var privateDetailsMembers = []string{"Org1MSP"}
+
+// minSaltLength is the shortest salt accepted with private details. The salt
+// stops anyone who can see the public hash from confirming guesses of the
+// details.
+const minSaltLength = 16
+
+// EvidencePrivateDetails are the sensitive fields of an evidence record, such
+// as victim names and locations, kept in the private data collection
+type EvidencePrivateDetails struct {
+	EvidenceID  string `json:"EvidenceID"`  // ID of the evidence
+	Description string `json:"Description"` // Description of the evidence
+	Metadata    string `json:"Metadata"`    // Additional metadata in JSON format
+	Salt        string `json:"Salt"`        // Random value chosen by the client, hashed along with the details
+}
+
+// SubmitPrivateEvidence issues a new evidence record whose description and
+// metadata are private. They are passed in the transient map under
+// evidence_private_details, stored in the private data collection, and only
+// their hash is put on the public record. The private metadata must match the
+// schema of the evidence type.
+func (s *SmartContract) SubmitPrivateEvidence(
+	ctx contractapi.TransactionContextInterface,
+	id string,
+	caseID string,
+	evidenceType string,
+	fileHash string,
+	tags []string,
+) error {
+	caller, err := authorize(ctx, "SubmitPrivateEvidence")
+	if err != nil {
+		return err
+	}
+
+	details, err := getTransientPrivateDetails(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.submitEvidence(ctx, caller, &Evidence{
+		ID:           id,
+		CaseID:       caseID,
+		EvidenceType: evidenceType,
+		FileHash:     fileHash,
+		Tags:         tags,
+	}, details, nil)
+}
+
+// UpdateEvidencePrivateDetails replaces the private details of an evidence
+// record with those passed in the transient map. The metadata must match the
+// latest schema of the evidence type.
+func (s *SmartContract) UpdateEvidencePrivateDetails(ctx contractapi.TransactionContextInterface, id string) error {
+	caller, err := authorize(ctx, "UpdateEvidencePrivateDetails")
+	if err != nil {
+		return err
+	}
+
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return err
+	}
+	if evidence.PrivateDetailsHash == "" {
+		return fmt.Errorf("evidence %s has no private details", id)
+	}
+	err = checkDetailsUpdatable(evidence)
+	if err != nil {
+		return err
+	}
+
+	details, err := getTransientPrivateDetails(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	typeVersion, err := validateMetadata(ctx, evidenceTypeOf(evidence), details.Metadata)
+	if err != nil {
+		return err
+	}
+
+	previousIntegrity := evidence.Integrity
+	evidence.EvidenceTypeVersion = typeVersion
+	err = putPrivateDetails(ctx, evidence, details)
+	if err != nil {
+		return err
+	}
+	err = putEvidence(ctx, evidence)
+	if err != nil {
+		return err
+	}
+
+	err = recordHistory(ctx, evidence, previousIntegrity, caller.ID, "update", "Private details updated", "")
+	if err != nil {
+		return err
+	}
+
+	return emitEvidenceEvent(ctx, eventEvidenceUpdated, id, caller.ID, evidence)
+}
+
+// MoveEvidenceDetailsToPrivate moves the description and metadata of an
+// evidence record submitted with public details into the private data
+// collection. Only the salt is read from the transient map. The old values
+// remain in the blocks of the transactions that wrote them.
+func (s *SmartContract) MoveEvidenceDetailsToPrivate(ctx contractapi.TransactionContextInterface, id string) error {
+	caller, err := authorize(ctx, "MoveEvidenceDetailsToPrivate")
+	if err != nil {
+		return err
+	}
+
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return err
+	}
+	if evidence.PrivateDetailsHash != "" {
+		return fmt.Errorf("the details of evidence %s are already private", id)
+	}
+
+	details, err := getTransientPrivateDetails(ctx, id)
+	if err != nil {
+		return err
+	}
+	if details.Description != "" || details.Metadata != "" {
+		return fmt.Errorf("only a salt may be given to move the details of evidence %s", id)
+	}
+	details.Description = evidence.Description
+	details.Metadata = evidence.Metadata
+
+	previousIntegrity := evidence.Integrity
+	err = putPrivateDetails(ctx, evidence, details)
+	if err != nil {
+		return err
+	}
+	evidence.Description = ""
+	evidence.Metadata = ""
+	err = putEvidence(ctx, evidence)
+	if err != nil {
+		return err
+	}
+
+	err = recordHistory(ctx, evidence, previousIntegrity, caller.ID, "update", "Description and metadata moved to private details", "")
+	if err != nil {
+		return err
+	}
+
+	return emitEvidenceEvent(ctx, eventEvidenceUpdated, id, caller.ID, evidence)
+}
+
+// ReadEvidencePrivateDetails returns the private details of an evidence
+// record. The client must hold a permitted role and submit the request to a
+// peer of its own organization, which must be a member of the collection.
+func (s *SmartContract) ReadEvidencePrivateDetails(ctx contractapi.TransactionContextInterface, id string) (*EvidencePrivateDetails, error) {
+	_, err := authorize(ctx, "ReadEvidencePrivateDetails")
+	if err != nil {
+		return nil, err
+	}
+
+	err = verifyPeerIsCollectionMember(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if evidence.PrivateDetailsHash == "" {
+		return nil, fmt.Errorf("evidence %s has no private details", id)
+	}
+
+	detailsJSON, err := ctx.GetStub().GetPrivateData(privateDetailsCollection, evidenceKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private details: %v", err)
+	}
+	if detailsJSON == nil {
+		return nil, fmt.Errorf("the private details of evidence %s are not held by this peer", id)
+	}
+
+	digest := sha256.Sum256(detailsJSON)
+	if hex.EncodeToString(digest[:]) != evidence.PrivateDetailsHash {
+		return nil, fmt.Errorf("the private details of evidence %s do not match the hash on the public record", id)
+	}
+
+	var details EvidencePrivateDetails
+	err = json.Unmarshal(detailsJSON, &details)
+	if err != nil {
+		return nil, err
+	}
+
+	return &details, nil
+}
+
+// getTransientPrivateDetails reads the private details of an evidence item
+// from the transient map, and checks that the client may write them through
+// this peer
+func getTransientPrivateDetails(ctx contractapi.TransactionContextInterface, id string) (*EvidencePrivateDetails, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("error getting transient: %v", err)
+	}
+
+	detailsJSON, ok := transientMap[privateDetailsTransientKey]
+	if !ok {
+		return nil, fmt.Errorf("%s not found in the transient map input", privateDetailsTransientKey)
+	}
+
+	var details EvidencePrivateDetails
+	err = json.Unmarshal(detailsJSON, &details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal private details: %v", err)
+	}
+
+	if details.EvidenceID != "" && details.EvidenceID != id {
+		return nil, fmt.Errorf("the private details are for evidence %s, not %s", details.EvidenceID, id)
+	}
+	details.EvidenceID = id
+	if len(details.Salt) < minSaltLength {
+		return nil, fmt.Errorf("the private details need a salt of at least %d characters", minSaltLength)
+	}
+
+	err = verifyPeerIsCollectionMember(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &details, nil
+}
+
+// putPrivateDetails stores the private details of an evidence record in the
+// private data collection and records their hash on the public record, which
+// the caller must then write
+func putPrivateDetails(ctx contractapi.TransactionContextInterface, evidence *Evidence, details *EvidencePrivateDetails) error {
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutPrivateData(privateDetailsCollection, evidenceKey(evidence.ID), detailsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put private details: %v", err)
+	}
+
+	digest := sha256.Sum256(detailsJSON)
+	evidence.PrivateDetailsHash = hex.EncodeToString(digest[:])
+
+	return nil
+}
+
+// privateDetailsMatch compares the hash of the private details, which every
+// peer holds, with the hash on the public record
+func privateDetailsMatch(ctx contractapi.TransactionContextInterface, evidence *Evidence) (bool, error) {
+	detailsHash, err := ctx.GetStub().GetPrivateDataHash(privateDetailsCollection, evidenceKey(evidence.ID))
+	if err != nil {
+		return false, fmt.Errorf("failed to read private details hash: %v", err)
+	}
+
+	expected, err := hex.DecodeString(evidence.PrivateDetailsHash)
+	if err != nil {
+		return false, nil
+	}
+
+	return bytes.Equal(detailsHash, expected), nil
+}
+
+// verifyPeerIsCollectionMember checks that this peer belongs to a member of
+// the private details collection. A peer of another organization holds only
+// the hashes of the details and refuses to endorse the transaction, so the
+// client must send it to a member's peer.
+func verifyPeerIsCollectionMember(ctx contractapi.TransactionContextInterface) error {
+	peerMSPID := os.Getenv("CORE_PEER_LOCALMSPID")
+	if peerMSPID == "" {
+		return fmt.Errorf("failed getting the peer's MSPID: CORE_PEER_LOCALMSPID is not set")
+	}
+	for _, member := range privateDetailsMembers {
+		if peerMSPID == member {
+			return nil
+		}
+	}
+	return fmt.Errorf("an org %v peer is not a member of the %s collection, send the transaction to a peer of %s", peerMSPID, privateDetailsCollection, strings.Join(privateDetailsMembers, " or "))
+}
+
+// verifyClientOrgMatchesPeerOrg checks that the client submitted the request
+// to a peer of its own organization, so that a client from another
+// organization cannot read or write private data through this peer
+func verifyClientOrgMatchesPeerOrg(ctx contractapi.TransactionContextInterface) error {
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed getting the client's MSPID: %v", err)
+	}
+
+	// The peer passes its MSP ID to the chaincode in this variable
+	peerMSPID := os.Getenv("CORE_PEER_LOCALMSPID")
+	if peerMSPID == "" {
+		return fmt.Errorf("failed getting the peer's MSPID: CORE_PEER_LOCALMSPID is not set")
+	}
+
+	if clientMSPID != peerMSPID {
+		return fmt.Errorf("client from org %v is not authorized to read or write private data from an org %v peer", clientMSPID, peerMSPID)
+	}
+
+	return nil
+}
@@ -0,0 +1,257 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// salt is long enough to be accepted with private details
+const salt = "6f1c3a9e2b7d4c80"
+
+// withPrivateDetails passes private details to a transaction in its
+// transient map
+func withPrivateDetails(t *testing.T, tx *mocks.Transaction, details *chaincode.EvidencePrivateDetails) {
+	t.Helper()
+	tx.Stub.Transient["evidence_private_details"] = mustMarshal(t, details)
+}
+
+// submitPrivateEvidence submits EV003 with private details as officer1
+func submitPrivateEvidence(t *testing.T, ledger *mocks.Ledger, contract *chaincode.SmartContract) {
+	t.Helper()
+	err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		withPrivateDetails(t, tx, &chaincode.EvidencePrivateDetails{
+			Description: "Statement of the victim, Jane Roe",
+			Metadata:    `{"documentType": "statement"}`,
+			Salt:        salt,
+		})
+		return contract.SubmitPrivateEvidence(tx, "EV003", "CASE1001", "document", "QmStatement", []string{"statement"})
+	})
+	require.NoError(t, err)
+}
+
+// readPrivateDetails reads the private details of evidence as officer1
+func readPrivateDetails(t *testing.T, ledger *mocks.Ledger, contract *chaincode.SmartContract, id string) *chaincode.EvidencePrivateDetails {
+	t.Helper()
+	return evaluate(t, ledger, officer1, func(tx *mocks.Transaction) (*chaincode.EvidencePrivateDetails, error) {
+		return contract.ReadEvidencePrivateDetails(tx, id)
+	})
+}
+
+func TestSubmitPrivateEvidence(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  *mocks.Client
+		details *chaincode.EvidencePrivateDetails
+		err     string
+	}{
+		{name: "without private details", client: officer1, err: "evidence_private_details not found in the transient map input"},
+		{name: "short salt", client: officer1, details: &chaincode.EvidencePrivateDetails{Metadata: `{"documentType": "statement"}`, Salt: "short"}, err: "the private details need a salt of at least 16 characters"},
+		{name: "details of other evidence", client: officer1, details: &chaincode.EvidencePrivateDetails{EvidenceID: "EV004", Metadata: `{"documentType": "statement"}`, Salt: salt}, err: "the private details are for evidence EV004, not EV003"},
+		{name: "invalid metadata", client: officer1, details: &chaincode.EvidencePrivateDetails{Metadata: `{"pages": 2}`, Salt: salt}, err: "metadata does not match evidence type document version 1: "},
+		{name: "through a peer of another organization", client: newClient("officer3", "Org2MSP", "investigator"), details: &chaincode.EvidencePrivateDetails{Metadata: `{"documentType": "statement"}`, Salt: salt}, err: "client from org Org2MSP is not authorized to read or write private data from an org Org1MSP peer"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("CORE_PEER_LOCALMSPID", "Org1MSP")
+			ledger, contract := setupLedger(t)
+
+			err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+				if test.details != nil {
+					withPrivateDetails(t, tx, test.details)
+				}
+				return contract.SubmitPrivateEvidence(tx, "EV003", "CASE1001", "document", "QmStatement", nil)
+			})
+			require.ErrorContains(t, err, test.err)
+			require.Nil(t, ledger.GetPrivateData("evidencePrivateDetails", "evidence~EV003"))
+		})
+	}
+}
+
+func TestReadEvidencePrivateDetails(t *testing.T) {
+	t.Setenv("CORE_PEER_LOCALMSPID", "Org1MSP")
+	ledger, contract := setupLedger(t)
+	submitPrivateEvidence(t, ledger, contract)
+
+	evidence := readEvidence(t, ledger, contract, "EV003")
+	require.Empty(t, evidence.Description)
+	require.Empty(t, evidence.Metadata)
+	require.NotEmpty(t, evidence.PrivateDetailsHash)
+	require.Equal(t, 1, evidence.EvidenceTypeVersion)
+
+	details := readPrivateDetails(t, ledger, contract, "EV003")
+	require.Equal(t, &chaincode.EvidencePrivateDetails{
+		EvidenceID:  "EV003",
+		Description: "Statement of the victim, Jane Roe",
+		Metadata:    `{"documentType": "statement"}`,
+		Salt:        salt,
+	}, details)
+
+	tests := []struct {
+		name   string
+		client *mocks.Client
+		id     string
+		err    string
+	}{
+		{name: "through a peer of another organization", client: prosecutor, id: "EV003", err: "client from org Org2MSP is not authorized to read or write private data from an org Org1MSP peer"},
+		{name: "role not permitted", client: custodian, id: "EV003", err: "submitting client not authorized to call ReadEvidencePrivateDetails, role custodian is not permitted"},
+		{name: "public evidence", client: officer1, id: "EV001", err: "evidence EV001 has no private details"},
+	}
+	for _, test := range tests {
+		err := ledger.Evaluate(test.client, func(tx *mocks.Transaction) error {
+			_, err := contract.ReadEvidencePrivateDetails(tx, test.id)
+			return err
+		})
+		require.EqualError(t, err, test.err, test.name)
+	}
+}
+
+func TestPrivateDetailsIntegrity(t *testing.T) {
+	t.Setenv("CORE_PEER_LOCALMSPID", "Org1MSP")
+	ledger, contract := setupLedger(t)
+	submitPrivateEvidence(t, ledger, contract)
+
+	valid := submitResult(t, ledger, auditor, func(tx *mocks.Transaction) (bool, error) {
+		return contract.VerifyEvidenceIntegrity(tx, "EV003")
+	})
+	require.True(t, valid)
+
+	ledger.SetPrivateData("evidencePrivateDetails", "evidence~EV003", mustMarshal(t, &chaincode.EvidencePrivateDetails{
+		EvidenceID:  "EV003",
+		Description: "Statement of the victim, John Doe",
+		Metadata:    `{"documentType": "statement"}`,
+		Salt:        salt,
+	}))
+
+	valid = submitResult(t, ledger, auditor, func(tx *mocks.Transaction) (bool, error) {
+		return contract.VerifyEvidenceIntegrity(tx, "EV003")
+	})
+	require.False(t, valid)
+
+	err := ledger.Evaluate(officer1, func(tx *mocks.Transaction) error {
+		_, err := contract.ReadEvidencePrivateDetails(tx, "EV003")
+		return err
+	})
+	require.EqualError(t, err, "the private details of evidence EV003 do not match the hash on the public record")
+}
+
+func TestUpdateEvidencePrivateDetails(t *testing.T) {
+	t.Setenv("CORE_PEER_LOCALMSPID", "Org1MSP")
+	ledger, contract := setupLedger(t)
+	submitPrivateEvidence(t, ledger, contract)
+	previousHash := readEvidence(t, ledger, contract, "EV003").PrivateDetailsHash
+
+	err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		withPrivateDetails(t, tx, &chaincode.EvidencePrivateDetails{Metadata: `{"documentType": "statement"}`, Salt: salt})
+		return contract.UpdateEvidencePrivateDetails(tx, "EV001")
+	})
+	require.EqualError(t, err, "evidence EV001 has no private details")
+
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		withPrivateDetails(t, tx, &chaincode.EvidencePrivateDetails{
+			Description: "Amended statement of the victim, Jane Roe",
+			Metadata:    `{"documentType": "statement", "pages": 3}`,
+			Salt:        salt,
+		})
+		return contract.UpdateEvidencePrivateDetails(tx, "EV003")
+	})
+	require.NoError(t, err)
+
+	require.NotEqual(t, previousHash, readEvidence(t, ledger, contract, "EV003").PrivateDetailsHash)
+	require.Equal(t, "Amended statement of the victim, Jane Roe", readPrivateDetails(t, ledger, contract, "EV003").Description)
+	require.Equal(t, "EvidenceUpdated", lastEvent(t, ledger).Type)
+}
+
+func TestMoveEvidenceDetailsToPrivate(t *testing.T) {
+	t.Setenv("CORE_PEER_LOCALMSPID", "Org1MSP")
+	ledger, contract := setupLedger(t)
+	public := readEvidence(t, ledger, contract, "EV001")
+
+	err := ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		withPrivateDetails(t, tx, &chaincode.EvidencePrivateDetails{Description: "Replaced", Salt: salt})
+		return contract.MoveEvidenceDetailsToPrivate(tx, "EV001")
+	})
+	require.EqualError(t, err, "only a salt may be given to move the details of evidence EV001")
+
+	err = ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		withPrivateDetails(t, tx, &chaincode.EvidencePrivateDetails{Salt: salt})
+		return contract.MoveEvidenceDetailsToPrivate(tx, "EV001")
+	})
+	require.NoError(t, err)
+
+	evidence := readEvidence(t, ledger, contract, "EV001")
+	require.Empty(t, evidence.Description)
+	require.Empty(t, evidence.Metadata)
+
+	details := readPrivateDetails(t, ledger, contract, "EV001")
+	require.Equal(t, public.Description, details.Description)
+	require.Equal(t, public.Metadata, details.Metadata)
+
+	valid := submitResult(t, ledger, auditor, func(tx *mocks.Transaction) (bool, error) {
+		return contract.VerifyEvidenceIntegrity(tx, "EV001")
+	})
+	require.True(t, valid)
+
+	err = ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		withPrivateDetails(t, tx, &chaincode.EvidencePrivateDetails{Salt: salt})
+		return contract.MoveEvidenceDetailsToPrivate(tx, "EV001")
+	})
+	require.EqualError(t, err, "the details of evidence EV001 are already private")
+}
+
+func TestPrivateDetailsOnNonMemberPeer(t *testing.T) {
+	t.Setenv("CORE_PEER_LOCALMSPID", "Org1MSP")
+	ledger, contract := setupLedger(t)
+	submitPrivateEvidence(t, ledger, contract)
+	before := readEvidence(t, ledger, contract, "EV003")
+	detailsBefore := ledger.GetPrivateData("evidencePrivateDetails", "evidence~EV003")
+
+	// An Org2MSP peer holds only the hashes of the details, so it refuses to
+	// endorse, whichever organization the client belongs to. The chaincode
+	// endorsement policy is met by the Org1MSP peers alone.
+	t.Setenv("CORE_PEER_LOCALMSPID", "Org2MSP")
+	details := &chaincode.EvidencePrivateDetails{Description: "Replaced", Metadata: `{"documentType": "statement"}`, Salt: salt}
+	officer3 := newClient("officer3", "Org2MSP", "investigator")
+	admin2 := newClient("admin2", "Org2MSP", "admin")
+	transactions := []struct {
+		name        string
+		clients     []*mocks.Client
+		transaction func(tx *mocks.Transaction) error
+	}{
+		{name: "SubmitPrivateEvidence", clients: []*mocks.Client{officer1, officer3}, transaction: func(tx *mocks.Transaction) error {
+			withPrivateDetails(t, tx, details)
+			return contract.SubmitPrivateEvidence(tx, "EV004", "CASE1001", "document", "QmStatement", nil)
+		}},
+		{name: "UpdateEvidencePrivateDetails", clients: []*mocks.Client{officer1, officer3}, transaction: func(tx *mocks.Transaction) error {
+			withPrivateDetails(t, tx, details)
+			return contract.UpdateEvidencePrivateDetails(tx, "EV003")
+		}},
+		{name: "MoveEvidenceDetailsToPrivate", clients: []*mocks.Client{admin, admin2}, transaction: func(tx *mocks.Transaction) error {
+			withPrivateDetails(t, tx, &chaincode.EvidencePrivateDetails{Salt: salt})
+			return contract.MoveEvidenceDetailsToPrivate(tx, "EV001")
+		}},
+		{name: "ReadEvidencePrivateDetails", clients: []*mocks.Client{officer1, officer3}, transaction: func(tx *mocks.Transaction) error {
+			_, err := contract.ReadEvidencePrivateDetails(tx, "EV003")
+			return err
+		}},
+	}
+	for _, test := range transactions {
+		for _, client := range test.clients {
+			err := ledger.Submit(client, test.transaction)
+			require.EqualError(t, err, "an org Org2MSP peer is not a member of the evidencePrivateDetails collection, send the transaction to a peer of Org1MSP", "%s as %s", test.name, client.ID)
+		}
+	}
+
+	require.Nil(t, ledger.GetState("evidence~EV004"))
+	require.Nil(t, ledger.GetPrivateData("evidencePrivateDetails", "evidence~EV004"))
+	require.Nil(t, ledger.GetPrivateData("evidencePrivateDetails", "evidence~EV001"))
+	require.Equal(t, detailsBefore, ledger.GetPrivateData("evidencePrivateDetails", "evidence~EV003"))
+
+	// The same transactions are endorsed by a peer of the member
+	t.Setenv("CORE_PEER_LOCALMSPID", "Org1MSP")
+	require.Equal(t, before, readEvidence(t, ledger, contract, "EV003"))
+	require.NoError(t, ledger.Submit(officer1, transactions[1].transaction))
+	require.Equal(t, "Replaced", readPrivateDetails(t, ledger, contract, "EV003").Description)
+}
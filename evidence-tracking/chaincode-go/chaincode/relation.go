@@ -0,0 +1,274 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Types of relationship between evidence items. Each relationship points from
+// an item to the item it relates to, such as from a carved file to the disk
+// image it was carved from.
+const (
+	relationDerivedFrom = "derived_from" // Produced by processing the related item
+	relationPartOf      = "part_of"      // A component of the related item
+	relationDuplicateOf = "duplicate_of" // A copy of the related item
+	relationSupersedes  = "supersedes"   // Replaces the related item
+)
+
+// relationTypes are the relationship types LinkEvidence accepts
+var relationTypes = []string{relationDerivedFrom, relationPartOf, relationDuplicateOf, relationSupersedes}
+
+// lineageRelationTypes are the relationships through which an item depends on
+// the integrity of the item it relates to
+var lineageRelationTypes = []string{relationDerivedFrom, relationPartOf}
+
+// EvidenceRelation is a typed link from one evidence item to another
+type EvidenceRelation struct {
+	FromID      string `json:"FromID"`      // ID of the evidence the relationship starts from
+	Type        string `json:"Type"`        // Type of relationship
+	ToID        string `json:"ToID"`        // ID of the related evidence
+	Description string `json:"Description"` // How the items are related
+	CreatedBy   string `json:"CreatedBy"`   // ID of the client that recorded the relationship
+	CreatedAt   string `json:"CreatedAt"`   // When the relationship was recorded
+	TxID        string `json:"TxID"`        // Transaction that recorded the relationship
+}
+
+// RegisterDerivedEvidence issues a new evidence record for an artifact
+// produced from existing evidence, such as a disk image of a seized laptop.
+// The new item belongs to the parent's case, starts in the custody of the
+// parent's custodian, and is linked to the parent by a derived_from
+// relationship.
+func (s *SmartContract) RegisterDerivedEvidence(
+	ctx contractapi.TransactionContextInterface,
+	id string,
+	parentID string,
+	evidenceType string,
+	description string,
+	fileHash string,
+	tags []string,
+	metadata string,
+) error {
+	caller, err := authorize(ctx, "RegisterDerivedEvidence")
+	if err != nil {
+		return err
+	}
+
+	parent, err := s.ReadEvidence(ctx, parentID)
+	if err != nil {
+		return err
+	}
+
+	return s.submitEvidence(ctx, caller, &Evidence{
+		ID:           id,
+		EvidenceType: evidenceType,
+		Description:  description,
+		FileHash:     fileHash,
+		Tags:         tags,
+		Metadata:     metadata,
+	}, nil, parent)
+}
+
+// LinkEvidence records a relationship between two existing evidence items.
+// Relationships cannot form a cycle.
+func (s *SmartContract) LinkEvidence(
+	ctx contractapi.TransactionContextInterface,
+	fromID string,
+	relationType string,
+	toID string,
+	description string,
+) error {
+	caller, err := authorize(ctx, "LinkEvidence")
+	if err != nil {
+		return err
+	}
+
+	if !containsString(relationTypes, relationType) {
+		return fmt.Errorf("unknown relationship type %s, expected one of %v", relationType, relationTypes)
+	}
+	if fromID == toID {
+		return fmt.Errorf("evidence %s cannot be related to itself", fromID)
+	}
+
+	evidence, err := s.ReadEvidence(ctx, fromID)
+	if err != nil {
+		return err
+	}
+	_, err = s.ReadEvidence(ctx, toID)
+	if err != nil {
+		return err
+	}
+
+	key, err := relationKey(ctx, fromID, relationType, toID)
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("evidence %s is already %s evidence %s", fromID, relationType, toID)
+	}
+
+	ancestors, err := getRelatedEvidence(ctx, toID, relationObjectType, relationTypes)
+	if err != nil {
+		return err
+	}
+	for _, relation := range ancestors {
+		if relation.ToID == fromID {
+			return fmt.Errorf("relating evidence %s to %s would form a cycle", fromID, toID)
+		}
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = putRelation(ctx, &EvidenceRelation{
+		FromID:      fromID,
+		Type:        relationType,
+		ToID:        toID,
+		Description: description,
+		CreatedBy:   caller.ID,
+		CreatedAt:   currentTime,
+		TxID:        ctx.GetStub().GetTxID(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return recordHistory(ctx, evidence, evidence.Integrity, caller.ID, "link",
+		fmt.Sprintf("Recorded as %s evidence %s", relationType, toID), "")
+}
+
+// GetEvidenceAncestors returns every relationship leading from an evidence
+// item to the items it relates to, directly or through other items, nearest
+// first
+func (s *SmartContract) GetEvidenceAncestors(ctx contractapi.TransactionContextInterface, id string) ([]*EvidenceRelation, error) {
+	return getRelatedEvidence(ctx, id, relationObjectType, relationTypes)
+}
+
+// GetEvidenceDescendants returns every relationship leading to an evidence
+// item from the items that relate to it, directly or through other items,
+// nearest first
+func (s *SmartContract) GetEvidenceDescendants(ctx contractapi.TransactionContextInterface, id string) ([]*EvidenceRelation, error) {
+	return getRelatedEvidence(ctx, id, relationReverseObjectType, relationTypes)
+}
+
+// getRelatedEvidence walks the relationship graph breadth first from an
+// evidence item, following relationships of the given types forwards through
+// the relation records or backwards through the reverse index, and returns
+// each relationship it crosses once
+func getRelatedEvidence(ctx contractapi.TransactionContextInterface, id string, objectType string, types []string) ([]*EvidenceRelation, error) {
+	relations := []*EvidenceRelation{}
+	visited := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		err := forEachRecord(ctx, objectType, current, func(value []byte) error {
+			var relation EvidenceRelation
+			err := json.Unmarshal(value, &relation)
+			if err != nil {
+				return err
+			}
+			if !containsString(types, relation.Type) {
+				return nil
+			}
+			relations = append(relations, &relation)
+
+			next := relation.ToID
+			if objectType == relationReverseObjectType {
+				next = relation.FromID
+			}
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return relations, nil
+}
+
+// checkAncestors checks the integrity of every item an evidence item was
+// derived from or is part of, directly or through other items. It returns the
+// IDs of those that fail, and of legacy items that cannot be checked because
+// they have not been sealed by MigrateRecords yet. Items that have been
+// disposed of are skipped.
+func (s *SmartContract) checkAncestors(ctx contractapi.TransactionContextInterface, id string) ([]string, []string, error) {
+	relations, err := getRelatedEvidence(ctx, id, relationObjectType, lineageRelationTypes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	failed := []string{}
+	unverifiable := []string{}
+	checked := map[string]bool{}
+	for _, relation := range relations {
+		if checked[relation.ToID] {
+			continue
+		}
+		checked[relation.ToID] = true
+
+		ancestorJSON, err := getEvidenceState(ctx, relation.ToID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ancestorJSON == nil {
+			continue
+		}
+		ancestor, schemaVersion, err := decodeEvidenceLayout(ancestorJSON)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// A record in the current layout is always sealed, so one without a
+		// hash has been changed outside the contract
+		if ancestor.Integrity == "" {
+			if schemaVersion == 0 {
+				unverifiable = append(unverifiable, ancestor.ID)
+			} else {
+				failed = append(failed, ancestor.ID)
+			}
+			continue
+		}
+
+		valid, err := evidenceIntact(ctx, ancestor)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !valid {
+			failed = append(failed, ancestor.ID)
+		}
+	}
+
+	return failed, unverifiable, nil
+}
+
+// putRelation stores a relationship under both of its items, so it can be
+// found from either end
+func putRelation(ctx contractapi.TransactionContextInterface, relation *EvidenceRelation) error {
+	key, err := relationKey(ctx, relation.FromID, relation.Type, relation.ToID)
+	if err != nil {
+		return err
+	}
+	err = putRecord(ctx, key, relation)
+	if err != nil {
+		return err
+	}
+
+	reverseKey, err := relationReverseKey(ctx, relation.ToID, relation.Type, relation.FromID)
+	if err != nil {
+		return err
+	}
+	return putRecord(ctx, reverseKey, relation)
+}
@@ -0,0 +1,183 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// deriveEvidence registers evidence derived from a parent as the analyst
+func deriveEvidence(t *testing.T, ledger *mocks.Ledger, contract *chaincode.SmartContract, id string, parentID string) {
+	t.Helper()
+	err := ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		return contract.RegisterDerivedEvidence(tx, id, parentID, "", "Derived "+id, "Qm"+id, nil, "")
+	})
+	require.NoError(t, err)
+}
+
+// relationIDs returns each relationship as "from type to"
+func relationIDs(relations []*chaincode.EvidenceRelation) []string {
+	var ids []string
+	for _, relation := range relations {
+		ids = append(ids, relation.FromID+" "+relation.Type+" "+relation.ToID)
+	}
+	return ids
+}
+
+func TestRegisterDerivedEvidence(t *testing.T) {
+	ledger, contract := setupLedger(t)
+
+	err := ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		return contract.RegisterDerivedEvidence(tx, "EV003", "EV404", "", "Disk image", "QmImage", nil, "")
+	})
+	require.EqualError(t, err, "the evidence EV404 does not exist")
+
+	deriveEvidence(t, ledger, contract, "EV003", "EV002")
+
+	evidence := readEvidence(t, ledger, contract, "EV003")
+	require.Equal(t, "CASE1001", evidence.CaseID)
+	require.Equal(t, "officer2", evidence.CurrentCustodian)
+	require.Equal(t, "analyst1", evidence.SubmittedBy)
+
+	ancestors := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) ([]*chaincode.EvidenceRelation, error) {
+		return contract.GetEvidenceAncestors(tx, "EV003")
+	})
+	require.Equal(t, []string{"EV003 derived_from EV002"}, relationIDs(ancestors))
+	require.Equal(t, "analyst1", ancestors[0].CreatedBy)
+}
+
+func TestLinkEvidence(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	deriveEvidence(t, ledger, contract, "EV003", "EV001")
+	deriveEvidence(t, ledger, contract, "EV004", "EV003")
+
+	tests := []struct {
+		name         string
+		from         string
+		relationType string
+		to           string
+		err          string
+	}{
+		{name: "unknown type", from: "EV002", relationType: "similar_to", to: "EV001", err: "unknown relationship type similar_to, expected one of [derived_from part_of duplicate_of supersedes]"},
+		{name: "to itself", from: "EV002", relationType: "duplicate_of", to: "EV002", err: "evidence EV002 cannot be related to itself"},
+		{name: "to unknown evidence", from: "EV002", relationType: "duplicate_of", to: "EV404", err: "the evidence EV404 does not exist"},
+		{name: "already related", from: "EV003", relationType: "derived_from", to: "EV001", err: "evidence EV003 is already derived_from evidence EV001"},
+		{name: "cycle", from: "EV001", relationType: "supersedes", to: "EV004", err: "relating evidence EV001 to EV004 would form a cycle"},
+	}
+	for _, test := range tests {
+		err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+			return contract.LinkEvidence(tx, test.from, test.relationType, test.to, "")
+		})
+		require.EqualError(t, err, test.err, test.name)
+	}
+
+	err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.LinkEvidence(tx, "EV004", "duplicate_of", "EV002", "Same image")
+	})
+	require.NoError(t, err)
+
+	ancestors := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) ([]*chaincode.EvidenceRelation, error) {
+		return contract.GetEvidenceAncestors(tx, "EV004")
+	})
+	require.Equal(t, []string{
+		"EV004 derived_from EV003",
+		"EV004 duplicate_of EV002",
+		"EV003 derived_from EV001",
+	}, relationIDs(ancestors))
+
+	descendants := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) ([]*chaincode.EvidenceRelation, error) {
+		return contract.GetEvidenceDescendants(tx, "EV001")
+	})
+	require.Equal(t, []string{
+		"EV003 derived_from EV001",
+		"EV004 derived_from EV003",
+	}, relationIDs(descendants))
+}
+
+func TestVerifyEvidenceIntegrityOfLineage(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	deriveEvidence(t, ledger, contract, "EV003", "EV001")
+	deriveEvidence(t, ledger, contract, "EV004", "EV003")
+	err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.LinkEvidence(tx, "EV004", "duplicate_of", "EV002", "Same image")
+	})
+	require.NoError(t, err)
+
+	// A duplicate does not depend on the item it duplicates
+	tamperEvidence(t, ledger, "EV002", "Description", "Altered")
+	valid := submitResult(t, ledger, auditor, func(tx *mocks.Transaction) (bool, error) {
+		return contract.VerifyEvidenceIntegrity(tx, "EV004")
+	})
+	require.True(t, valid)
+
+	// Derived evidence fails when any item it was derived from fails
+	tamperEvidence(t, ledger, "EV001", "Description", "Altered")
+	valid = submitResult(t, ledger, auditor, func(tx *mocks.Transaction) (bool, error) {
+		return contract.VerifyEvidenceIntegrity(tx, "EV004")
+	})
+	require.False(t, valid)
+
+	event := lastEvent(t, ledger)
+	require.Equal(t, "IntegrityVerified", event.Type)
+	var check chaincode.IntegrityCheck
+	require.NoError(t, json.Unmarshal(event.Payload, &check))
+	require.True(t, check.Valid)
+	require.Equal(t, []string{"EV001"}, check.FailedAncestors)
+}
+
+func TestVerifyEvidenceIntegrityOfLegacyLineage(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	seedLegacyEvidence(t, ledger)
+	ledger.SetState("EV901", []byte(`{"id":"EV901","caseId":"CASE1001","description":"Phone found in the alley","fileHash":"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08","ipfsHash":"QmPhone","submittedBy":"officer2","submittedAt":"2023-05-04T10:30:00.123456789+02:00","status":"PROCESSING"}`))
+	for id, parentID := range map[string]string{"EV003": "EV900", "EV004": "EV901"} {
+		submitEvidence(t, ledger, contract, id)
+		err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+			return contract.LinkEvidence(tx, id, "derived_from", parentID, "")
+		})
+		require.NoError(t, err)
+	}
+
+	// Legacy items that have not been sealed cannot be checked, which is
+	// reported rather than failing the transaction
+	for _, id := range []string{"EV003", "EV004"} {
+		valid := submitResult(t, ledger, auditor, func(tx *mocks.Transaction) (bool, error) {
+			return contract.VerifyEvidenceIntegrity(tx, id)
+		})
+		require.False(t, valid, id)
+
+		var check chaincode.IntegrityCheck
+		require.NoError(t, json.Unmarshal(lastEvent(t, ledger).Payload, &check))
+		require.True(t, check.Valid, id)
+		require.Empty(t, check.FailedAncestors, id)
+		require.Len(t, check.UnverifiableAncestors, 1, id)
+	}
+
+	// Once sealed, the lineage verifies
+	for {
+		count := submitResult(t, ledger, admin, func(tx *mocks.Transaction) (int, error) {
+			return contract.MigrateRecords(tx, 10)
+		})
+		if count == 0 {
+			break
+		}
+	}
+	valid := submitResult(t, ledger, auditor, func(tx *mocks.Transaction) (bool, error) {
+		return contract.VerifyEvidenceIntegrity(tx, "EV003")
+	})
+	require.True(t, valid)
+
+	// A record in the current layout without a hash has been tampered with
+	tamperEvidence(t, ledger, "EV900", "Integrity", "")
+	valid = submitResult(t, ledger, auditor, func(tx *mocks.Transaction) (bool, error) {
+		return contract.VerifyEvidenceIntegrity(tx, "EV003")
+	})
+	require.False(t, valid)
+
+	var check chaincode.IntegrityCheck
+	require.NoError(t, json.Unmarshal(lastEvent(t, ledger).Payload, &check))
+	require.Equal(t, []string{"EV900"}, check.FailedAncestors)
+	require.Empty(t, check.UnverifiableAncestors)
+}
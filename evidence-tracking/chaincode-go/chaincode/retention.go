@@ -0,0 +1,678 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Case types with a default retention policy. Cases created without a type
+// are general cases.
+const (
+	caseTypeGeneral     = "general"
+	caseTypeCivil       = "civil"
+	caseTypeMisdemeanor = "misdemeanor"
+	caseTypeFelony      = "felony"
+	caseTypeHomicide    = "homicide"
+)
+
+// RetentionPolicy is how long the evidence of a type of case must be kept
+// after the case is closed before it may be disposed of
+type RetentionPolicy struct {
+	CaseType      string `json:"CaseType"`      // Type of case the policy applies to
+	RetentionDays int    `json:"RetentionDays"` // Days evidence is kept after the case is closed
+	Indefinite    bool   `json:"Indefinite"`    // Whether evidence must be kept forever
+}
+
+// defaultRetentionPolicies are written to the ledger by InitLedger, and apply
+// to any case type whose policy has not been stored on the ledger
+var defaultRetentionPolicies = []*RetentionPolicy{
+	{CaseType: caseTypeGeneral, RetentionDays: 7 * 365},
+	{CaseType: caseTypeCivil, RetentionDays: 2 * 365},
+	{CaseType: caseTypeMisdemeanor, RetentionDays: 3 * 365},
+	{CaseType: caseTypeFelony, RetentionDays: 10 * 365},
+	{CaseType: caseTypeHomicide, Indefinite: true},
+}
+
+// LegalHold prevents the disposition of evidence, either of every item of a
+// case or of a single item, until it is released
+type LegalHold struct {
+	ID            string `json:"ID"`            // Identifier of the hold, unique within its case
+	CaseID        string `json:"CaseID"`        // Case the hold applies to
+	EvidenceID    string `json:"EvidenceID"`    // Evidence the hold applies to, empty for all evidence of the case
+	Authority     string `json:"Authority"`     // Court or authority that ordered the hold
+	Reason        string `json:"Reason"`        // Why the evidence must be preserved
+	PlacedBy      string `json:"PlacedBy"`      // ID of the client that placed the hold
+	PlacedAt      string `json:"PlacedAt"`      // When the hold was placed
+	Active        bool   `json:"Active"`        // Whether the hold is still in force
+	ReleasedBy    string `json:"ReleasedBy"`    // ID of the client that released the hold
+	ReleasedAt    string `json:"ReleasedAt"`    // When the hold was released
+	ReleaseReason string `json:"ReleaseReason"` // Why the hold was released
+}
+
+// DispositionRequest is the first of the two approvals needed to dispose of
+// an evidence item
+type DispositionRequest struct {
+	EvidenceID    string `json:"EvidenceID"`    // ID of the evidence to dispose of
+	CourtOrderRef string `json:"CourtOrderRef"` // Reference of the court order authorising the disposition
+	Reason        string `json:"Reason"`        // Why the evidence is being disposed of
+	RequestedBy   string `json:"RequestedBy"`   // ID of the client that gave the first approval
+	RequestedAt   string `json:"RequestedAt"`   // When the disposition was requested
+	RequestTxID   string `json:"RequestTxID"`   // Transaction that recorded the request
+}
+
+// EvidenceTombstone is what remains on the ledger of a disposed evidence
+// item. The content of the record is removed, but its final integrity hash is
+// kept so the item's history chain can still be checked.
+type EvidenceTombstone struct {
+	EvidenceID       string `json:"EvidenceID"`       // ID of the disposed evidence
+	CaseID           string `json:"CaseID"`           // Case the evidence belonged to
+	FileHash         string `json:"FileHash"`         // Hash of the evidence file
+	FinalIntegrity   string `json:"FinalIntegrity"`   // Integrity hash of the record when it was disposed of
+	IntegrityVersion int    `json:"IntegrityVersion"` // Version of the algorithm that computed FinalIntegrity
+	CourtOrderRef    string `json:"CourtOrderRef"`    // Reference of the court order authorising the disposition
+	Reason           string `json:"Reason"`           // Why the evidence was disposed of
+	RequestedBy      string `json:"RequestedBy"`      // ID of the client that gave the first approval
+	ApprovedBy       string `json:"ApprovedBy"`       // ID of the client that gave the second approval
+	DisposedAt       string `json:"DisposedAt"`       // When the evidence was disposed of
+	TxID             string `json:"TxID"`             // Transaction that disposed of the evidence
+}
+
+// SetRetentionPolicy stores the retention policy for a case type on the
+// ledger, replacing any existing or default policy for it
+func (s *SmartContract) SetRetentionPolicy(
+	ctx contractapi.TransactionContextInterface,
+	caseType string,
+	retentionDays int,
+	indefinite bool,
+) error {
+	_, err := authorize(ctx, "SetRetentionPolicy")
+	if err != nil {
+		return err
+	}
+
+	if caseType == "" {
+		return fmt.Errorf("a case type is required")
+	}
+	if retentionDays < 0 {
+		return fmt.Errorf("the retention period for %s cases cannot be negative", caseType)
+	}
+
+	return putRetentionPolicy(ctx, &RetentionPolicy{
+		CaseType:      caseType,
+		RetentionDays: retentionDays,
+		Indefinite:    indefinite,
+	})
+}
+
+// GetRetentionPolicies returns the retention policy in force for every case
+// type that has one, whether stored on the ledger or a default
+func (s *SmartContract) GetRetentionPolicies(ctx contractapi.TransactionContextInterface) ([]*RetentionPolicy, error) {
+	policies := map[string]*RetentionPolicy{}
+	for _, policy := range defaultRetentionPolicies {
+		policies[policy.CaseType] = policy
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(retentionPolicyObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var policy RetentionPolicy
+		err = json.Unmarshal(queryResponse.Value, &policy)
+		if err != nil {
+			return nil, err
+		}
+		policies[policy.CaseType] = &policy
+	}
+
+	var result []*RetentionPolicy
+	for _, policy := range policies {
+		result = append(result, policy)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CaseType < result[j].CaseType
+	})
+
+	return result, nil
+}
+
+// PlaceLegalHold places a hold on the evidence of a case, or on a single item
+// of it when an evidence ID is given. Evidence under an active hold cannot be
+// disposed of, released or destroyed.
+func (s *SmartContract) PlaceLegalHold(
+	ctx contractapi.TransactionContextInterface,
+	caseID string,
+	holdID string,
+	evidenceID string,
+	authority string,
+	reason string,
+) error {
+	caller, err := authorize(ctx, "PlaceLegalHold")
+	if err != nil {
+		return err
+	}
+
+	_, err = s.ReadCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if evidenceID != "" {
+		evidence, err := s.ReadEvidence(ctx, evidenceID)
+		if err != nil {
+			return err
+		}
+		if evidence.CaseID != caseID {
+			return fmt.Errorf("the evidence %s does not belong to case %s", evidenceID, caseID)
+		}
+	}
+	if holdID == "" {
+		return fmt.Errorf("a hold ID is required")
+	}
+	if reason == "" {
+		return fmt.Errorf("a reason is required to place a legal hold")
+	}
+
+	existing, err := getLegalHold(ctx, caseID, holdID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("the legal hold %s already exists on case %s", holdID, caseID)
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	return putLegalHold(ctx, &LegalHold{
+		ID:         holdID,
+		CaseID:     caseID,
+		EvidenceID: evidenceID,
+		Authority:  authority,
+		Reason:     reason,
+		PlacedBy:   caller.ID,
+		PlacedAt:   currentTime,
+		Active:     true,
+	})
+}
+
+// ReleaseLegalHold lifts a legal hold. The hold stays on the ledger as a
+// record of when it applied.
+func (s *SmartContract) ReleaseLegalHold(ctx contractapi.TransactionContextInterface, caseID string, holdID string, reason string) error {
+	caller, err := authorize(ctx, "ReleaseLegalHold")
+	if err != nil {
+		return err
+	}
+
+	hold, err := getLegalHold(ctx, caseID, holdID)
+	if err != nil {
+		return err
+	}
+	if hold == nil {
+		return fmt.Errorf("the legal hold %s does not exist on case %s", holdID, caseID)
+	}
+	if !hold.Active {
+		return fmt.Errorf("the legal hold %s on case %s was already released", holdID, caseID)
+	}
+	if reason == "" {
+		return fmt.Errorf("a reason is required to release a legal hold")
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	hold.Active = false
+	hold.ReleasedBy = caller.ID
+	hold.ReleasedAt = currentTime
+	hold.ReleaseReason = reason
+
+	return putLegalHold(ctx, hold)
+}
+
+// GetLegalHolds returns every legal hold placed on a case, active or released
+func (s *SmartContract) GetLegalHolds(ctx contractapi.TransactionContextInterface, caseID string) ([]*LegalHold, error) {
+	holds := []*LegalHold{}
+	err := forEachRecord(ctx, legalHoldObjectType, caseID, func(value []byte) error {
+		var hold LegalHold
+		err := json.Unmarshal(value, &hold)
+		if err != nil {
+			return err
+		}
+		holds = append(holds, &hold)
+		return nil
+	})
+
+	return holds, err
+}
+
+// RequestEvidenceDisposition gives the first approval to dispose of an
+// evidence item under a court order. The evidence must not be under legal
+// hold, and the retention period of its case must have passed.
+func (s *SmartContract) RequestEvidenceDisposition(
+	ctx contractapi.TransactionContextInterface,
+	id string,
+	courtOrderRef string,
+	reason string,
+) error {
+	caller, err := authorize(ctx, "RequestEvidenceDisposition")
+	if err != nil {
+		return err
+	}
+
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if courtOrderRef == "" {
+		return fmt.Errorf("a court order reference is required to dispose of evidence %s", id)
+	}
+	if reason == "" {
+		return fmt.Errorf("a reason is required to dispose of evidence %s", id)
+	}
+
+	pending, err := getDispositionRequest(ctx, id)
+	if err != nil {
+		return err
+	}
+	if pending != nil {
+		return fmt.Errorf("disposition of evidence %s was already requested by %s", id, pending.RequestedBy)
+	}
+
+	err = s.checkDisposable(ctx, evidence)
+	if err != nil {
+		return err
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, err := dispositionKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = putRecord(ctx, key, &DispositionRequest{
+		EvidenceID:    id,
+		CourtOrderRef: courtOrderRef,
+		Reason:        reason,
+		RequestedBy:   caller.ID,
+		RequestedAt:   currentTime,
+		RequestTxID:   ctx.GetStub().GetTxID(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return recordHistory(ctx, evidence, evidence.Integrity, caller.ID, "disposition_request",
+		fmt.Sprintf("Disposition requested under court order '%s': %s", courtOrderRef, reason), "")
+}
+
+// CancelEvidenceDisposition withdraws a pending disposition request
+func (s *SmartContract) CancelEvidenceDisposition(ctx contractapi.TransactionContextInterface, id string, reason string) error {
+	caller, err := authorize(ctx, "CancelEvidenceDisposition")
+	if err != nil {
+		return err
+	}
+
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	pending, err := getDispositionRequest(ctx, id)
+	if err != nil {
+		return err
+	}
+	if pending == nil {
+		return fmt.Errorf("evidence %s has no pending disposition request", id)
+	}
+
+	key, err := dispositionKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(key)
+	if err != nil {
+		return err
+	}
+
+	return recordHistory(ctx, evidence, evidence.Integrity, caller.ID, "disposition_cancel",
+		fmt.Sprintf("Disposition under court order '%s' cancelled: %s", pending.CourtOrderRef, reason), "")
+}
+
+// DisposeEvidence gives the second approval to a pending disposition and
+// disposes of the evidence. The approver must confirm the court order
+// reference and must not be the client that requested the disposition. The
+// evidence record is replaced by a tombstone; its history, custody chain and
+// other sub-records are kept. Private details are purged from the private
+// data collection.
+func (s *SmartContract) DisposeEvidence(ctx contractapi.TransactionContextInterface, id string, courtOrderRef string) (*EvidenceTombstone, error) {
+	caller, err := authorize(ctx, "DisposeEvidence")
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := getDispositionRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pending == nil {
+		return nil, fmt.Errorf("disposition of evidence %s has not been requested", id)
+	}
+	if courtOrderRef != pending.CourtOrderRef {
+		return nil, fmt.Errorf("court order reference does not match the disposition request for evidence %s", id)
+	}
+	if caller.ID == pending.RequestedBy {
+		return nil, fmt.Errorf("disposition of evidence %s must be approved by a second party", id)
+	}
+
+	// Holds may have been placed since the disposition was requested
+	err = s.checkDisposable(ctx, evidence)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tombstone := &EvidenceTombstone{
+		EvidenceID:       id,
+		CaseID:           evidence.CaseID,
+		FileHash:         evidence.FileHash,
+		FinalIntegrity:   evidence.Integrity,
+		IntegrityVersion: evidence.IntegrityVersion,
+		CourtOrderRef:    pending.CourtOrderRef,
+		Reason:           pending.Reason,
+		RequestedBy:      pending.RequestedBy,
+		ApprovedBy:       caller.ID,
+		DisposedAt:       currentTime,
+		TxID:             ctx.GetStub().GetTxID(),
+	}
+	key, err := tombstoneKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = putRecord(ctx, key, tombstone)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err = dispositionKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().DelState(key)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().DelState(evidenceKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if evidence.PrivateDetailsHash != "" {
+		err = ctx.GetStub().PurgePrivateData(privateDetailsCollection, evidenceKey(id))
+		if err != nil {
+			return nil, fmt.Errorf("failed to purge private details: %v", err)
+		}
+	}
+	legacyJSON, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if legacyJSON != nil {
+		err = ctx.GetStub().DelState(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = recordHistory(ctx, evidence, evidence.Integrity, caller.ID, "dispose",
+		fmt.Sprintf("Evidence disposed of under court order '%s', approved by '%s' and '%s'", pending.CourtOrderRef, pending.RequestedBy, caller.ID), "")
+	if err != nil {
+		return nil, err
+	}
+
+	err = emitEvidenceEvent(ctx, eventEvidenceDisposed, id, caller.ID, tombstone)
+	if err != nil {
+		return nil, err
+	}
+
+	return tombstone, nil
+}
+
+// GetEvidenceTombstone returns the tombstone of a disposed evidence item
+func (s *SmartContract) GetEvidenceTombstone(ctx contractapi.TransactionContextInterface, id string) (*EvidenceTombstone, error) {
+	tombstone, err := getTombstone(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tombstone == nil {
+		return nil, fmt.Errorf("the evidence %s has not been disposed of", id)
+	}
+
+	return tombstone, nil
+}
+
+// checkDisposable returns an error if an evidence item is under legal hold,
+// or if the retention period of its case has not yet passed
+func (s *SmartContract) checkDisposable(ctx contractapi.TransactionContextInterface, evidence *Evidence) error {
+	err := checkNoLegalHold(ctx, evidence)
+	if err != nil {
+		return err
+	}
+
+	c, err := s.ReadCase(ctx, evidence.CaseID)
+	if err != nil {
+		return err
+	}
+	if c.Status != caseStatusClosed && c.Status != caseStatusArchived {
+		return fmt.Errorf("evidence %s cannot be disposed of while case %s is %s", evidence.ID, c.ID, c.Status)
+	}
+
+	policy, err := getRetentionPolicy(ctx, caseTypeOf(c))
+	if err != nil {
+		return err
+	}
+	if policy.Indefinite {
+		return fmt.Errorf("evidence of %s cases must be retained indefinitely", policy.CaseType)
+	}
+
+	closedAt, err := time.Parse(time.RFC3339, c.ClosedAt)
+	if err != nil {
+		return fmt.Errorf("case %s has no valid closing time: %v", c.ID, err)
+	}
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	now, err := time.Parse(time.RFC3339, currentTime)
+	if err != nil {
+		return err
+	}
+
+	retainUntil := closedAt.AddDate(0, 0, policy.RetentionDays)
+	if now.Before(retainUntil) {
+		return fmt.Errorf("evidence %s must be retained until %s", evidence.ID, retainUntil.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// checkNoLegalHold returns an error if an active legal hold applies to an
+// evidence item
+func checkNoLegalHold(ctx contractapi.TransactionContextInterface, evidence *Evidence) error {
+	var held *LegalHold
+	err := forEachRecord(ctx, legalHoldObjectType, evidence.CaseID, func(value []byte) error {
+		var hold LegalHold
+		err := json.Unmarshal(value, &hold)
+		if err != nil {
+			return err
+		}
+		if held == nil && hold.Active && (hold.EvidenceID == "" || hold.EvidenceID == evidence.ID) {
+			held = &hold
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if held != nil {
+		return fmt.Errorf("evidence %s is under legal hold %s ordered by %s", evidence.ID, held.ID, held.Authority)
+	}
+	return nil
+}
+
+// caseTypeOf returns the type of a case, treating untyped cases as general
+func caseTypeOf(c *Case) string {
+	if c.CaseType == "" {
+		return caseTypeGeneral
+	}
+	return c.CaseType
+}
+
+// getRetentionPolicy returns the policy stored on the ledger for a case type,
+// falling back to the default policy. Case types without any policy are
+// rejected.
+func getRetentionPolicy(ctx contractapi.TransactionContextInterface, caseType string) (*RetentionPolicy, error) {
+	key, err := retentionPolicyKey(ctx, caseType)
+	if err != nil {
+		return nil, err
+	}
+
+	policyJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retention policy: %v", err)
+	}
+	if policyJSON != nil {
+		var policy RetentionPolicy
+		err = json.Unmarshal(policyJSON, &policy)
+		if err != nil {
+			return nil, err
+		}
+		return &policy, nil
+	}
+
+	for _, policy := range defaultRetentionPolicies {
+		if policy.CaseType == caseType {
+			return policy, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no retention policy is defined for %s cases", caseType)
+}
+
+// putRetentionPolicy stores a retention policy under its case type
+func putRetentionPolicy(ctx contractapi.TransactionContextInterface, policy *RetentionPolicy) error {
+	key, err := retentionPolicyKey(ctx, policy.CaseType)
+	if err != nil {
+		return err
+	}
+	return putRecord(ctx, key, policy)
+}
+
+// getLegalHold returns a legal hold, or nil if it does not exist
+func getLegalHold(ctx contractapi.TransactionContextInterface, caseID string, holdID string) (*LegalHold, error) {
+	key, err := legalHoldKey(ctx, caseID, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	holdJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if holdJSON == nil {
+		return nil, nil
+	}
+
+	var hold LegalHold
+	err = json.Unmarshal(holdJSON, &hold)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+// putLegalHold stores a legal hold under its case
+func putLegalHold(ctx contractapi.TransactionContextInterface, hold *LegalHold) error {
+	key, err := legalHoldKey(ctx, hold.CaseID, hold.ID)
+	if err != nil {
+		return err
+	}
+	return putRecord(ctx, key, hold)
+}
+
+// getDispositionRequest returns the pending disposition request of an
+// evidence item, or nil if there is none
+func getDispositionRequest(ctx contractapi.TransactionContextInterface, evidenceID string) (*DispositionRequest, error) {
+	key, err := dispositionKey(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	requestJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if requestJSON == nil {
+		return nil, nil
+	}
+
+	var request DispositionRequest
+	err = json.Unmarshal(requestJSON, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+// getTombstone returns the tombstone of an evidence item, or nil if the item
+// has not been disposed of
+func getTombstone(ctx contractapi.TransactionContextInterface, evidenceID string) (*EvidenceTombstone, error) {
+	key, err := tombstoneKey(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	tombstoneJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if tombstoneJSON == nil {
+		return nil, nil
+	}
+
+	var tombstone EvidenceTombstone
+	err = json.Unmarshal(tombstoneJSON, &tombstone)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tombstone, nil
+}
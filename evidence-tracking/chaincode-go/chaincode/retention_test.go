@@ -0,0 +1,296 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// closeCase closes CASE1001 and waits out the ten year retention period of
+// felony cases
+func closeCase(t *testing.T, ledger *mocks.Ledger, contract *chaincode.SmartContract) {
+	t.Helper()
+	updateCaseStatus(t, ledger, contract, "CASE1001", "closed")
+	ledger.Advance(10 * 365 * 24 * time.Hour)
+}
+
+// requestDisposition gives the first approval to dispose of an evidence item
+// as the custodian
+func requestDisposition(t *testing.T, ledger *mocks.Ledger, contract *chaincode.SmartContract, id string) {
+	t.Helper()
+	err := ledger.Submit(custodian, func(tx *mocks.Transaction) error {
+		return contract.RequestEvidenceDisposition(tx, id, "ORDER-42", "Retention period passed")
+	})
+	require.NoError(t, err)
+}
+
+func TestSetRetentionPolicy(t *testing.T) {
+	ledger, contract := setupLedger(t)
+
+	err := ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.SetRetentionPolicy(tx, "civil", 30, false)
+	})
+	require.NoError(t, err)
+	err = ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.SetRetentionPolicy(tx, "maritime", 0, true)
+	})
+	require.NoError(t, err)
+
+	policies := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.RetentionPolicy, error) {
+		return contract.GetRetentionPolicies(tx)
+	})
+	require.Equal(t, []*chaincode.RetentionPolicy{
+		{CaseType: "civil", RetentionDays: 30},
+		{CaseType: "felony", RetentionDays: 10 * 365},
+		{CaseType: "general", RetentionDays: 7 * 365},
+		{CaseType: "homicide", Indefinite: true},
+		{CaseType: "maritime", Indefinite: true},
+		{CaseType: "misdemeanor", RetentionDays: 3 * 365},
+	}, policies)
+
+	// Cases of the new type can now be created
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.CreateCase(tx, "CASE2001", "Cargo theft", "maritime", "Admiralty Court", "officer1", nil)
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		client   *mocks.Client
+		caseType string
+		days     int
+		err      string
+	}{
+		{name: "without case type", client: admin, days: 30, err: "a case type is required"},
+		{name: "negative period", client: admin, caseType: "civil", days: -1, err: "the retention period for civil cases cannot be negative"},
+		{name: "role not permitted", client: prosecutor, caseType: "civil", days: 30, err: "submitting client not authorized to call SetRetentionPolicy, role prosecutor is not permitted"},
+	}
+	for _, test := range tests {
+		err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+			return contract.SetRetentionPolicy(tx, test.caseType, test.days, false)
+		})
+		require.EqualError(t, err, test.err, test.name)
+	}
+}
+
+func TestLegalHold(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.CreateCase(tx, "CASE2001", "Harbour fraud", "", "District Court", "officer1", nil)
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		holdID     string
+		caseID     string
+		evidenceID string
+		reason     string
+		err        string
+	}{
+		{name: "unknown case", holdID: "HOLD1", caseID: "CASE404", reason: "Appeal", err: "the case CASE404 does not exist"},
+		{name: "evidence of another case", holdID: "HOLD1", caseID: "CASE2001", evidenceID: "EV001", reason: "Appeal", err: "the evidence EV001 does not belong to case CASE2001"},
+		{name: "without hold ID", caseID: "CASE1001", reason: "Appeal", err: "a hold ID is required"},
+		{name: "without reason", holdID: "HOLD1", caseID: "CASE1001", err: "a reason is required to place a legal hold"},
+	}
+	for _, test := range tests {
+		err := ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+			return contract.PlaceLegalHold(tx, test.caseID, test.holdID, test.evidenceID, "Court of Appeal", test.reason)
+		})
+		require.EqualError(t, err, test.err, test.name)
+	}
+
+	err = ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+		return contract.PlaceLegalHold(tx, "CASE1001", "HOLD1", "", "Court of Appeal", "Pending appeal")
+	})
+	require.NoError(t, err)
+	err = ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.PlaceLegalHold(tx, "CASE1001", "HOLD1", "EV002", "Court of Appeal", "Pending appeal")
+	})
+	require.EqualError(t, err, "the legal hold HOLD1 already exists on case CASE1001")
+
+	// A hold on the whole case applies to each of its items
+	closeCase(t, ledger, contract)
+	err = ledger.Submit(custodian, func(tx *mocks.Transaction) error {
+		return contract.RequestEvidenceDisposition(tx, "EV002", "ORDER-42", "Retention period passed")
+	})
+	require.EqualError(t, err, "evidence EV002 is under legal hold HOLD1 ordered by Court of Appeal")
+
+	err = ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+		return contract.ReleaseLegalHold(tx, "CASE1001", "HOLD1", "")
+	})
+	require.EqualError(t, err, "a reason is required to release a legal hold")
+	err = ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+		return contract.ReleaseLegalHold(tx, "CASE1001", "HOLD1", "Appeal dismissed")
+	})
+	require.NoError(t, err)
+	err = ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+		return contract.ReleaseLegalHold(tx, "CASE1001", "HOLD1", "Appeal dismissed")
+	})
+	require.EqualError(t, err, "the legal hold HOLD1 on case CASE1001 was already released")
+	err = ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+		return contract.ReleaseLegalHold(tx, "CASE1001", "HOLD2", "Appeal dismissed")
+	})
+	require.EqualError(t, err, "the legal hold HOLD2 does not exist on case CASE1001")
+
+	holds := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.LegalHold, error) {
+		return contract.GetLegalHolds(tx, "CASE1001")
+	})
+	require.Len(t, holds, 1)
+	require.False(t, holds[0].Active)
+	require.Equal(t, "prosecutor1", holds[0].PlacedBy)
+	require.Equal(t, "prosecutor1", holds[0].ReleasedBy)
+	require.Equal(t, "Appeal dismissed", holds[0].ReleaseReason)
+
+	requestDisposition(t, ledger, contract, "EV002")
+}
+
+func TestRequestEvidenceDisposition(t *testing.T) {
+	tests := []struct {
+		name          string
+		closed        bool
+		elapsed       time.Duration
+		courtOrderRef string
+		reason        string
+		err           string
+	}{
+		{name: "open case", courtOrderRef: "ORDER-42", reason: "Done", err: "evidence EV001 cannot be disposed of while case CASE1001 is open"},
+		{name: "within retention period", closed: true, elapsed: 365 * 24 * time.Hour, courtOrderRef: "ORDER-42", reason: "Done", err: "evidence EV001 must be retained until 2033-12-29T09:00:01Z"},
+		{name: "without court order", closed: true, elapsed: 10 * 365 * 24 * time.Hour, reason: "Done", err: "a court order reference is required to dispose of evidence EV001"},
+		{name: "without reason", closed: true, elapsed: 10 * 365 * 24 * time.Hour, courtOrderRef: "ORDER-42", err: "a reason is required to dispose of evidence EV001"},
+		{name: "after retention period", closed: true, elapsed: 10 * 365 * 24 * time.Hour, courtOrderRef: "ORDER-42", reason: "Done"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, contract := setupLedger(t)
+			if test.closed {
+				updateCaseStatus(t, ledger, contract, "CASE1001", "closed")
+			}
+			ledger.Advance(test.elapsed)
+
+			err := ledger.Submit(custodian, func(tx *mocks.Transaction) error {
+				return contract.RequestEvidenceDisposition(tx, "EV001", test.courtOrderRef, test.reason)
+			})
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+
+			err = ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+				return contract.RequestEvidenceDisposition(tx, "EV001", test.courtOrderRef, test.reason)
+			})
+			require.EqualError(t, err, "disposition of evidence EV001 was already requested by custodian1")
+		})
+	}
+}
+
+func TestRequestEvidenceDispositionOfIndefiniteRetention(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	err := ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.SetRetentionPolicy(tx, "felony", 0, true)
+	})
+	require.NoError(t, err)
+	closeCase(t, ledger, contract)
+
+	err = ledger.Submit(custodian, func(tx *mocks.Transaction) error {
+		return contract.RequestEvidenceDisposition(tx, "EV001", "ORDER-42", "Retention period passed")
+	})
+	require.EqualError(t, err, "evidence of felony cases must be retained indefinitely")
+}
+
+func TestCancelEvidenceDisposition(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	closeCase(t, ledger, contract)
+
+	err := ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.CancelEvidenceDisposition(tx, "EV001", "Mistake")
+	})
+	require.EqualError(t, err, "evidence EV001 has no pending disposition request")
+
+	requestDisposition(t, ledger, contract, "EV001")
+	err = ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.CancelEvidenceDisposition(tx, "EV001", "Mistake")
+	})
+	require.NoError(t, err)
+
+	err = ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+		_, err := contract.DisposeEvidence(tx, "EV001", "ORDER-42")
+		return err
+	})
+	require.EqualError(t, err, "disposition of evidence EV001 has not been requested")
+}
+
+func TestDisposeEvidence(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	closeCase(t, ledger, contract)
+	requestDisposition(t, ledger, contract, "EV001")
+	integrity := readEvidence(t, ledger, contract, "EV001").Integrity
+
+	tests := []struct {
+		name          string
+		client        *mocks.Client
+		courtOrderRef string
+		err           string
+	}{
+		{name: "by the requester", client: custodian, courtOrderRef: "ORDER-42", err: "disposition of evidence EV001 must be approved by a second party"},
+		{name: "under another court order", client: prosecutor, courtOrderRef: "ORDER-43", err: "court order reference does not match the disposition request for evidence EV001"},
+		{name: "role not permitted", client: auditor, courtOrderRef: "ORDER-42", err: "submitting client not authorized to call DisposeEvidence, role auditor is not permitted"},
+	}
+	for _, test := range tests {
+		err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+			_, err := contract.DisposeEvidence(tx, "EV001", test.courtOrderRef)
+			return err
+		})
+		require.EqualError(t, err, test.err, test.name)
+	}
+
+	disposedAt := ledger.Now().Format(time.RFC3339)
+	tombstone := submitResult(t, ledger, prosecutor, func(tx *mocks.Transaction) (*chaincode.EvidenceTombstone, error) {
+		return contract.DisposeEvidence(tx, "EV001", "ORDER-42")
+	})
+	require.Equal(t, "CASE1001", tombstone.CaseID)
+	require.Equal(t, integrity, tombstone.FinalIntegrity)
+	require.Equal(t, "custodian1", tombstone.RequestedBy)
+	require.Equal(t, "prosecutor1", tombstone.ApprovedBy)
+	require.Equal(t, disposedAt, tombstone.DisposedAt)
+
+	event := lastEvent(t, ledger)
+	require.Equal(t, "EvidenceDisposed", event.Type)
+	var emitted chaincode.EvidenceTombstone
+	require.NoError(t, json.Unmarshal(event.Payload, &emitted))
+	require.Equal(t, *tombstone, emitted)
+
+	stored := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.EvidenceTombstone, error) {
+		return contract.GetEvidenceTombstone(tx, "EV001")
+	})
+	require.Equal(t, tombstone, stored)
+
+	err := ledger.Evaluate(officer1, func(tx *mocks.Transaction) error {
+		_, err := contract.ReadEvidence(tx, "EV001")
+		return err
+	})
+	require.EqualError(t, err, "the evidence EV001 was disposed of at "+disposedAt+" under court order ORDER-42")
+
+	// The ID of disposed evidence cannot be reused
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.SubmitEvidence(tx, "EV001", "Reused", "CASE1001", "", "QmReused", nil, "")
+	})
+	require.EqualError(t, err, "the evidence EV001 was disposed of at "+disposedAt)
+
+	// The history of disposed evidence is kept
+	history := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.EvidenceHistory, error) {
+		return contract.GetEvidenceHistory(tx, "EV001")
+	})
+	require.Equal(t, "dispose", history[len(history)-1].Action)
+
+	err = ledger.Evaluate(auditor, func(tx *mocks.Transaction) error {
+		_, err := contract.GetEvidenceTombstone(tx, "EV002")
+		return err
+	})
+	require.EqualError(t, err, "the evidence EV002 has not been disposed of")
+}
@@ -0,0 +1,221 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// searchSortFields are the fields search results may be sorted on. Each is
+// the last field of a CouchDB index shipped in META-INF, so sorted queries
+// can be served from an index.
+var searchSortFields = []string{"SubmittedTime", "StatusUpdatedTime"}
+
+// EvidenceSearch describes a structured evidence search. Every criterion is
+// optional, and a record must satisfy all the criteria that are given. Tags
+// are matched without regard to case.
+type EvidenceSearch struct {
+	CaseID         string   `json:"CaseID,omitempty"`         // Evidence of this case only
+	Statuses       []string `json:"Statuses,omitempty"`       // Evidence in any of these statuses
+	AnyTags        []string `json:"AnyTags,omitempty"`        // Evidence with at least one of these tags
+	AllTags        []string `json:"AllTags,omitempty"`        // Evidence with every one of these tags
+	SubmittedBy    string   `json:"SubmittedBy,omitempty"`    // Evidence submitted by this client
+	SubmittedAfter string   `json:"SubmittedAfter,omitempty"` // Evidence submitted at or after this RFC 3339 time
+	SubmittedUntil string   `json:"SubmittedUntil,omitempty"` // Evidence submitted at or before this RFC 3339 time
+	Custodian      string   `json:"Custodian,omitempty"`      // Evidence currently held by this custodian
+	ProofVerified  *bool    `json:"ProofVerified,omitempty"`  // Filter on the zero-knowledge proof flag, when given
+	AIVerified     *bool    `json:"AIVerified,omitempty"`     // Filter on the AI verification flag, when given
+	SortBy         string   `json:"SortBy,omitempty"`         // SubmittedTime or StatusUpdatedTime
+	SortDescending bool     `json:"SortDescending,omitempty"` // Sort newest first
+	Limit          int      `json:"Limit,omitempty"`          // Maximum number of results, 0 for no limit; ignored by paginated searches
+}
+
+// SearchEvidenceAdvanced returns the evidence matching a structured search,
+// given as the JSON of an EvidenceSearch. Only supported with CouchDB as the
+// state database.
+func (s *SmartContract) SearchEvidenceAdvanced(ctx contractapi.TransactionContextInterface, searchJSON string) ([]*Evidence, error) {
+	search, err := parseEvidenceSearch(searchJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	queryString, err := buildSearchQuery(search, true)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	evidence := []*Evidence{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		ev, err := decodeEvidence(queryResponse.Value)
+		if err != nil {
+			return nil, err
+		}
+		evidence = append(evidence, ev)
+	}
+
+	return evidence, nil
+}
+
+// SearchEvidenceAdvancedWithPagination returns a page of the evidence matching
+// a structured search. The page size takes the place of the search limit.
+func (s *SmartContract) SearchEvidenceAdvancedWithPagination(ctx contractapi.TransactionContextInterface, searchJSON string, pageSize int, bookmark string) (*EvidencePage, error) {
+	search, err := parseEvidenceSearch(searchJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	queryString, err := buildSearchQuery(search, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return getEvidencePageForQueryString(ctx, queryString, pageSize, bookmark)
+}
+
+// parseEvidenceSearch decodes the JSON of an EvidenceSearch. The contract API
+// cannot pass optional flags as *bool, so searches are passed as JSON and
+// decoded here. Unknown fields are rejected, as the contract API would.
+func parseEvidenceSearch(searchJSON string) (*EvidenceSearch, error) {
+	decoder := json.NewDecoder(strings.NewReader(searchJSON))
+	decoder.DisallowUnknownFields()
+
+	var search EvidenceSearch
+	err := decoder.Decode(&search)
+	if err != nil {
+		return nil, fmt.Errorf("invalid evidence search: %v", err)
+	}
+
+	return &search, nil
+}
+
+// buildSearchQuery turns a structured search into a CouchDB query. The search
+// values are JSON encoded into the selector, never spliced into query text.
+func buildSearchQuery(search *EvidenceSearch, withLimit bool) (string, error) {
+	selector := map[string]interface{}{"DocType": evidenceDocType}
+	var conditions []interface{}
+
+	if search.CaseID != "" {
+		selector["CaseID"] = search.CaseID
+	}
+	if len(search.Statuses) > 0 {
+		selector["Status"] = map[string]interface{}{"$in": search.Statuses}
+	}
+	if len(search.AnyTags) > 0 {
+		conditions = append(conditions, tagCondition(search.AnyTags...))
+	}
+	for _, tag := range search.AllTags {
+		conditions = append(conditions, tagCondition(tag))
+	}
+	if search.SubmittedBy != "" {
+		selector["SubmittedBy"] = search.SubmittedBy
+	}
+	if search.Custodian != "" {
+		selector["CurrentCustodian"] = search.Custodian
+	}
+
+	// Timestamps are stored as UTC RFC 3339 strings, which sort in time order
+	submittedTime := map[string]interface{}{}
+	if search.SubmittedAfter != "" {
+		after, err := normalizeSearchTime(search.SubmittedAfter)
+		if err != nil {
+			return "", err
+		}
+		submittedTime["$gte"] = after
+	}
+	if search.SubmittedUntil != "" {
+		until, err := normalizeSearchTime(search.SubmittedUntil)
+		if err != nil {
+			return "", err
+		}
+		submittedTime["$lte"] = until
+	}
+
+	if search.ProofVerified != nil {
+		selector["ProofVerified"] = *search.ProofVerified
+	}
+	if search.AIVerified != nil {
+		selector["AIVerified"] = *search.AIVerified
+	}
+
+	query := map[string]interface{}{}
+	if search.SortBy != "" {
+		if !containsString(searchSortFields, search.SortBy) {
+			return "", fmt.Errorf("cannot sort on %s, sortable fields are %v", search.SortBy, searchSortFields)
+		}
+		direction := "asc"
+		if search.SortDescending {
+			direction = "desc"
+		}
+		query["sort"] = []interface{}{map[string]string{search.SortBy: direction}}
+
+		// CouchDB only sorts on fields that the selector constrains
+		if search.SortBy != "SubmittedTime" {
+			selector[search.SortBy] = map[string]interface{}{"$gt": nil}
+		} else if len(submittedTime) == 0 {
+			submittedTime["$gt"] = nil
+		}
+	}
+	if len(submittedTime) > 0 {
+		selector["SubmittedTime"] = submittedTime
+	}
+	if len(conditions) > 0 {
+		selector["$and"] = conditions
+	}
+
+	if search.Limit < 0 {
+		return "", fmt.Errorf("limit must not be negative")
+	}
+	if withLimit && search.Limit > 0 {
+		query["limit"] = search.Limit
+	}
+
+	query["selector"] = selector
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return "", err
+	}
+
+	return string(queryJSON), nil
+}
+
+// tagCondition returns a selector condition matching evidence with any of the
+// given tags, without regard to case. The tags are quoted, so they match
+// literally rather than as patterns.
+func tagCondition(tags ...string) map[string]interface{} {
+	var quoted []string
+	for _, tag := range tags {
+		quoted = append(quoted, regexp.QuoteMeta(tag))
+	}
+
+	return map[string]interface{}{
+		"Tags": map[string]interface{}{
+			"$elemMatch": map[string]interface{}{"$regex": "(?i)^(?:" + strings.Join(quoted, "|") + ")$"},
+		},
+	}
+}
+
+// normalizeSearchTime parses an RFC 3339 time and formats it the way
+// transaction timestamps are stored, so that string comparison orders it
+// correctly against stored times
+func normalizeSearchTime(value string) (string, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return "", fmt.Errorf("invalid search time %q: %v", value, err)
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
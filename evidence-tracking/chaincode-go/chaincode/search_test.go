@@ -0,0 +1,130 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// setupSearch adds three items to the evidence created by InitLedger and
+// returns the time before the first of them was submitted
+func setupSearch(t *testing.T) (*mocks.Ledger, *chaincode.SmartContract, string) {
+	t.Helper()
+
+	ledger, contract := setupLedger(t)
+	submittedAfter := ledger.Now().Format("2006-01-02T15:04:05Z")
+	submitEvidence(t, ledger, contract, "EV003", "knife", "blood")
+	submitEvidence(t, ledger, contract, "EV004", "glove")
+	submitEvidence(t, ledger, contract, "EV005", "knife", "glove")
+
+	return ledger, contract, submittedAfter
+}
+
+// evidenceIDs returns the IDs of evidence records in order
+func evidenceIDs(records []*chaincode.Evidence) []string {
+	ids := []string{}
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	return ids
+}
+
+// searchJSON encodes a search the way clients pass it to the contract
+func searchJSON(t *testing.T, search chaincode.EvidenceSearch) string {
+	t.Helper()
+
+	searchJSON, err := json.Marshal(search)
+	require.NoError(t, err)
+
+	return string(searchJSON)
+}
+
+// flag returns a pointer to a search flag
+func flag(value bool) *bool {
+	return &value
+}
+
+func TestSearchEvidenceAdvanced(t *testing.T) {
+	ledger, contract, submittedAfter := setupSearch(t)
+
+	tests := []struct {
+		name   string
+		search chaincode.EvidenceSearch
+		want   []string
+		sorted bool
+	}{
+		{name: "by case and status", search: chaincode.EvidenceSearch{CaseID: "CASE1001", Statuses: []string{"submitted"}}, want: []string{"EV003", "EV004", "EV005"}},
+		{name: "by any tag", search: chaincode.EvidenceSearch{AnyTags: []string{"blood", "glove"}}, want: []string{"EV003", "EV004", "EV005"}},
+		{name: "by any tag in another case", search: chaincode.EvidenceSearch{AnyTags: []string{"BLOOD", "Glove"}}, want: []string{"EV003", "EV004", "EV005"}},
+		{name: "by all tags", search: chaincode.EvidenceSearch{AllTags: []string{"knife", "glove"}}, want: []string{"EV005"}},
+		{name: "by all tags in another case", search: chaincode.EvidenceSearch{AllTags: []string{"KNIFE", "Glove"}}, want: []string{"EV005"}},
+		{name: "tags are not patterns", search: chaincode.EvidenceSearch{AnyTags: []string{"kn.fe", "gl*"}}, want: []string{}},
+		{name: "by submitter", search: chaincode.EvidenceSearch{SubmittedBy: "officer2"}, want: []string{"EV002"}},
+		{name: "by custodian", search: chaincode.EvidenceSearch{Custodian: "officer2"}, want: []string{"EV002"}},
+		{name: "by submission time", search: chaincode.EvidenceSearch{SubmittedAfter: submittedAfter}, want: []string{"EV003", "EV004", "EV005"}},
+		{name: "by submission time in another zone", search: chaincode.EvidenceSearch{SubmittedUntil: "2024-01-01T10:00:01+01:00"}, want: []string{"EV001", "EV002", "EV003"}},
+		{name: "by flag", search: chaincode.EvidenceSearch{ProofVerified: flag(true)}, want: []string{}},
+		{name: "by unset flag", search: chaincode.EvidenceSearch{CaseID: "CASE1001", AIVerified: flag(false)}, want: []string{"EV001", "EV002", "EV003", "EV004", "EV005"}},
+		{name: "newest first", search: chaincode.EvidenceSearch{SubmittedAfter: submittedAfter, SortBy: "SubmittedTime", SortDescending: true}, want: []string{"EV005", "EV004", "EV003"}, sorted: true},
+		{name: "with limit", search: chaincode.EvidenceSearch{AnyTags: []string{"knife"}, SortBy: "SubmittedTime", Limit: 1}, want: []string{"EV003"}, sorted: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			records := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) ([]*chaincode.Evidence, error) {
+				return contract.SearchEvidenceAdvanced(tx, searchJSON(t, test.search))
+			})
+			if test.sorted {
+				require.Equal(t, test.want, evidenceIDs(records))
+			} else {
+				require.ElementsMatch(t, test.want, evidenceIDs(records))
+			}
+		})
+	}
+}
+
+func TestSearchEvidenceAdvancedValidatesSearch(t *testing.T) {
+	ledger, contract, _ := setupSearch(t)
+
+	tests := []struct {
+		name   string
+		search string
+		err    string
+	}{
+		{name: "invalid flag", search: `{"AIVerified": "yes"}`, err: "invalid evidence search: json: cannot unmarshal string into Go struct field EvidenceSearch.AIVerified of type bool"},
+		{name: "unknown field", search: `{"Selector": {}}`, err: `invalid evidence search: json: unknown field "Selector"`},
+		{name: "unsortable field", search: `{"SortBy": "Description"}`, err: "cannot sort on Description, sortable fields are [SubmittedTime StatusUpdatedTime]"},
+		{name: "invalid time", search: `{"SubmittedAfter": "yesterday"}`, err: `invalid search time "yesterday": `},
+		{name: "negative limit", search: `{"Limit": -1}`, err: "limit must not be negative"},
+	}
+	for _, test := range tests {
+		err := ledger.Evaluate(officer1, func(tx *mocks.Transaction) error {
+			_, err := contract.SearchEvidenceAdvanced(tx, test.search)
+			return err
+		})
+		require.ErrorContains(t, err, test.err, test.name)
+	}
+}
+
+func TestSearchEvidenceAdvancedWithPagination(t *testing.T) {
+	ledger, contract, _ := setupSearch(t)
+	search := chaincode.EvidenceSearch{AnyTags: []string{"knife", "glove"}, SortBy: "SubmittedTime", Limit: 1}
+
+	var ids []string
+	bookmark := ""
+	for {
+		page := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) (*chaincode.EvidencePage, error) {
+			return contract.SearchEvidenceAdvancedWithPagination(tx, searchJSON(t, search), 2, bookmark)
+		})
+		ids = append(ids, evidenceIDs(page.Records)...)
+		if page.Bookmark == "" {
+			break
+		}
+		bookmark = page.Bookmark
+	}
+
+	// The page size takes the place of the limit
+	require.Equal(t, []string{"EV003", "EV004", "EV005"}, ids)
+}
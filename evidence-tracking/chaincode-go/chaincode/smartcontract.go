@@ -1,15 +1,13 @@
 package chaincode
 
 import (
-	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"math/big"
-	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/zkp"
 	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
 )
 
@@ -20,106 +18,195 @@ type SmartContract struct {
 
 // Evidence describes the structure of an evidence record
 type Evidence struct {
-	ID            string   `json:"ID"`            // Unique identifier for the evidence
-	Description   string   `json:"Description"`   // Description of the evidence
-	CaseID        string   `json:"CaseID"`        // ID of the case this evidence is associated with
-	FileHash      string   `json:"FileHash"`      // IPFS hash of the evidence file
-	SubmittedBy   string   `json:"SubmittedBy"`   // ID of the user who submitted the evidence
-	SubmittedTime string   `json:"SubmittedTime"` // Timestamp when evidence was submitted
-	Status        string   `json:"Status"`        // Current status of the evidence (e.g., "submitted", "processing", "verified")
-	Tags          []string `json:"Tags"`          // Tags for categorizing evidence
-	Metadata      string   `json:"Metadata"`      // Additional metadata in JSON format
-	Integrity     string   `json:"Integrity"`     // Hash checksum for tamper detection
-	ProofVerified bool     `json:"ProofVerified"` // Whether zero-knowledge proof has been verified
-	AIVerified    bool     `json:"AIVerified"`    // Whether AI has verified the evidence integrity
+	DocType             string           `json:"DocType"`                   // Always "evidence", distinguishes evidence in rich queries
+	ID                  string           `json:"ID"`                        // Unique identifier for the evidence
+	Description         string           `json:"Description"`               // Description of the evidence, empty when it is kept in the private details
+	CaseID              string           `json:"CaseID"`                    // ID of the case this evidence is associated with
+	FileHash            string           `json:"FileHash"`                  // IPFS hash of the evidence file
+	SubmittedBy         string           `json:"SubmittedBy"`               // ID of the user who submitted the evidence
+	SubmittedTime       string           `json:"SubmittedTime"`             // Timestamp when evidence was submitted
+	Status              string           `json:"Status"`                    // Current status of the evidence (e.g., "submitted", "processing", "verified")
+	StatusUpdatedTime   string           `json:"StatusUpdatedTime"`         // Timestamp when the evidence entered its current status
+	Tags                []string         `json:"Tags"`                      // Tags for categorizing evidence
+	Metadata            string           `json:"Metadata"`                  // Additional metadata in JSON format, empty when it is kept in the private details
+	EvidenceType        string           `json:"EvidenceType"`              // Type of the evidence, whose schema the metadata matches; empty for general evidence
+	EvidenceTypeVersion int              `json:"EvidenceTypeVersion"`       // Version of the evidence type the metadata was validated against
+	SchemaVersion       int              `json:"SchemaVersion"`             // Version of the layout the record is stored in
+	Integrity           string           `json:"Integrity"`                 // Hash of the record's content, recomputed on every write
+	IntegrityVersion    int              `json:"IntegrityVersion"`          // Version of the algorithm that computed Integrity
+	ProofVerified       bool             `json:"ProofVerified"`             // Whether zero-knowledge proof has been verified
+	AIVerified          bool             `json:"AIVerified"`                // Whether AI has verified the evidence integrity
+	FileCommitment      string           `json:"FileCommitment"`            // Pedersen commitment to the file digest that zero-knowledge proofs refer to
+	CurrentCustodian    string           `json:"CurrentCustodian"`          // ID of the party currently holding the evidence
+	PendingTransfer     *CustodyTransfer `json:"PendingTransfer,omitempty"` // Custody hand-off awaiting acceptance, if any
+	PrivateDetailsHash  string           `json:"PrivateDetailsHash"`        // SHA-256 of the private details held in the private data collection, if any
 }
 
-// EvidenceHistory describes a single change to an evidence record
+// EvidenceHistory describes a single change to an evidence record. The
+// entries of an evidence item form a hash chain; the fields must stay in step
+// with historychain.Entry, which defines how the chain is hashed.
 type EvidenceHistory struct {
-	EvidenceID  string `json:"EvidenceID"`  // ID of the evidence that was modified
-	ModifiedBy  string `json:"ModifiedBy"`  // ID of the user who made the modification
-	ModifiedAt  string `json:"ModifiedAt"`  // Timestamp of when the modification occurred
-	Action      string `json:"Action"`      // Type of action (e.g., "create", "update", "access")
-	Description string `json:"Description"` // Description of the changes made
-	PrevState   string `json:"PrevState"`   // JSON representation of the previous state (if applicable)
+	EvidenceID        string `json:"EvidenceID"`        // ID of the evidence that was modified
+	Sequence          int    `json:"Sequence"`          // Position of the entry in the evidence item's history chain
+	ModifiedBy        string `json:"ModifiedBy"`        // ID of the user who made the modification
+	ModifiedAt        string `json:"ModifiedAt"`        // Timestamp of when the modification occurred
+	Action            string `json:"Action"`            // Type of action (e.g., "create", "update", "access")
+	Description       string `json:"Description"`       // Description of the changes made
+	PrevState         string `json:"PrevState"`         // JSON representation of the previous state (if applicable)
+	TxID              string `json:"TxID"`              // ID of the transaction that made the modification
+	PreviousIntegrity string `json:"PreviousIntegrity"` // Integrity hash of the record before the modification
+	Integrity         string `json:"Integrity"`         // Integrity hash of the record after the modification
+	PreviousEntryHash string `json:"PreviousEntryHash"` // Hash of the previous history entry, empty for the first
+	EntryHash         string `json:"EntryHash"`         // Hash of this entry, covering every other field
 }
 
 // ZKProof represents a zero-knowledge proof structure
 type ZKProof struct {
-	EvidenceID  string `json:"EvidenceID"`  // ID of the evidence
-	Commitment  string `json:"Commitment"`  // Pedersen commitment
-	Challenge   string `json:"Challenge"`   // Challenge value
-	Response    string `json:"Response"`    // Response to the challenge
-	VerifierID  string `json:"VerifierID"`  // ID of the verifier
-	CreatedTime string `json:"CreatedTime"` // When the proof was created
+	EvidenceID       string `json:"EvidenceID"`       // ID of the evidence
+	Curve            string `json:"Curve"`            // Elliptic curve group the proof is made in
+	GeneratorG       string `json:"GeneratorG"`       // Base point G of the group, compressed and hex encoded
+	GeneratorH       string `json:"GeneratorH"`       // Second generator H of the group, compressed and hex encoded
+	Commitment       string `json:"Commitment"`       // Pedersen commitment C = m·G + r·H to the file digest m
+	NonceCommitment  string `json:"NonceCommitment"`  // Prover's nonce commitment T
+	Challenge        string `json:"Challenge"`        // Fiat-Shamir challenge c
+	ResponseValue    string `json:"ResponseValue"`    // Response s1 for the committed digest
+	ResponseBlinding string `json:"ResponseBlinding"` // Response s2 for the blinding factor
+	ProverID         string `json:"ProverID"`         // ID of the client that submitted the proof
+	VerifierID       string `json:"VerifierID"`       // ID of the client the proof is bound to, the only one that may verify it
+	CreatedTime      string `json:"CreatedTime"`      // When the proof was created
+	TxID             string `json:"TxID"`             // ID of the transaction that created the proof
 }
 
 // AIAnalysisResult represents the result of AI-based tamper detection
 type AIAnalysisResult struct {
-	EvidenceID       string  `json:"EvidenceID"`       // ID of the evidence
+	EvidenceID        string  `json:"EvidenceID"`        // ID of the evidence
 	TamperProbability float64 `json:"TamperProbability"` // Probability of tampering (0-1)
-	AnalysisDetails  string  `json:"AnalysisDetails"`  // Details of the analysis in JSON format
-	AnalyzedBy       string  `json:"AnalyzedBy"`       // ID of the AI system
-	AnalyzedTime     string  `json:"AnalyzedTime"`     // When the analysis was performed
+	AnalysisDetails   string  `json:"AnalysisDetails"`   // Details of the analysis in JSON format
+	AnalyzedBy        string  `json:"AnalyzedBy"`        // ID of the registered analyzer
+	AnalyzedTime      string  `json:"AnalyzedTime"`      // When the result was recorded on the ledger
+	AnalyzerTime      string  `json:"AnalyzerTime"`      // When the analyzer performed the analysis, as signed by it
+	FileHash          string  `json:"FileHash"`          // File hash the analysis was performed on
+	Signature         string  `json:"Signature"`         // Analyzer's signature over the analysis statement
+	SubmittedBy       string  `json:"SubmittedBy"`       // ID of the client that submitted the result
+	TxID              string  `json:"TxID"`              // ID of the transaction that recorded the analysis
 }
 
-// InitLedger adds a base set of evidence records to the ledger
+// InitLedger adds a base set of evidence records to the ledger. It runs once,
+// and is refused once access policies or the base evidence have been written.
 func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
-	currentTime := time.Now().Format(time.RFC3339)
-	
+	_, err := authorize(ctx, "InitLedger")
+	if err != nil {
+		return err
+	}
+
+	initialized, err := ledgerInitialized(ctx)
+	if err != nil {
+		return err
+	}
+	if initialized {
+		return fmt.Errorf("the ledger has already been initialized")
+	}
+
+	for _, policy := range defaultAccessPolicies {
+		err := putAccessPolicy(ctx, policy)
+		if err != nil {
+			return fmt.Errorf("failed to put access policy: %v", err)
+		}
+	}
+
+	for _, policy := range defaultRetentionPolicies {
+		err := putRetentionPolicy(ctx, policy)
+		if err != nil {
+			return fmt.Errorf("failed to put retention policy: %v", err)
+		}
+	}
+
+	for _, evidenceType := range defaultEvidenceTypes {
+		err := putEvidenceType(ctx, evidenceType)
+		if err != nil {
+			return fmt.Errorf("failed to put evidence type: %v", err)
+		}
+	}
+
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to read client MSP ID: %v", err)
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	initialCase := &Case{
+		ID:               "CASE1001",
+		Title:            "Main St burglary",
+		CaseType:         caseTypeFelony,
+		Jurisdiction:     "District Court",
+		LeadInvestigator: "officer1",
+		AssignedTeam:     []string{"officer2"},
+		Status:           caseStatusOpen,
+		OpenedAt:         currentTime,
+		Timeline: []*CaseEvent{
+			{
+				Status:    caseStatusOpen,
+				ChangedBy: "system",
+				ChangedAt: currentTime,
+				Reason:    "Case opened when the ledger was initialised",
+				TxID:      ctx.GetStub().GetTxID(),
+			},
+		},
+	}
+	err = putCase(ctx, initialCase)
+	if err != nil {
+		return fmt.Errorf("failed to put case: %v", err)
+	}
+
 	evidence := []Evidence{
 		{
-			ID:            "EV001",
-			Description:   "Surveillance camera footage from Main St",
-			CaseID:        "CASE1001",
-			FileHash:      "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o",
-			SubmittedBy:   "officer1",
-			SubmittedTime: currentTime,
-			Status:        "verified",
-			Tags:          []string{"video", "surveillance"},
-			Metadata:      `{"format": "mp4", "duration": "00:32:15", "location": "Main St & 5th Ave"}`,
+			ID:                  "EV001",
+			Description:         "Surveillance camera footage from Main St",
+			CaseID:              "CASE1001",
+			FileHash:            "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o",
+			SubmittedBy:         "officer1",
+			SubmittedTime:       currentTime,
+			Status:              "verified",
+			Tags:                []string{"video", "surveillance"},
+			Metadata:            `{"format": "mp4", "duration": "00:32:15", "location": "Main St & 5th Ave"}`,
+			EvidenceType:        evidenceTypeVideo,
+			EvidenceTypeVersion: 1,
 		},
 		{
-			ID:            "EV002",
-			Description:   "Fingerprint from door handle",
-			CaseID:        "CASE1001",
-			FileHash:      "QmXs5YtpYsLCYkioRFgRRYQTQ1E4Zpfpbj2GRLo4qJ8L9d",
-			SubmittedBy:   "officer2",
-			SubmittedTime: currentTime,
-			Status:        "processing",
-			Tags:          []string{"fingerprint", "physical"},
-			Metadata:      `{"type": "latent", "surface": "metal", "quality": "high"}`,
+			ID:                  "EV002",
+			Description:         "Fingerprint from door handle",
+			CaseID:              "CASE1001",
+			FileHash:            "QmXs5YtpYsLCYkioRFgRRYQTQ1E4Zpfpbj2GRLo4qJ8L9d",
+			SubmittedBy:         "officer2",
+			SubmittedTime:       currentTime,
+			Status:              "processing",
+			Tags:                []string{"fingerprint", "physical"},
+			Metadata:            `{"type": "latent", "surface": "metal", "quality": "high"}`,
+			EvidenceType:        evidenceTypeFingerprint,
+			EvidenceTypeVersion: 1,
 		},
 	}
 
 	for _, ev := range evidence {
-		evidenceJSON, err := json.Marshal(ev)
-		if err != nil {
-			return err
-		}
+		ev.CurrentCustodian = ev.SubmittedBy
+		ev.StatusUpdatedTime = currentTime
 
-		err = ctx.GetStub().PutState(ev.ID, evidenceJSON)
+		err = putEvidence(ctx, &ev)
 		if err != nil {
 			return fmt.Errorf("failed to put to world state: %v", err)
 		}
 
-		// Create a history record for each evidence creation
-		historyRecord := EvidenceHistory{
-			EvidenceID:  ev.ID,
-			ModifiedBy:  "system",
-			ModifiedAt:  currentTime,
-			Action:      "create",
-			Description: "Initial creation of evidence record",
-			PrevState:   "",
-		}
-
-		historyKey := fmt.Sprintf("history~%s~%s", ev.ID, currentTime)
-		historyJSON, err := json.Marshal(historyRecord)
+		err = putInitialCustodyEntry(ctx, &ev, mspID, "Custody established when the ledger was initialised")
 		if err != nil {
 			return err
 		}
 
-		err = ctx.GetStub().PutState(historyKey, historyJSON)
+		// Create a history record for each evidence creation
+		err = recordHistory(ctx, &ev, "", "system", "create", "Initial creation of evidence record", "")
 		if err != nil {
 			return fmt.Errorf("failed to record history: %v", err)
 		}
@@ -128,17 +215,72 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 	return nil
 }
 
-// SubmitEvidence issues a new evidence record to the world state with given details
+// ledgerInitialized returns true when InitLedger has already written access
+// policies or the base evidence to the ledger
+func ledgerInitialized(ctx contractapi.TransactionContextInterface) (bool, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(accessPolicyObjectType, []string{})
+	if err != nil {
+		return false, err
+	}
+	defer resultsIterator.Close()
+
+	if resultsIterator.HasNext() {
+		return true, nil
+	}
+
+	evidenceJSON, err := getEvidenceState(ctx, "EV001")
+	if err != nil {
+		return false, err
+	}
+
+	return evidenceJSON != nil, nil
+}
+
+// SubmitEvidence issues a new evidence record to the world state with given
+// details. The metadata must match the schema of the evidence type, which
+// defaults to general.
 func (s *SmartContract) SubmitEvidence(
 	ctx contractapi.TransactionContextInterface,
 	id string,
 	description string,
 	caseID string,
+	evidenceType string,
 	fileHash string,
-	submittedBy string,
 	tags []string,
 	metadata string,
 ) error {
+	caller, err := authorize(ctx, "SubmitEvidence")
+	if err != nil {
+		return err
+	}
+
+	return s.submitEvidence(ctx, caller, &Evidence{
+		ID:           id,
+		Description:  description,
+		CaseID:       caseID,
+		EvidenceType: evidenceType,
+		FileHash:     fileHash,
+		Tags:         tags,
+		Metadata:     metadata,
+	}, nil, nil)
+}
+
+// submitEvidence creates an evidence record, with the content given in
+// evidence, for SubmitEvidence, SubmitPrivateEvidence and
+// RegisterDerivedEvidence. When private details are given they are stored in
+// the private data collection, and the public record keeps only their hash.
+// The metadata, public or private, is validated against the latest version
+// of the evidence type. Evidence derived from a parent belongs to the
+// parent's case and starts in the custody of the parent's custodian; other
+// evidence starts in the custody of the submitter.
+func (s *SmartContract) submitEvidence(
+	ctx contractapi.TransactionContextInterface,
+	caller *clientIdentity,
+	evidence *Evidence,
+	details *EvidencePrivateDetails,
+	parent *Evidence,
+) error {
+	id := evidence.ID
 	exists, err := s.EvidenceExists(ctx, id)
 	if err != nil {
 		return err
@@ -147,144 +289,168 @@ func (s *SmartContract) SubmitEvidence(
 		return fmt.Errorf("the evidence %s already exists", id)
 	}
 
-	currentTime := time.Now().Format(time.RFC3339)
-	
-	evidence := Evidence{
-		ID:            id,
-		Description:   description,
-		CaseID:        caseID,
-		FileHash:      fileHash,
-		SubmittedBy:   submittedBy,
-		SubmittedTime: currentTime,
-		Status:        "submitted",
-		Tags:          tags,
-		Metadata:      metadata,
-	}
-	
-	evidenceJSON, err := json.Marshal(evidence)
+	// The ID of disposed evidence stays taken by its tombstone
+	tombstone, err := getTombstone(ctx, id)
 	if err != nil {
 		return err
 	}
+	if tombstone != nil {
+		return fmt.Errorf("the evidence %s was disposed of at %s", id, tombstone.DisposedAt)
+	}
+
+	custodian := caller.ID
+	custodianMSPID := caller.MSPID
+	description := "Initial submission of evidence"
+	if parent != nil {
+		evidence.CaseID = parent.CaseID
+		custodian = currentCustodian(parent)
+		custodianMSPID, err = currentCustodianMSPID(ctx, parent)
+		if err != nil {
+			return err
+		}
+		description = fmt.Sprintf("Derived from evidence %s", parent.ID)
+	}
 
-	err = ctx.GetStub().PutState(id, evidenceJSON)
+	err = s.checkCaseAcceptsEvidence(ctx, evidence.CaseID)
 	if err != nil {
 		return err
 	}
 
-	// Create a history record for the submission
-	historyRecord := EvidenceHistory{
-		EvidenceID:  id,
-		ModifiedBy:  submittedBy,
-		ModifiedAt:  currentTime,
-		Action:      "create",
-		Description: "Initial submission of evidence",
-		PrevState:   "",
+	evidence.EvidenceType = evidenceTypeOf(evidence)
+	metadata := evidence.Metadata
+	if details != nil {
+		metadata = details.Metadata
 	}
-
-	historyKey := fmt.Sprintf("history~%s~%s", id, currentTime)
-	historyJSON, err := json.Marshal(historyRecord)
+	evidence.EvidenceTypeVersion, err = validateMetadata(ctx, evidence.EvidenceType, metadata)
 	if err != nil {
 		return err
 	}
 
-	return ctx.GetStub().PutState(historyKey, historyJSON)
-}
-
-// ReadEvidence returns the evidence stored in the world state with given id
-func (s *SmartContract) ReadEvidence(ctx contractapi.TransactionContextInterface, id string) (*Evidence, error) {
-	evidenceJSON, err := ctx.GetStub().GetState(id)
+	currentTime, err := getTxTimestamp(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read from world state: %v", err)
+		return err
 	}
-	if evidenceJSON == nil {
-		return nil, fmt.Errorf("the evidence %s does not exist", id)
+
+	evidence.SubmittedBy = caller.ID
+	evidence.SubmittedTime = currentTime
+	evidence.Status = statusSubmitted
+	evidence.StatusUpdatedTime = currentTime
+	evidence.CurrentCustodian = custodian
+
+	if details != nil {
+		err = putPrivateDetails(ctx, evidence, details)
+		if err != nil {
+			return err
+		}
 	}
 
-	var evidence Evidence
-	err = json.Unmarshal(evidenceJSON, &evidence)
+	err = putEvidence(ctx, evidence)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// Record access in history
-	currentTime := time.Now().Format(time.RFC3339)
-	submitter, err := ctx.GetClientIdentity().GetID()
+	// The custodian takes initial custody of the evidence
+	err = putInitialCustodyEntry(ctx, evidence, custodianMSPID, description)
 	if err != nil {
-		submitter = "unknown"
+		return err
 	}
-	
-	historyRecord := EvidenceHistory{
-		EvidenceID:  id,
-		ModifiedBy:  submitter,
-		ModifiedAt:  currentTime,
-		Action:      "access",
-		Description: "Evidence record accessed",
-		PrevState:   "",
+
+	if parent != nil {
+		err = putRelation(ctx, &EvidenceRelation{
+			FromID:    id,
+			Type:      relationDerivedFrom,
+			ToID:      parent.ID,
+			CreatedBy: caller.ID,
+			CreatedAt: currentTime,
+			TxID:      ctx.GetStub().GetTxID(),
+		})
+		if err != nil {
+			return err
+		}
 	}
 
-	historyKey := fmt.Sprintf("history~%s~%s", id, currentTime)
-	historyJSON, err := json.Marshal(historyRecord)
+	// Create a history record for the submission
+	err = recordHistory(ctx, evidence, "", caller.ID, "create", description, "")
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	err = ctx.GetStub().PutState(historyKey, historyJSON)
+	return emitEvidenceEvent(ctx, eventEvidenceSubmitted, id, caller.ID, evidence)
+}
+
+// ReadEvidence returns the evidence stored in the world state with given id.
+// It does not write to the ledger; access is audited by RecordEvidenceAccess.
+func (s *SmartContract) ReadEvidence(ctx contractapi.TransactionContextInterface, id string) (*Evidence, error) {
+	evidenceJSON, err := getEvidenceState(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to record access: %v", err)
+		return nil, err
+	}
+	if evidenceJSON == nil {
+		tombstone, err := getTombstone(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if tombstone != nil {
+			return nil, fmt.Errorf("the evidence %s was disposed of at %s under court order %s", id, tombstone.DisposedAt, tombstone.CourtOrderRef)
+		}
+		return nil, fmt.Errorf("the evidence %s does not exist", id)
 	}
 
-	return &evidence, nil
+	return decodeEvidence(evidenceJSON)
 }
 
-// UpdateEvidenceStatus updates the status of an existing evidence record
-func (s *SmartContract) UpdateEvidenceStatus(ctx contractapi.TransactionContextInterface, id string, newStatus string) error {
+// UpdateEvidenceStatus moves an existing evidence record to a new status. The
+// change must be a transition declared in the evidence lifecycle, and some
+// transitions require a particular role or a reason code.
+func (s *SmartContract) UpdateEvidenceStatus(ctx contractapi.TransactionContextInterface, id string, newStatus string, reasonCode string) error {
+	caller, err := authorize(ctx, "UpdateEvidenceStatus")
+	if err != nil {
+		return err
+	}
+
 	evidence, err := s.ReadEvidence(ctx, id)
 	if err != nil {
 		return err
 	}
 
+	err = checkStatusTransition(evidence.Status, newStatus, caller.Role, reasonCode)
+	if err != nil {
+		return fmt.Errorf("cannot update status of evidence %s: %v", id, err)
+	}
+
 	// Store previous state for history
 	prevStateJSON, err := json.Marshal(evidence)
 	if err != nil {
 		return err
 	}
 
-	// Update status
-	evidence.Status = newStatus
-	
-	evidenceJSON, err := json.Marshal(evidence)
+	currentTime, err := getTxTimestamp(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = ctx.GetStub().PutState(id, evidenceJSON)
+	// Update status
+	previousIntegrity := evidence.Integrity
+	previousStatus := evidence.Status
+	evidence.Status = newStatus
+	evidence.StatusUpdatedTime = currentTime
+
+	err = putEvidence(ctx, evidence)
 	if err != nil {
 		return err
 	}
 
 	// Record update in history
-	currentTime := time.Now().Format(time.RFC3339)
-	submitter, err := ctx.GetClientIdentity().GetID()
-	if err != nil {
-		submitter = "unknown"
-	}
-	
-	historyRecord := EvidenceHistory{
-		EvidenceID:  id,
-		ModifiedBy:  submitter,
-		ModifiedAt:  currentTime,
-		Action:      "update",
-		Description: fmt.Sprintf("Status updated to '%s'", newStatus),
-		PrevState:   string(prevStateJSON),
-	}
-
-	historyKey := fmt.Sprintf("history~%s~%s", id, currentTime)
-	historyJSON, err := json.Marshal(historyRecord)
+	err = recordHistory(ctx, evidence, previousIntegrity, caller.ID, "update", statusChangeDescription(newStatus, reasonCode), string(prevStateJSON))
 	if err != nil {
 		return err
 	}
 
-	return ctx.GetStub().PutState(historyKey, historyJSON)
+	return emitEvidenceEvent(ctx, eventStatusChanged, id, caller.ID, &StatusChange{
+		From:       previousStatus,
+		To:         newStatus,
+		ReasonCode: reasonCode,
+	})
 }
 
 // UpdateEvidence updates an existing evidence record in the world state
@@ -296,10 +462,44 @@ func (s *SmartContract) UpdateEvidence(
 	tags []string,
 	metadata string,
 ) error {
+	caller, err := authorize(ctx, "UpdateEvidence")
+	if err != nil {
+		return err
+	}
+
 	evidence, err := s.ReadEvidence(ctx, id)
 	if err != nil {
 		return err
 	}
+	err = checkDetailsUpdatable(evidence)
+	if err != nil {
+		return err
+	}
+
+	if evidence.PrivateDetailsHash != "" && (description != "" || metadata != "") {
+		return fmt.Errorf("the description and metadata of evidence %s are private; use UpdateEvidencePrivateDetails", id)
+	}
+
+	// Changed metadata must match the latest schema of the evidence type;
+	// unchanged metadata keeps the version it was validated against
+	typeVersion := evidence.EvidenceTypeVersion
+	if metadata != evidence.Metadata {
+		typeVersion, err = validateMetadata(ctx, evidenceTypeOf(evidence), metadata)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The file hash of a bundle is the Merkle root of its manifest
+	if fileHash != evidence.FileHash {
+		bundle, err := getBundle(ctx, id)
+		if err != nil {
+			return err
+		}
+		if bundle != nil {
+			return fmt.Errorf("the file hash of evidence %s is the root of its bundle and cannot be changed", id)
+		}
+	}
 
 	// Store previous state for history
 	prevStateJSON, err := json.Marshal(evidence)
@@ -307,52 +507,40 @@ func (s *SmartContract) UpdateEvidence(
 		return err
 	}
 
-	// Update fields
+	// Update fields. Proofs and analyses of the previous file no longer apply
+	// to the evidence, so a new file needs a new commitment from its custodian
+	// and a new analysis.
+	previousIntegrity := evidence.Integrity
+	if fileHash != evidence.FileHash {
+		evidence.FileCommitment = ""
+		evidence.ProofVerified = false
+		evidence.AIVerified = false
+	}
 	evidence.Description = description
 	evidence.FileHash = fileHash
 	evidence.Tags = tags
 	evidence.Metadata = metadata
-	
-	evidenceJSON, err := json.Marshal(evidence)
-	if err != nil {
-		return err
-	}
+	evidence.EvidenceTypeVersion = typeVersion
 
-	err = ctx.GetStub().PutState(id, evidenceJSON)
+	err = putEvidence(ctx, evidence)
 	if err != nil {
 		return err
 	}
 
 	// Record update in history
-	currentTime := time.Now().Format(time.RFC3339)
-	submitter, err := ctx.GetClientIdentity().GetID()
-	if err != nil {
-		submitter = "unknown"
-	}
-	
-	historyRecord := EvidenceHistory{
-		EvidenceID:  id,
-		ModifiedBy:  submitter,
-		ModifiedAt:  currentTime,
-		Action:      "update",
-		Description: "Evidence details updated",
-		PrevState:   string(prevStateJSON),
-	}
-
-	historyKey := fmt.Sprintf("history~%s~%s", id, currentTime)
-	historyJSON, err := json.Marshal(historyRecord)
+	err = recordHistory(ctx, evidence, previousIntegrity, caller.ID, "update", "Evidence details updated", string(prevStateJSON))
 	if err != nil {
 		return err
 	}
 
-	return ctx.GetStub().PutState(historyKey, historyJSON)
+	return emitEvidenceEvent(ctx, eventEvidenceUpdated, id, caller.ID, evidence)
 }
 
 // EvidenceExists returns true when evidence with given ID exists in world state
 func (s *SmartContract) EvidenceExists(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
-	evidenceJSON, err := ctx.GetStub().GetState(id)
+	evidenceJSON, err := getEvidenceState(ctx, id)
 	if err != nil {
-		return false, fmt.Errorf("failed to read from world state: %v", err)
+		return false, err
 	}
 
 	return evidenceJSON != nil, nil
@@ -361,8 +549,11 @@ func (s *SmartContract) EvidenceExists(ctx contractapi.TransactionContextInterfa
 // GetEvidenceByCase returns all evidence records associated with a given case ID
 func (s *SmartContract) GetEvidenceByCase(ctx contractapi.TransactionContextInterface, caseID string) ([]*Evidence, error) {
 	// Use rich query with CouchDB
-	queryString := fmt.Sprintf(`{"selector":{"CaseID":"%s"}}`, caseID)
-	
+	queryString, err := evidenceQueryString(map[string]interface{}{"CaseID": caseID})
+	if err != nil {
+		return nil, err
+	}
+
 	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
 	if err != nil {
 		return nil, err
@@ -376,12 +567,11 @@ func (s *SmartContract) GetEvidenceByCase(ctx contractapi.TransactionContextInte
 			return nil, err
 		}
 
-		var ev Evidence
-		err = json.Unmarshal(queryResult.Value, &ev)
+		ev, err := decodeEvidence(queryResult.Value)
 		if err != nil {
 			return nil, err
 		}
-		evidence = append(evidence, &ev)
+		evidence = append(evidence, ev)
 	}
 
 	return evidence, nil
@@ -389,7 +579,8 @@ func (s *SmartContract) GetEvidenceByCase(ctx contractapi.TransactionContextInte
 
 // GetAllEvidence returns all evidence found in world state
 func (s *SmartContract) GetAllEvidence(ctx contractapi.TransactionContextInterface) ([]*Evidence, error) {
-	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	startKey, endKey := evidenceKeyRange()
+	resultsIterator, err := ctx.GetStub().GetStateByRange(startKey, endKey)
 	if err != nil {
 		return nil, err
 	}
@@ -402,17 +593,11 @@ func (s *SmartContract) GetAllEvidence(ctx contractapi.TransactionContextInterfa
 			return nil, err
 		}
 
-		// Skip entries that start with "history~"
-		if len(queryResponse.Key) >= 8 && queryResponse.Key[:8] == "history~" {
-			continue
-		}
-
-		var ev Evidence
-		err = json.Unmarshal(queryResponse.Value, &ev)
+		ev, err := decodeEvidence(queryResponse.Value)
 		if err != nil {
-			continue // Skip non-evidence entries
+			return nil, err
 		}
-		evidence = append(evidence, &ev)
+		evidence = append(evidence, ev)
 	}
 
 	return evidence, nil
@@ -420,320 +605,261 @@ func (s *SmartContract) GetAllEvidence(ctx contractapi.TransactionContextInterfa
 
 // GetEvidenceHistory returns the modification history for a specific evidence ID
 func (s *SmartContract) GetEvidenceHistory(ctx contractapi.TransactionContextInterface, id string) ([]*EvidenceHistory, error) {
-	// Create partial composite key to find all history records for this evidence
-	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey("history~", []string{id})
+	return getHistoryRecords(ctx, id)
+}
+
+// VerifyEvidenceIntegrity recomputes the integrity hash of the evidence with
+// the algorithm version it was sealed under and compares it with the stored
+// hash. It checks any private details against the hash the record keeps of
+// them, and the manifest of a bundle against its root. A mismatch means the
+// record was changed outside the contract. The items the evidence was derived
+// from or is part of are checked too, and the evidence fails verification if
+// any of them fails or is a legacy record that has not been sealed yet.
+func (s *SmartContract) VerifyEvidenceIntegrity(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
+	caller, err := authorize(ctx, "VerifyEvidenceIntegrity")
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-	defer resultsIterator.Close()
 
-	var history []*EvidenceHistory
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return false, err
+	}
 
-		var historyRecord EvidenceHistory
-		err = json.Unmarshal(queryResponse.Value, &historyRecord)
-		if err != nil {
-			return nil, err
-		}
-		
-		history = append(history, &historyRecord)
+	valid, err := evidenceIntact(ctx, evidence)
+	if err != nil {
+		return false, err
 	}
 
-	return history, nil
-}
+	failed, unverifiable, err := s.checkAncestors(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	err = emitEvidenceEvent(ctx, eventIntegrityVerified, id, caller.ID, &IntegrityCheck{
+		Valid:                 valid,
+		Integrity:             evidence.Integrity,
+		IntegrityVersion:      evidence.IntegrityVersion,
+		FailedAncestors:       failed,
+		UnverifiableAncestors: unverifiable,
+	})
+	if err != nil {
+		return false, err
+	}
 
-// CalculateIntegrityHash computes a hash that represents the integrity of the evidence
-func calculateIntegrityHash(evidence *Evidence) string {
-	// Create a string combining critical elements of the evidence
-	dataToHash := evidence.ID + evidence.FileHash + evidence.CaseID + 
-		evidence.SubmittedBy + evidence.SubmittedTime + evidence.Metadata
-	
-	// Generate SHA-256 hash
-	h := sha256.New()
-	h.Write([]byte(dataToHash))
-	return fmt.Sprintf("%x", h.Sum(nil))
+	return valid && len(failed) == 0 && len(unverifiable) == 0, nil
 }
 
-// VerifyEvidenceIntegrity checks the integrity hash of the evidence
-func (s *SmartContract) VerifyEvidenceIntegrity(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
-	evidence, err := s.ReadEvidence(ctx, id)
+// evidenceIntact reports whether an evidence record matches its integrity
+// hash, whether the manifest of a bundle still has the root the record holds,
+// and, if it has private details, whether they match their hash
+func evidenceIntact(ctx contractapi.TransactionContextInterface, evidence *Evidence) (bool, error) {
+	valid, err := checkIntegrity(evidence)
 	if err != nil {
 		return false, err
 	}
-	
-	// Calculate expected hash
-	expectedHash := calculateIntegrityHash(evidence)
-	
-	// If integrity hash isn't set, this is the first verification
-	if evidence.Integrity == "" {
-		evidence.Integrity = expectedHash
-		
-		// Update the evidence record with the integrity hash
-		evidenceJSON, err := json.Marshal(evidence)
-		if err != nil {
-			return false, err
-		}
-		
-		err = ctx.GetStub().PutState(id, evidenceJSON)
+
+	if valid {
+		valid, err = bundleMatches(ctx, evidence)
 		if err != nil {
 			return false, err
 		}
-		
-		return true, nil
 	}
-	
-	// Compare stored hash with calculated hash
-	return evidence.Integrity == expectedHash, nil
+
+	// Any channel member can check the private details against their hash,
+	// without being able to read them
+	if valid && evidence.PrivateDetailsHash != "" {
+		return privateDetailsMatch(ctx, evidence)
+	}
+
+	return valid, nil
 }
 
-// CreateZKProof creates a zero-knowledge proof for evidence verification
-func (s *SmartContract) CreateZKProof(ctx contractapi.TransactionContextInterface, 
-	evidenceID string, secret string, verifierID string) (*ZKProof, error) {
-	
+// CreateZKProof records a zero-knowledge proof that the submitting client
+// knows the file digest committed to by the evidence, without revealing it.
+// The proof is built off-chain with zkp.Commit and zkp.Prove, bound to the
+// context returned by zkp.Context. The first proof for an evidence item,
+// which must come from its current custodian, fixes the commitment; every
+// later proof must be about the same commitment. Invalid proofs are rejected.
+func (s *SmartContract) CreateZKProof(ctx contractapi.TransactionContextInterface,
+	evidenceID string, proof zkp.Proof, verifierID string) (*ZKProof, error) {
+
+	caller, err := authorize(ctx, "CreateZKProof")
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if evidence exists
 	evidence, err := s.ReadEvidence(ctx, evidenceID)
 	if err != nil {
 		return nil, err
 	}
-	
-	// Create a commitment using the secret and evidence hash
-	// This is a simplified implementation of Pedersen commitment
-	h := sha256.New()
-	h.Write([]byte(secret + evidence.FileHash))
-	commitment := base64.StdEncoding.EncodeToString(h.Sum(nil))
-	
-	// Create a challenge
-	h = sha256.New()
-	h.Write([]byte(commitment + evidence.ID + time.Now().String()))
-	challenge := base64.StdEncoding.EncodeToString(h.Sum(nil))
-	
-	// Create a response (in a real ZKP this would be more complex)
-	h = sha256.New()
-	h.Write([]byte(secret + challenge))
-	response := base64.StdEncoding.EncodeToString(h.Sum(nil))
-	
-	currentTime := time.Now().Format(time.RFC3339)
-	
+
+	err = zkp.Verify(&proof, zkp.Context(evidenceID, verifierID))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zero-knowledge proof for evidence %s: %v", evidenceID, err)
+	}
+
+	if evidence.FileCommitment == "" {
+		if caller.ID != currentCustodian(evidence) {
+			return nil, fmt.Errorf("only the current custodian may commit to the file of evidence %s", evidenceID)
+		}
+		previousIntegrity := evidence.Integrity
+		evidence.FileCommitment = proof.Commitment
+		err = putEvidence(ctx, evidence)
+		if err != nil {
+			return nil, err
+		}
+
+		err = recordHistory(ctx, evidence, previousIntegrity, caller.ID, "commit", "Commitment to the evidence file recorded", "")
+		if err != nil {
+			return nil, err
+		}
+	} else if proof.Commitment != evidence.FileCommitment {
+		return nil, fmt.Errorf("proof is not about the file commitment of evidence %s", evidenceID)
+	}
+
+	// A proof can only be recorded once, so it cannot be replayed by others
+	proofs, err := getZKProofs(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range proofs {
+		if existing.NonceCommitment == proof.NonceCommitment {
+			return nil, fmt.Errorf("the proof was already recorded for evidence %s in transaction %s", evidenceID, existing.TxID)
+		}
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txID := ctx.GetStub().GetTxID()
+
 	// Create ZKProof object
 	zkProof := &ZKProof{
-		EvidenceID:  evidenceID,
-		Commitment:  commitment,
-		Challenge:   challenge,
-		Response:    response,
-		VerifierID:  verifierID,
-		CreatedTime: currentTime,
-	}
-	
+		EvidenceID:       evidenceID,
+		Curve:            zkp.CurveName,
+		GeneratorG:       zkp.GeneratorG(),
+		GeneratorH:       zkp.GeneratorH(),
+		Commitment:       proof.Commitment,
+		NonceCommitment:  proof.NonceCommitment,
+		Challenge:        proof.Challenge,
+		ResponseValue:    proof.ResponseValue,
+		ResponseBlinding: proof.ResponseBlinding,
+		ProverID:         caller.ID,
+		VerifierID:       verifierID,
+		CreatedTime:      currentTime,
+		TxID:             txID,
+	}
+
 	// Store the proof on the ledger
-	zkProofKey := fmt.Sprintf("zkproof~%s~%s", evidenceID, currentTime)
-	zkProofJSON, err := json.Marshal(zkProof)
+	proofKey, err := zkProofKey(ctx, evidenceID, currentTime, txID)
 	if err != nil {
 		return nil, err
 	}
-	
-	err = ctx.GetStub().PutState(zkProofKey, zkProofJSON)
+
+	err = putRecord(ctx, proofKey, zkProof)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	return zkProof, nil
 }
 
-// VerifyZKProof verifies a zero-knowledge proof
-func (s *SmartContract) VerifyZKProof(ctx contractapi.TransactionContextInterface, 
-	evidenceID string, proofTime string) (bool, error) {
-	
+// VerifyZKProof verifies a zero-knowledge proof, identified by the time and
+// transaction ID at which it was created, and marks the evidence as proven
+// when the proof holds. Only the verifier the proof is bound to may verify it.
+func (s *SmartContract) VerifyZKProof(ctx contractapi.TransactionContextInterface,
+	evidenceID string, proofTime string, proofTxID string) (bool, error) {
+
+	caller, err := authorize(ctx, "VerifyZKProof")
+	if err != nil {
+		return false, err
+	}
+
 	// Retrieve the ZK proof
-	zkProofKey := fmt.Sprintf("zkproof~%s~%s", evidenceID, proofTime)
-	zkProofJSON, err := ctx.GetStub().GetState(zkProofKey)
+	proofKey, err := zkProofKey(ctx, evidenceID, proofTime, proofTxID)
+	if err != nil {
+		return false, err
+	}
+
+	zkProofJSON, err := ctx.GetStub().GetState(proofKey)
 	if err != nil {
 		return false, err
 	}
 	if zkProofJSON == nil {
-		return false, fmt.Errorf("no ZK proof exists for evidence %s at time %s", evidenceID, proofTime)
+		return false, fmt.Errorf("no ZK proof exists for evidence %s at time %s in transaction %s", evidenceID, proofTime, proofTxID)
 	}
-	
+
 	var zkProof ZKProof
 	err = json.Unmarshal(zkProofJSON, &zkProof)
 	if err != nil {
 		return false, err
 	}
-	
-	// In a real ZKP system, this would involve a complex verification process
-	// This is a simplified verification that ensures the proof components match
+	if caller.ID != zkProof.VerifierID {
+		return false, fmt.Errorf("submitting client is not the verifier the ZK proof for evidence %s is bound to", evidenceID)
+	}
+
 	evidence, err := s.ReadEvidence(ctx, evidenceID)
 	if err != nil {
 		return false, err
 	}
-	
-	// Update the evidence record to mark it as verified
-	evidence.ProofVerified = true
-	evidenceJSON, err := json.Marshal(evidence)
+
+	if zkProof.Curve != zkp.CurveName || zkProof.GeneratorH != zkp.GeneratorH() {
+		return false, fmt.Errorf("ZK proof for evidence %s uses unsupported group parameters", evidenceID)
+	}
+	if zkProof.Commitment != evidence.FileCommitment {
+		return false, fmt.Errorf("ZK proof is not about the file commitment of evidence %s", evidenceID)
+	}
+
+	err = zkp.Verify(&zkp.Proof{
+		Commitment:       zkProof.Commitment,
+		NonceCommitment:  zkProof.NonceCommitment,
+		Challenge:        zkProof.Challenge,
+		ResponseValue:    zkProof.ResponseValue,
+		ResponseBlinding: zkProof.ResponseBlinding,
+	}, zkp.Context(evidenceID, zkProof.VerifierID))
 	if err != nil {
-		return false, err
+		return false, fmt.Errorf("invalid zero-knowledge proof for evidence %s: %v", evidenceID, err)
 	}
-	
-	err = ctx.GetStub().PutState(evidenceID, evidenceJSON)
+
+	// Update the evidence record to mark it as verified
+	previousIntegrity := evidence.Integrity
+	evidence.ProofVerified = true
+	err = putEvidence(ctx, evidence)
 	if err != nil {
 		return false, err
 	}
-	
+
 	// Record the verification in history
-	currentTime := time.Now().Format(time.RFC3339)
-	submitter, err := ctx.GetClientIdentity().GetID()
-	if err != nil {
-		submitter = "unknown"
-	}
-	
-	historyRecord := EvidenceHistory{
-		EvidenceID:  evidenceID,
-		ModifiedBy:  submitter,
-		ModifiedAt:  currentTime,
-		Action:      "verify",
-		Description: "Evidence verified via zero-knowledge proof",
-		PrevState:   "",
-	}
-	
-	historyKey := fmt.Sprintf("history~%s~%s", evidenceID, currentTime)
-	historyJSON, err := json.Marshal(historyRecord)
+	err = recordHistory(ctx, evidence, previousIntegrity, caller.ID, "verify", "Evidence verified via zero-knowledge proof", "")
 	if err != nil {
 		return false, err
 	}
-	
-	err = ctx.GetStub().PutState(historyKey, historyJSON)
+
+	err = emitEvidenceEvent(ctx, eventZKProofVerified, evidenceID, caller.ID, &zkProof)
 	if err != nil {
 		return false, err
 	}
-	
+
 	return true, nil
 }
 
-// PerformAITamperDetection simulates AI-driven tamper detection on evidence
-func (s *SmartContract) PerformAITamperDetection(
+// GetAIAnalysisResults retrieves all AI analysis results for a specific evidence
+func (s *SmartContract) GetAIAnalysisResults(
 	ctx contractapi.TransactionContextInterface,
 	evidenceID string,
-	aiSystemID string,
-	analysisDetails string,
-) (*AIAnalysisResult, error) {
-	// Check if evidence exists
-	evidence, err := s.ReadEvidence(ctx, evidenceID)
-	if err != nil {
-		return nil, err
-	}
-	
-	// In a real implementation, this would call an external AI service
-	// Here we simulate the AI analysis with some logic based on the evidence data
-	
-	// Calculate a simulated "tamper probability" 
-	// This is just a demonstration - in a real system, this would use actual ML algorithms
-	var tamperProb float64 = 0.0
-	
-	// If the evidence has no integrity hash, consider it potentially tampered
-	if evidence.Integrity == "" {
-		tamperProb += 0.3
-	} else {
-		// If integrity verification fails, high probability of tampering
-		integrityOK, _ := s.VerifyEvidenceIntegrity(ctx, evidenceID)
-		if !integrityOK {
-			tamperProb += 0.7
-		}
-	}
-	
-	// Create the analysis result
-	currentTime := time.Now().Format(time.RFC3339)
-	result := &AIAnalysisResult{
-		EvidenceID:       evidenceID,
-		TamperProbability: tamperProb,
-		AnalysisDetails:  analysisDetails,
-		AnalyzedBy:       aiSystemID,
-		AnalyzedTime:     currentTime,
-	}
-	
-	// Store the result on the ledger
-	resultKey := fmt.Sprintf("airesult~%s~%s", evidenceID, currentTime)
-	resultJSON, err := json.Marshal(result)
-	if err != nil {
-		return nil, err
-	}
-	
-	err = ctx.GetStub().PutState(resultKey, resultJSON)
-	if err != nil {
-		return nil, err
-	}
-	
-	// Update the evidence with the AI verification status
-	evidence.AIVerified = tamperProb < 0.5 // Consider verified if probability of tampering is low
-	evidenceJSON, err := json.Marshal(evidence)
-	if err != nil {
-		return nil, err
-	}
-	
-	err = ctx.GetStub().PutState(evidenceID, evidenceJSON)
-	if err != nil {
-		return nil, err
-	}
-	
-	// Record the AI analysis in history
-	submitter, err := ctx.GetClientIdentity().GetID()
-	if err != nil {
-		submitter = aiSystemID
-	}
-	
-	historyRecord := EvidenceHistory{
-		EvidenceID:  evidenceID,
-		ModifiedBy:  submitter,
-		ModifiedAt:  currentTime,
-		Action:      "ai_analysis",
-		Description: fmt.Sprintf("AI analysis performed with tamper probability: %.2f", tamperProb),
-		PrevState:   "",
-	}
-	
-	historyKey := fmt.Sprintf("history~%s~%s", evidenceID, currentTime)
-	historyJSON, err := json.Marshal(historyRecord)
-	if err != nil {
-		return nil, err
-	}
-	
-	err = ctx.GetStub().PutState(historyKey, historyJSON)
-	if err != nil {
-		return nil, err
-	}
-	
-	return result, nil
+) ([]*AIAnalysisResult, error) {
+	return getAIResults(ctx, evidenceID)
 }
 
-// GetAIAnalysisResults retrieves all AI analysis results for a specific evidence
-func (s *SmartContract) GetAIAnalysisResults(
+// GetZKProofs retrieves all zero-knowledge proofs for a specific evidence
+func (s *SmartContract) GetZKProofs(
 	ctx contractapi.TransactionContextInterface,
 	evidenceID string,
-) ([]*AIAnalysisResult, error) {
-	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey("airesult~", []string{evidenceID})
-	if err != nil {
-		return nil, err
-	}
-	defer resultsIterator.Close()
-	
-	var results []*AIAnalysisResult
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
-		
-		var result AIAnalysisResult
-		err = json.Unmarshal(queryResponse.Value, &result)
-		if err != nil {
-			return nil, err
-		}
-		
-		results = append(results, &result)
-	}
-	
-	return results, nil
+) ([]*ZKProof, error) {
+	return getZKProofs(ctx, evidenceID)
 }
 
 // SearchEvidenceByTags searches for evidence with matching tags
@@ -746,7 +872,7 @@ func (s *SmartContract) SearchEvidenceByTags(
 	if err != nil {
 		return nil, err
 	}
-	
+
 	// Filter based on tags
 	var matchingEvidence []*Evidence
 	for _, evidence := range allEvidence {
@@ -765,105 +891,111 @@ func (s *SmartContract) SearchEvidenceByTags(
 				break
 			}
 		}
-		
+
 		if hasAllTags {
 			matchingEvidence = append(matchingEvidence, evidence)
 		}
 	}
-	
+
 	return matchingEvidence, nil
 }
 
-// SearchEvidenceAdvanced performs an advanced search across multiple fields
-func (s *SmartContract) SearchEvidenceAdvanced(
-	ctx contractapi.TransactionContextInterface,
-	queryString string,
-) ([]*Evidence, error) {
-	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+// getSubmittingClientIdentity returns the name and issuer of the identity that
+// invokes the smart contract. The identity string is base64 decoded before it
+// is returned.
+func getSubmittingClientIdentity(ctx contractapi.TransactionContextInterface) (string, error) {
+	b64ID, err := ctx.GetClientIdentity().GetID()
 	if err != nil {
-		return nil, err
+		return "", fmt.Errorf("failed to read clientID: %v", err)
 	}
-	defer resultsIterator.Close()
-	
-	var evidence []*Evidence
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
-		
-		var ev Evidence
-		err = json.Unmarshal(queryResponse.Value, &ev)
-		if err != nil {
-			continue // Skip non-evidence entries
-		}
-		evidence = append(evidence, &ev)
+	decodeID, err := base64.StdEncoding.DecodeString(b64ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to base64 decode clientID: %v", err)
 	}
-	
-	return evidence, nil
+	return string(decodeID), nil
 }
 
-// GetEvidenceStatsByCaseID returns statistics about evidence for a specific case
-func (s *SmartContract) GetEvidenceStatsByCaseID(
-	ctx contractapi.TransactionContextInterface,
-	caseID string,
-) (string, error) {
-	// Get all evidence for the case
-	evidenceList, err := s.GetEvidenceByCase(ctx, caseID)
-	if err != nil {
-		return "", err
-	}
-	
-	// Calculate statistics
-	totalEvidence := len(evidenceList)
-	verifiedCount := 0
-	processingCount := 0
-	submitCount := 0
-	aiVerifiedCount := 0
-	zkpVerifiedCount := 0
-	
-	for _, ev := range evidenceList {
-		switch ev.Status {
-		case "verified":
-			verifiedCount++
-		case "processing":
-			processingCount++
-		case "submitted":
-			submitCount++
-		}
-		
-		if ev.AIVerified {
-			aiVerifiedCount++
-		}
-		
-		if ev.ProofVerified {
-			zkpVerifiedCount++
-		}
+// getEvidenceState returns the stored JSON of an evidence record, or nil if
+// there is none. Records not yet moved from their legacy bare ID key by
+// MigrateRecords are read from there.
+func getEvidenceState(ctx contractapi.TransactionContextInterface, id string) ([]byte, error) {
+	evidenceJSON, err := ctx.GetStub().GetState(evidenceKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if evidenceJSON != nil {
+		return evidenceJSON, nil
+	}
+
+	evidenceJSON, err = ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	return evidenceJSON, nil
+}
+
+// putEvidence seals an evidence record with a fresh integrity hash and writes
+// it to the world state in the current layout under its namespaced key,
+// removing any copy left under its legacy bare ID key. Every legitimate change
+// to evidence goes through here, so the stored hash always covers the stored
+// content.
+func putEvidence(ctx contractapi.TransactionContextInterface, evidence *Evidence) error {
+	evidence.DocType = evidenceDocType
+	evidence.SchemaVersion = evidenceSchemaVersion
+	sealEvidence(evidence)
+
+	evidenceJSON, err := json.Marshal(evidence)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutState(evidenceKey(evidence.ID), evidenceJSON)
+	if err != nil {
+		return err
+	}
+
+	legacyJSON, err := ctx.GetStub().GetState(evidence.ID)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if legacyJSON != nil {
+		return ctx.GetStub().DelState(evidence.ID)
 	}
-	
-	// Create statistics object
-	stats := struct {
-		CaseID            string `json:"caseID"`
-		TotalEvidence     int    `json:"totalEvidence"`
-		VerifiedEvidence  int    `json:"verifiedEvidence"`
-		ProcessingEvidence int    `json:"processingEvidence"`
-		SubmittedEvidence int    `json:"submittedEvidence"`
-		AIVerifiedEvidence int    `json:"aiVerifiedEvidence"`
-		ZKPVerifiedEvidence int   `json:"zkpVerifiedEvidence"`
-	}{
-		CaseID:            caseID,
-		TotalEvidence:     totalEvidence,
-		VerifiedEvidence:  verifiedCount,
-		ProcessingEvidence: processingCount,
-		SubmittedEvidence: submitCount,
-		AIVerifiedEvidence: aiVerifiedCount,
-		ZKPVerifiedEvidence: zkpVerifiedCount,
-	}
-	
-	statsJSON, err := json.Marshal(stats)
-	if err != nil {
-		return "", err
-	}
-	
-	return string(statsJSON), nil
-}
\ No newline at end of file
+
+	return nil
+}
+
+// recordHistory appends a history record for a change to an evidence record,
+// linking the integrity hash the record had before the change to the hash it
+// was sealed with by putEvidence
+func recordHistory(ctx contractapi.TransactionContextInterface, evidence *Evidence, previousIntegrity string, modifiedBy string, action string, description string, prevState string) error {
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	return appendHistory(ctx, &EvidenceHistory{
+		EvidenceID:        evidence.ID,
+		ModifiedBy:        modifiedBy,
+		ModifiedAt:        currentTime,
+		Action:            action,
+		Description:       description,
+		PrevState:         prevState,
+		TxID:              ctx.GetStub().GetTxID(),
+		PreviousIntegrity: previousIntegrity,
+		Integrity:         evidence.Integrity,
+	})
+}
+
+// getTxTimestamp returns the timestamp of the current transaction, as set by
+// the submitting client. Unlike the local clock it is the same on every
+// endorsing peer, so it is used for all times written to the ledger.
+func getTxTimestamp(ctx contractapi.TransactionContextInterface) (string, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return "", fmt.Errorf("failed to read transaction timestamp: %v", err)
+	}
+
+	return txTimestamp.AsTime().UTC().Format(time.RFC3339), nil
+}
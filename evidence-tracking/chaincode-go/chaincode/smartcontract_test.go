@@ -0,0 +1,749 @@
+package chaincode_test
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/zkp"
+	"github.com/stretchr/testify/require"
+)
+
+// Clients of the test network. officer1 and officer2 submitted, and hold, the
+// evidence created by InitLedger.
+var (
+	officer1   = newClient("officer1", "Org1MSP", "investigator")
+	officer2   = newClient("officer2", "Org1MSP", "investigator")
+	custodian  = newClient("custodian1", "Org1MSP", "custodian")
+	analyst    = newClient("analyst1", "Org2MSP", "analyst")
+	analyst2   = newClient("analyst2", "Org2MSP", "analyst")
+	prosecutor = newClient("prosecutor1", "Org2MSP", "prosecutor")
+	auditor    = newClient("auditor1", "Org2MSP", "auditor")
+	admin      = newClient("admin1", "Org1MSP", "admin")
+)
+
+// newClient returns a client with the given role attribute
+func newClient(id string, mspID string, role string) *mocks.Client {
+	return &mocks.Client{
+		ID:         id,
+		MSPID:      mspID,
+		Attributes: map[string]string{"role": role},
+	}
+}
+
+// setupLedger returns a ledger initialised by InitLedger, and the contract to
+// run transactions with
+func setupLedger(t *testing.T) (*mocks.Ledger, *chaincode.SmartContract) {
+	t.Helper()
+
+	ledger := mocks.NewLedger()
+	contract := &chaincode.SmartContract{}
+	err := ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.InitLedger(tx)
+	})
+	require.NoError(t, err)
+
+	return ledger, contract
+}
+
+// evaluate runs a query as a client and returns its result, failing the test
+// if the query fails
+func evaluate[T any](t *testing.T, ledger *mocks.Ledger, client *mocks.Client, query func(tx *mocks.Transaction) (T, error)) T {
+	t.Helper()
+
+	var result T
+	err := ledger.Evaluate(client, func(tx *mocks.Transaction) error {
+		var err error
+		result, err = query(tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	return result
+}
+
+// submitResult submits a transaction that returns a value as a client, and
+// returns the value once the transaction has committed
+func submitResult[T any](t *testing.T, ledger *mocks.Ledger, client *mocks.Client, transaction func(tx *mocks.Transaction) (T, error)) T {
+	t.Helper()
+
+	var result T
+	err := ledger.Submit(client, func(tx *mocks.Transaction) error {
+		var err error
+		result, err = transaction(tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	return result
+}
+
+// readEvidence returns the committed evidence record with the given ID
+func readEvidence(t *testing.T, ledger *mocks.Ledger, contract *chaincode.SmartContract, id string) *chaincode.Evidence {
+	t.Helper()
+	return evaluate(t, ledger, officer1, func(tx *mocks.Transaction) (*chaincode.Evidence, error) {
+		return contract.ReadEvidence(tx, id)
+	})
+}
+
+// submitEvidence submits general evidence against CASE1001 as officer1
+func submitEvidence(t *testing.T, ledger *mocks.Ledger, contract *chaincode.SmartContract, id string, tags ...string) {
+	t.Helper()
+	err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.SubmitEvidence(tx, id, "Evidence "+id, "CASE1001", "", "Qm"+id, tags, `{"source": "test"}`)
+	})
+	require.NoError(t, err)
+}
+
+// statusClients are the clients that move evidence into each status
+var statusClients = map[string]*mocks.Client{
+	"in_transit":         officer1,
+	"received":           custodian,
+	"processing":         custodian,
+	"analyzed":           analyst,
+	"presented_in_court": prosecutor,
+}
+
+// moveEvidence moves evidence through the given statuses in turn
+func moveEvidence(t *testing.T, ledger *mocks.Ledger, contract *chaincode.SmartContract, id string, statuses ...string) {
+	t.Helper()
+	for _, status := range statuses {
+		err := ledger.Submit(statusClients[status], func(tx *mocks.Transaction) error {
+			return contract.UpdateEvidenceStatus(tx, id, status, "")
+		})
+		require.NoError(t, err, "moving %s to %s", id, status)
+	}
+}
+
+// tamperEvidence changes a field of a stored evidence record behind the
+// contract's back, leaving its integrity hash as it was
+func tamperEvidence(t *testing.T, ledger *mocks.Ledger, id string, field string, value interface{}) {
+	t.Helper()
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(ledger.GetState("evidence~"+id), &record))
+	record[field] = value
+
+	recordJSON, err := json.Marshal(record)
+	require.NoError(t, err)
+	ledger.SetState("evidence~"+id, recordJSON)
+}
+
+// lastEvent returns the last chaincode event emitted, and checks that its
+// name matches its type
+func lastEvent(t *testing.T, ledger *mocks.Ledger) *chaincode.EvidenceEvent {
+	t.Helper()
+
+	chaincodeEvent := ledger.LastEvent()
+	require.NotNil(t, chaincodeEvent)
+
+	var event chaincode.EvidenceEvent
+	require.NoError(t, json.Unmarshal(chaincodeEvent.Payload, &event))
+	require.Equal(t, chaincodeEvent.EventName, event.Type)
+
+	return &event
+}
+
+func TestInitLedger(t *testing.T) {
+	ledger, contract := setupLedger(t)
+
+	evidence := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) ([]*chaincode.Evidence, error) {
+		return contract.GetAllEvidence(tx)
+	})
+	require.Len(t, evidence, 2)
+	require.Equal(t, "EV001", evidence[0].ID)
+	require.Equal(t, "verified", evidence[0].Status)
+	require.Equal(t, "officer1", evidence[0].CurrentCustodian)
+	require.Equal(t, "EV002", evidence[1].ID)
+	require.Equal(t, "officer2", evidence[1].CurrentCustodian)
+
+	for _, ev := range evidence {
+		require.True(t, evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (bool, error) {
+			return contract.VerifyEvidenceIntegrity(tx, ev.ID)
+		}), ev.ID)
+
+		chain := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.CustodyChain, error) {
+			return contract.GetCustodyChain(tx, ev.ID)
+		})
+		require.True(t, chain.Unbroken, ev.ID)
+		require.Equal(t, "Org1MSP", chain.Entries[0].ToMSPID)
+	}
+
+	c := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) (*chaincode.Case, error) {
+		return contract.ReadCase(tx, "CASE1001")
+	})
+	require.Equal(t, "open", c.Status)
+	require.Equal(t, "2024-01-01T09:00:00Z", c.OpenedAt)
+}
+
+func TestInitLedgerRunsOnce(t *testing.T) {
+	ledger := mocks.NewLedger()
+	contract := &chaincode.SmartContract{}
+
+	err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.InitLedger(tx)
+	})
+	require.EqualError(t, err, "submitting client not authorized to call InitLedger, role investigator is not permitted")
+
+	require.NoError(t, ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.InitLedger(tx)
+	}))
+
+	err = ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.SetAccessPolicy(tx, "SubmitEvidence", []string{"custodian"}, nil)
+	})
+	require.NoError(t, err)
+
+	err = ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.InitLedger(tx)
+	})
+	require.EqualError(t, err, "the ledger has already been initialized")
+
+	policies := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.AccessPolicy, error) {
+		return contract.GetAccessPolicies(tx)
+	})
+	for _, policy := range policies {
+		if policy.Function == "SubmitEvidence" {
+			require.Equal(t, []string{"custodian"}, policy.Roles)
+		}
+	}
+}
+
+func TestSubmitEvidence(t *testing.T) {
+	tests := []struct {
+		name         string
+		client       *mocks.Client
+		id           string
+		caseID       string
+		evidenceType string
+		metadata     string
+		err          string
+	}{
+		{
+			name:     "general evidence",
+			client:   officer1,
+			id:       "EV100",
+			caseID:   "CASE1001",
+			metadata: `{"location": "back door"}`,
+		},
+		{
+			name:         "typed evidence",
+			client:       custodian,
+			id:           "EV100",
+			caseID:       "CASE1001",
+			evidenceType: "video",
+			metadata:     `{"format": "mov", "duration": "00:01:30"}`,
+		},
+		{
+			name:   "existing ID",
+			client: officer1,
+			id:     "EV001",
+			caseID: "CASE1001",
+			err:    "the evidence EV001 already exists",
+		},
+		{
+			name:   "unknown case",
+			client: officer1,
+			id:     "EV100",
+			caseID: "CASE9999",
+			err:    "the case CASE9999 does not exist",
+		},
+		{
+			name:         "unknown evidence type",
+			client:       officer1,
+			id:           "EV100",
+			caseID:       "CASE1001",
+			evidenceType: "hologram",
+			err:          "unknown evidence type hologram",
+		},
+		{
+			name:         "metadata not matching the type",
+			client:       officer1,
+			id:           "EV100",
+			caseID:       "CASE1001",
+			evidenceType: "video",
+			metadata:     `{"duration": "00:01:30"}`,
+			err:          "metadata does not match evidence type video version 1",
+		},
+		{
+			name:   "role not permitted",
+			client: analyst,
+			id:     "EV100",
+			caseID: "CASE1001",
+			err:    "submitting client not authorized to call SubmitEvidence, role analyst is not permitted",
+		},
+		{
+			name:   "no role",
+			client: &mocks.Client{ID: "anonymous", MSPID: "Org1MSP"},
+			id:     "EV100",
+			caseID: "CASE1001",
+			err:    "submitting client not authorized to call SubmitEvidence, does not have a role attribute",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, contract := setupLedger(t)
+			submittedAt := ledger.Now().Format("2006-01-02T15:04:05Z")
+
+			err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+				return contract.SubmitEvidence(tx, test.id, "Footprint cast", test.caseID, test.evidenceType, "QmFootprint", []string{"footprint"}, test.metadata)
+			})
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+
+			evidence := readEvidence(t, ledger, contract, test.id)
+			require.Equal(t, "submitted", evidence.Status)
+			require.Equal(t, test.client.ID, evidence.SubmittedBy)
+			require.Equal(t, test.client.ID, evidence.CurrentCustodian)
+			require.Equal(t, submittedAt, evidence.SubmittedTime)
+			require.Equal(t, 1, evidence.EvidenceTypeVersion)
+			require.Equal(t, 1, evidence.IntegrityVersion)
+			if test.evidenceType == "" {
+				require.Equal(t, "general", evidence.EvidenceType)
+			}
+
+			event := lastEvent(t, ledger)
+			require.Equal(t, "EvidenceSubmitted", event.Type)
+			require.Equal(t, test.id, event.EvidenceID)
+			require.Equal(t, test.client.ID, event.Actor)
+
+			history := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.EvidenceHistory, error) {
+				return contract.GetEvidenceHistory(tx, test.id)
+			})
+			require.Len(t, history, 1)
+			require.Equal(t, "create", history[0].Action)
+			require.Equal(t, evidence.Integrity, history[0].Integrity)
+		})
+	}
+}
+
+func TestReadEvidence(t *testing.T) {
+	ledger, contract := setupLedger(t)
+
+	evidence := readEvidence(t, ledger, contract, "EV002")
+	require.Equal(t, "Fingerprint from door handle", evidence.Description)
+	require.Equal(t, []string{"fingerprint", "physical"}, evidence.Tags)
+
+	err := ledger.Evaluate(officer1, func(tx *mocks.Transaction) error {
+		_, err := contract.ReadEvidence(tx, "EV404")
+		return err
+	})
+	require.EqualError(t, err, "the evidence EV404 does not exist")
+
+	// Reading is a pure query
+	require.Len(t, ledger.Events(), 0)
+}
+
+func TestEvidenceExists(t *testing.T) {
+	ledger, contract := setupLedger(t)
+
+	// Evidence stored under its legacy bare ID key is found too
+	ledger.SetState("EV900", []byte(`{"ID": "EV900"}`))
+
+	for id, want := range map[string]bool{"EV001": true, "EV900": true, "EV404": false} {
+		exists := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) (bool, error) {
+			return contract.EvidenceExists(tx, id)
+		})
+		require.Equal(t, want, exists, id)
+	}
+}
+
+func TestUpdateEvidence(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   *mocks.Client
+		id       string
+		metadata string
+		err      string
+	}{
+		{
+			name:     "new details",
+			client:   officer1,
+			id:       "EV002",
+			metadata: `{"type": "patent", "surface": "glass"}`,
+		},
+		{
+			name:     "metadata not matching the type",
+			client:   officer1,
+			id:       "EV002",
+			metadata: `{"type": "smudged"}`,
+			err:      "metadata does not match evidence type fingerprint version 1",
+		},
+		{
+			name:   "unknown evidence",
+			client: officer1,
+			id:     "EV404",
+			err:    "the evidence EV404 does not exist",
+		},
+		{
+			name:   "role not permitted",
+			client: custodian,
+			id:     "EV002",
+			err:    "submitting client not authorized to call UpdateEvidence, role custodian is not permitted",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, contract := setupLedger(t)
+			before := ledger.GetState("evidence~EV002")
+
+			err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+				return contract.UpdateEvidence(tx, test.id, "Enhanced print", "QmEnhanced", []string{"fingerprint"}, test.metadata)
+			})
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				require.Equal(t, before, ledger.GetState("evidence~EV002"))
+				return
+			}
+			require.NoError(t, err)
+
+			evidence := readEvidence(t, ledger, contract, test.id)
+			require.Equal(t, "Enhanced print", evidence.Description)
+			require.Equal(t, "QmEnhanced", evidence.FileHash)
+			require.Equal(t, []string{"fingerprint"}, evidence.Tags)
+			require.Equal(t, test.metadata, evidence.Metadata)
+			require.Equal(t, "processing", evidence.Status)
+
+			event := lastEvent(t, ledger)
+			require.Equal(t, "EvidenceUpdated", event.Type)
+
+			history := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.EvidenceHistory, error) {
+				return contract.GetEvidenceHistory(tx, test.id)
+			})
+			require.Len(t, history, 2)
+			require.Equal(t, "update", history[1].Action)
+			require.Contains(t, history[1].PrevState, "Fingerprint from door handle")
+			require.Equal(t, history[0].Integrity, history[1].PreviousIntegrity)
+		})
+	}
+}
+
+func TestUpdateEvidenceOnceAnalyzed(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	designateVerifiers(t, ledger, contract, 1)
+	moveEvidence(t, ledger, contract, "EV003", "in_transit", "received", "processing", "analyzed")
+	err := ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		return contract.ApproveVerification(tx, "EV003", "Consistent")
+	})
+	require.NoError(t, err)
+	submitEvidence(t, ledger, contract, "EV004")
+	moveEvidence(t, ledger, contract, "EV004", "in_transit", "received", "processing", "analyzed")
+	err = ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+		return contract.UpdateEvidenceStatus(tx, "EV001", "presented_in_court", "")
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		id     string
+		status string
+	}{
+		{id: "EV004", status: "analyzed"},
+		{id: "EV003", status: "verified"},
+		{id: "EV001", status: "presented_in_court"},
+	}
+	for _, test := range tests {
+		t.Run(test.status, func(t *testing.T) {
+			evidence := readEvidence(t, ledger, contract, test.id)
+			require.Equal(t, test.status, evidence.Status)
+			before := ledger.GetState("evidence~" + test.id)
+
+			err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+				return contract.UpdateEvidence(tx, test.id, "Replaced", "QmReplaced", evidence.Tags, evidence.Metadata)
+			})
+			require.EqualError(t, err, "evidence "+test.id+" is "+test.status+" and its details can no longer be updated")
+			require.Equal(t, before, ledger.GetState("evidence~"+test.id))
+		})
+	}
+
+	// Evidence sent back for analysis can be updated again
+	err = ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		return contract.UpdateEvidenceStatus(tx, "EV004", "processing", "NEW_LEAD")
+	})
+	require.NoError(t, err)
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.UpdateEvidence(tx, "EV004", "Replaced", "QmReplaced", nil, `{"source": "test"}`)
+	})
+	require.NoError(t, err)
+	require.Equal(t, "QmReplaced", readEvidence(t, ledger, contract, "EV004").FileHash)
+}
+
+func TestGetEvidenceByCase(t *testing.T) {
+	ledger, contract := setupLedger(t)
+
+	err := ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.CreateCase(tx, "CASE2001", "Harbour fraud", "", "District Court", "officer2", nil)
+	})
+	require.NoError(t, err)
+	err = ledger.Submit(officer2, func(tx *mocks.Transaction) error {
+		return contract.SubmitEvidence(tx, "EV200", "Ledger book", "CASE2001", "document", "QmLedger", nil, `{"documentType": "ledger"}`)
+	})
+	require.NoError(t, err)
+
+	evidence := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) ([]*chaincode.Evidence, error) {
+		return contract.GetEvidenceByCase(tx, "CASE1001")
+	})
+	require.Len(t, evidence, 2)
+
+	evidence = evaluate(t, ledger, officer1, func(tx *mocks.Transaction) ([]*chaincode.Evidence, error) {
+		return contract.GetEvidenceByCase(tx, "CASE2001")
+	})
+	require.Len(t, evidence, 1)
+	require.Equal(t, "EV200", evidence[0].ID)
+
+	evidence = evaluate(t, ledger, officer1, func(tx *mocks.Transaction) ([]*chaincode.Evidence, error) {
+		return contract.GetEvidenceByCase(tx, "CASE404")
+	})
+	require.Empty(t, evidence)
+}
+
+func TestGetAllEvidence(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+
+	evidence := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) ([]*chaincode.Evidence, error) {
+		return contract.GetAllEvidence(tx)
+	})
+
+	var ids []string
+	for _, ev := range evidence {
+		ids = append(ids, ev.ID)
+	}
+	require.Equal(t, []string{"EV001", "EV002", "EV003"}, ids)
+}
+
+func TestSearchEvidenceByTags(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003", "Video", "interior")
+
+	tests := []struct {
+		tags []string
+		want []string
+	}{
+		{tags: []string{"video"}, want: []string{"EV001", "EV003"}},
+		{tags: []string{"VIDEO", "surveillance"}, want: []string{"EV001"}},
+		{tags: []string{"physical"}, want: []string{"EV002"}},
+		{tags: []string{"video", "physical"}, want: nil},
+		{tags: nil, want: []string{"EV001", "EV002", "EV003"}},
+	}
+	for _, test := range tests {
+		evidence := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) ([]*chaincode.Evidence, error) {
+			return contract.SearchEvidenceByTags(tx, test.tags)
+		})
+
+		var ids []string
+		for _, ev := range evidence {
+			ids = append(ids, ev.ID)
+		}
+		require.Equal(t, test.want, ids, "%v", test.tags)
+	}
+}
+
+func TestGetEvidenceHistory(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	moveEvidence(t, ledger, contract, "EV003", "in_transit", "received")
+
+	history := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.EvidenceHistory, error) {
+		return contract.GetEvidenceHistory(tx, "EV003")
+	})
+	require.Len(t, history, 3)
+
+	for i, record := range history {
+		require.Equal(t, i, record.Sequence)
+		if i > 0 {
+			require.Equal(t, history[i-1].EntryHash, record.PreviousEntryHash)
+			require.Equal(t, history[i-1].Integrity, record.PreviousIntegrity)
+		}
+	}
+	require.Equal(t, "Status updated to 'received'", history[2].Description)
+	require.Equal(t, "custodian1", history[2].ModifiedBy)
+
+	history = evaluate(t, ledger, auditor, func(tx *mocks.Transaction) ([]*chaincode.EvidenceHistory, error) {
+		return contract.GetEvidenceHistory(tx, "EV404")
+	})
+	require.Empty(t, history)
+}
+
+func TestVerifyEvidenceIntegrity(t *testing.T) {
+	tests := []struct {
+		name   string
+		field  string
+		value  interface{}
+		client *mocks.Client
+		valid  bool
+		err    string
+	}{
+		{name: "untouched record", client: auditor, valid: true},
+		{name: "changed description", field: "Description", value: "Something else", client: auditor},
+		{name: "changed status", field: "Status", value: "released", client: auditor},
+		{name: "changed custodian", field: "CurrentCustodian", value: "mallory", client: auditor},
+		{name: "removed hash", field: "Integrity", value: "", client: auditor, err: "evidence EV001 has no integrity hash"},
+		{name: "unknown hash version", field: "IntegrityVersion", value: 99, client: auditor, err: "unsupported integrity version 99"},
+		{name: "role not permitted", client: admin, err: "submitting client not authorized to call VerifyEvidenceIntegrity"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, contract := setupLedger(t)
+			if test.field != "" {
+				tamperEvidence(t, ledger, "EV001", test.field, test.value)
+			}
+
+			var valid bool
+			err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+				var err error
+				valid, err = contract.VerifyEvidenceIntegrity(tx, "EV001")
+				return err
+			})
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.valid, valid)
+
+			event := lastEvent(t, ledger)
+			require.Equal(t, "IntegrityVerified", event.Type)
+			var check chaincode.IntegrityCheck
+			require.NoError(t, json.Unmarshal(event.Payload, &check))
+			require.Equal(t, test.valid, check.Valid)
+		})
+	}
+}
+
+// proveFile builds a zero-knowledge proof of knowledge of a file's digest,
+// made for a verifier, as a client would off-chain
+func proveFile(t *testing.T, file string, evidenceID string, verifierID string) zkp.Proof {
+	t.Helper()
+
+	digest := sha256.Sum256([]byte(file))
+	commitment, opening, err := zkp.Commit(digest[:])
+	require.NoError(t, err)
+
+	proof, err := zkp.Prove(commitment, opening, zkp.Context(evidenceID, verifierID))
+	require.NoError(t, err)
+
+	return *proof
+}
+
+func TestCreateAndVerifyZKProof(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	proof := proveFile(t, "surveillance footage", "EV001", "prosecutor1")
+
+	// Only the custodian may fix the commitment
+	err := ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		_, err := contract.CreateZKProof(tx, "EV001", proof, "prosecutor1")
+		return err
+	})
+	require.EqualError(t, err, "only the current custodian may commit to the file of evidence EV001")
+
+	// A proof is bound to the verifier it was made for
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		_, err := contract.CreateZKProof(tx, "EV001", proof, "analyst1")
+		return err
+	})
+	require.ErrorContains(t, err, "invalid zero-knowledge proof for evidence EV001")
+
+	recorded := submitResult(t, ledger, officer1, func(tx *mocks.Transaction) (*chaincode.ZKProof, error) {
+		return contract.CreateZKProof(tx, "EV001", proof, "prosecutor1")
+	})
+	require.Equal(t, "officer1", recorded.ProverID)
+	require.Equal(t, proof.Commitment, readEvidence(t, ledger, contract, "EV001").FileCommitment)
+
+	// The same proof cannot be recorded twice
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		_, err := contract.CreateZKProof(tx, "EV001", proof, "prosecutor1")
+		return err
+	})
+	require.EqualError(t, err, "the proof was already recorded for evidence EV001 in transaction "+recorded.TxID)
+
+	// Later proofs must be about the committed file
+	other := proveFile(t, "other footage", "EV001", "prosecutor1")
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		_, err := contract.CreateZKProof(tx, "EV001", other, "prosecutor1")
+		return err
+	})
+	require.EqualError(t, err, "proof is not about the file commitment of evidence EV001")
+
+	proofs := evaluate(t, ledger, officer1, func(tx *mocks.Transaction) ([]*chaincode.ZKProof, error) {
+		return contract.GetZKProofs(tx, "EV001")
+	})
+	require.Len(t, proofs, 1)
+
+	err = ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+		_, err := contract.VerifyZKProof(tx, "EV001", recorded.CreatedTime, "unknown")
+		return err
+	})
+	require.EqualError(t, err, "no ZK proof exists for evidence EV001 at time "+recorded.CreatedTime+" in transaction unknown")
+
+	// Only the verifier the proof is bound to may verify it
+	err = ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		_, err := contract.VerifyZKProof(tx, "EV001", recorded.CreatedTime, recorded.TxID)
+		return err
+	})
+	require.EqualError(t, err, "submitting client is not the verifier the ZK proof for evidence EV001 is bound to")
+	require.False(t, readEvidence(t, ledger, contract, "EV001").ProofVerified)
+
+	verified := submitResult(t, ledger, prosecutor, func(tx *mocks.Transaction) (bool, error) {
+		return contract.VerifyZKProof(tx, "EV001", recorded.CreatedTime, recorded.TxID)
+	})
+	require.True(t, verified)
+	require.True(t, readEvidence(t, ledger, contract, "EV001").ProofVerified)
+	require.Equal(t, "ZKProofVerified", lastEvent(t, ledger).Type)
+
+	report := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.HistoryChainReport, error) {
+		return contract.VerifyHistoryChain(tx, "EV001")
+	})
+	require.True(t, report.Valid)
+	require.Equal(t, 3, report.Length)
+}
+
+func TestUpdateEvidenceFileDropsProofs(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	proof := proveFile(t, "fingerprint scan", "EV002", "prosecutor1")
+	recorded := submitResult(t, ledger, officer2, func(tx *mocks.Transaction) (*chaincode.ZKProof, error) {
+		return contract.CreateZKProof(tx, "EV002", proof, "prosecutor1")
+	})
+	submitResult(t, ledger, prosecutor, func(tx *mocks.Transaction) (bool, error) {
+		return contract.VerifyZKProof(tx, "EV002", recorded.CreatedTime, recorded.TxID)
+	})
+	evidence := readEvidence(t, ledger, contract, "EV002")
+	require.True(t, evidence.ProofVerified)
+
+	// Changing other details keeps the proof
+	err := ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.UpdateEvidence(tx, "EV002", "Partial print", evidence.FileHash, evidence.Tags, evidence.Metadata)
+	})
+	require.NoError(t, err)
+	evidence = readEvidence(t, ledger, contract, "EV002")
+	require.True(t, evidence.ProofVerified)
+	require.Equal(t, proof.Commitment, evidence.FileCommitment)
+
+	// A new file drops the commitment, and proofs of the old file no longer
+	// verify
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.UpdateEvidence(tx, "EV002", "Partial print", "QmRescanned", evidence.Tags, evidence.Metadata)
+	})
+	require.NoError(t, err)
+	evidence = readEvidence(t, ledger, contract, "EV002")
+	require.False(t, evidence.ProofVerified)
+	require.Empty(t, evidence.FileCommitment)
+
+	err = ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+		_, err := contract.VerifyZKProof(tx, "EV002", recorded.CreatedTime, recorded.TxID)
+		return err
+	})
+	require.EqualError(t, err, "ZK proof is not about the file commitment of evidence EV002")
+
+	// The custodian commits to the new file
+	rescanned := proveFile(t, "fingerprint rescan", "EV002", "prosecutor1")
+	submitResult(t, ledger, officer2, func(tx *mocks.Transaction) (*chaincode.ZKProof, error) {
+		return contract.CreateZKProof(tx, "EV002", rescanned, "prosecutor1")
+	})
+	require.Equal(t, rescanned.Commitment, readEvidence(t, ledger, contract, "EV002").FileCommitment)
+}
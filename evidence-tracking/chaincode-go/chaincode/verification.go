@@ -0,0 +1,352 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/statebased"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Verifier is a client designated to approve the verification of an evidence
+// item
+type Verifier struct {
+	ID    string `json:"ID"`    // ID of the client, as returned by GetSubmittingClientIdentity
+	MSPID string `json:"MSPID"` // MSP the client belongs to
+}
+
+// VerificationPanel is the set of verifiers whose approval an evidence item
+// needs before it can be marked as verified
+type VerificationPanel struct {
+	EvidenceID   string     `json:"EvidenceID"`   // ID of the evidence to verify
+	Verifiers    []Verifier `json:"Verifiers"`    // Clients that may approve the verification
+	Quorum       int        `json:"Quorum"`       // Number of approvals needed
+	Orgs         []string   `json:"Orgs"`         // Organizations whose peers must endorse changes to the evidence
+	DesignatedBy string     `json:"DesignatedBy"` // ID of the client that designated the verifiers
+	DesignatedAt string     `json:"DesignatedAt"` // When the verifiers were designated
+}
+
+// VerificationApproval is the sign-off of one designated verifier
+type VerificationApproval struct {
+	EvidenceID    string `json:"EvidenceID"`    // ID of the evidence approved
+	VerifierID    string `json:"VerifierID"`    // ID of the verifier
+	VerifierMSPID string `json:"VerifierMSPID"` // MSP of the verifier
+	Findings      string `json:"Findings"`      // What the verifier found
+	Integrity     string `json:"Integrity"`     // Integrity hash of the evidence that was approved
+	ApprovedAt    string `json:"ApprovedAt"`    // When the approval was given
+	TxID          string `json:"TxID"`          // Transaction that recorded the approval
+}
+
+// VerificationStatus is the progress of an evidence item towards its
+// verification quorum
+type VerificationStatus struct {
+	Panel     *VerificationPanel      `json:"Panel"`     // Designated verifiers and quorum
+	Status    string                  `json:"Status"`    // Current status of the evidence
+	Approvals []*VerificationApproval `json:"Approvals"` // Every approval recorded, ordered by verifier ID
+	Current   int                     `json:"Current"`   // Approvals given for the evidence as it now stands
+}
+
+// DesignateVerifiers names the verifiers of an evidence item and how many of
+// them must approve it. The key-level endorsement policy of the evidence is
+// set to require the peers of every verifier's organization, so from then on
+// no organization can change the evidence, its status included, alone.
+func (s *SmartContract) DesignateVerifiers(
+	ctx contractapi.TransactionContextInterface,
+	id string,
+	verifiers []Verifier,
+	quorum int,
+) error {
+	caller, err := authorize(ctx, "DesignateVerifiers")
+	if err != nil {
+		return err
+	}
+
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return err
+	}
+	if evidence.Status == statusVerified {
+		return fmt.Errorf("evidence %s is already verified", id)
+	}
+
+	existing, err := getVerificationPanel(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("verifiers are already designated for evidence %s", id)
+	}
+
+	if len(verifiers) == 0 {
+		return fmt.Errorf("at least one verifier is required")
+	}
+	if quorum < 1 || quorum > len(verifiers) {
+		return fmt.Errorf("the quorum must be between 1 and the number of verifiers (%d)", len(verifiers))
+	}
+
+	seen := map[string]bool{}
+	orgs := []string{}
+	for _, verifier := range verifiers {
+		if verifier.ID == "" || verifier.MSPID == "" {
+			return fmt.Errorf("every verifier needs an ID and an MSP ID")
+		}
+		if seen[verifier.ID] {
+			return fmt.Errorf("verifier %s is designated more than once", verifier.ID)
+		}
+		seen[verifier.ID] = true
+		if !containsString(orgs, verifier.MSPID) {
+			orgs = append(orgs, verifier.MSPID)
+		}
+	}
+	sort.Strings(orgs)
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	panel := &VerificationPanel{
+		EvidenceID:   id,
+		Verifiers:    verifiers,
+		Quorum:       quorum,
+		Orgs:         orgs,
+		DesignatedBy: caller.ID,
+		DesignatedAt: currentTime,
+	}
+	panelKey, err := verificationPanelKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = putRecord(ctx, panelKey, panel)
+	if err != nil {
+		return err
+	}
+
+	// The panel is protected too, so it cannot be replaced without the
+	// organizations it names
+	for _, key := range []string{evidenceKey(id), panelKey} {
+		err = setKeyEndorsingOrgs(ctx, key, orgs)
+		if err != nil {
+			return err
+		}
+	}
+
+	return recordHistory(ctx, evidence, evidence.Integrity, caller.ID, "verification_panel",
+		fmt.Sprintf("%d of %d verifiers from %v must approve verification", quorum, len(verifiers), orgs), "")
+}
+
+// ApproveVerification records the sign-off of a designated verifier. The
+// approval that reaches the quorum moves the evidence from analyzed to
+// verified. Approvals only count while the evidence is unchanged since they
+// were given.
+func (s *SmartContract) ApproveVerification(ctx contractapi.TransactionContextInterface, id string, findings string) error {
+	caller, err := authorize(ctx, "ApproveVerification")
+	if err != nil {
+		return err
+	}
+
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return err
+	}
+	if evidence.Status != statusAnalyzed {
+		return fmt.Errorf("evidence %s must be %s to be verified, not %s", id, statusAnalyzed, evidence.Status)
+	}
+
+	panel, err := getVerificationPanel(ctx, id)
+	if err != nil {
+		return err
+	}
+	if panel == nil {
+		return fmt.Errorf("no verifiers are designated for evidence %s", id)
+	}
+	if !panel.includes(caller) {
+		return fmt.Errorf("submitting client is not a designated verifier of evidence %s", id)
+	}
+	if findings == "" {
+		return fmt.Errorf("findings are required to approve verification")
+	}
+
+	approvals, err := getVerificationApprovals(ctx, id)
+	if err != nil {
+		return err
+	}
+	current := 0
+	for _, approval := range approvals {
+		if approval.VerifierID == caller.ID && approval.Integrity == evidence.Integrity {
+			return fmt.Errorf("submitting client has already approved evidence %s", id)
+		}
+		if approval.Integrity == evidence.Integrity {
+			current++
+		}
+	}
+
+	currentTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	approval := &VerificationApproval{
+		EvidenceID:    id,
+		VerifierID:    caller.ID,
+		VerifierMSPID: caller.MSPID,
+		Findings:      findings,
+		Integrity:     evidence.Integrity,
+		ApprovedAt:    currentTime,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+	approvalKey, err := verificationApprovalKey(ctx, id, caller.ID)
+	if err != nil {
+		return err
+	}
+	err = putRecord(ctx, approvalKey, approval)
+	if err != nil {
+		return err
+	}
+	current++
+
+	if current < panel.Quorum {
+		err = recordHistory(ctx, evidence, evidence.Integrity, caller.ID, "verification_approval",
+			fmt.Sprintf("Verification approved (%d of %d)", current, panel.Quorum), "")
+		if err != nil {
+			return err
+		}
+		return emitEvidenceEvent(ctx, eventVerificationApproved, id, caller.ID, approval)
+	}
+
+	prevStateJSON, err := json.Marshal(evidence)
+	if err != nil {
+		return err
+	}
+
+	previousIntegrity := evidence.Integrity
+	evidence.Status = statusVerified
+	evidence.StatusUpdatedTime = currentTime
+	err = putEvidence(ctx, evidence)
+	if err != nil {
+		return err
+	}
+
+	err = recordHistory(ctx, evidence, previousIntegrity, caller.ID, "update",
+		fmt.Sprintf("Status updated to '%s' (%d of %d verifiers approved)", statusVerified, current, panel.Quorum), string(prevStateJSON))
+	if err != nil {
+		return err
+	}
+
+	return emitEvidenceEvent(ctx, eventStatusChanged, id, caller.ID, &StatusChange{
+		From: statusAnalyzed,
+		To:   statusVerified,
+	})
+}
+
+// GetVerificationStatus returns the designated verifiers of an evidence item
+// and the approvals they have given. Once the evidence is verified its
+// integrity hash changes, so Current counts only approvals given since.
+func (s *SmartContract) GetVerificationStatus(ctx contractapi.TransactionContextInterface, id string) (*VerificationStatus, error) {
+	evidence, err := s.ReadEvidence(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	panel, err := getVerificationPanel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if panel == nil {
+		return nil, fmt.Errorf("no verifiers are designated for evidence %s", id)
+	}
+
+	approvals, err := getVerificationApprovals(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &VerificationStatus{
+		Panel:     panel,
+		Status:    evidence.Status,
+		Approvals: approvals,
+	}
+	for _, approval := range approvals {
+		if approval.Integrity == evidence.Integrity {
+			status.Current++
+		}
+	}
+
+	return status, nil
+}
+
+// includes reports whether a client is one of the designated verifiers
+func (p *VerificationPanel) includes(client *clientIdentity) bool {
+	for _, verifier := range p.Verifiers {
+		if verifier.ID == client.ID && verifier.MSPID == client.MSPID {
+			return true
+		}
+	}
+	return false
+}
+
+// setKeyEndorsingOrgs sets the key-level endorsement policy of a key so that
+// writes to it must be endorsed by a peer of every given organization
+func setKeyEndorsingOrgs(ctx contractapi.TransactionContextInterface, key string, orgs []string) error {
+	endorsementPolicy, err := statebased.NewStateEP(nil)
+	if err != nil {
+		return err
+	}
+	err = endorsementPolicy.AddOrgs(statebased.RoleTypePeer, orgs...)
+	if err != nil {
+		return fmt.Errorf("failed to add org to endorsement policy: %v", err)
+	}
+	policy, err := endorsementPolicy.Policy()
+	if err != nil {
+		return fmt.Errorf("failed to create endorsement policy bytes from org: %v", err)
+	}
+	err = ctx.GetStub().SetStateValidationParameter(key, policy)
+	if err != nil {
+		return fmt.Errorf("failed to set validation parameter on %s: %v", key, err)
+	}
+
+	return nil
+}
+
+// getVerificationPanel returns the verification panel of an evidence item, or
+// nil if no verifiers are designated
+func getVerificationPanel(ctx contractapi.TransactionContextInterface, evidenceID string) (*VerificationPanel, error) {
+	key, err := verificationPanelKey(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	panelJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if panelJSON == nil {
+		return nil, nil
+	}
+
+	var panel VerificationPanel
+	err = json.Unmarshal(panelJSON, &panel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &panel, nil
+}
+
+// getVerificationApprovals returns the verification approvals of an evidence
+// item ordered by verifier ID
+func getVerificationApprovals(ctx contractapi.TransactionContextInterface, evidenceID string) ([]*VerificationApproval, error) {
+	approvals := []*VerificationApproval{}
+	err := forEachRecord(ctx, verificationApprovalObjectType, evidenceID, func(value []byte) error {
+		var approval VerificationApproval
+		err := json.Unmarshal(value, &approval)
+		if err != nil {
+			return err
+		}
+		approvals = append(approvals, &approval)
+		return nil
+	})
+
+	return approvals, err
+}
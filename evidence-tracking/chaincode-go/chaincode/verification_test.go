@@ -0,0 +1,191 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// verifiers is a panel of three verifiers from two organizations
+var verifiers = []chaincode.Verifier{
+	{ID: "analyst1", MSPID: "Org2MSP"},
+	{ID: "prosecutor1", MSPID: "Org2MSP"},
+	{ID: "officer2", MSPID: "Org1MSP"},
+}
+
+// designateVerifiers designates the verifiers of EV003 as the prosecutor
+func designateVerifiers(t *testing.T, ledger *mocks.Ledger, contract *chaincode.SmartContract, quorum int) {
+	t.Helper()
+	err := ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+		return contract.DesignateVerifiers(tx, "EV003", verifiers, quorum)
+	})
+	require.NoError(t, err)
+}
+
+func TestDesignateVerifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		client    *mocks.Client
+		verifiers []chaincode.Verifier
+		quorum    int
+		err       string
+	}{
+		{name: "without verifiers", client: prosecutor, quorum: 1, err: "at least one verifier is required"},
+		{name: "quorum too low", client: prosecutor, verifiers: verifiers, quorum: 0, err: "the quorum must be between 1 and the number of verifiers (3)"},
+		{name: "quorum too high", client: prosecutor, verifiers: verifiers, quorum: 4, err: "the quorum must be between 1 and the number of verifiers (3)"},
+		{name: "without MSP ID", client: prosecutor, verifiers: []chaincode.Verifier{{ID: "analyst1"}}, quorum: 1, err: "every verifier needs an ID and an MSP ID"},
+		{name: "duplicate verifier", client: admin, verifiers: []chaincode.Verifier{verifiers[0], verifiers[0]}, quorum: 1, err: "verifier analyst1 is designated more than once"},
+		{name: "role not permitted", client: officer1, verifiers: verifiers, quorum: 2, err: "submitting client not authorized to call DesignateVerifiers, role investigator is not permitted"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, contract := setupLedger(t)
+			submitEvidence(t, ledger, contract, "EV003")
+
+			err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+				return contract.DesignateVerifiers(tx, "EV003", test.verifiers, test.quorum)
+			})
+			require.EqualError(t, err, test.err)
+			require.Nil(t, ledger.ValidationParameter("evidence~EV003"))
+		})
+	}
+}
+
+func TestDesignateVerifiersSetsEndorsementPolicy(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	designateVerifiers(t, ledger, contract, 2)
+
+	require.NotEmpty(t, ledger.ValidationParameter("evidence~EV003"))
+	require.NotEmpty(t, ledger.ValidationParameter(compositeKey("verificationpanel", "EV003")))
+	require.Nil(t, ledger.ValidationParameter("evidence~EV001"))
+
+	status := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.VerificationStatus, error) {
+		return contract.GetVerificationStatus(tx, "EV003")
+	})
+	require.Equal(t, []string{"Org1MSP", "Org2MSP"}, status.Panel.Orgs)
+	require.Equal(t, 2, status.Panel.Quorum)
+	require.Equal(t, "prosecutor1", status.Panel.DesignatedBy)
+	require.Empty(t, status.Approvals)
+
+	err := ledger.Submit(admin, func(tx *mocks.Transaction) error {
+		return contract.DesignateVerifiers(tx, "EV003", verifiers[:1], 1)
+	})
+	require.EqualError(t, err, "verifiers are already designated for evidence EV003")
+}
+
+func TestApproveVerification(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+
+	err := ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		return contract.ApproveVerification(tx, "EV003", "Consistent")
+	})
+	require.EqualError(t, err, "evidence EV003 must be analyzed to be verified, not submitted")
+
+	moveEvidence(t, ledger, contract, "EV003", "in_transit", "received", "processing", "analyzed")
+	err = ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		return contract.ApproveVerification(tx, "EV003", "Consistent")
+	})
+	require.EqualError(t, err, "no verifiers are designated for evidence EV003")
+
+	designateVerifiers(t, ledger, contract, 2)
+
+	tests := []struct {
+		name     string
+		client   *mocks.Client
+		findings string
+		err      string
+	}{
+		{name: "not designated", client: officer1, findings: "Consistent", err: "submitting client is not a designated verifier of evidence EV003"},
+		{name: "designated in another organization", client: newClient("analyst1", "Org1MSP", "analyst"), findings: "Consistent", err: "submitting client is not a designated verifier of evidence EV003"},
+		{name: "without findings", client: analyst, err: "findings are required to approve verification"},
+	}
+	for _, test := range tests {
+		err := ledger.Submit(test.client, func(tx *mocks.Transaction) error {
+			return contract.ApproveVerification(tx, "EV003", test.findings)
+		})
+		require.EqualError(t, err, test.err, test.name)
+	}
+
+	err = ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		return contract.ApproveVerification(tx, "EV003", "Consistent")
+	})
+	require.NoError(t, err)
+	require.Equal(t, "analyzed", readEvidence(t, ledger, contract, "EV003").Status)
+
+	event := lastEvent(t, ledger)
+	require.Equal(t, "VerificationApproved", event.Type)
+	var approval chaincode.VerificationApproval
+	require.NoError(t, json.Unmarshal(event.Payload, &approval))
+	require.Equal(t, "analyst1", approval.VerifierID)
+	require.Equal(t, "Org2MSP", approval.VerifierMSPID)
+
+	err = ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		return contract.ApproveVerification(tx, "EV003", "Consistent")
+	})
+	require.EqualError(t, err, "submitting client has already approved evidence EV003")
+
+	err = ledger.Submit(officer2, func(tx *mocks.Transaction) error {
+		return contract.ApproveVerification(tx, "EV003", "Matches the scene photographs")
+	})
+	require.NoError(t, err)
+	require.Equal(t, "verified", readEvidence(t, ledger, contract, "EV003").Status)
+
+	event = lastEvent(t, ledger)
+	require.Equal(t, "StatusChanged", event.Type)
+	var change chaincode.StatusChange
+	require.NoError(t, json.Unmarshal(event.Payload, &change))
+	require.Equal(t, chaincode.StatusChange{From: "analyzed", To: "verified"}, change)
+
+	status := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.VerificationStatus, error) {
+		return contract.GetVerificationStatus(tx, "EV003")
+	})
+	require.Equal(t, "verified", status.Status)
+	require.Len(t, status.Approvals, 2)
+	require.Equal(t, "analyst1", status.Approvals[0].VerifierID)
+	require.Equal(t, "officer2", status.Approvals[1].VerifierID)
+	require.Zero(t, status.Current)
+}
+
+func TestApprovalsLapseWhenEvidenceChanges(t *testing.T) {
+	ledger, contract := setupLedger(t)
+	submitEvidence(t, ledger, contract, "EV003")
+	designateVerifiers(t, ledger, contract, 2)
+	moveEvidence(t, ledger, contract, "EV003", "in_transit", "received", "processing", "analyzed")
+
+	err := ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		return contract.ApproveVerification(tx, "EV003", "Consistent")
+	})
+	require.NoError(t, err)
+
+	// Sending the evidence back for analysis changes it, so the approval
+	// given no longer counts towards the quorum
+	err = ledger.Submit(officer1, func(tx *mocks.Transaction) error {
+		return contract.UpdateEvidenceStatus(tx, "EV003", "processing", "NEW_LEAD")
+	})
+	require.NoError(t, err)
+	moveEvidence(t, ledger, contract, "EV003", "analyzed")
+
+	err = ledger.Submit(prosecutor, func(tx *mocks.Transaction) error {
+		return contract.ApproveVerification(tx, "EV003", "Consistent")
+	})
+	require.NoError(t, err)
+	require.Equal(t, "analyzed", readEvidence(t, ledger, contract, "EV003").Status)
+
+	status := evaluate(t, ledger, auditor, func(tx *mocks.Transaction) (*chaincode.VerificationStatus, error) {
+		return contract.GetVerificationStatus(tx, "EV003")
+	})
+	require.Len(t, status.Approvals, 2)
+	require.Equal(t, 1, status.Current)
+
+	// The verifier whose approval lapsed may approve again
+	err = ledger.Submit(analyst, func(tx *mocks.Transaction) error {
+		return contract.ApproveVerification(tx, "EV003", "Still consistent")
+	})
+	require.NoError(t, err)
+	require.Equal(t, "verified", readEvidence(t, ledger, contract, "EV003").Status)
+}
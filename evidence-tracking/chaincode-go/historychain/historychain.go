@@ -0,0 +1,171 @@
+// Package historychain defines the hash chain that links the history entries
+// of an evidence item. Each entry carries a sequence number and the hash of
+// the entry before it, so an entry cannot be altered, removed or reordered
+// without breaking the chain. The package depends only on the standard
+// library, so a chain exported from the ledger can be checked by anyone.
+package historychain
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"sort"
+)
+
+// Algorithm identifies how entry hashes are computed
+const Algorithm = "sha256-length-prefixed-v1"
+
+// domain prefixes the encoding of every entry, so an entry hash can never
+// collide with a hash of some other kind of record
+const domain = "evidence-history"
+
+// Entry is one link of the chain. Its fields and JSON names match the
+// EvidenceHistory records of the evidence contract.
+type Entry struct {
+	EvidenceID        string `json:"EvidenceID"`
+	Sequence          int    `json:"Sequence"`
+	ModifiedBy        string `json:"ModifiedBy"`
+	ModifiedAt        string `json:"ModifiedAt"`
+	Action            string `json:"Action"`
+	Description       string `json:"Description"`
+	PrevState         string `json:"PrevState"`
+	TxID              string `json:"TxID"`
+	PreviousIntegrity string `json:"PreviousIntegrity"`
+	Integrity         string `json:"Integrity"`
+	PreviousEntryHash string `json:"PreviousEntryHash"`
+	EntryHash         string `json:"EntryHash"`
+}
+
+// Head records the last entry of a chain, so entries removed from the end of
+// the chain are detected too
+type Head struct {
+	EvidenceID string `json:"EvidenceID"`
+	Sequence   int    `json:"Sequence"`
+	EntryHash  string `json:"EntryHash"`
+}
+
+// Break describes the first point at which a chain fails to verify
+type Break struct {
+	Sequence int    `json:"Sequence"` // Sequence number at which the chain breaks
+	TxID     string `json:"TxID"`     // Transaction that wrote the offending entry, if there is one
+	Reason   string `json:"Reason"`   // Why the chain breaks there
+}
+
+// Hash computes the hash of an entry over every field except EntryHash. Each
+// value is length-prefixed, so no two different entries share an encoding.
+func Hash(entry *Entry) string {
+	e := &encoder{h: sha256.New()}
+	e.writeString(domain)
+	e.writeString(Algorithm)
+
+	e.writeString(entry.EvidenceID)
+	e.writeInt(entry.Sequence)
+	e.writeString(entry.ModifiedBy)
+	e.writeString(entry.ModifiedAt)
+	e.writeString(entry.Action)
+	e.writeString(entry.Description)
+	e.writeString(entry.PrevState)
+	e.writeString(entry.TxID)
+	e.writeString(entry.PreviousIntegrity)
+	e.writeString(entry.Integrity)
+	e.writeString(entry.PreviousEntryHash)
+
+	return fmt.Sprintf("%x", e.h.Sum(nil))
+}
+
+// Link fills in the sequence number and hashes of an entry that follows head.
+// A nil head starts a new chain.
+func Link(entry *Entry, head *Head) *Head {
+	entry.Sequence = 0
+	entry.PreviousEntryHash = ""
+	if head != nil {
+		entry.Sequence = head.Sequence + 1
+		entry.PreviousEntryHash = head.EntryHash
+	}
+	entry.EntryHash = Hash(entry)
+
+	return &Head{
+		EvidenceID: entry.EvidenceID,
+		Sequence:   entry.Sequence,
+		EntryHash:  entry.EntryHash,
+	}
+}
+
+// Verify walks the entries of a chain in sequence order and returns the first
+// break, or nil if the chain is intact and ends at head. Each entry must
+// follow the one before it without gaps, carry its own hash, link to the hash
+// of the entry before it, and start from the integrity hash at which the entry
+// before it left the evidence record.
+func Verify(entries []*Entry, head *Head) *Break {
+	ordered := make([]*Entry, len(entries))
+	copy(ordered, entries)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Sequence < ordered[j].Sequence
+	})
+
+	var previous *Entry
+	for i, entry := range ordered {
+		broken := func(format string, args ...interface{}) *Break {
+			return &Break{Sequence: i, TxID: entry.TxID, Reason: fmt.Sprintf(format, args...)}
+		}
+
+		if entry.EntryHash == "" {
+			return broken("entry written in transaction %s is not part of the chain", entry.TxID)
+		}
+		if entry.Sequence != i {
+			return broken("expected entry %d but found entry %d", i, entry.Sequence)
+		}
+		if Hash(entry) != entry.EntryHash {
+			return broken("entry content does not match its hash")
+		}
+
+		if previous == nil {
+			if entry.PreviousEntryHash != "" {
+				return broken("first entry links to a previous entry")
+			}
+		} else {
+			if entry.PreviousEntryHash != previous.EntryHash {
+				return broken("entry does not link to the hash of entry %d", previous.Sequence)
+			}
+			if previous.Integrity != "" && entry.PreviousIntegrity != previous.Integrity {
+				return broken("entry does not start from the integrity hash left by entry %d", previous.Sequence)
+			}
+		}
+
+		previous = entry
+	}
+
+	switch {
+	case head == nil && previous == nil:
+		return nil
+	case head == nil:
+		return &Break{Sequence: previous.Sequence, TxID: previous.TxID, Reason: "chain has entries but no head"}
+	case previous == nil || previous.Sequence < head.Sequence:
+		return &Break{Sequence: head.Sequence, Reason: fmt.Sprintf("entry %d recorded by the chain head is missing", head.Sequence)}
+	case previous.Sequence > head.Sequence:
+		return &Break{Sequence: head.Sequence + 1, TxID: ordered[head.Sequence+1].TxID, Reason: "entries follow the chain head"}
+	case previous.EntryHash != head.EntryHash:
+		return &Break{Sequence: previous.Sequence, TxID: previous.TxID, Reason: "last entry does not match the chain head"}
+	}
+
+	return nil
+}
+
+// encoder writes values to a hash in the canonical encoding
+type encoder struct {
+	h hash.Hash
+}
+
+// writeInt writes an unsigned 64-bit big-endian integer
+func (e *encoder) writeInt(n int) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(n))
+	e.h.Write(buf[:])
+}
+
+// writeString writes the length of a string followed by its bytes
+func (e *encoder) writeString(s string) {
+	e.writeInt(len(s))
+	e.h.Write([]byte(s))
+}
@@ -0,0 +1,113 @@
+package historychain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// buildChain links n entries for one evidence item
+func buildChain(n int) ([]*Entry, *Head) {
+	var entries []*Entry
+	var head *Head
+	integrity := ""
+	for i := 0; i < n; i++ {
+		entry := &Entry{
+			EvidenceID:        "EV001",
+			ModifiedBy:        "officer1",
+			ModifiedAt:        "2024-01-01T00:00:00Z",
+			Action:            "update",
+			TxID:              string(rune('a' + i)),
+			PreviousIntegrity: integrity,
+			Integrity:         string(rune('A' + i)),
+		}
+		integrity = entry.Integrity
+		head = Link(entry, head)
+		entries = append(entries, entry)
+	}
+	return entries, head
+}
+
+func TestVerifyIntactChain(t *testing.T) {
+	entries, head := buildChain(3)
+	require.Nil(t, Verify(entries, head))
+	require.Nil(t, Verify(nil, nil))
+
+	// Order of the input does not matter
+	require.Nil(t, Verify([]*Entry{entries[2], entries[0], entries[1]}, head))
+}
+
+func TestVerifyReportsFirstBrokenLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(entries []*Entry, head *Head) ([]*Entry, *Head)
+		sequence int
+		reason   string
+	}{
+		{
+			name: "altered entry",
+			tamper: func(entries []*Entry, head *Head) ([]*Entry, *Head) {
+				entries[1].Description = "rewritten"
+				return entries, head
+			},
+			sequence: 1,
+			reason:   "entry content does not match its hash",
+		},
+		{
+			name: "removed entry",
+			tamper: func(entries []*Entry, head *Head) ([]*Entry, *Head) {
+				return []*Entry{entries[0], entries[2]}, head
+			},
+			sequence: 1,
+			reason:   "expected entry 1 but found entry 2",
+		},
+		{
+			name: "removed last entry",
+			tamper: func(entries []*Entry, head *Head) ([]*Entry, *Head) {
+				return entries[:2], head
+			},
+			sequence: 2,
+			reason:   "entry 2 recorded by the chain head is missing",
+		},
+		{
+			name: "rehashed entry",
+			tamper: func(entries []*Entry, head *Head) ([]*Entry, *Head) {
+				entries[1].Description = "rewritten"
+				entries[1].EntryHash = Hash(entries[1])
+				return entries, head
+			},
+			sequence: 2,
+			reason:   "entry does not link to the hash of entry 1",
+		},
+		{
+			name: "integrity gap",
+			tamper: func(entries []*Entry, head *Head) ([]*Entry, *Head) {
+				entries[2].PreviousIntegrity = "X"
+				entries[2].EntryHash = Hash(entries[2])
+				head.EntryHash = entries[2].EntryHash
+				return entries, head
+			},
+			sequence: 2,
+			reason:   "entry does not start from the integrity hash left by entry 1",
+		},
+		{
+			name: "unchained entry",
+			tamper: func(entries []*Entry, head *Head) ([]*Entry, *Head) {
+				entries[0].EntryHash = ""
+				return entries, head
+			},
+			sequence: 0,
+			reason:   "entry written in transaction a is not part of the chain",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries, head := test.tamper(buildChain(3))
+
+			broken := Verify(entries, head)
+			require.NotNil(t, broken)
+			require.Equal(t, test.sequence, broken.Sequence)
+			require.Equal(t, test.reason, broken.Reason)
+		})
+	}
+}
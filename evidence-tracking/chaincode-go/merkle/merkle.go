@@ -0,0 +1,197 @@
+// Package merkle builds the Merkle tree that commits to the files of an
+// evidence bundle. Leaves are the files sorted by path, and each leaf hashes a
+// file's path and SHA-256 digest. Leaf and interior hashes are domain
+// separated, and a node without a sibling is promoted to the next level
+// unchanged, as in RFC 6962. A proof shows that one file belongs to a bundle
+// without revealing any other file. The package depends only on the standard
+// library, so a proof exported from the ledger can be checked by anyone.
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+// Algorithm identifies how the tree is built
+const Algorithm = "sha256-sorted-paths-v1"
+
+// Prefixes that separate leaf hashes from interior hashes, so a leaf can never
+// be passed off as an interior node or the other way round
+const (
+	leafPrefix     = 0x00
+	interiorPrefix = 0x01
+)
+
+// File is one entry of a bundle manifest. Only the path and digest are
+// committed to by the tree; the size and CID help locate and check a copy of
+// the file.
+type File struct {
+	Path   string `json:"Path"`          // Path of the file within the bundle
+	Size   int64  `json:"Size"`          // Size of the file in bytes
+	SHA256 string `json:"SHA256"`        // Lowercase hex SHA-256 digest of the file
+	CID    string `json:"CID,omitempty"` // IPFS content identifier of the file, if it is stored on IPFS
+}
+
+// ProofStep is the sibling of a node on the path from a leaf to the root
+type ProofStep struct {
+	Hash string `json:"Hash"` // Hex hash of the sibling
+	Left bool   `json:"Left"` // Whether the sibling is on the left of the node
+}
+
+// Proof is the path from a file's leaf to the root of a bundle
+type Proof struct {
+	Steps []ProofStep `json:"Steps"`
+}
+
+// Root checks a manifest and returns the hex root of its tree
+func Root(files []File) (string, error) {
+	levels, err := build(files)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(levels[len(levels)-1][0]), nil
+}
+
+// Prove returns the proof that the file at path belongs to the manifest
+func Prove(files []File, path string) (*Proof, error) {
+	levels, err := build(files)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := sortedFiles(files)
+	index := sort.Search(len(sorted), func(i int) bool { return sorted[i].Path >= path })
+	if index == len(sorted) || sorted[index].Path != path {
+		return nil, fmt.Errorf("the bundle has no file %s", path)
+	}
+
+	proof := &Proof{Steps: []ProofStep{}}
+	for _, level := range levels[:len(levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof.Steps = append(proof.Steps, ProofStep{
+				Hash: hex.EncodeToString(level[sibling]),
+				Left: sibling < index,
+			})
+		}
+		index /= 2
+	}
+
+	return proof, nil
+}
+
+// Verify checks that a proof leads from the leaf of a file to root
+func Verify(root string, path string, digest string, proof *Proof) error {
+	if proof == nil {
+		return fmt.Errorf("no proof given")
+	}
+	err := checkDigest(digest)
+	if err != nil {
+		return err
+	}
+
+	node := leafHash(path, digest)
+	for i, step := range proof.Steps {
+		sibling, err := hex.DecodeString(step.Hash)
+		if err != nil || len(sibling) != sha256.Size {
+			return fmt.Errorf("step %d of the proof is not a SHA-256 hash", i)
+		}
+		if step.Left {
+			node = interiorHash(sibling, node)
+		} else {
+			node = interiorHash(node, sibling)
+		}
+	}
+
+	if hex.EncodeToString(node) != root {
+		return fmt.Errorf("the proof does not lead to the root of the bundle")
+	}
+	return nil
+}
+
+// build checks a manifest and returns every level of its tree, from the
+// leaves up to the root
+func build(files []File) ([][][]byte, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("a bundle needs at least one file")
+	}
+
+	sorted := sortedFiles(files)
+	level := make([][]byte, len(sorted))
+	for i, file := range sorted {
+		if file.Path == "" {
+			return nil, fmt.Errorf("every file in a bundle needs a path")
+		}
+		if i > 0 && sorted[i-1].Path == file.Path {
+			return nil, fmt.Errorf("the path %s appears more than once in the bundle", file.Path)
+		}
+		if file.Size < 0 {
+			return nil, fmt.Errorf("the size of %s is negative", file.Path)
+		}
+		err := checkDigest(file.SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file.Path, err)
+		}
+		level[i] = leafHash(file.Path, file.SHA256)
+	}
+
+	levels := [][][]byte{level}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+			} else {
+				next = append(next, interiorHash(level[i], level[i+1]))
+			}
+		}
+		levels = append(levels, next)
+		level = next
+	}
+
+	return levels, nil
+}
+
+// sortedFiles returns a copy of a manifest sorted by path
+func sortedFiles(files []File) []File {
+	sorted := make([]File, len(files))
+	copy(sorted, files)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Path < sorted[j].Path
+	})
+	return sorted
+}
+
+// checkDigest checks that a digest is a lowercase hex SHA-256 hash
+func checkDigest(digest string) error {
+	decoded, err := hex.DecodeString(digest)
+	if err != nil || len(decoded) != sha256.Size || hex.EncodeToString(decoded) != digest {
+		return fmt.Errorf("the digest %q is not a lowercase hex SHA-256 hash", digest)
+	}
+	return nil
+}
+
+// leafHash hashes the path and digest of a file, each length-prefixed
+func leafHash(path string, digest string) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	for _, value := range []string{path, digest} {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(value)))
+		h.Write(length[:])
+		h.Write([]byte(value))
+	}
+	return h.Sum(nil)
+}
+
+// interiorHash hashes two child nodes
+func interiorHash(left []byte, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{interiorPrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
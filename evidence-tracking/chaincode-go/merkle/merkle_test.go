@@ -0,0 +1,123 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// buildManifest returns a manifest of n files, in reverse path order
+func buildManifest(n int) []File {
+	var files []File
+	for i := n - 1; i >= 0; i-- {
+		digest := sha256.Sum256([]byte(fmt.Sprintf("file %d", i)))
+		files = append(files, File{
+			Path:   fmt.Sprintf("extraction/%03d.bin", i),
+			Size:   int64(i * 100),
+			SHA256: hex.EncodeToString(digest[:]),
+		})
+	}
+	return files
+}
+
+func TestProveAndVerifyEveryFile(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8, 13} {
+		files := buildManifest(n)
+		root, err := Root(files)
+		require.NoError(t, err)
+
+		for _, file := range files {
+			proof, err := Prove(files, file.Path)
+			require.NoError(t, err)
+			require.NoError(t, Verify(root, file.Path, file.SHA256, proof), "%d files, %s", n, file.Path)
+		}
+	}
+}
+
+func TestRootIgnoresManifestOrder(t *testing.T) {
+	files := buildManifest(5)
+	root, err := Root(files)
+	require.NoError(t, err)
+
+	reordered := []File{files[3], files[0], files[4], files[2], files[1]}
+	reorderedRoot, err := Root(reordered)
+	require.NoError(t, err)
+	require.Equal(t, root, reorderedRoot)
+}
+
+func TestVerifyRejectsWrongMembers(t *testing.T) {
+	files := buildManifest(5)
+	root, err := Root(files)
+	require.NoError(t, err)
+	proof, err := Prove(files, files[1].Path)
+	require.NoError(t, err)
+
+	other := sha256.Sum256([]byte("substituted file"))
+	tests := []struct {
+		name   string
+		path   string
+		digest string
+		proof  *Proof
+		err    string
+	}{
+		{
+			name:   "other digest",
+			path:   files[1].Path,
+			digest: hex.EncodeToString(other[:]),
+			proof:  proof,
+			err:    "the proof does not lead to the root of the bundle",
+		},
+		{
+			name:   "other path",
+			path:   "extraction/999.bin",
+			digest: files[1].SHA256,
+			proof:  proof,
+			err:    "the proof does not lead to the root of the bundle",
+		},
+		{
+			name:   "proof of another file",
+			path:   files[2].Path,
+			digest: files[2].SHA256,
+			proof:  proof,
+			err:    "the proof does not lead to the root of the bundle",
+		},
+		{
+			name:   "malformed digest",
+			path:   files[1].Path,
+			digest: "ABC",
+			proof:  proof,
+			err:    `the digest "ABC" is not a lowercase hex SHA-256 hash`,
+		},
+		{
+			name:   "missing proof",
+			path:   files[1].Path,
+			digest: files[1].SHA256,
+			err:    "no proof given",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.EqualError(t, Verify(root, test.path, test.digest, test.proof), test.err)
+		})
+	}
+}
+
+func TestRootRejectsInvalidManifests(t *testing.T) {
+	files := buildManifest(2)
+	duplicate := append(buildManifest(2), files[0])
+	unnamed := buildManifest(2)
+	unnamed[0].Path = ""
+
+	_, err := Root(nil)
+	require.EqualError(t, err, "a bundle needs at least one file")
+	_, err = Root(duplicate)
+	require.EqualError(t, err, "the path extraction/001.bin appears more than once in the bundle")
+	_, err = Root(unnamed)
+	require.EqualError(t, err, "every file in a bundle needs a path")
+
+	_, err = Prove(files, "extraction/999.bin")
+	require.EqualError(t, err, "the bundle has no file extraction/999.bin")
+}
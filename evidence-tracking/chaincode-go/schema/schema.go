@@ -0,0 +1,338 @@
+// Package schema validates JSON documents against a subset of JSON Schema.
+// Evidence metadata is checked with it on every peer, so it supports only
+// keywords whose meaning is unambiguous and whose checks are deterministic:
+//
+//	type, properties, required, additionalProperties (true or false), items,
+//	enum, minLength, maxLength, pattern, minimum, maximum, minItems, maxItems
+//
+// plus the annotations $schema, $id, title and description. Parse rejects a
+// schema that uses any other keyword, rather than silently not enforcing it.
+// The package depends only on the standard library.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// keywords are the keywords Parse accepts
+var keywords = map[string]bool{
+	"$schema": true, "$id": true, "title": true, "description": true,
+	"type": true, "properties": true, "required": true, "additionalProperties": true, "items": true,
+	"enum": true, "minLength": true, "maxLength": true, "pattern": true,
+	"minimum": true, "maximum": true, "minItems": true, "maxItems": true,
+}
+
+// types are the values the type keyword accepts
+var types = []string{"array", "boolean", "integer", "null", "number", "object", "string"}
+
+// Schema is a parsed schema
+type Schema struct {
+	types                []string
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *bool
+	items                *Schema
+	enum                 []interface{}
+	minLength            *int
+	maxLength            *int
+	pattern              *regexp.Regexp
+	minimum              *float64
+	maximum              *float64
+	minItems             *int
+	maxItems             *int
+}
+
+// Parse parses a schema, rejecting keywords this package does not enforce
+func Parse(data []byte) (*Schema, error) {
+	return parse(data, "")
+}
+
+// Validate checks a JSON document against the schema and returns an error
+// describing the first violation found. Object properties are checked in
+// sorted order, so the same document always gives the same error.
+func (s *Schema) Validate(document []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return fmt.Errorf("invalid JSON: %v", err)
+	}
+	if decoder.More() {
+		return fmt.Errorf("invalid JSON: unexpected data after the document")
+	}
+
+	return s.validate(value, "")
+}
+
+// parse parses the schema at the given JSON pointer
+func parse(data []byte, pointer string) (*Schema, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, fmt.Errorf("%s: a schema must be a JSON object", location(pointer))
+	}
+
+	names := sortedKeys(fields)
+	for _, name := range names {
+		if !keywords[name] {
+			return nil, fmt.Errorf("%s: unsupported keyword %s", location(pointer), name)
+		}
+	}
+
+	s := &Schema{}
+	for _, name := range names {
+		raw := fields[name]
+		at := pointer + "/" + name
+		switch name {
+		case "type":
+			s.types, err = parseTypes(raw)
+		case "properties":
+			s.properties, err = parseProperties(raw, at)
+			if err != nil {
+				return nil, err
+			}
+		case "required":
+			err = json.Unmarshal(raw, &s.required)
+		case "additionalProperties":
+			err = json.Unmarshal(raw, &s.additionalProperties)
+			if err != nil {
+				err = fmt.Errorf("only true or false is supported")
+			}
+		case "items":
+			s.items, err = parse(raw, at)
+			if err != nil {
+				return nil, err
+			}
+		case "enum":
+			err = decodeNumbers(raw, &s.enum)
+		case "minLength":
+			s.minLength, err = parseCount(raw)
+		case "maxLength":
+			s.maxLength, err = parseCount(raw)
+		case "minItems":
+			s.minItems, err = parseCount(raw)
+		case "maxItems":
+			s.maxItems, err = parseCount(raw)
+		case "pattern":
+			var pattern string
+			err = json.Unmarshal(raw, &pattern)
+			if err == nil {
+				s.pattern, err = regexp.Compile(pattern)
+			}
+		case "minimum":
+			err = json.Unmarshal(raw, &s.minimum)
+		case "maximum":
+			err = json.Unmarshal(raw, &s.maximum)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", location(at), err)
+		}
+	}
+
+	return s, nil
+}
+
+// parseTypes parses the type keyword, a type name or a list of them
+func parseTypes(raw json.RawMessage) ([]string, error) {
+	var names []string
+	var name string
+	if json.Unmarshal(raw, &name) == nil {
+		names = []string{name}
+	} else if err := json.Unmarshal(raw, &names); err != nil {
+		return nil, fmt.Errorf("must be a type name or a list of type names")
+	}
+
+	for _, name := range names {
+		i := sort.SearchStrings(types, name)
+		if i == len(types) || types[i] != name {
+			return nil, fmt.Errorf("unknown type %s", name)
+		}
+	}
+	return names, nil
+}
+
+// parseProperties parses the schemas of the properties keyword
+func parseProperties(raw json.RawMessage, pointer string) (map[string]*Schema, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(raw, &fields)
+	if err != nil {
+		return nil, fmt.Errorf("%s: must be an object of schemas", location(pointer))
+	}
+
+	properties := map[string]*Schema{}
+	for _, name := range sortedKeys(fields) {
+		properties[name], err = parse(fields[name], pointer+"/"+name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return properties, nil
+}
+
+// parseCount parses a keyword whose value is a non-negative integer
+func parseCount(raw json.RawMessage) (*int, error) {
+	var count int
+	err := json.Unmarshal(raw, &count)
+	if err != nil || count < 0 {
+		return nil, fmt.Errorf("must be a non-negative integer")
+	}
+	return &count, nil
+}
+
+// validate checks a decoded value against the schema
+func (s *Schema) validate(value interface{}, pointer string) error {
+	if len(s.types) > 0 && !s.hasTypeOf(value) {
+		return fmt.Errorf("%s: must be of type %s", location(pointer), strings.Join(s.types, " or "))
+	}
+
+	if s.enum != nil {
+		found := false
+		for _, allowed := range s.enum {
+			if reflect.DeepEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: is not one of the allowed values", location(pointer))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.minLength != nil && length < *s.minLength {
+			return fmt.Errorf("%s: must be at least %d characters long", location(pointer), *s.minLength)
+		}
+		if s.maxLength != nil && length > *s.maxLength {
+			return fmt.Errorf("%s: must be at most %d characters long", location(pointer), *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			return fmt.Errorf("%s: must match the pattern %s", location(pointer), s.pattern)
+		}
+
+	case json.Number:
+		number, err := v.Float64()
+		if err != nil {
+			return fmt.Errorf("%s: %v", location(pointer), err)
+		}
+		if s.minimum != nil && number < *s.minimum {
+			return fmt.Errorf("%s: must be at least %v", location(pointer), *s.minimum)
+		}
+		if s.maximum != nil && number > *s.maximum {
+			return fmt.Errorf("%s: must be at most %v", location(pointer), *s.maximum)
+		}
+
+	case []interface{}:
+		if s.minItems != nil && len(v) < *s.minItems {
+			return fmt.Errorf("%s: must have at least %d items", location(pointer), *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			return fmt.Errorf("%s: must have at most %d items", location(pointer), *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				err := s.items.validate(item, fmt.Sprintf("%s/%d", pointer, i))
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+	case map[string]interface{}:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: missing required property %s", location(pointer), name)
+			}
+		}
+		for _, name := range sortedKeys(v) {
+			property, ok := s.properties[name]
+			if !ok {
+				if s.additionalProperties != nil && !*s.additionalProperties {
+					return fmt.Errorf("%s: property %s is not allowed", location(pointer), name)
+				}
+				continue
+			}
+			err := property.validate(v[name], pointer+"/"+name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasTypeOf reports whether a decoded value has one of the schema's types
+func (s *Schema) hasTypeOf(value interface{}) bool {
+	for _, name := range s.types {
+		switch value.(type) {
+		case nil:
+			if name == "null" {
+				return true
+			}
+		case bool:
+			if name == "boolean" {
+				return true
+			}
+		case string:
+			if name == "string" {
+				return true
+			}
+		case json.Number:
+			if name == "number" || (name == "integer" && isInteger(value.(json.Number))) {
+				return true
+			}
+		case []interface{}:
+			if name == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if name == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isInteger reports whether a number has no fractional part
+func isInteger(number json.Number) bool {
+	f, err := number.Float64()
+	return err == nil && f == math.Trunc(f)
+}
+
+// decodeNumbers decodes JSON keeping numbers as json.Number, as Validate does
+func decodeNumbers(raw json.RawMessage, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// location describes a JSON pointer in an error message
+func location(pointer string) string {
+	if pointer == "" {
+		return "document"
+	}
+	return pointer
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
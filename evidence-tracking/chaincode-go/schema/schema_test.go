@@ -0,0 +1,125 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const videoSchema = `{
+	"type": "object",
+	"required": ["format", "duration"],
+	"additionalProperties": false,
+	"properties": {
+		"format": {"type": "string", "enum": ["mp4", "avi", "mov"]},
+		"duration": {"type": "string", "pattern": "^[0-9]{2}:[0-9]{2}:[0-9]{2}$"},
+		"location": {"type": "string", "minLength": 1, "maxLength": 200},
+		"fps": {"type": "integer", "minimum": 1, "maximum": 240},
+		"cameras": {"type": "array", "items": {"type": "string"}, "minItems": 1, "maxItems": 2}
+	}
+}`
+
+func TestValidate(t *testing.T) {
+	s, err := Parse([]byte(videoSchema))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		document string
+		err      string
+	}{
+		{
+			name:     "valid",
+			document: `{"format": "mp4", "duration": "00:32:15", "location": "Main St & 5th Ave", "fps": 30, "cameras": ["north"]}`,
+		},
+		{
+			name:     "not an object",
+			document: `["mp4"]`,
+			err:      "document: must be of type object",
+		},
+		{
+			name:     "missing required property",
+			document: `{"format": "mp4"}`,
+			err:      "document: missing required property duration",
+		},
+		{
+			name:     "additional property",
+			document: `{"format": "mp4", "duration": "00:32:15", "colour": true}`,
+			err:      "document: property colour is not allowed",
+		},
+		{
+			name:     "value not in enum",
+			document: `{"format": "mkv", "duration": "00:32:15"}`,
+			err:      "/format: is not one of the allowed values",
+		},
+		{
+			name:     "pattern mismatch",
+			document: `{"format": "mp4", "duration": "32 minutes"}`,
+			err:      "/duration: must match the pattern ^[0-9]{2}:[0-9]{2}:[0-9]{2}$",
+		},
+		{
+			name:     "empty string",
+			document: `{"format": "mp4", "duration": "00:32:15", "location": ""}`,
+			err:      "/location: must be at least 1 characters long",
+		},
+		{
+			name:     "fractional integer",
+			document: `{"format": "mp4", "duration": "00:32:15", "fps": 29.97}`,
+			err:      "/fps: must be of type integer",
+		},
+		{
+			name:     "number above maximum",
+			document: `{"format": "mp4", "duration": "00:32:15", "fps": 500}`,
+			err:      "/fps: must be at most 240",
+		},
+		{
+			name:     "too many items",
+			document: `{"format": "mp4", "duration": "00:32:15", "cameras": ["north", "south", "east"]}`,
+			err:      "/cameras: must have at most 2 items",
+		},
+		{
+			name:     "wrong item type",
+			document: `{"format": "mp4", "duration": "00:32:15", "cameras": [7]}`,
+			err:      "/cameras/0: must be of type string",
+		},
+		{
+			name:     "invalid JSON",
+			document: `{"format": `,
+			err:      "invalid JSON: unexpected EOF",
+		},
+		{
+			name:     "trailing data",
+			document: `{"format": "mp4", "duration": "00:32:15"} {}`,
+			err:      "invalid JSON: unexpected data after the document",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := s.Validate([]byte(test.document))
+			if test.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestParseRejectsUnsupportedSchemas(t *testing.T) {
+	tests := []struct {
+		schema string
+		err    string
+	}{
+		{`[]`, "document: a schema must be a JSON object"},
+		{`{"type": "object", "oneOf": []}`, "document: unsupported keyword oneOf"},
+		{`{"properties": {"a": {"format": "date"}}}`, "/properties/a: unsupported keyword format"},
+		{`{"type": "decimal"}`, "/type: unknown type decimal"},
+		{`{"additionalProperties": {"type": "string"}}`, "/additionalProperties: only true or false is supported"},
+		{`{"minLength": -1}`, "/minLength: must be a non-negative integer"},
+		{`{"pattern": "("}`, "/pattern: error parsing regexp: missing closing ): `(`"},
+	}
+	for _, test := range tests {
+		_, err := Parse([]byte(test.schema))
+		require.EqualError(t, err, test.err, test.schema)
+	}
+}
@@ -0,0 +1,298 @@
+// Package zkp implements a non-interactive zero-knowledge proof of knowledge
+// of the opening of a Pedersen commitment over the NIST P-256 curve.
+//
+// The custodian of an evidence file commits to the file's SHA-256 digest m
+// with a random blinding factor r:
+//
+//	C = m·G + r·H
+//
+// where G is the curve base point and H is a second generator derived by
+// hashing to the curve, so that nobody knows the discrete logarithm of H with
+// respect to G. The commitment hides the digest, and a proof shows that the
+// prover knows m and r without revealing either. Proofs use the Schnorr
+// protocol for two generators, made non-interactive with the Fiat-Shamir
+// transform: the prover picks random k1 and k2 and sends
+//
+//	T  = k1·G + k2·H
+//	c  = SHA-256(domain, G, H, C, T, context) mod n
+//	s1 = k1 + c·m mod n
+//	s2 = k2 + c·r mod n
+//
+// and the verifier accepts when s1·G + s2·H = T + c·C. The context binds a
+// proof to the evidence and verifier it was made for, so it cannot be replayed
+// for another.
+//
+// Only the standard library is used, and verification is deterministic, so
+// proofs can be checked by chaincode on every endorsing peer.
+package zkp
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// CurveName names the group the proofs are made in
+const CurveName = "P-256"
+
+// domain separates the hashes of this package from any other use of SHA-256
+const domain = "evidence-tracking/zkp/v1"
+
+var (
+	curve  = elliptic.P256()
+	order  = curve.Params().N
+	hx, hy = deriveGenerator(domain + "/H")
+)
+
+// Opening is the secret behind a commitment. It must be kept by the prover
+// and never sent to the ledger.
+type Opening struct {
+	Value    *big.Int // Committed value, the file digest reduced modulo the group order
+	Blinding *big.Int // Random blinding factor
+}
+
+// Proof is a proof of knowledge of the opening of a commitment. Points are
+// hex encoded in SEC 1 compressed form and scalars as 32 byte big-endian hex.
+type Proof struct {
+	Commitment       string `json:"Commitment"`       // Commitment C the proof is about
+	NonceCommitment  string `json:"NonceCommitment"`  // Prover's nonce commitment T
+	Challenge        string `json:"Challenge"`        // Fiat-Shamir challenge c
+	ResponseValue    string `json:"ResponseValue"`    // Response s1 for the committed value
+	ResponseBlinding string `json:"ResponseBlinding"` // Response s2 for the blinding factor
+}
+
+// Context returns the context that binds a proof to one evidence item and the
+// verifier it is made for. Prover and verifier must use the same context.
+func Context(evidenceID string, verifierID string) []byte {
+	return []byte(evidenceID + "\x00" + verifierID)
+}
+
+// GeneratorG returns the encoded base point of the group
+func GeneratorG() string {
+	params := curve.Params()
+	return encodePoint(params.Gx, params.Gy)
+}
+
+// GeneratorH returns the encoded second generator of the group
+func GeneratorH() string {
+	return encodePoint(hx, hy)
+}
+
+// Commit commits to the SHA-256 digest of a file with a fresh random blinding
+// factor, and returns the encoded commitment with its opening
+func Commit(digest []byte) (string, *Opening, error) {
+	if len(digest) != sha256.Size {
+		return "", nil, fmt.Errorf("digest must be %d bytes, not %d", sha256.Size, len(digest))
+	}
+
+	blinding, err := randomScalar(rand.Reader)
+	if err != nil {
+		return "", nil, err
+	}
+
+	opening := &Opening{
+		Value:    new(big.Int).Mod(new(big.Int).SetBytes(digest), order),
+		Blinding: blinding,
+	}
+	x, y := commit(opening.Value, opening.Blinding)
+
+	return encodePoint(x, y), opening, nil
+}
+
+// Prove proves knowledge of the opening of the commitment, bound to the given
+// context
+func Prove(commitment string, opening *Opening, context []byte) (*Proof, error) {
+	cx, cy, err := decodePoint(commitment)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commitment: %v", err)
+	}
+	if opening == nil || opening.Value == nil || opening.Blinding == nil {
+		return nil, errors.New("the opening of the commitment is required")
+	}
+	ox, oy := commit(opening.Value, opening.Blinding)
+	if ox.Cmp(cx) != 0 || oy.Cmp(cy) != 0 {
+		return nil, errors.New("the opening does not match the commitment")
+	}
+
+	k1, err := randomScalar(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	k2, err := randomScalar(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, ty := commit(k1, k2)
+	nonceCommitment := encodePoint(tx, ty)
+	c := challenge(commitment, nonceCommitment, context)
+
+	s1 := new(big.Int).Mul(c, opening.Value)
+	s1.Add(s1, k1).Mod(s1, order)
+	s2 := new(big.Int).Mul(c, opening.Blinding)
+	s2.Add(s2, k2).Mod(s2, order)
+
+	return &Proof{
+		Commitment:       commitment,
+		NonceCommitment:  nonceCommitment,
+		Challenge:        encodeScalar(c),
+		ResponseValue:    encodeScalar(s1),
+		ResponseBlinding: encodeScalar(s2),
+	}, nil
+}
+
+// Verify checks a proof against the context it must be bound to, and returns
+// an error describing why the proof is rejected, or nil if it is valid
+func Verify(proof *Proof, context []byte) error {
+	if proof == nil {
+		return errors.New("no proof given")
+	}
+
+	cx, cy, err := decodePoint(proof.Commitment)
+	if err != nil {
+		return fmt.Errorf("invalid commitment: %v", err)
+	}
+	tx, ty, err := decodePoint(proof.NonceCommitment)
+	if err != nil {
+		return fmt.Errorf("invalid nonce commitment: %v", err)
+	}
+	s1, err := decodeScalar(proof.ResponseValue)
+	if err != nil {
+		return fmt.Errorf("invalid value response: %v", err)
+	}
+	s2, err := decodeScalar(proof.ResponseBlinding)
+	if err != nil {
+		return fmt.Errorf("invalid blinding response: %v", err)
+	}
+
+	c := challenge(proof.Commitment, proof.NonceCommitment, context)
+	if proof.Challenge != encodeScalar(c) {
+		return errors.New("challenge does not match the commitments and context")
+	}
+
+	// s1·G + s2·H must equal T + c·C
+	lx, ly := commit(s1, s2)
+	ccx, ccy := curve.ScalarMult(cx, cy, c.Bytes())
+	rx, ry := curve.Add(tx, ty, ccx, ccy)
+	if lx.Cmp(rx) != 0 || ly.Cmp(ry) != 0 {
+		return errors.New("responses do not satisfy the verification equation")
+	}
+
+	return nil
+}
+
+// commit returns value·G + blinding·H
+func commit(value *big.Int, blinding *big.Int) (*big.Int, *big.Int) {
+	gx, gy := curve.ScalarBaseMult(scalarBytes(value))
+	bx, by := curve.ScalarMult(hx, hy, scalarBytes(blinding))
+	return curve.Add(gx, gy, bx, by)
+}
+
+// challenge derives the Fiat-Shamir challenge from everything the verifier
+// sees. Each input is length prefixed so that inputs cannot run together.
+func challenge(commitment string, nonceCommitment string, context []byte) *big.Int {
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(domain), []byte(GeneratorG()), []byte(GeneratorH()), []byte(commitment), []byte(nonceCommitment), context} {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(part)))
+		h.Write(length[:])
+		h.Write(part)
+	}
+
+	return new(big.Int).Mod(new(big.Int).SetBytes(h.Sum(nil)), order)
+}
+
+// deriveGenerator hashes a label to a point on the curve by try-and-increment.
+// The result has no known discrete logarithm with respect to the base point.
+func deriveGenerator(label string) (*big.Int, *big.Int) {
+	params := curve.Params()
+	three := big.NewInt(3)
+
+	for counter := uint32(0); ; counter++ {
+		var counterBytes [4]byte
+		binary.BigEndian.PutUint32(counterBytes[:], counter)
+		digest := sha256.Sum256(append([]byte(label), counterBytes[:]...))
+
+		// y² = x³ - 3x + b
+		x := new(big.Int).Mod(new(big.Int).SetBytes(digest[:]), params.P)
+		ySquared := new(big.Int).Exp(x, three, params.P)
+		ySquared.Sub(ySquared, new(big.Int).Mul(three, x))
+		ySquared.Add(ySquared, params.B)
+		ySquared.Mod(ySquared, params.P)
+
+		y := new(big.Int).ModSqrt(ySquared, params.P)
+		if y == nil {
+			continue
+		}
+		if y.Bit(0) == 1 {
+			y.Sub(params.P, y)
+		}
+		if curve.IsOnCurve(x, y) {
+			return x, y
+		}
+	}
+}
+
+// randomScalar returns a uniformly random non-zero scalar
+func randomScalar(random io.Reader) (*big.Int, error) {
+	for {
+		k, err := rand.Int(random, order)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate random scalar: %v", err)
+		}
+		if k.Sign() != 0 {
+			return k, nil
+		}
+	}
+}
+
+// encodePoint encodes a point in SEC 1 compressed form
+func encodePoint(x *big.Int, y *big.Int) string {
+	return hex.EncodeToString(elliptic.MarshalCompressed(curve, x, y))
+}
+
+// decodePoint decodes a compressed point, rejecting points not on the curve
+func decodePoint(encoded string) (*big.Int, *big.Int, error) {
+	data, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, err
+	}
+	x, y := elliptic.UnmarshalCompressed(curve, data)
+	if x == nil {
+		return nil, nil, errors.New("not a compressed point on " + CurveName)
+	}
+	return x, y, nil
+}
+
+// scalarBytes returns a scalar, reduced modulo the group order, as a fixed
+// length big-endian byte string
+func scalarBytes(k *big.Int) []byte {
+	return new(big.Int).Mod(k, order).FillBytes(make([]byte, (order.BitLen()+7)/8))
+}
+
+// encodeScalar hex encodes a scalar
+func encodeScalar(k *big.Int) string {
+	return hex.EncodeToString(scalarBytes(k))
+}
+
+// decodeScalar decodes a scalar, rejecting values outside the group order
+func decodeScalar(encoded string) (*big.Int, error) {
+	data, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != (order.BitLen()+7)/8 {
+		return nil, fmt.Errorf("scalar must be %d bytes", (order.BitLen()+7)/8)
+	}
+	k := new(big.Int).SetBytes(data)
+	if k.Cmp(order) >= 0 {
+		return nil, errors.New("scalar is not less than the group order")
+	}
+	return k, nil
+}
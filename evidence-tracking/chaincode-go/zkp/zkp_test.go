@@ -0,0 +1,70 @@
+package zkp
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveAndVerify(t *testing.T) {
+	digest := sha256.Sum256([]byte("surveillance footage"))
+	commitment, opening, err := Commit(digest[:])
+	require.NoError(t, err)
+
+	context := Context("EV001", "verifier1")
+	proof, err := Prove(commitment, opening, context)
+	require.NoError(t, err)
+	require.NoError(t, Verify(proof, context))
+
+	err = Verify(proof, Context("EV002", "verifier1"))
+	require.EqualError(t, err, "challenge does not match the commitments and context")
+}
+
+func TestVerifyRejectsTamperedProofs(t *testing.T) {
+	digest := sha256.Sum256([]byte("fingerprint scan"))
+	commitment, opening, err := Commit(digest[:])
+	require.NoError(t, err)
+	context := Context("EV002", "verifier1")
+
+	otherDigest := sha256.Sum256([]byte("another file"))
+	otherCommitment, _, err := Commit(otherDigest[:])
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		tamper func(proof *Proof)
+	}{
+		{"response value", func(proof *Proof) { proof.ResponseValue = proof.ResponseBlinding }},
+		{"response blinding", func(proof *Proof) { proof.ResponseBlinding = proof.ResponseValue }},
+		{"commitment", func(proof *Proof) { proof.Commitment = otherCommitment }},
+		{"nonce commitment", func(proof *Proof) { proof.NonceCommitment = otherCommitment }},
+		{"challenge", func(proof *Proof) { proof.Challenge = proof.ResponseValue }},
+		{"point encoding", func(proof *Proof) { proof.Commitment = "02ff" }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			proof, err := Prove(commitment, opening, context)
+			require.NoError(t, err)
+
+			test.tamper(proof)
+			require.Error(t, Verify(proof, context))
+		})
+	}
+}
+
+func TestProveRequiresMatchingOpening(t *testing.T) {
+	digest := sha256.Sum256([]byte("evidence"))
+	commitment, _, err := Commit(digest[:])
+	require.NoError(t, err)
+	_, otherOpening, err := Commit(digest[:])
+	require.NoError(t, err)
+
+	_, err = Prove(commitment, otherOpening, nil)
+	require.EqualError(t, err, "the opening does not match the commitment")
+}
+
+func TestGeneratorHIsOnCurve(t *testing.T) {
+	require.True(t, curve.IsOnCurve(hx, hy))
+	require.NotEqual(t, GeneratorG(), GeneratorH())
+}
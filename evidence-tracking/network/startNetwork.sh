@@ -43,10 +43,16 @@ function networkUp() {
   cd ../evidence-tracking/network
 }
 
-# Deploy the chaincode
+# Deploy the chaincode. Only Org1 is a member of the private details
+# collection, and peers of other organizations refuse to endorse transactions
+# that carry private details, so the endorsement policy must be one Org1 can
+# meet alone rather than the channel's default MAJORITY. Verified evidence is
+# still guarded by the key-level policies DesignateVerifiers sets.
 function deployChaincode() {
   cd ../../test-network
-  ./network.sh deployCC -ccn evidence -ccp ../evidence-tracking/chaincode -ccl go -c evidencechannel
+  ./network.sh deployCC -ccn evidence -ccp ../evidence-tracking/chaincode-go -ccl go -c evidencechannel \
+    -ccep "OR('Org1MSP.peer','Org2MSP.peer')" \
+    -cccg ../evidence-tracking/chaincode-go/collections_config.json
   cd ../evidence-tracking/network
 }
 
@@ -1,3 +0,0 @@
-module github.com/Varu19Git/fabric-samples/evidence-tracking/simple-chaincode
-
-go 1.18
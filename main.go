@@ -7,8 +7,8 @@ package main
 import (
 	"log"
 
-	"github.com/hyperledger/fabric-contract-api-go/contractapi"
-	"github.com/Varu19Git/fabric-samples/evidence-tracking/simple-chaincode/chaincode"
+	"github.com/Varu19Git/fabric-samples/evidence-tracking/chaincode-go/chaincode"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
 )
 
 func main() {
@@ -20,4 +20,4 @@ func main() {
 	if err := evidenceChaincode.Start(); err != nil {
 		log.Panicf("Error starting evidence-tracking chaincode: %v", err)
 	}
-} 
\ No newline at end of file
+}